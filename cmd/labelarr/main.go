@@ -2,34 +2,83 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/nullable-eth/labelarr/internal/anilist"
+	"github.com/nullable-eth/labelarr/internal/animelists"
+	"github.com/nullable-eth/labelarr/internal/bazarr"
 	"github.com/nullable-eth/labelarr/internal/config"
+	"github.com/nullable-eth/labelarr/internal/export"
+	"github.com/nullable-eth/labelarr/internal/imdb"
+	"github.com/nullable-eth/labelarr/internal/jellyfin"
+	"github.com/nullable-eth/labelarr/internal/justwatch"
+	"github.com/nullable-eth/labelarr/internal/letterboxd"
+	"github.com/nullable-eth/labelarr/internal/mal"
+	"github.com/nullable-eth/labelarr/internal/mappings"
+	"github.com/nullable-eth/labelarr/internal/mdblist"
 	"github.com/nullable-eth/labelarr/internal/media"
+	"github.com/nullable-eth/labelarr/internal/omdb"
+	"github.com/nullable-eth/labelarr/internal/overseerr"
 	"github.com/nullable-eth/labelarr/internal/plex"
+	"github.com/nullable-eth/labelarr/internal/provider"
 	"github.com/nullable-eth/labelarr/internal/radarr"
+	"github.com/nullable-eth/labelarr/internal/s3"
 	"github.com/nullable-eth/labelarr/internal/sonarr"
+	"github.com/nullable-eth/labelarr/internal/tautulli"
 	"github.com/nullable-eth/labelarr/internal/tmdb"
+	"github.com/nullable-eth/labelarr/internal/trakt"
+	"github.com/nullable-eth/labelarr/internal/tvdb"
 	"github.com/nullable-eth/labelarr/internal/utils"
 	"github.com/nullable-eth/labelarr/internal/version"
 	"github.com/nullable-eth/labelarr/internal/webhook"
+	"github.com/nullable-eth/labelarr/internal/wikidata"
 )
 
 func main() {
+	once := flag.Bool("once", false, "run a single full pass and exit (same as RUN_ONCE=true)")
+	flag.Parse()
+
 	fmt.Printf("[INFO] Labelarr v%s\n", version.Version)
 
 	cfg := config.Load()
+	cfg.RunOnce = cfg.RunOnce || *once
+	if cfg.ProcessItem != "" {
+		// Show the full matching and normalization trail for the one item.
+		cfg.VerboseLogging = true
+	}
 
 	if err := cfg.Validate(); err != nil {
 		fmt.Printf("[ERROR] Configuration error: %v\n", err)
 		os.Exit(1)
 	}
 
-	plexClient := plex.NewClient(cfg)
+	var server media.MediaServer
+	var throttle *utils.AdaptiveDelay
+	if cfg.UsesPlex() {
+		plexClient := plex.NewClient(cfg)
+		if cfg.AdaptiveDelay {
+			throttle = utils.NewAdaptiveDelay(cfg.ItemDelay, cfg.MaxItemDelay)
+			plexClient.SetAdaptiveDelay(throttle)
+		}
+		server = plexClient
+	} else {
+		jellyfinClient := jellyfin.NewClient(cfg.JellyfinURL, cfg.JellyfinAPIKey, cfg.JellyfinUserID)
+		if err := jellyfinClient.TestConnection(); err != nil {
+			fmt.Printf("[ERROR] Failed to connect to %s: %v\n", cfg.MediaServer, err)
+			os.Exit(1)
+		}
+		fmt.Printf("[OK] Successfully connected to %s\n", cfg.MediaServer)
+		server = jellyfinClient
+	}
 	tmdbClient := tmdb.NewClient(cfg)
 
 	if err := tmdbClient.TestConnection(); err != nil {
@@ -38,31 +87,205 @@ func main() {
 	}
 	fmt.Println("[OK] Successfully connected to TMDb")
 
-	var radarrClient *radarr.Client
+	var radarrClients []*radarr.Client
 	if cfg.UseRadarr {
-		radarrClient = radarr.NewClient(cfg.RadarrURL, cfg.RadarrAPIKey)
-		if err := radarrClient.TestConnection(); err != nil {
-			fmt.Printf("[ERROR] Failed to connect to Radarr: %v\n", err)
-			os.Exit(1)
+		for _, instance := range cfg.RadarrInstances {
+			radarrClient := radarr.NewClient(instance.URL, instance.APIKey, cfg.ArrCacheTTL)
+			radarrClient.SetProxyAuth(proxyAuth(instance))
+			if err := radarrClient.TestConnection(); err != nil {
+				fmt.Printf("[ERROR] Failed to connect to Radarr (%s_URL): %v\n", instance.Name, err)
+				os.Exit(1)
+			}
+			fmt.Printf("[OK] Successfully connected to Radarr (%s_URL)\n", instance.Name)
+			printHealth("Radarr", instance.Name, radarrClient)
+			radarrClients = append(radarrClients, radarrClient)
 		}
-		fmt.Println("[OK] Successfully connected to Radarr")
 	}
 
-	var sonarrClient *sonarr.Client
+	var sonarrClients []*sonarr.Client
 	if cfg.UseSonarr {
-		sonarrClient = sonarr.NewClient(cfg.SonarrURL, cfg.SonarrAPIKey)
-		if err := sonarrClient.TestConnection(); err != nil {
-			fmt.Printf("[ERROR] Failed to connect to Sonarr: %v\n", err)
+		for _, instance := range cfg.SonarrInstances {
+			sonarrClient := sonarr.NewClient(instance.URL, instance.APIKey, cfg.ArrCacheTTL)
+			sonarrClient.SetProxyAuth(proxyAuth(instance))
+			if err := sonarrClient.TestConnection(); err != nil {
+				fmt.Printf("[ERROR] Failed to connect to Sonarr (%s_URL): %v\n", instance.Name, err)
+				os.Exit(1)
+			}
+			fmt.Printf("[OK] Successfully connected to Sonarr (%s_URL)\n", instance.Name)
+			printHealth("Sonarr", instance.Name, sonarrClient)
+			sonarrClients = append(sonarrClients, sonarrClient)
+		}
+	}
+
+	var imdbClient *imdb.Client
+	if cfg.IMDbKeywords {
+		imdbClient = imdb.NewClient(cfg.DataDir, cfg.IMDbKeywordsMax, cfg.IMDbCacheTTL)
+		fmt.Println("[INFO] IMDb keywords enabled")
+	}
+
+	var tvdbClient *tvdb.Client
+	if cfg.TVDbKeywords != "" {
+		tvdbClient = tvdb.NewClient(cfg.TVDbAPIKey, cfg.TVDbPIN)
+		if err := tvdbClient.TestConnection(); err != nil {
+			fmt.Printf("[ERROR] Failed to connect to TVDB: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("[OK] Successfully connected to TVDB (TVDB_KEYWORDS=%s)\n", cfg.TVDbKeywords)
+	}
+
+	var omdbClient *omdb.Client
+	if len(cfg.OMDbScoreLabels) > 0 {
+		omdbClient = omdb.NewClient(cfg.OMDbAPIKey, cfg.DataDir, cfg.OMDbCacheTTL)
+		if err := omdbClient.TestConnection(); err != nil {
+			fmt.Printf("[ERROR] Failed to connect to OMDb: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("[OK] Successfully connected to OMDb")
+	}
+
+	var traktClient *trakt.Client
+	if len(cfg.TraktLists) > 0 {
+		traktClient = trakt.NewClient(cfg.TraktClientID, cfg.TraktClientSecret, cfg.DataDir)
+		if err := traktClient.TestConnection(); err != nil {
+			fmt.Printf("[ERROR] Failed to connect to Trakt: %v\n", err)
+			os.Exit(1)
+		}
+		if cfg.TraktClientSecret != "" {
+			if err := traktClient.Authorize(); err != nil {
+				fmt.Printf("[ERROR] Trakt authorization failed: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		fmt.Println("[OK] Successfully connected to Trakt")
+	}
+
+	var letterboxdClient *letterboxd.Client
+	if len(cfg.LetterboxdLists) > 0 {
+		letterboxdClient = letterboxd.NewClient(cfg.DataDir, cfg.LetterboxdRefresh)
+		fmt.Printf("[INFO] Letterboxd list labels enabled for %d list(s)\n", len(cfg.LetterboxdLists))
+	}
+
+	var mappingFile *mappings.File
+	if cfg.MappingFile != "" {
+		var err error
+		mappingFile, err = mappings.Open(cfg.MappingFile)
+		if err != nil {
+			fmt.Printf("[ERROR] Failed to load MAPPING_FILE: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("[INFO] Loaded label mappings from %s\n", cfg.MappingFile)
+	}
+
+	var keywordAliases *mappings.File
+	if cfg.KeywordAliasFile != "" {
+		var err error
+		keywordAliases, err = mappings.Open(cfg.KeywordAliasFile)
+		if err != nil {
+			fmt.Printf("[ERROR] Failed to load KEYWORD_ALIAS_FILE: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("[INFO] Loaded keyword aliases from %s\n", cfg.KeywordAliasFile)
+	}
+
+	var justwatchClient *justwatch.Client
+	if cfg.AvailabilitySource == "justwatch" {
+		justwatchClient = justwatch.NewClient(cfg.AvailabilityRegion)
+	}
+	if cfg.AvailabilitySource != "" {
+		fmt.Printf("[INFO] Streaming availability labels enabled (%s, region %s)\n", cfg.AvailabilitySource, cfg.AvailabilityRegion)
+	}
+
+	var tautulliClient *tautulli.Client
+	if cfg.UsesTautulli() {
+		tautulliClient = tautulli.NewClient(cfg.TautulliURL, cfg.TautulliAPIKey)
+		if err := tautulliClient.TestConnection(); err != nil {
+			fmt.Printf("[ERROR] Failed to connect to Tautulli: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("[OK] Successfully connected to Sonarr")
+		fmt.Println("[OK] Successfully connected to Tautulli")
+	}
+
+	var overseerrClient *overseerr.Client
+	if cfg.OverseerrLabels {
+		overseerrClient = overseerr.NewClient(cfg.OverseerrURL, cfg.OverseerrAPIKey)
+		if err := overseerrClient.TestConnection(); err != nil {
+			fmt.Printf("[ERROR] Failed to connect to Overseerr: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("[OK] Successfully connected to Overseerr")
+	}
+
+	var bazarrClient *bazarr.Client
+	if cfg.BazarrMissingLabel != "" {
+		bazarrClient = bazarr.NewClient(cfg.BazarrURL, cfg.BazarrAPIKey)
+		if err := bazarrClient.TestConnection(); err != nil {
+			fmt.Printf("[ERROR] Failed to connect to Bazarr: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("[OK] Successfully connected to Bazarr")
+	}
+
+	var keywordProvider *provider.Client
+	if cfg.KeywordProviderURL != "" {
+		keywordProvider = provider.NewClient(cfg.KeywordProviderURL, cfg.KeywordProviderToken, cfg.KeywordProviderTimeout)
+		fmt.Printf("[INFO] Keyword provider enabled: %s\n", cfg.KeywordProviderURL)
+	}
+
+	var wikidataClient *wikidata.Client
+	if len(cfg.WikidataAwards) > 0 {
+		awards, err := wikidata.ResolveAwards(cfg.WikidataAwards)
+		if err != nil {
+			fmt.Printf("[ERROR] Invalid WIKIDATA_AWARDS: %v\n", err)
+			os.Exit(1)
+		}
+		wikidataClient = wikidata.NewClient(awards, cfg.DataDir, cfg.WikidataCacheTTL)
+		fmt.Printf("[INFO] Wikidata award labels enabled for %d award(s)\n", len(awards))
+	}
+
+	var mdblistClient *mdblist.Client
+	if len(cfg.MDBListLists) > 0 {
+		mdblistClient = mdblist.NewClient()
+		fmt.Printf("[INFO] MDBList list labels enabled for %d list(s)\n", len(cfg.MDBListLists))
+	}
+
+	var anilistClient *anilist.Client
+	var malClient *mal.Client
+	var animeMapping *animelists.Mapping
+	if cfg.UsesLibrarySource("anilist") {
+		anilistClient = anilist.NewClient(cfg.AniListMinTagRank, cfg.DataDir, cfg.AniListCacheTTL)
+		fmt.Println("[INFO] AniList keywords enabled for anime libraries (LIBRARY_SOURCE_OVERRIDE)")
+	}
+	if len(cfg.MALLabels) > 0 {
+		malClient = mal.NewClient(cfg.DataDir, cfg.MALCacheTTL)
+		fmt.Printf("[INFO] MyAnimeList labels enabled: %s\n", strings.Join(cfg.MALLabels, ", "))
+	}
+	if anilistClient != nil || malClient != nil {
+		animeMapping = animelists.NewMapping(cfg.DataDir)
 	}
 
 	processor, err := media.NewProcessor(cfg, media.Clients{
-		Plex:   plexClient,
-		TMDb:   tmdbClient,
-		Radarr: radarrClient,
-		Sonarr: sonarrClient,
+		Server:     server,
+		TMDb:       tmdbClient,
+		Radarr:     radarrClients,
+		Sonarr:     sonarrClients,
+		IMDb:       imdbClient,
+		Trakt:      traktClient,
+		TVDb:       tvdbClient,
+		OMDb:       omdbClient,
+		Letterboxd: letterboxdClient,
+		AniList:    anilistClient,
+		AnimeMap:   animeMapping,
+		MAL:        malClient,
+		MDBList:    mdblistClient,
+		Wikidata:   wikidataClient,
+		Provider:   keywordProvider,
+		Mappings:   mappingFile,
+		Aliases:    keywordAliases,
+		JustWatch:  justwatchClient,
+		Tautulli:   tautulliClient,
+		Overseerr:  overseerrClient,
+		Bazarr:     bazarrClient,
+		Throttle:   throttle,
 	})
 	if err != nil {
 		fmt.Printf("[ERROR] Failed to initialize processor: %v\n", err)
@@ -70,21 +293,32 @@ func main() {
 	}
 
 	fmt.Println("[INFO] Starting Labelarr with TMDb Integration...")
-	fmt.Printf("[NET] Server: %s://%s:%s\n", cfg.Protocol, cfg.PlexServer, cfg.PlexPort)
+	if cfg.UsesPlex() {
+		fmt.Printf("[NET] Server: %s://%s:%s\n", cfg.Protocol, cfg.PlexServer, cfg.PlexPort)
+	} else {
+		fmt.Printf("[NET] Server: %s (%s)\n", cfg.JellyfinURL, cfg.MediaServer)
+	}
 
-	movieLibraries, tvLibraries := getLibraries(cfg, plexClient)
+	movieLibraries, tvLibraries := getLibraries(cfg, server)
 
 	if cfg.IsRemoveMode() {
+		exitOnSignal(processor)
 		handleRemoveMode(cfg, processor, movieLibraries, tvLibraries)
-		os.Exit(0)
+		flushAndExit(processor, 0)
+	}
+
+	if cfg.ProcessItem != "" {
+		exitOnSignal(processor)
+		handleProcessItem(cfg, processor, movieLibraries, tvLibraries)
+		flushAndExit(processor, 0)
 	}
 
 	handleNormalMode(cfg, processor, movieLibraries, tvLibraries)
 }
 
-func getLibraries(cfg *config.Config, plexClient *plex.Client) ([]plex.Library, []plex.Library) {
+func getLibraries(cfg *config.Config, server media.MediaServer) ([]plex.Library, []plex.Library) {
 	fmt.Println("[INFO] Fetching all libraries...")
-	libraries, err := plexClient.GetAllLibraries()
+	libraries, err := server.GetAllLibraries()
 	if err != nil {
 		fmt.Printf("[ERROR] Error fetching libraries: %v\n", err)
 		os.Exit(1)
@@ -109,8 +343,10 @@ func getLibraries(cfg *config.Config, plexClient *plex.Client) ([]plex.Library,
 			tvLibraries = append(tvLibraries, lib)
 		}
 	}
-	movieLibraries = filterExcluded(movieLibraries, utils.StringSet(cfg.MovieLibraryExclude), "movie")
-	tvLibraries = filterExcluded(tvLibraries, utils.StringSet(cfg.TVLibraryExclude), "TV")
+	// LIBRARY_EXCLUDE was checked by Validate, so this can't fail.
+	excludeNames, _ := utils.NewPatternList(cfg.LibraryExclude)
+	movieLibraries = filterExcluded(movieLibraries, utils.StringSet(cfg.MovieLibraryExclude), excludeNames, "movie")
+	tvLibraries = filterExcluded(tvLibraries, utils.StringSet(cfg.TVLibraryExclude), excludeNames, "TV")
 
 	if len(movieLibraries) == 0 && !cfg.ProcessTVShows() {
 		fmt.Println("[ERROR] No movie library found!")
@@ -125,13 +361,37 @@ func getLibraries(cfg *config.Config, plexClient *plex.Client) ([]plex.Library,
 	return movieLibraries, tvLibraries
 }
 
-func filterExcluded(libs []plex.Library, exclude map[string]bool, kind string) []plex.Library {
-	if len(exclude) == 0 {
+// printHealth logs the warnings and errors from an *arr instance's health
+// checks. They don't stop labelarr, but explain why matching may fail.
+func printHealth(service, name string, client interface{ HealthWarnings() ([]string, error) }) {
+	warnings, err := client.HealthWarnings()
+	if err != nil {
+		fmt.Printf("[WARN] Could not fetch %s health (%s_URL): %v\n", service, name, err)
+		return
+	}
+	for _, warning := range warnings {
+		fmt.Printf("[WARN] %s health (%s_URL): %s\n", service, name, warning)
+	}
+}
+
+// proxyAuth returns the reverse-proxy credentials configured for an *arr
+// instance, or nil when it has none.
+func proxyAuth(instance config.ArrInstance) *utils.ProxyAuth {
+	if len(instance.Headers) == 0 && instance.Username == "" {
+		return nil
+	}
+	return &utils.ProxyAuth{Headers: instance.Headers, Username: instance.Username, Password: instance.Password}
+}
+
+// filterExcluded drops libraries whose ID is in exclude or whose title
+// matches excludeNames (LIBRARY_EXCLUDE).
+func filterExcluded(libs []plex.Library, exclude map[string]bool, excludeNames *utils.PatternList, kind string) []plex.Library {
+	if len(exclude) == 0 && excludeNames.Len() == 0 {
 		return libs
 	}
 	kept := libs[:0]
 	for _, lib := range libs {
-		if exclude[lib.Key] {
+		if exclude[lib.Key] || excludeNames.Match(lib.Title) {
 			fmt.Printf("[INFO] Excluding %s library: %s (ID: %s)\n", kind, lib.Title, lib.Key)
 			continue
 		}
@@ -213,6 +473,52 @@ func handleRemoveMode(cfg *config.Config, processor *media.Processor, movieLibra
 	fmt.Println("\n[OK] Keyword removal completed. Exiting.")
 }
 
+// handleProcessItem processes the single PROCESS_ITEM item and exits non-zero
+// if it can't be found or processed.
+func handleProcessItem(cfg *config.Config, processor *media.Processor, movieLibraries, tvLibraries []plex.Library) {
+	var libraries []media.ItemLibrary
+	if cfg.ProcessMovies() {
+		forEachLibrary(cfg.MovieProcessAll, cfg.MovieLibraryID, movieLibraries, "Movies", func(id, name string) {
+			libraries = append(libraries, media.ItemLibrary{ID: id, Name: name, MediaType: media.MediaTypeMovie})
+		})
+	}
+	if cfg.ProcessTVShows() {
+		forEachLibrary(cfg.TVProcessAll, cfg.TVLibraryID, tvLibraries, "TV Shows", func(id, name string) {
+			libraries = append(libraries, media.ItemLibrary{ID: id, Name: name, MediaType: media.MediaTypeTV})
+		})
+	}
+
+	fmt.Printf("\n[INFO] Processing single item PROCESS_ITEM=%q across %d libraries...\n", cfg.ProcessItem, len(libraries))
+	if err := processor.ProcessItemByQuery(cfg.ProcessItem, libraries); err != nil {
+		fmt.Printf("[ERROR] %v\n", err)
+		flushAndExit(processor, 1)
+	}
+	fmt.Println("\n[OK] Item processed. Exiting.")
+}
+
+// flushAndExit writes storage changes still waiting for a batched save and
+// exits with code, or with 1 if they can't be written.
+func flushAndExit(processor *media.Processor, code int) {
+	if err := processor.FlushStorage(); err != nil {
+		fmt.Printf("[ERROR] Failed to flush storage: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(code)
+}
+
+// exitOnSignal saves storage and exits when SIGINT or SIGTERM arrives, for
+// the one-shot modes that have no scan to wind down.
+func exitOnSignal(processor *media.Processor) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigCh
+		fmt.Printf("\n[INFO] Received %s, saving storage and exiting...\n", sig)
+		processor.Stop()
+		flushAndExit(processor, 1)
+	}()
+}
+
 func handleNormalMode(cfg *config.Config, processor *media.Processor, movieLibraries, tvLibraries []plex.Library) {
 	displayLibrarySelection(cfg, movieLibraries, tvLibraries)
 
@@ -232,20 +538,51 @@ func handleNormalMode(cfg *config.Config, processor *media.Processor, movieLibra
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		sig := <-sigCh
-		fmt.Printf("\n[INFO] Received %s, shutting down...\n", sig)
+		fmt.Printf("\n[INFO] Received %s, shutting down after the current item...\n", sig)
 		if webhookServer != nil {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
 			_ = webhookServer.Stop(ctx)
 		}
+		scanner.shutdown()
+		if err := processor.FlushStorage(); err != nil {
+			fmt.Printf("[ERROR] Failed to flush storage: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("[OK] Shutdown complete")
 		os.Exit(0)
 	}()
 
+	if cfg.ExportTimer > 0 && cfg.HasExportEnabled() {
+		fmt.Printf("[INFO] Refreshing exports every %v without syncing keywords\n", cfg.ExportTimer)
+		go scanner.runExportTimer(cfg.ExportTimer)
+	}
+
 	if cfg.WebhookOnly {
 		fmt.Println("[INFO] WEBHOOK_ONLY=true: skipping startup full scan and periodic timer; webhook server is the only trigger")
 		select {}
 	}
 
+	if cfg.RunOnce {
+		fmt.Println("[INFO] RUN_ONCE=true: running a single full pass")
+		err := scanner.runAll()
+		if errors.Is(err, media.ErrStopped) {
+			// The signal handler flushes and exits.
+			select {}
+		}
+		if err != nil {
+			fmt.Printf("[ERROR] Run finished with errors: %v\n", err)
+			flushAndExit(processor, 1)
+		}
+		fmt.Println("[OK] Run complete. Exiting.")
+		flushAndExit(processor, 0)
+	}
+
+	if len(cfg.Schedules) > 0 {
+		runOnSchedule(cfg, scanner)
+		return
+	}
+
 	fmt.Printf("[INFO] Starting periodic processing interval: %v\n", cfg.ProcessTimer)
 
 	scanner.RunAll()
@@ -259,6 +596,38 @@ func handleNormalMode(cfg *config.Config, processor *media.Processor, movieLibra
 	}
 }
 
+// runOnSchedule runs a full scan at every time matched by one of the
+// SCHEDULE cron expressions, in the container's local time zone. Unlike
+// PROCESS_TIMER there is no scan at startup. Times that pass while a scan is
+// still running are skipped, so scans never overlap.
+func runOnSchedule(cfg *config.Config, scanner *scanRunner) {
+	var schedules []*utils.CronSchedule
+	for _, expr := range cfg.Schedules {
+		// Validate already checked every expression, so this can't fail.
+		schedule, _ := utils.ParseCron(expr)
+		schedules = append(schedules, schedule)
+	}
+	fmt.Printf("[INFO] Processing on schedule: %s\n", strings.Join(cfg.Schedules, "; "))
+
+	for {
+		var next time.Time
+		for _, schedule := range schedules {
+			if t := schedule.Next(time.Now()); !t.IsZero() && (next.IsZero() || t.Before(next)) {
+				next = t
+			}
+		}
+		if next.IsZero() {
+			fmt.Println("[WARN] SCHEDULE never matches; no scheduled scans will run")
+			select {}
+		}
+
+		fmt.Printf("[TIMER] Next scheduled scan at %s\n", next.Format("2006-01-02 15:04 MST"))
+		time.Sleep(time.Until(next))
+		fmt.Printf("\n[TIMER] Schedule triggered - processing at %s\n", time.Now().Format("15:04:05"))
+		scanner.RunAll()
+	}
+}
+
 // scanRunner implements webhook.Scanner. Used by both the periodic timer and
 // the /scan HTTP endpoint.
 type scanRunner struct {
@@ -266,61 +635,204 @@ type scanRunner struct {
 	processor *media.Processor
 	movieLibs []plex.Library
 	tvLibs    []plex.Library
+
+	// active counts in-flight scans so shutdown can wait for them.
+	active   sync.WaitGroup
+	mu       sync.Mutex
+	stopping bool
+
+	// scanMu keeps export-only runs from interleaving with scans, which
+	// share the exporter.
+	scanMu sync.Mutex
+}
+
+// begin registers a scan with active. It reports false once shutdown has
+// started, in which case the scan must not run.
+func (r *scanRunner) begin() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopping {
+		return false
+	}
+	r.active.Add(1)
+	return true
+}
+
+// shutdown stops the processor and waits for in-flight scans to finish the
+// item they are on and write their exports.
+func (r *scanRunner) shutdown() {
+	r.mu.Lock()
+	r.stopping = true
+	r.mu.Unlock()
+
+	r.processor.Stop()
+	r.active.Wait()
 }
 
 func (r *scanRunner) RunAll() {
+	_ = r.runAll()
+}
+
+// Track runs webhook work such as an import lookup as an in-flight scan.
+func (r *scanRunner) Track(fn func()) {
+	if !r.begin() {
+		return
+	}
+	defer r.active.Done()
+	fn()
+}
+
+// runAll scans every selected library, then prunes storage and writes
+// exports. Errors are logged as they happen; the returned error joins the
+// libraries that failed and any export failure, for RUN_ONCE's exit status.
+// A scan stopped by shutdown still writes what it exported so far and returns
+// media.ErrStopped.
+func (r *scanRunner) runAll() error {
+	if !r.begin() {
+		return media.ErrStopped
+	}
+	defer r.active.Done()
+	r.scanMu.Lock()
+	defer r.scanMu.Unlock()
+
+	r.processor.WaitQuietHours("Scan")
 	r.processor.ClearCaches()
 
+	var errs []error
 	if len(r.movieLibs) > 0 {
 		forEachLibrary(r.cfg.MovieProcessAll, r.cfg.MovieLibraryID, r.movieLibs, "Movies", func(id, name string) {
+			if r.processor.Stopped() {
+				return
+			}
 			fmt.Printf("[MOVIE] Processing library: %s (ID: %s)\n", name, id)
 			if err := r.processor.ProcessAllItems(id, name, media.MediaTypeMovie); err != nil {
 				fmt.Printf("[ERROR] Error processing movies: %v\n", err)
+				errs = append(errs, fmt.Errorf("library %s: %w", name, err))
 			}
 		})
 	}
 
 	if r.cfg.ProcessTVShows() {
 		forEachLibrary(r.cfg.TVProcessAll, r.cfg.TVLibraryID, r.tvLibs, "TV Shows", func(id, name string) {
+			if r.processor.Stopped() {
+				return
+			}
 			fmt.Printf("[TV] Processing TV library: %s (ID: %s)\n", name, id)
 			if err := r.processor.ProcessAllItems(id, name, media.MediaTypeTV); err != nil {
 				fmt.Printf("[ERROR] Error processing TV shows: %v\n", err)
+				errs = append(errs, fmt.Errorf("library %s: %w", name, err))
 			}
 		})
 	}
 
+	if r.processor.Stopped() {
+		// Pruning looks unseen items up one by one; leave it to the next
+		// full scan rather than delay shutdown.
+		errs = append(errs, media.ErrStopped)
+	} else {
+		r.processor.PruneDeleted()
+	}
+
 	if r.cfg.HasExportEnabled() {
-		writeExportFiles(r.cfg, r.processor)
+		if err := writeExportFiles(r.cfg, r.processor); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errors.Join(errs...)
 }
 
 func (r *scanRunner) RunLibrary(libraryID, libraryName string, mediaType media.MediaType) error {
+	if !r.begin() {
+		return media.ErrStopped
+	}
+	defer r.active.Done()
+	r.scanMu.Lock()
+	defer r.scanMu.Unlock()
+
+	r.processor.WaitQuietHours("Scan")
 	r.processor.ClearCaches()
 	tag := "[MOVIE]"
 	if mediaType == media.MediaTypeTV {
 		tag = "[TV]"
 	}
 	fmt.Printf("%s Processing library: %s (ID: %s)\n", tag, libraryName, libraryID)
-	if err := r.processor.ProcessAllItems(libraryID, libraryName, mediaType); err != nil {
+	err := r.processor.ProcessAllItems(libraryID, libraryName, mediaType)
+	if err != nil && !errors.Is(err, media.ErrStopped) {
 		return err
 	}
 	if r.cfg.HasExportEnabled() {
-		writeExportFiles(r.cfg, r.processor)
+		if exportErr := writeExportFiles(r.cfg, r.processor); exportErr != nil {
+			return exportErr
+		}
 	}
-	return nil
+	return err
+}
+
+// runExportTimer refreshes the exports every interval (EXPORT_TIMER).
+func (r *scanRunner) runExportTimer(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		_ = r.exportAll()
+	}
+}
+
+// exportAll rebuilds the exports of every selected library from the values
+// already on the server, without syncing keywords. A run that comes due
+// while a scan is in progress is skipped, since the scan writes exports
+// itself.
+func (r *scanRunner) exportAll() error {
+	if !r.begin() {
+		return media.ErrStopped
+	}
+	defer r.active.Done()
+	if !r.scanMu.TryLock() {
+		fmt.Println("[EXPORT] A scan is in progress; skipping this export-only run")
+		return nil
+	}
+	defer r.scanMu.Unlock()
+
+	r.processor.WaitQuietHours("Export")
+	fmt.Printf("\n[TIMER] Export timer triggered - exporting at %s\n", time.Now().Format("15:04:05"))
+
+	var errs []error
+	exportLibrary := func(mediaType media.MediaType) func(id, name string) {
+		return func(id, name string) {
+			if r.processor.Stopped() {
+				return
+			}
+			if err := r.processor.ExportLibrary(id, name, mediaType); err != nil && !errors.Is(err, media.ErrStopped) {
+				fmt.Printf("[ERROR] Error exporting library %s: %v\n", name, err)
+				errs = append(errs, fmt.Errorf("library %s: %w", name, err))
+			}
+		}
+	}
+	if len(r.movieLibs) > 0 {
+		forEachLibrary(r.cfg.MovieProcessAll, r.cfg.MovieLibraryID, r.movieLibs, "Movies", exportLibrary(media.MediaTypeMovie))
+	}
+	if r.cfg.ProcessTVShows() {
+		forEachLibrary(r.cfg.TVProcessAll, r.cfg.TVLibraryID, r.tvLibs, "TV Shows", exportLibrary(media.MediaTypeTV))
+	}
+
+	if err := writeExportFiles(r.cfg, r.processor); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
 }
 
-func writeExportFiles(cfg *config.Config, processor *media.Processor) {
+// writeExportFiles flushes the accumulated exports to disk. Failures are
+// logged and returned.
+func writeExportFiles(cfg *config.Config, processor *media.Processor) error {
 	fmt.Printf("\n[EXPORT] Writing export files to %s...\n", cfg.ExportLocation)
 	exporter := processor.GetExporter()
 	if exporter == nil {
-		return
+		return nil
 	}
 
 	totalSummary, err := exporter.GetExportSummary()
 	if err != nil {
 		fmt.Printf("[ERROR] Error getting export summary: %v\n", err)
-		return
+		return fmt.Errorf("export summary: %w", err)
 	}
 
 	totalAccumulated := 0
@@ -337,14 +849,100 @@ func writeExportFiles(cfg *config.Config, processor *media.Processor) {
 		fmt.Printf("[INFO] No matching items found for export labels\n")
 	}
 
+	if processor.Stopped() {
+		if cfg.ExportDiff {
+			fmt.Printf("[EXPORT] Scan was stopped early; skipping the export diff\n")
+		}
+		exporter.MarkIncomplete()
+	}
+
 	if err := exporter.FlushAll(); err != nil {
 		fmt.Printf("[ERROR] Failed to write export files: %v\n", err)
-		return
+		return fmt.Errorf("writing export files: %w", err)
 	}
 
-	if cfg.ExportMode == "json" {
+	switch cfg.ExportMode {
+	case "json":
 		fmt.Printf("[OK] Successfully wrote export data to export.json\n")
-	} else {
+	case "csv":
+		fmt.Printf("[OK] Successfully wrote export data to export.csv\n")
+	case "yaml":
+		fmt.Printf("[OK] Successfully wrote export data to export.yaml\n")
+	case "template":
+		fmt.Printf("[OK] Successfully wrote templated export files to library subdirectories\n")
+	case "m3u":
+		fmt.Printf("[OK] Successfully wrote playlists to library subdirectories\n")
+	case "links":
+		fmt.Printf("[OK] Successfully linked export files into library subdirectories\n")
+	case "prom":
+		fmt.Printf("[OK] Successfully wrote export metrics to labelarr.prom\n")
+	case "arr":
+		fmt.Printf("[OK] Successfully wrote TMDb ID lists to library subdirectories\n")
+	default:
 		fmt.Printf("[OK] Successfully wrote export files to library subdirectories\n")
 	}
+
+	if cfg.HasExportUploadEnabled() {
+		if err := uploadExportFiles(cfg, exporter); err != nil {
+			fmt.Printf("[ERROR] Failed to upload export files: %v\n", err)
+			return fmt.Errorf("uploading export files: %w", err)
+		}
+	}
+
+	if cfg.ExportWebhookURL != "" {
+		payload := exporter.WebhookPayload()
+		if err := export.PostWebhook(cfg.ExportWebhookURL, cfg.ExportWebhookHeaders, payload); err != nil {
+			fmt.Printf("[ERROR] Failed to post export webhook: %v\n", err)
+			return fmt.Errorf("posting export webhook: %w", err)
+		}
+		fmt.Printf("[OK] Posted %s webhook\n", payload.Event)
+	}
+	return nil
+}
+
+// uploadExportFiles copies the files written by the last flush to the
+// configured S3-compatible bucket, keeping their layout under EXPORT_S3_PREFIX.
+func uploadExportFiles(cfg *config.Config, exporter *export.Exporter) error {
+	files := exporter.WrittenFiles()
+	client := s3.NewClient(cfg.ExportS3Endpoint, cfg.ExportS3Region, cfg.ExportS3Bucket,
+		cfg.ExportS3AccessKey, cfg.ExportS3SecretKey)
+	prefix := strings.Trim(cfg.ExportS3Prefix, "/")
+
+	fmt.Printf("[EXPORT] Uploading %d export files to bucket %s...\n", len(files), cfg.ExportS3Bucket)
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join(exporter.ExportLocation(), filepath.FromSlash(name)))
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", name, err)
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "/" + name
+		}
+		if err := client.PutObject(key, data, exportContentType(name)); err != nil {
+			return err
+		}
+		if cfg.VerboseLogging {
+			fmt.Printf("   [EXPORT] Uploaded %s\n", key)
+		}
+	}
+	fmt.Printf("[OK] Uploaded export files to %s/%s\n", cfg.ExportS3Bucket, prefix)
+	return nil
+}
+
+// exportContentType returns the MIME type for an export file name.
+func exportContentType(name string) string {
+	switch filepath.Ext(name) {
+	case ".gz":
+		return "application/gzip"
+	case ".json":
+		return "application/json"
+	case ".csv":
+		return "text/csv; charset=utf-8"
+	case ".yaml":
+		return "application/yaml"
+	case ".m3u":
+		return "audio/x-mpegurl"
+	default:
+		return "text/plain; charset=utf-8"
+	}
 }
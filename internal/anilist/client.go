@@ -0,0 +1,155 @@
+package anilist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+const (
+	apiURL = "https://graphql.anilist.co"
+
+	// AniList allows 90 requests per minute but has been running at a
+	// degraded 30; stay under that.
+	requestsPerSecond   = 0.5
+	maxRateLimitRetries = 5
+)
+
+const mediaQuery = `query ($id: Int) {
+  Media(id: $id, type: ANIME) {
+    id
+    genres
+    tags { name rank isGeneralSpoiler isMediaSpoiler }
+  }
+}`
+
+// Client is an AniList GraphQL API client. No authentication is needed for
+// public media data.
+type Client struct {
+	minTagRank int
+	httpClient *http.Client
+	limiter    *utils.RateLimiter
+	cache      *utils.FileCache[[]string]
+}
+
+// NewClient creates an AniList client. Tags ranked below minTagRank are
+// ignored; results are cached in dataDir (in memory if empty) for cacheTTL.
+func NewClient(minTagRank int, dataDir string, cacheTTL time.Duration) *Client {
+	cachePath := ""
+	if dataDir != "" {
+		cachePath = filepath.Join(dataDir, "anilist_keywords.json")
+	}
+	return &Client{
+		minTagRank: minTagRank,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		limiter:    utils.NewRateLimiter(requestsPerSecond),
+		cache:      utils.NewFileCache[[]string](cachePath, cacheTTL),
+	}
+}
+
+// GetKeywords returns the normalized genres and tags of an AniList anime.
+// Spoiler tags and tags below the minimum rank are skipped.
+func (c *Client) GetKeywords(anilistID string) ([]string, error) {
+	if keywords, ok := c.cache.Get(anilistID); ok {
+		return keywords, nil
+	}
+
+	media, err := c.getMedia(anilistID)
+	if err != nil {
+		return nil, err
+	}
+	keywords := mediaKeywords(media, c.minTagRank)
+
+	if err := c.cache.Set(anilistID, keywords); err != nil {
+		fmt.Printf("[WARN] Failed to save AniList cache: %v\n", err)
+	}
+	return keywords, nil
+}
+
+// mediaKeywords returns the media's genres followed by its qualifying tags
+func mediaKeywords(media *Media, minTagRank int) []string {
+	keywords := append([]string{}, media.Genres...)
+	for _, tag := range media.Tags {
+		if tag.IsGeneralSpoiler || tag.IsMediaSpoiler || tag.Rank < minTagRank {
+			continue
+		}
+		keywords = append(keywords, tag.Name)
+	}
+	return utils.NormalizeKeywords(keywords)
+}
+
+func (c *Client) getMedia(anilistID string) (*Media, error) {
+	id, err := strconv.Atoi(anilistID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AniList ID %q", anilistID)
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"query":     mediaQuery,
+		"variables": map[string]int{"id": id},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding query: %w", err)
+	}
+
+	resp, err := c.post(payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("AniList API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result mediaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("AniList API error: %s", result.Errors[0].Message)
+	}
+	if result.Data.Media == nil {
+		return nil, fmt.Errorf("AniList anime %s not found", anilistID)
+	}
+	return result.Data.Media, nil
+}
+
+// post sends a GraphQL request through the rate limiter, backing off and
+// retrying when AniList answers 429.
+func (c *Client) post(payload []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(context.Background()); err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest("POST", apiURL, bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error making request: %w", err)
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= maxRateLimitRetries {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		backoff := time.Minute
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			backoff = time.Duration(seconds) * time.Second
+		}
+		c.limiter.Pause(backoff)
+	}
+}
@@ -0,0 +1,23 @@
+package anilist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMediaKeywords(t *testing.T) {
+	media := &Media{
+		Genres: []string{"Action", "Drama"},
+		Tags: []Tag{
+			{Name: "Military", Rank: 90},
+			{Name: "Time Skip", Rank: 70, IsMediaSpoiler: true},
+			{Name: "Male Protagonist", Rank: 40},
+		},
+	}
+
+	got := mediaKeywords(media, 60)
+	want := []string{"Action", "Drama", "Military"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mediaKeywords() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,27 @@
+package anilist
+
+// Tag represents an AniList media tag. Rank is how relevant the tag is to
+// the media (0-100).
+type Tag struct {
+	Name             string `json:"name"`
+	Rank             int    `json:"rank"`
+	IsGeneralSpoiler bool   `json:"isGeneralSpoiler"`
+	IsMediaSpoiler   bool   `json:"isMediaSpoiler"`
+}
+
+// Media represents the fields labelarr reads from an AniList anime
+type Media struct {
+	ID     int      `json:"id"`
+	Genres []string `json:"genres"`
+	Tags   []Tag    `json:"tags"`
+}
+
+// mediaResponse represents the GraphQL response for a Media query
+type mediaResponse struct {
+	Data struct {
+		Media *Media `json:"Media"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
@@ -0,0 +1,183 @@
+package animelists
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+// Anime databases don't share IDs with TMDb, and AniList/MAL can't be
+// queried by AniDB or TVDB ID. The community-maintained Fribb/anime-lists
+// mapping ties them together; it is downloaded once and refreshed weekly.
+const (
+	mappingURL = "https://raw.githubusercontent.com/Fribb/anime-lists/master/anime-list-full.json"
+	mappingTTL = 7 * 24 * time.Hour
+)
+
+// Entry is one anime in the mapping. IDs are kept as strings since the
+// upstream file mixes numbers and strings; absent IDs are empty.
+type Entry struct {
+	AniDB   ID     `json:"anidb_id"`
+	AniList ID     `json:"anilist_id"`
+	MAL     ID     `json:"mal_id"`
+	TVDB    ID     `json:"thetvdb_id"`
+	TMDb    ID     `json:"themoviedb_id"`
+	IMDb    ID     `json:"imdb_id"`
+	Season  Season `json:"season"`
+}
+
+// Season is the TVDB season an entry corresponds to (anime seasons are
+// usually separate AniDB/AniList entries).
+type Season struct {
+	TVDB int `json:"tvdb"`
+}
+
+// ID accepts a JSON number or string
+type ID string
+
+func (id *ID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = ID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err == nil {
+		*id = ID(n.String())
+		return nil
+	}
+	*id = ""
+	return nil
+}
+
+// Mapping looks up anime entries by any of their external IDs
+type Mapping struct {
+	cache      *utils.FileCache[[]Entry]
+	httpClient *http.Client
+	mu         sync.Mutex
+	loaded     time.Time
+	index      map[string]Entry // "anidb:1", "tvdb:81797", "tmdb:129", "imdb:tt0245429"
+}
+
+// NewMapping creates a mapping persisted to dataDir (in memory if empty).
+// Nothing is downloaded until the first lookup.
+func NewMapping(dataDir string) *Mapping {
+	path := ""
+	if dataDir != "" {
+		path = filepath.Join(dataDir, "anime_mapping.json")
+	}
+	return &Mapping{
+		cache:      utils.NewFileCache[[]Entry](path, mappingTTL),
+		httpClient: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// Lookup returns the entry for an external ID. source is "anidb", "tvdb",
+// "tmdb" or "imdb". TVDB IDs resolve to the entry for the first season.
+func (m *Mapping) Lookup(source, id string) (Entry, bool, error) {
+	if err := m.load(); err != nil {
+		return Entry{}, false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.index[source+":"+id]
+	return entry, ok, nil
+}
+
+func (m *Mapping) load() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.index != nil && time.Since(m.loaded) < mappingTTL {
+		return nil
+	}
+
+	entries, ok := m.cache.Get("entries")
+	if !ok {
+		fetched, err := m.download()
+		if err != nil {
+			if m.index != nil {
+				// Keep serving the stale mapping rather than failing every lookup.
+				fmt.Printf("[WARN] Failed to refresh anime ID mapping: %v\n", err)
+				m.loaded = time.Now()
+				return nil
+			}
+			return err
+		}
+		entries = fetched
+		if err := m.cache.Set("entries", entries); err != nil {
+			fmt.Printf("[WARN] Failed to save anime ID mapping: %v\n", err)
+		}
+	}
+
+	m.index = buildIndex(entries)
+	m.loaded = time.Now()
+	return nil
+}
+
+func (m *Mapping) download() ([]Entry, error) {
+	resp, err := m.httpClient.Get(mappingURL)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading anime ID mapping: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("anime ID mapping download failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var entries []Entry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("error decoding anime ID mapping: %w", err)
+	}
+	return entries, nil
+}
+
+// buildIndex indexes entries by each of their IDs. Where several entries
+// share a TVDB, TMDb or IMDb ID (one per season), the lowest regular TVDB
+// season wins so a show maps to its first season rather than its specials.
+func buildIndex(entries []Entry) map[string]Entry {
+	index := make(map[string]Entry, len(entries)*2)
+	add := func(key string, entry Entry) {
+		if existing, ok := index[key]; ok && seasonRank(existing) <= seasonRank(entry) {
+			return
+		}
+		index[key] = entry
+	}
+	for _, entry := range entries {
+		if entry.AniDB != "" {
+			index["anidb:"+string(entry.AniDB)] = entry
+		}
+		if _, err := strconv.Atoi(string(entry.TVDB)); err == nil {
+			add("tvdb:"+string(entry.TVDB), entry)
+		}
+		for _, id := range strings.Split(string(entry.TMDb), ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				add("tmdb:"+id, entry)
+			}
+		}
+		for _, id := range strings.Split(string(entry.IMDb), ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				add("imdb:"+id, entry)
+			}
+		}
+	}
+	return index
+}
+
+// seasonRank orders entries by TVDB season, with specials (season 0) and
+// entries without a season last.
+func seasonRank(entry Entry) int {
+	if entry.Season.TVDB <= 0 {
+		return int(^uint(0) >> 1)
+	}
+	return entry.Season.TVDB
+}
@@ -0,0 +1,37 @@
+package animelists
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildIndex(t *testing.T) {
+	data := `[
+		{"anidb_id": 4563, "anilist_id": 1, "mal_id": 1, "thetvdb_id": 76885, "season": {"tvdb": 1}},
+		{"anidb_id": 5, "anilist_id": 5, "thetvdb_id": 76885, "season": {"tvdb": 0}},
+		{"anidb_id": 9, "anilist_id": 9, "thetvdb_id": "unknown", "themoviedb_id": "129,130", "imdb_id": "tt0245429"}
+	]`
+	var entries []Entry
+	if err := json.Unmarshal([]byte(data), &entries); err != nil {
+		t.Fatalf("failed to decode entries: %v", err)
+	}
+
+	index := buildIndex(entries)
+	tests := []struct {
+		key         string
+		wantAniList ID
+	}{
+		{"anidb:5", "5"},
+		{"tvdb:76885", "1"},
+		{"tmdb:130", "9"},
+		{"imdb:tt0245429", "9"},
+	}
+	for _, tt := range tests {
+		if got := index[tt.key].AniList; got != tt.wantAniList {
+			t.Errorf("index[%q].AniList = %q, want %q", tt.key, got, tt.wantAniList)
+		}
+	}
+	if _, ok := index["tvdb:unknown"]; ok {
+		t.Errorf("expected non-numeric TVDB IDs to be skipped")
+	}
+}
@@ -0,0 +1,135 @@
+package bazarr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+// wantedPageSize is how many wanted entries are requested per page.
+const wantedPageSize = 500
+
+// Client is a Bazarr API client. Bazarr identifies movies and shows by their
+// Radarr and Sonarr IDs.
+type Client struct {
+	baseURL     string
+	apiKey      string
+	httpClient  *http.Client
+	retryClient *utils.RetryableHTTPClient
+}
+
+// NewClient creates a Bazarr client
+func NewClient(baseURL, apiKey string) *Client {
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	return &Client{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		apiKey:      apiKey,
+		httpClient:  httpClient,
+		retryClient: utils.NewRetryableHTTPClient(httpClient, nil),
+	}
+}
+
+func (c *Client) makeRequest(endpoint string, params url.Values) (*http.Response, error) {
+	fullURL := c.baseURL + endpoint
+	if len(params) > 0 {
+		fullURL += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("X-API-KEY", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.retryClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		return nil, fmt.Errorf("bazarr API rejected the key (status 401) - check BAZARR_API_KEY")
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("bazarr API returned status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// getWanted fetches a full wanted list, following pagination.
+func (c *Client) getWanted(endpoint string) ([]WantedEntry, error) {
+	var entries []WantedEntry
+	for start := 0; ; start += wantedPageSize {
+		params := url.Values{}
+		params.Set("start", strconv.Itoa(start))
+		params.Set("length", strconv.Itoa(wantedPageSize))
+
+		resp, err := c.makeRequest(endpoint, params)
+		if err != nil {
+			return nil, err
+		}
+		var page wantedPage
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error decoding wanted list: %w", err)
+		}
+
+		entries = append(entries, page.Data...)
+		if len(page.Data) < wantedPageSize || len(entries) >= page.Total {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// GetMoviesMissingSubtitles returns the Radarr IDs of movies with missing
+// subtitles.
+func (c *Client) GetMoviesMissingSubtitles() (map[int]bool, error) {
+	entries, err := c.getWanted("/api/movies/wanted")
+	if err != nil {
+		return nil, err
+	}
+	return missingIDs(entries, func(e WantedEntry) int { return e.RadarrID }), nil
+}
+
+// GetSeriesMissingSubtitles returns the Sonarr series IDs of shows with at
+// least one episode missing subtitles.
+func (c *Client) GetSeriesMissingSubtitles() (map[int]bool, error) {
+	entries, err := c.getWanted("/api/episodes/wanted")
+	if err != nil {
+		return nil, err
+	}
+	return missingIDs(entries, func(e WantedEntry) int { return e.SonarrSeriesID }), nil
+}
+
+// missingIDs collects the IDs of entries that still miss a subtitle.
+func missingIDs(entries []WantedEntry, id func(WantedEntry) int) map[int]bool {
+	ids := make(map[int]bool)
+	for _, entry := range entries {
+		if len(entry.MissingSubtitles) > 0 && id(entry) != 0 {
+			ids[id(entry)] = true
+		}
+	}
+	return ids
+}
+
+// TestConnection verifies the URL and API key.
+func (c *Client) TestConnection() error {
+	resp, err := c.makeRequest("/api/system/status", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,24 @@
+package bazarr
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMissingIDs(t *testing.T) {
+	body := `[
+		{"seriesTitle": "Show", "sonarrSeriesId": 7, "sonarrEpisodeId": 70, "missing_subtitles": [{"name": "English", "code2": "en"}]},
+		{"seriesTitle": "Show", "sonarrSeriesId": 7, "sonarrEpisodeId": 71, "missing_subtitles": [{"name": "French", "code2": "fr"}]},
+		{"seriesTitle": "Other", "sonarrSeriesId": 9, "sonarrEpisodeId": 90, "missing_subtitles": []}
+	]`
+	var entries []WantedEntry
+	if err := json.Unmarshal([]byte(body), &entries); err != nil {
+		t.Fatalf("failed to decode wanted entries: %v", err)
+	}
+
+	got := missingIDs(entries, func(e WantedEntry) int { return e.SonarrSeriesID })
+	if want := map[int]bool{7: true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("missingIDs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,25 @@
+package bazarr
+
+// WantedEntry is a movie or episode from Bazarr's wanted lists, i.e. one
+// with subtitles that are wanted but not yet downloaded
+type WantedEntry struct {
+	Title            string     `json:"title"`
+	RadarrID         int        `json:"radarrId,omitempty"`
+	SonarrSeriesID   int        `json:"sonarrSeriesId,omitempty"`
+	SonarrEpisodeID  int        `json:"sonarrEpisodeId,omitempty"`
+	MissingSubtitles []Subtitle `json:"missing_subtitles"`
+}
+
+// Subtitle is a subtitle language Bazarr is looking for
+type Subtitle struct {
+	Name   string `json:"name"`
+	Code2  string `json:"code2"`
+	Code3  string `json:"code3"`
+	Forced bool   `json:"forced"`
+	HI     bool   `json:"hi"`
+}
+
+type wantedPage struct {
+	Data  []WantedEntry `json:"data"`
+	Total int           `json:"total"`
+}
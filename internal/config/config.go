@@ -3,9 +3,13 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
 )
 
 // Config holds all application configuration
@@ -21,31 +25,86 @@ type Config struct {
 	TVLibraryID            string
 	TVProcessAll           bool
 	TVLibraryExclude       []string
+	LibraryExclude         []string // library names, globs or /regex/
 	ExcludeLabels          []string
 	WebhookOnly            bool
+	RunOnce                bool
+	ProcessItem            string
 	UpdateField            string
 	RemoveMode             string
 	TMDbReadAccessToken    string
 	ProcessTimer           time.Duration
+	Schedules              []string // cron expressions; replace ProcessTimer when set
+	NoProcessWindows       []string // daily "HH:MM-HH:MM" quiet hours
+
+	// Media server configuration (Plex unless MEDIA_SERVER says otherwise)
+	MediaServer    string
+	JellyfinURL    string
+	JellyfinAPIKey string
+	JellyfinUserID string
 
 	// Radarr configuration
-	RadarrURL    string
-	RadarrAPIKey string
-	UseRadarr    bool
+	UseRadarr       bool
+	RadarrInstances []ArrInstance // RADARR_URL, then RADARR_1_URL, RADARR_2_URL, ...
 
 	// Sonarr configuration
-	SonarrURL    string
-	SonarrAPIKey string
-	UseSonarr    bool
+	UseSonarr       bool
+	SonarrInstances []ArrInstance // SONARR_URL, then SONARR_1_URL, SONARR_2_URL, ...
+
+	// Radarr/Sonarr cache configuration
+	ArrCacheTTL time.Duration
+
+	// Radarr/Sonarr label configuration
+	RadarrQualityLabels         []string // "profile", "file"
+	RadarrQualityLabelFormat    string
+	RadarrTagSync               bool
+	RadarrTagSyncLabels         []string // labels to mirror; empty mirrors all
+	ArrKeywordTags              bool
+	ArrKeywordTagPrefix         string
+	ArrKeywordTagAllowlist      []string // lowercased keywords to push; empty pushes all
+	RadarrCollectionLabels      bool
+	RadarrCollectionLabelFormat string
+	RadarrCollections           bool
+	SonarrTagLabels             bool
+	SonarrTagLabelPrefix        string
+	ArrStatusLabels             map[string]string // "monitored", "unmonitored" or lowercased status -> label
+	SonarrSeriesTypeLabels      map[string]string // "standard", "daily", "anime" -> label
+	SonarrCompletionLabels      map[string]string // "complete", "incomplete", "downloading" -> label
+	RadarrMissingLabel          string
+	SonarrMissingLabel          string
+	RadarrLanguageLabels        bool
+	RadarrLanguageLabelFormat   string
+	RadarrImportListLabels      bool
+	RadarrImportListLabelFormat string
+
+	// Radarr/Sonarr filter configuration
+	RadarrTagFilter []string // lowercased; "!tag" excludes
+	SonarrTagFilter []string // lowercased; "!tag" excludes
 
 	// Logging configuration
 	VerboseLogging bool
 
 	// Storage configuration
-	DataDir string
+	DataDir      string
+	PruneDeleted bool
+	ResumeRuns   bool
+	SaveEvery    int
+	SaveInterval time.Duration
 
 	// Force update configuration
 	ForceUpdate bool
+	SyncMode    string // "additive" or "exact"
+
+	// Label ownership configuration
+	ProtectManualLabels bool
+
+	// Genre seeding configuration
+	SeedGenres     bool
+	SeedGenresLock bool
+
+	// Change detection configuration
+	ChangeDetection         bool
+	ChangeDetectionLookback time.Duration
 
 	// Webhook configuration
 	WebhookEnabled  bool
@@ -55,15 +114,195 @@ type Config struct {
 	// Keyword prefix configuration
 	KeywordPrefix string
 
+	// TMDb keyword filtering configuration
+	TMDbKeywordIDBlacklist []string
+
+	// Keyword filter configuration
+	KeywordWhitelist []string // exact names, globs or /regex/
+	KeywordBlacklist []string // exact names, globs or /regex/
+	KeywordPattern   string   // regular expression keywords must match
+	KeywordExclude   string   // regular expression keywords must not match
+	KeywordMinItems  int
+	MaxKeywords      int
+	KeywordPriority  string // "relevance" or "frequency"
+
+	// IMDb keyword configuration
+	IMDbKeywords    bool
+	IMDbKeywordsMax int
+	IMDbCacheTTL    time.Duration
+
+	// TVDB keyword configuration
+	TVDbKeywords string
+	TVDbAPIKey   string
+	TVDbPIN      string
+
+	// NFO keyword configuration (Plex path prefix -> local path prefix)
+	NFOKeywords     bool
+	NFOPathMappings map[string]string
+
+	// Keyword provider configuration
+	KeywordProviderURL     string
+	KeywordProviderToken   string
+	KeywordProviderTimeout time.Duration
+
+	// AniList keyword configuration (library ID -> keyword source)
+	LibrarySourceOverride map[string]string
+	AniListMinTagRank     int
+	AniListCacheTTL       time.Duration
+
+	// MyAnimeList label configuration (genres, themes, demographics)
+	MALLabels   []string
+	MALCacheTTL time.Duration
+
+	// Studio label configuration
+	StudioLabels         bool
+	StudioLabelAllowlist []string
+
+	// Language/country label configuration
+	LanguageLabels       bool
+	LanguageLabelMap     map[string]string
+	LanguageLabelExclude []string
+	CountryLabels        bool
+	CountryLabelMap      map[string]string
+
+	// Audio language label configuration
+	AudioLanguageLabels      bool
+	AudioLanguageLabelFormat string
+	DualAudioLabel           string
+
+	// Quality label configuration
+	QualityLabels   []string          // "resolution", "hdr"
+	QualityLabelMap map[string]string // lowercased label -> replacement
+
+	// Size label configuration
+	SizeLabels map[string]string // rule ("size>60", "bitrate<4") -> label
+
+	// Decade label configuration
+	DecadeLabels bool
+
+	// Adult label configuration
+	AdultLabel string
+
+	// Age label configuration
+	AgeProviders            []string
+	AgeCertificationCountry string
+	AgeOverrideFile         string
+	AgeLabelFormat          string
+
+	// Mapping file configuration
+	MappingFile      string
+	KeywordAliasFile string
+
+	// Plex similar-cluster configuration (seed rating key -> label)
+	PlexSimilarSeeds       map[string]string
+	PlexSimilarHubs        []string
+	PlexSimilarLabelFormat string
+
+	// TMDb list label configuration (list ID -> label)
+	TMDbListLabels map[string]string
+
+	// OMDb score label configuration ("source:min" -> label)
+	OMDbAPIKey      string
+	OMDbScoreLabels map[string]string
+	OMDbCacheTTL    time.Duration
+
+	// Wikidata award label configuration (award alias or QID -> label, "" = default)
+	WikidataAwards   map[string]string
+	WikidataCacheTTL time.Duration
+
+	// Trakt list label configuration (list -> label)
+	TraktClientID     string
+	TraktClientSecret string
+	TraktLists        map[string]string
+
+	// Letterboxd list label configuration (list URL, username or CSV path -> label, "" = list title)
+	LetterboxdLists   map[string]string
+	LetterboxdRefresh time.Duration
+
+	// MDBList list label configuration (list URL or user/slug -> label, "" = list name)
+	MDBListLists map[string]string
+
+	// Streaming availability label configuration
+	AvailabilitySource      string
+	AvailabilityRegion      string
+	AvailabilityProviders   []string
+	AvailabilityLabelFormat string
+	LeavingSoonDays         int
+	LeavingSoonLabelFormat  string
+
+	// Tautulli watch-history label configuration
+	TautulliURL               string
+	TautulliAPIKey            string
+	TautulliNeverWatchedLabel string
+	TautulliStaleLabel        string
+	TautulliStaleAfter        time.Duration
+	TautulliUserLabels        map[string]string // lowercased user -> label
+
+	// Overseerr requester label configuration
+	OverseerrLabels      bool
+	OverseerrURL         string
+	OverseerrAPIKey      string
+	OverseerrLabelFormat string
+
+	// Bazarr subtitle label configuration
+	BazarrURL          string
+	BazarrAPIKey       string
+	BazarrMissingLabel string
+
+	// Trending label configuration
+	TrendingLabel  string
+	TrendingWindow string
+	TrendingPages  int
+
+	// TMDb rate limit configuration
+	TMDbRateLimit float64
+
+	// TMDb alternative title matching configuration
+	TMDbAlternativeTitles bool
+
+	// TMDb search fallback configuration
+	TMDbSearchFallback      bool
+	TMDbSearchMinConfidence float64
+
 	// Batch processing configuration
-	BatchSize  int
-	BatchDelay time.Duration
-	ItemDelay  time.Duration
+	BatchSize     int
+	BatchDelay    time.Duration
+	ItemDelay     time.Duration
+	AdaptiveDelay bool
+	MaxItemDelay  time.Duration
 
 	// Export configuration
 	ExportLabels   []string
+	ExportExclude  []string // EXPORT_EXCLUDE_LABELS
+	ExportMatch    string   // "label", "genre" or "collection"; empty follows UPDATE_FIELD
 	ExportLocation string
 	ExportMode     string
+	ExportSonarr   bool
+	ExportTemplate string
+	ExportMetadata bool
+	ExportDiff     bool
+	ExportLinkType string
+	ExportTimer    time.Duration // export-only refresh interval; 0 disables
+	ExportKeep     int           // EXPORT_SNAPSHOTS: timestamped snapshots to keep; 0 disables
+	ExportGzip     bool
+	ExportStream   bool
+	ExportSplit    bool   // EXPORT_SPLIT_LIBRARIES: one json, csv or yaml file per library
+	ExportSort     string // "path", "title" or empty for processing order
+
+	ExportPathMappings    map[string]string // from=to prefixes rewritten in every exported path
+	ExportM3UPathMappings map[string]string // from=to prefixes rewritten in m3u playlists only
+
+	// Export upload configuration
+	ExportS3Endpoint  string
+	ExportS3Bucket    string
+	ExportS3Prefix    string
+	ExportS3Region    string
+	ExportS3AccessKey string
+	ExportS3SecretKey string
+
+	// Export webhook configuration
+	ExportWebhookURL     string
+	ExportWebhookHeaders map[string]string
 }
 
 // Load loads configuration from environment variables
@@ -79,31 +318,86 @@ func Load() *Config {
 		TVLibraryID:            os.Getenv("TV_LIBRARY_ID"),
 		TVProcessAll:           getBoolEnvWithDefault("TV_PROCESS_ALL", false),
 		TVLibraryExclude:       parseCSV(os.Getenv("TV_LIBRARY_EXCLUDE")),
-		ExcludeLabels:          parseCSV(os.Getenv("EXCLUDE_LABELS")),
+		LibraryExclude:         parseCSV(os.Getenv("LIBRARY_EXCLUDE")),
+		ExcludeLabels:          parseCSV(getSetEnvWithDefault("EXCLUDE_LABELS", "labelarr-skip")),
 		WebhookOnly:            getBoolEnvWithDefault("WEBHOOK_ONLY", false),
+		RunOnce:                getBoolEnvWithDefault("RUN_ONCE", false),
+		ProcessItem:            strings.TrimSpace(os.Getenv("PROCESS_ITEM")),
 		UpdateField:            getEnvWithDefault("UPDATE_FIELD", "label"),
 		RemoveMode:             os.Getenv("REMOVE"),
 		TMDbReadAccessToken:    os.Getenv("TMDB_READ_ACCESS_TOKEN"),
 		ProcessTimer:           getDurationEnvWithDefault("PROCESS_TIMER", "1h"),
+		Schedules:              parseScheduleList(os.Getenv("SCHEDULE")),
+		NoProcessWindows:       parseCSV(os.Getenv("NO_PROCESS_WINDOW")),
+
+		// Media server configuration
+		MediaServer:    strings.ToLower(getEnvWithDefault("MEDIA_SERVER", "plex")),
+		JellyfinURL:    os.Getenv("JELLYFIN_URL"),
+		JellyfinAPIKey: os.Getenv("JELLYFIN_API_KEY"),
+		JellyfinUserID: os.Getenv("JELLYFIN_USER_ID"),
 
 		// Radarr configuration
-		RadarrURL:    os.Getenv("RADARR_URL"),
-		RadarrAPIKey: os.Getenv("RADARR_API_KEY"),
-		UseRadarr:    getBoolEnvWithDefault("USE_RADARR", false),
+		UseRadarr:       getBoolEnvWithDefault("USE_RADARR", false),
+		RadarrInstances: parseArrInstances("RADARR"),
 
 		// Sonarr configuration
-		SonarrURL:    os.Getenv("SONARR_URL"),
-		SonarrAPIKey: os.Getenv("SONARR_API_KEY"),
-		UseSonarr:    getBoolEnvWithDefault("USE_SONARR", false),
+		UseSonarr:       getBoolEnvWithDefault("USE_SONARR", false),
+		SonarrInstances: parseArrInstances("SONARR"),
+
+		// Radarr/Sonarr cache configuration
+		ArrCacheTTL: getDurationEnvWithDefault("ARR_CACHE_TTL", "0s"),
+
+		// Radarr/Sonarr label configuration
+		RadarrQualityLabels:         parseCSV(strings.ToLower(os.Getenv("RADARR_QUALITY_LABELS"))),
+		RadarrQualityLabelFormat:    getEnvWithDefault("RADARR_QUALITY_LABEL_FORMAT", "%s"),
+		RadarrTagSync:               getBoolEnvWithDefault("RADARR_TAG_SYNC", false),
+		RadarrTagSyncLabels:         parseCSV(os.Getenv("RADARR_TAG_SYNC_LABELS")),
+		ArrKeywordTags:              getBoolEnvWithDefault("ARR_KEYWORD_TAGS", false),
+		ArrKeywordTagPrefix:         getEnvWithDefault("ARR_KEYWORD_TAG_PREFIX", "kw-"),
+		ArrKeywordTagAllowlist:      parseCSV(strings.ToLower(os.Getenv("ARR_KEYWORD_TAG_ALLOWLIST"))),
+		RadarrCollectionLabels:      getBoolEnvWithDefault("RADARR_COLLECTION_LABELS", false),
+		RadarrCollectionLabelFormat: getEnvWithDefault("RADARR_COLLECTION_LABEL_FORMAT", "%s"),
+		RadarrCollections:           getBoolEnvWithDefault("RADARR_COLLECTIONS", false),
+		SonarrTagLabels:             getBoolEnvWithDefault("SONARR_TAG_LABELS", false),
+		SonarrTagLabelPrefix:        os.Getenv("SONARR_TAG_LABEL_PREFIX"),
+		ArrStatusLabels:             parseKeyValueCSV(os.Getenv("ARR_STATUS_LABELS")),
+		SonarrSeriesTypeLabels:      parseKeyValueCSV(os.Getenv("SONARR_SERIES_TYPE_LABELS")),
+		SonarrCompletionLabels:      parseKeyValueCSV(os.Getenv("SONARR_COMPLETION_LABELS")),
+		RadarrMissingLabel:          os.Getenv("RADARR_MISSING_LABEL"),
+		SonarrMissingLabel:          os.Getenv("SONARR_MISSING_LABEL"),
+		RadarrLanguageLabels:        getBoolEnvWithDefault("RADARR_LANGUAGE_LABELS", false),
+		RadarrLanguageLabelFormat:   getEnvWithDefault("RADARR_LANGUAGE_LABEL_FORMAT", "Original: %s"),
+		RadarrImportListLabels:      getBoolEnvWithDefault("RADARR_IMPORT_LIST_LABELS", false),
+		RadarrImportListLabelFormat: getEnvWithDefault("RADARR_IMPORT_LIST_LABEL_FORMAT", "%s"),
+
+		// Radarr/Sonarr filter configuration
+		RadarrTagFilter: parseCSV(strings.ToLower(os.Getenv("RADARR_TAG_FILTER"))),
+		SonarrTagFilter: parseCSV(strings.ToLower(os.Getenv("SONARR_TAG_FILTER"))),
 
 		// Logging configuration
 		VerboseLogging: getBoolEnvWithDefault("VERBOSE_LOGGING", false),
 
 		// Storage configuration
-		DataDir: os.Getenv("DATA_DIR"), // No default - ephemeral if not set
+		DataDir:      os.Getenv("DATA_DIR"), // No default - ephemeral if not set
+		PruneDeleted: getBoolEnvWithDefault("PRUNE_DELETED", true),
+		ResumeRuns:   getBoolEnvWithDefault("RESUME_RUNS", true),
+		SaveEvery:    getIntEnvWithDefault("STORAGE_SAVE_ITEMS", 100),
+		SaveInterval: getDurationEnvWithDefault("STORAGE_SAVE_INTERVAL", "30s"),
 
 		// Force update configuration
 		ForceUpdate: getBoolEnvWithDefault("FORCE_UPDATE", false),
+		SyncMode:    strings.ToLower(getEnvWithDefault("SYNC_MODE", "additive")),
+
+		// Label ownership configuration
+		ProtectManualLabels: getBoolEnvWithDefault("PROTECT_MANUAL_LABELS", false),
+
+		// Genre seeding configuration
+		SeedGenres:     getBoolEnvWithDefault("SEED_GENRES", false),
+		SeedGenresLock: getBoolEnvWithDefault("SEED_GENRES_LOCK", true),
+
+		// Change detection configuration
+		ChangeDetection:         getBoolEnvWithDefault("CHANGE_DETECTION", false),
+		ChangeDetectionLookback: getDurationEnvWithDefault("CHANGE_DETECTION_LOOKBACK", "336h"),
 
 		// Webhook configuration
 		WebhookEnabled:  getBoolEnvWithDefault("WEBHOOK_ENABLED", false),
@@ -113,15 +407,195 @@ func Load() *Config {
 		// Keyword prefix configuration
 		KeywordPrefix: os.Getenv("KEYWORD_PREFIX"),
 
+		// TMDb keyword filtering configuration
+		TMDbKeywordIDBlacklist: parseCSV(os.Getenv("TMDB_KEYWORD_ID_BLACKLIST")),
+
+		// Keyword filter configuration
+		KeywordWhitelist: parseCSV(os.Getenv("KEYWORD_WHITELIST")),
+		KeywordBlacklist: parseCSV(os.Getenv("KEYWORD_BLACKLIST")),
+		KeywordPattern:   os.Getenv("KEYWORD_PATTERN"),
+		KeywordExclude:   os.Getenv("KEYWORD_EXCLUDE_PATTERN"),
+		KeywordMinItems:  getIntEnvWithDefault("KEYWORD_MIN_ITEMS", 0),
+		MaxKeywords:      getIntEnvWithDefault("MAX_KEYWORDS_PER_ITEM", 0),
+		KeywordPriority:  strings.ToLower(getEnvWithDefault("KEYWORD_PRIORITY", "relevance")),
+
+		// IMDb keyword configuration
+		IMDbKeywords:    getBoolEnvWithDefault("IMDB_KEYWORDS", false),
+		IMDbKeywordsMax: getIntEnvWithDefault("IMDB_KEYWORDS_MAX", 20),
+		IMDbCacheTTL:    getDurationEnvWithDefault("IMDB_CACHE_TTL", "720h"),
+
+		// TVDB keyword configuration
+		TVDbKeywords: strings.ToLower(os.Getenv("TVDB_KEYWORDS")),
+		TVDbAPIKey:   os.Getenv("TVDB_API_KEY"),
+		TVDbPIN:      os.Getenv("TVDB_PIN"),
+
+		// NFO keyword configuration
+		NFOKeywords:     getBoolEnvWithDefault("NFO_KEYWORDS", false),
+		NFOPathMappings: parseOptionalLabelCSV(os.Getenv("NFO_PATH_MAPPINGS")),
+
+		// Keyword provider configuration
+		KeywordProviderURL:     os.Getenv("KEYWORD_PROVIDER_URL"),
+		KeywordProviderToken:   os.Getenv("KEYWORD_PROVIDER_TOKEN"),
+		KeywordProviderTimeout: getDurationEnvWithDefault("KEYWORD_PROVIDER_TIMEOUT", "30s"),
+
+		// AniList keyword configuration
+		LibrarySourceOverride: parseKeyValueCSV(strings.ToLower(os.Getenv("LIBRARY_SOURCE_OVERRIDE"))),
+		AniListMinTagRank:     getIntEnvWithDefault("ANILIST_MIN_TAG_RANK", 60),
+		AniListCacheTTL:       getDurationEnvWithDefault("ANILIST_CACHE_TTL", "720h"),
+
+		// MyAnimeList label configuration
+		MALLabels:   parseCSV(strings.ToLower(os.Getenv("MAL_LABELS"))),
+		MALCacheTTL: getDurationEnvWithDefault("MAL_CACHE_TTL", "720h"),
+
+		// Studio label configuration
+		StudioLabels:         getBoolEnvWithDefault("STUDIO_LABELS", false),
+		StudioLabelAllowlist: parseCSV(os.Getenv("STUDIO_LABEL_ALLOWLIST")),
+
+		// Language/country label configuration
+		LanguageLabels:       getBoolEnvWithDefault("LANGUAGE_LABELS", false),
+		LanguageLabelMap:     parseKeyValueCSV(os.Getenv("LANGUAGE_LABEL_MAP")),
+		LanguageLabelExclude: parseCSV(os.Getenv("LANGUAGE_LABEL_EXCLUDE")),
+		CountryLabels:        getBoolEnvWithDefault("COUNTRY_LABELS", false),
+		CountryLabelMap:      parseKeyValueCSV(os.Getenv("COUNTRY_LABEL_MAP")),
+
+		// Audio language label configuration
+		AudioLanguageLabels:      getBoolEnvWithDefault("AUDIO_LANGUAGE_LABELS", false),
+		AudioLanguageLabelFormat: getEnvWithDefault("AUDIO_LANGUAGE_LABEL_FORMAT", "Audio: %s"),
+		DualAudioLabel:           os.Getenv("DUAL_AUDIO_LABEL"),
+
+		// Quality label configuration
+		QualityLabels:   parseCSV(strings.ToLower(os.Getenv("QUALITY_LABELS"))),
+		QualityLabelMap: parseKeyValueCSV(os.Getenv("QUALITY_LABEL_MAP")),
+
+		// Size label configuration
+		SizeLabels: parseKeyValueCSV(os.Getenv("SIZE_LABELS")),
+
+		// Decade label configuration
+		DecadeLabels: getBoolEnvWithDefault("DECADE_LABELS", false),
+
+		// Adult label configuration
+		AdultLabel: os.Getenv("ADULT_LABEL"),
+
+		// Age label configuration
+		AgeProviders:            parseCSV(strings.ToLower(os.Getenv("AGE_PROVIDERS"))),
+		AgeCertificationCountry: strings.ToUpper(getEnvWithDefault("AGE_CERTIFICATION_COUNTRY", "US")),
+		AgeOverrideFile:         os.Getenv("AGE_OVERRIDE_FILE"),
+		AgeLabelFormat:          getEnvWithDefault("AGE_LABEL_FORMAT", "Age %d+"),
+
+		// Mapping file configuration
+		MappingFile:      os.Getenv("MAPPING_FILE"),
+		KeywordAliasFile: os.Getenv("KEYWORD_ALIAS_FILE"),
+
+		// Plex similar-cluster configuration
+		PlexSimilarSeeds:       parseOptionalLabelCSV(os.Getenv("PLEX_SIMILAR_SEEDS")),
+		PlexSimilarHubs:        parseCSV(strings.ToLower(getEnvWithDefault("PLEX_SIMILAR_HUBS", "similar"))),
+		PlexSimilarLabelFormat: getEnvWithDefault("PLEX_SIMILAR_LABEL_FORMAT", "Similar to %s"),
+
+		// TMDb list label configuration
+		TMDbListLabels: parseKeyValueCSV(os.Getenv("TMDB_LIST_LABELS")),
+
+		// OMDb score label configuration
+		OMDbAPIKey:      os.Getenv("OMDB_API_KEY"),
+		OMDbScoreLabels: parseKeyValueCSV(os.Getenv("OMDB_SCORE_LABELS")),
+		OMDbCacheTTL:    getDurationEnvWithDefault("OMDB_CACHE_TTL", "168h"),
+
+		// Wikidata award label configuration
+		WikidataAwards:   parseOptionalLabelCSV(os.Getenv("WIKIDATA_AWARDS")),
+		WikidataCacheTTL: getDurationEnvWithDefault("WIKIDATA_CACHE_TTL", "2160h"),
+
+		// Trakt list label configuration
+		TraktClientID:     os.Getenv("TRAKT_CLIENT_ID"),
+		TraktClientSecret: os.Getenv("TRAKT_CLIENT_SECRET"),
+		TraktLists:        parseKeyValueCSV(os.Getenv("TRAKT_LISTS")),
+
+		// Letterboxd list label configuration
+		LetterboxdLists:   parseOptionalLabelCSV(os.Getenv("LETTERBOXD_LISTS")),
+		LetterboxdRefresh: getDurationEnvWithDefault("LETTERBOXD_REFRESH", "24h"),
+
+		// MDBList list label configuration
+		MDBListLists: parseOptionalLabelCSV(os.Getenv("MDBLIST_LISTS")),
+
+		// Streaming availability label configuration
+		AvailabilitySource:      strings.ToLower(os.Getenv("AVAILABILITY_SOURCE")),
+		AvailabilityRegion:      strings.ToUpper(getEnvWithDefault("AVAILABILITY_REGION", "US")),
+		AvailabilityProviders:   parseCSV(os.Getenv("AVAILABILITY_PROVIDERS")),
+		AvailabilityLabelFormat: getEnvWithDefault("AVAILABILITY_LABEL_FORMAT", "%s"),
+		LeavingSoonDays:         getIntEnvWithDefault("LEAVING_SOON_DAYS", 14),
+		LeavingSoonLabelFormat:  getEnvWithDefault("LEAVING_SOON_LABEL_FORMAT", "Leaving %s"),
+
+		// Tautulli watch-history label configuration
+		TautulliURL:               os.Getenv("TAUTULLI_URL"),
+		TautulliAPIKey:            os.Getenv("TAUTULLI_API_KEY"),
+		TautulliNeverWatchedLabel: os.Getenv("TAUTULLI_NEVER_WATCHED_LABEL"),
+		TautulliStaleLabel:        os.Getenv("TAUTULLI_STALE_LABEL"),
+		TautulliStaleAfter:        getDurationEnvWithDefault("TAUTULLI_STALE_AFTER", "17520h"),
+		TautulliUserLabels:        parseKeyValueCSV(os.Getenv("TAUTULLI_USER_LABELS")),
+
+		// Overseerr requester label configuration
+		OverseerrLabels:      getBoolEnvWithDefault("OVERSEERR_LABELS", false),
+		OverseerrURL:         os.Getenv("OVERSEERR_URL"),
+		OverseerrAPIKey:      os.Getenv("OVERSEERR_API_KEY"),
+		OverseerrLabelFormat: getEnvWithDefault("OVERSEERR_LABEL_FORMAT", "Requested by %s"),
+
+		// Bazarr subtitle label configuration
+		BazarrURL:          os.Getenv("BAZARR_URL"),
+		BazarrAPIKey:       os.Getenv("BAZARR_API_KEY"),
+		BazarrMissingLabel: os.Getenv("BAZARR_MISSING_LABEL"),
+
+		// Trending label configuration
+		TrendingLabel:  os.Getenv("TRENDING_LABEL"),
+		TrendingWindow: getEnvWithDefault("TRENDING_WINDOW", "week"),
+		TrendingPages:  getIntEnvWithDefault("TRENDING_PAGES", 3),
+
+		// TMDb rate limit configuration
+		TMDbRateLimit: getFloatEnvWithDefault("TMDB_RATE_LIMIT", 40),
+
+		// TMDb alternative title matching configuration
+		TMDbAlternativeTitles: getBoolEnvWithDefault("TMDB_ALTERNATIVE_TITLES", false),
+
+		// TMDb search fallback configuration
+		TMDbSearchFallback:      getBoolEnvWithDefault("TMDB_SEARCH_FALLBACK", false),
+		TMDbSearchMinConfidence: getFloatEnvWithDefault("TMDB_SEARCH_MIN_CONFIDENCE", 0.8),
+
 		// Batch processing configuration
-		BatchSize:  getIntEnvWithDefault("BATCH_SIZE", 100),
-		BatchDelay: getDurationEnvWithDefault("BATCH_DELAY", "10s"),
-		ItemDelay:  getDurationEnvWithDefault("ITEM_DELAY", "500ms"),
+		BatchSize:     getIntEnvWithDefault("BATCH_SIZE", 100),
+		BatchDelay:    getDurationEnvWithDefault("BATCH_DELAY", "10s"),
+		ItemDelay:     getDurationEnvWithDefault("ITEM_DELAY", "500ms"),
+		AdaptiveDelay: getBoolEnvWithDefault("ADAPTIVE_DELAY", false),
+		MaxItemDelay:  getDurationEnvWithDefault("MAX_ITEM_DELAY", "10s"),
 
 		// Export configuration
 		ExportLabels:   parseCSV(os.Getenv("EXPORT_LABELS")),
+		ExportExclude:  parseCSV(os.Getenv("EXPORT_EXCLUDE_LABELS")),
+		ExportMatch:    strings.ToLower(os.Getenv("EXPORT_MATCH_FIELD")),
 		ExportLocation: os.Getenv("EXPORT_LOCATION"),
 		ExportMode:     getEnvWithDefault("EXPORT_MODE", "txt"),
+		ExportSonarr:   getBoolEnvWithDefault("EXPORT_SONARR", false),
+		ExportTemplate: os.Getenv("EXPORT_TEMPLATE"),
+		ExportMetadata: getBoolEnvWithDefault("EXPORT_METADATA", false),
+		ExportDiff:     getBoolEnvWithDefault("EXPORT_DIFF", false),
+		ExportLinkType: getEnvWithDefault("EXPORT_LINK_TYPE", "symlink"),
+		ExportTimer:    getDurationEnvWithDefault("EXPORT_TIMER", "0s"),
+		ExportKeep:     getIntEnvWithDefault("EXPORT_SNAPSHOTS", 0),
+		ExportGzip:     getBoolEnvWithDefault("EXPORT_GZIP", false),
+		ExportStream:   getBoolEnvWithDefault("EXPORT_STREAM", false),
+		ExportSplit:    getBoolEnvWithDefault("EXPORT_SPLIT_LIBRARIES", false),
+		ExportSort:     strings.ToLower(os.Getenv("EXPORT_SORT")),
+
+		ExportPathMappings:    parseOptionalLabelCSV(os.Getenv("EXPORT_PATH_MAPPINGS")),
+		ExportM3UPathMappings: parseOptionalLabelCSV(os.Getenv("EXPORT_M3U_PATH_MAPPINGS")),
+
+		// Export upload configuration
+		ExportS3Endpoint:  os.Getenv("EXPORT_S3_ENDPOINT"),
+		ExportS3Bucket:    os.Getenv("EXPORT_S3_BUCKET"),
+		ExportS3Prefix:    os.Getenv("EXPORT_S3_PREFIX"),
+		ExportS3Region:    getEnvWithDefault("EXPORT_S3_REGION", "us-east-1"),
+		ExportS3AccessKey: os.Getenv("EXPORT_S3_ACCESS_KEY"),
+		ExportS3SecretKey: os.Getenv("EXPORT_S3_SECRET_KEY"),
+
+		// Export webhook configuration
+		ExportWebhookURL:     os.Getenv("EXPORT_WEBHOOK_URL"),
+		ExportWebhookHeaders: parseHeaderCSV(os.Getenv("EXPORT_WEBHOOK_HEADERS")),
 	}
 
 	// Set protocol based on HTTPS requirement
@@ -149,28 +623,149 @@ func (c *Config) IsRemoveMode() bool {
 	return c.RemoveMode != ""
 }
 
+// UsesPlex returns true if Plex is the media server
+func (c *Config) UsesPlex() bool {
+	return c.MediaServer == "" || c.MediaServer == "plex"
+}
+
+// UsesTautulli returns true if any Tautulli watch-history label is configured
+func (c *Config) UsesTautulli() bool {
+	return c.TautulliNeverWatchedLabel != "" || c.TautulliStaleLabel != "" || len(c.TautulliUserLabels) > 0
+}
+
+// UsesLibrarySource returns true if LIBRARY_SOURCE_OVERRIDE assigns source to any library
+func (c *Config) UsesLibrarySource(source string) bool {
+	for _, s := range c.LibrarySourceOverride {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
-	if c.PlexToken == "" {
-		return fmt.Errorf("PLEX_TOKEN environment variable is required")
+	switch c.MediaServer {
+	case "", "plex":
+		if c.PlexToken == "" {
+			return fmt.Errorf("PLEX_TOKEN environment variable is required")
+		}
+	case "jellyfin", "emby":
+		if c.JellyfinURL == "" || c.JellyfinAPIKey == "" {
+			return fmt.Errorf("JELLYFIN_URL and JELLYFIN_API_KEY are required when MEDIA_SERVER is %s", c.MediaServer)
+		}
+	default:
+		return fmt.Errorf("MEDIA_SERVER must be 'plex', 'jellyfin' or 'emby'")
 	}
 	if c.TMDbReadAccessToken == "" {
 		return fmt.Errorf("TMDB_READ_ACCESS_TOKEN environment variable is required")
 	}
-	if c.PlexServer == "" {
-		return fmt.Errorf("PLEX_SERVER environment variable is required")
-	}
-	if c.PlexPort == "" {
-		return fmt.Errorf("PLEX_PORT environment variable is required")
+	if c.UsesPlex() {
+		if c.PlexServer == "" {
+			return fmt.Errorf("PLEX_SERVER environment variable is required")
+		}
+		if c.PlexPort == "" {
+			return fmt.Errorf("PLEX_PORT environment variable is required")
+		}
 	}
 	if c.UpdateField != "label" && c.UpdateField != "genre" {
 		return fmt.Errorf("UPDATE_FIELD must be 'label' or 'genre'")
 	}
-	if c.RemoveMode != "" && c.RemoveMode != "lock" && c.RemoveMode != "unlock" {
-		return fmt.Errorf("REMOVE must be 'lock' or 'unlock'")
+	if c.SeedGenres && c.UpdateField != "label" {
+		return fmt.Errorf("SEED_GENRES requires UPDATE_FIELD=label")
+	}
+	for _, expr := range c.Schedules {
+		if _, err := utils.ParseCron(expr); err != nil {
+			return fmt.Errorf("SCHEDULE: %w", err)
+		}
+	}
+	if _, err := utils.ParseTimeWindows(c.NoProcessWindows); err != nil {
+		return fmt.Errorf("NO_PROCESS_WINDOW: %w", err)
+	}
+	if c.RemoveMode != "" && c.RemoveMode != "lock" && c.RemoveMode != "unlock" && c.RemoveMode != "applied" {
+		return fmt.Errorf("REMOVE must be 'lock', 'unlock' or 'applied'")
+	}
+	if c.RemoveMode == "applied" && c.DataDir == "" {
+		return fmt.Errorf("DATA_DIR is required when REMOVE=applied")
+	}
+	if c.ExportMode != "txt" && c.ExportMode != "json" && c.ExportMode != "csv" && c.ExportMode != "yaml" && c.ExportMode != "template" && c.ExportMode != "m3u" && c.ExportMode != "links" && c.ExportMode != "prom" && c.ExportMode != "arr" {
+		return fmt.Errorf("EXPORT_MODE must be 'txt', 'json', 'csv', 'yaml', 'template', 'm3u', 'links', 'prom' or 'arr'")
+	}
+	if c.ExportMode == "links" && c.ExportLinkType != "symlink" && c.ExportLinkType != "hardlink" {
+		return fmt.Errorf("EXPORT_LINK_TYPE must be 'symlink' or 'hardlink'")
+	}
+	if (c.ExportMode == "template") != (c.ExportTemplate != "") {
+		return fmt.Errorf("EXPORT_MODE=template and EXPORT_TEMPLATE must be set together")
+	}
+	if c.ExportTimer < 0 {
+		return fmt.Errorf("EXPORT_TIMER must not be negative")
+	}
+	if c.ExportTimer > 0 && c.RunOnce {
+		return fmt.Errorf("EXPORT_TIMER can't be combined with RUN_ONCE=true")
+	}
+	if c.ExportKeep < 0 {
+		return fmt.Errorf("EXPORT_SNAPSHOTS must not be negative")
+	}
+	if c.ExportKeep > 0 && c.ExportMode == "links" {
+		return fmt.Errorf("EXPORT_SNAPSHOTS isn't supported with EXPORT_MODE=links")
 	}
-	if c.ExportMode != "txt" && c.ExportMode != "json" {
-		return fmt.Errorf("EXPORT_MODE must be 'txt' or 'json'")
+	if c.ExportGzip && (c.ExportMode == "links" || c.ExportMode == "prom") {
+		return fmt.Errorf("EXPORT_GZIP isn't supported with EXPORT_MODE=%s", c.ExportMode)
+	}
+	if c.ExportSplit && c.ExportMode != "json" && c.ExportMode != "csv" && c.ExportMode != "yaml" {
+		return fmt.Errorf("EXPORT_SPLIT_LIBRARIES requires EXPORT_MODE json, csv or yaml")
+	}
+	if c.ExportSort != "" && c.ExportSort != "path" && c.ExportSort != "title" {
+		return fmt.Errorf("EXPORT_SORT must be 'path' or 'title'")
+	}
+	if c.ExportMatch != "" && c.ExportMatch != "label" && c.ExportMatch != "genre" && c.ExportMatch != "collection" {
+		return fmt.Errorf("EXPORT_MATCH_FIELD must be 'label', 'genre' or 'collection'")
+	}
+	if c.ExportMetadata && (c.ExportMode == "txt" || c.ExportMode == "m3u" || c.ExportMode == "links" || c.ExportMode == "prom" || c.ExportMode == "arr") {
+		return fmt.Errorf("EXPORT_METADATA requires EXPORT_MODE json, csv, yaml or template")
+	}
+	if len(c.ExportM3UPathMappings) > 0 && c.ExportMode != "m3u" {
+		return fmt.Errorf("EXPORT_M3U_PATH_MAPPINGS requires EXPORT_MODE=m3u")
+	}
+	if c.SyncMode != "" && c.SyncMode != "additive" && c.SyncMode != "exact" {
+		return fmt.Errorf("SYNC_MODE must be 'additive' or 'exact'")
+	}
+	if c.SyncMode == "exact" && c.DataDir == "" {
+		return fmt.Errorf("DATA_DIR is required when SYNC_MODE=exact")
+	}
+	if c.ProtectManualLabels && c.DataDir == "" {
+		return fmt.Errorf("DATA_DIR is required when PROTECT_MANUAL_LABELS=true")
+	}
+	if c.ExportSonarr && !c.UseSonarr {
+		return fmt.Errorf("EXPORT_SONARR requires USE_SONARR=true")
+	}
+	if c.HasExportUploadEnabled() {
+		if !c.HasExportEnabled() {
+			return fmt.Errorf("EXPORT_S3_BUCKET requires EXPORT_LOCATION and EXPORT_LABELS or EXPORT_EXCLUDE_LABELS")
+		}
+		if c.ExportS3Endpoint == "" {
+			return fmt.Errorf("EXPORT_S3_ENDPOINT is required when EXPORT_S3_BUCKET is set")
+		}
+		if !strings.HasPrefix(c.ExportS3Endpoint, "http://") && !strings.HasPrefix(c.ExportS3Endpoint, "https://") {
+			return fmt.Errorf("EXPORT_S3_ENDPOINT must start with http:// or https://")
+		}
+		if c.ExportS3AccessKey == "" || c.ExportS3SecretKey == "" {
+			return fmt.Errorf("EXPORT_S3_ACCESS_KEY and EXPORT_S3_SECRET_KEY are required when EXPORT_S3_BUCKET is set")
+		}
+	}
+	if c.ExportWebhookURL != "" {
+		if !c.HasExportEnabled() {
+			return fmt.Errorf("EXPORT_WEBHOOK_URL requires EXPORT_LOCATION and EXPORT_LABELS or EXPORT_EXCLUDE_LABELS")
+		}
+		if !strings.HasPrefix(c.ExportWebhookURL, "http://") && !strings.HasPrefix(c.ExportWebhookURL, "https://") {
+			return fmt.Errorf("EXPORT_WEBHOOK_URL must be an http:// or https:// URL")
+		}
+	}
+	if c.ProcessItem != "" && c.RemoveMode != "" {
+		return fmt.Errorf("PROCESS_ITEM can't be combined with REMOVE")
+	}
+	if c.RunOnce && c.WebhookOnly {
+		return fmt.Errorf("RUN_ONCE=true can't be combined with WEBHOOK_ONLY=true")
 	}
 	if c.WebhookOnly && !c.WebhookEnabled {
 		return fmt.Errorf("WEBHOOK_ONLY=true requires WEBHOOK_ENABLED=true")
@@ -182,27 +777,351 @@ func (c *Config) Validate() error {
 	if c.BatchSize < 1 {
 		return fmt.Errorf("BATCH_SIZE must be at least 1")
 	}
+	if c.DataDir != "" {
+		if c.SaveEvery < 1 {
+			return fmt.Errorf("STORAGE_SAVE_ITEMS must be at least 1")
+		}
+		if c.SaveInterval <= 0 {
+			return fmt.Errorf("STORAGE_SAVE_INTERVAL must be positive")
+		}
+	}
 
 	// Validate Radarr configuration if enabled
 	if c.UseRadarr {
-		if c.RadarrURL == "" {
+		if len(c.RadarrInstances) == 0 {
 			return fmt.Errorf("RADARR_URL environment variable is required when USE_RADARR is true")
 		}
-		if c.RadarrAPIKey == "" {
-			return fmt.Errorf("RADARR_API_KEY environment variable is required when USE_RADARR is true")
+		for _, instance := range c.RadarrInstances {
+			if instance.APIKey == "" {
+				return fmt.Errorf("%s_API_KEY environment variable is required when USE_RADARR is true", instance.Name)
+			}
+			if instance.Password != "" && instance.Username == "" {
+				return fmt.Errorf("%s_USERNAME is required when %s_PASSWORD is set", instance.Name, instance.Name)
+			}
 		}
 	}
 
 	// Validate Sonarr configuration if enabled
 	if c.UseSonarr {
-		if c.SonarrURL == "" {
+		if len(c.SonarrInstances) == 0 {
 			return fmt.Errorf("SONARR_URL environment variable is required when USE_SONARR is true")
 		}
-		if c.SonarrAPIKey == "" {
-			return fmt.Errorf("SONARR_API_KEY environment variable is required when USE_SONARR is true")
+		for _, instance := range c.SonarrInstances {
+			if instance.APIKey == "" {
+				return fmt.Errorf("%s_API_KEY environment variable is required when USE_SONARR is true", instance.Name)
+			}
+			if instance.Password != "" && instance.Username == "" {
+				return fmt.Errorf("%s_USERNAME is required when %s_PASSWORD is set", instance.Name, instance.Name)
+			}
+		}
+	}
+
+	if c.ArrCacheTTL < 0 {
+		return fmt.Errorf("ARR_CACHE_TTL must not be negative")
+	}
+
+	if len(c.RadarrQualityLabels) > 0 {
+		if !c.UseRadarr {
+			return fmt.Errorf("RADARR_QUALITY_LABELS requires USE_RADARR=true")
+		}
+		for _, source := range c.RadarrQualityLabels {
+			if source != "profile" && source != "file" {
+				return fmt.Errorf("RADARR_QUALITY_LABELS must contain 'profile' or 'file', got %q", source)
+			}
+		}
+		if strings.Count(c.RadarrQualityLabelFormat, "%s") != 1 {
+			return fmt.Errorf("RADARR_QUALITY_LABEL_FORMAT must contain %%s exactly once")
+		}
+	}
+	if c.RadarrTagSync && !c.UseRadarr {
+		return fmt.Errorf("RADARR_TAG_SYNC requires USE_RADARR=true")
+	}
+	if c.ArrKeywordTags {
+		if !c.UseRadarr && !c.UseSonarr {
+			return fmt.Errorf("ARR_KEYWORD_TAGS requires USE_RADARR=true or USE_SONARR=true")
+		}
+		// The prefix is how labelarr tells its keyword tags from the user's
+		// own, so it can remove them when a keyword goes away.
+		if !strings.ContainsAny(strings.ToLower(c.ArrKeywordTagPrefix), "abcdefghijklmnopqrstuvwxyz0123456789") {
+			return fmt.Errorf("ARR_KEYWORD_TAG_PREFIX must contain a letter or digit")
+		}
+	}
+	if c.RadarrCollectionLabels {
+		if !c.UseRadarr {
+			return fmt.Errorf("RADARR_COLLECTION_LABELS requires USE_RADARR=true")
+		}
+		if strings.Count(c.RadarrCollectionLabelFormat, "%s") != 1 {
+			return fmt.Errorf("RADARR_COLLECTION_LABEL_FORMAT must contain %%s exactly once")
+		}
+	}
+	if c.RadarrCollections {
+		if !c.UseRadarr {
+			return fmt.Errorf("RADARR_COLLECTIONS requires USE_RADARR=true")
+		}
+		if !c.UsesPlex() {
+			return fmt.Errorf("RADARR_COLLECTIONS is only supported with Plex")
+		}
+	}
+	if c.SonarrTagLabels && !c.UseSonarr {
+		return fmt.Errorf("SONARR_TAG_LABELS requires USE_SONARR=true")
+	}
+	if len(c.SonarrSeriesTypeLabels) > 0 {
+		if !c.UseSonarr {
+			return fmt.Errorf("SONARR_SERIES_TYPE_LABELS requires USE_SONARR=true")
+		}
+		for seriesType := range c.SonarrSeriesTypeLabels {
+			if seriesType != "standard" && seriesType != "daily" && seriesType != "anime" {
+				return fmt.Errorf("SONARR_SERIES_TYPE_LABELS keys must be 'standard', 'daily' or 'anime', got %q", seriesType)
+			}
+		}
+	}
+	if len(c.SonarrCompletionLabels) > 0 {
+		if !c.UseSonarr {
+			return fmt.Errorf("SONARR_COMPLETION_LABELS requires USE_SONARR=true")
+		}
+		for state := range c.SonarrCompletionLabels {
+			if state != "complete" && state != "incomplete" && state != "downloading" {
+				return fmt.Errorf("SONARR_COMPLETION_LABELS keys must be 'complete', 'incomplete' or 'downloading', got %q", state)
+			}
+		}
+	}
+	if c.RadarrLanguageLabels {
+		if !c.UseRadarr {
+			return fmt.Errorf("RADARR_LANGUAGE_LABELS requires USE_RADARR=true")
+		}
+		if strings.Count(c.RadarrLanguageLabelFormat, "%s") != 1 {
+			return fmt.Errorf("RADARR_LANGUAGE_LABEL_FORMAT must contain %%s exactly once")
+		}
+	}
+	if c.RadarrImportListLabels {
+		if !c.UseRadarr {
+			return fmt.Errorf("RADARR_IMPORT_LIST_LABELS requires USE_RADARR=true")
+		}
+		if strings.Count(c.RadarrImportListLabelFormat, "%s") != 1 {
+			return fmt.Errorf("RADARR_IMPORT_LIST_LABEL_FORMAT must contain %%s exactly once")
+		}
+	}
+	if c.RadarrMissingLabel != "" && !c.UseRadarr {
+		return fmt.Errorf("RADARR_MISSING_LABEL requires USE_RADARR=true")
+	}
+	if c.SonarrMissingLabel != "" && !c.UseSonarr {
+		return fmt.Errorf("SONARR_MISSING_LABEL requires USE_SONARR=true")
+	}
+	if len(c.ArrStatusLabels) > 0 && !c.UseRadarr && !c.UseSonarr {
+		return fmt.Errorf("ARR_STATUS_LABELS requires USE_RADARR=true or USE_SONARR=true")
+	}
+	if len(c.RadarrTagFilter) > 0 && !c.UseRadarr {
+		return fmt.Errorf("RADARR_TAG_FILTER requires USE_RADARR=true")
+	}
+	if len(c.SonarrTagFilter) > 0 && !c.UseSonarr {
+		return fmt.Errorf("SONARR_TAG_FILTER requires USE_SONARR=true")
+	}
+
+	for _, id := range c.TMDbKeywordIDBlacklist {
+		if _, err := strconv.Atoi(id); err != nil {
+			return fmt.Errorf("TMDB_KEYWORD_ID_BLACKLIST must contain numeric TMDb keyword IDs, got %q", id)
+		}
+	}
+	if _, err := utils.NewPatternList(c.LibraryExclude); err != nil {
+		return fmt.Errorf("LIBRARY_EXCLUDE: %w", err)
+	}
+	if _, err := utils.NewPatternList(c.KeywordWhitelist); err != nil {
+		return fmt.Errorf("KEYWORD_WHITELIST: %w", err)
+	}
+	if _, err := utils.NewPatternList(c.KeywordBlacklist); err != nil {
+		return fmt.Errorf("KEYWORD_BLACKLIST: %w", err)
+	}
+	if _, err := CompileKeywordPattern(c.KeywordPattern); err != nil {
+		return fmt.Errorf("KEYWORD_PATTERN: %w", err)
+	}
+	if _, err := CompileKeywordPattern(c.KeywordExclude); err != nil {
+		return fmt.Errorf("KEYWORD_EXCLUDE_PATTERN: %w", err)
+	}
+	if c.KeywordMinItems < 0 {
+		return fmt.Errorf("KEYWORD_MIN_ITEMS must not be negative")
+	}
+	if c.MaxKeywords < 0 {
+		return fmt.Errorf("MAX_KEYWORDS_PER_ITEM must not be negative")
+	}
+	if c.IMDbKeywords && c.IMDbKeywordsMax < 1 {
+		return fmt.Errorf("IMDB_KEYWORDS_MAX must be at least 1")
+	}
+	if c.MaxKeywords > 0 && c.KeywordPriority != "relevance" && c.KeywordPriority != "frequency" {
+		return fmt.Errorf("KEYWORD_PRIORITY must be 'relevance' or 'frequency'")
+	}
+
+	if c.TrendingLabel != "" && c.TrendingWindow != "day" && c.TrendingWindow != "week" {
+		return fmt.Errorf("TRENDING_WINDOW must be 'day' or 'week'")
+	}
+
+	if c.ChangeDetection && c.ChangeDetectionLookback <= 0 {
+		return fmt.Errorf("CHANGE_DETECTION_LOOKBACK must be greater than 0")
+	}
+
+	for id := range c.TMDbListLabels {
+		if _, err := strconv.Atoi(id); err != nil {
+			return fmt.Errorf("TMDB_LIST_LABELS must map numeric TMDb list IDs to labels, got %q", id)
+		}
+	}
+
+	switch c.TVDbKeywords {
+	case "":
+	case "merge", "only":
+		if c.TVDbAPIKey == "" {
+			return fmt.Errorf("TVDB_API_KEY is required when TVDB_KEYWORDS is set")
+		}
+	default:
+		return fmt.Errorf("TVDB_KEYWORDS must be 'merge' or 'only'")
+	}
+
+	for libraryID, source := range c.LibrarySourceOverride {
+		if source != "tmdb" && source != "anilist" {
+			return fmt.Errorf("LIBRARY_SOURCE_OVERRIDE source for library %s must be 'tmdb' or 'anilist', got %q", libraryID, source)
+		}
+	}
+
+	if c.KeywordProviderURL != "" && !strings.HasPrefix(c.KeywordProviderURL, "http://") && !strings.HasPrefix(c.KeywordProviderURL, "https://") {
+		return fmt.Errorf("KEYWORD_PROVIDER_URL must be an http:// or https:// URL")
+	}
+
+	for _, provider := range c.AgeProviders {
+		switch provider {
+		case "tmdb":
+		case "override":
+			if c.AgeOverrideFile == "" {
+				return fmt.Errorf("AGE_OVERRIDE_FILE is required when AGE_PROVIDERS includes 'override'")
+			}
+		default:
+			return fmt.Errorf("AGE_PROVIDERS must contain 'tmdb' or 'override', got %q", provider)
+		}
+	}
+	if c.AudioLanguageLabels && strings.Count(c.AudioLanguageLabelFormat, "%s") != 1 {
+		return fmt.Errorf("AUDIO_LANGUAGE_LABEL_FORMAT must contain %%s exactly once")
+	}
+
+	for _, category := range c.QualityLabels {
+		if category != "resolution" && category != "hdr" {
+			return fmt.Errorf("QUALITY_LABELS must contain 'resolution' or 'hdr', got %q", category)
+		}
+	}
+
+	for rule := range c.SizeLabels {
+		if _, _, _, err := ParseSizeRule(rule); err != nil {
+			return fmt.Errorf("SIZE_LABELS: %w", err)
+		}
+	}
+
+	if len(c.AgeProviders) > 0 && strings.Count(c.AgeLabelFormat, "%d") != 1 {
+		return fmt.Errorf("AGE_LABEL_FORMAT must contain %%d exactly once")
+	}
+
+	if len(c.PlexSimilarSeeds) > 0 {
+		for _, hub := range c.PlexSimilarHubs {
+			if hub != "similar" && hub != "related" {
+				return fmt.Errorf("PLEX_SIMILAR_HUBS must contain 'similar' or 'related', got %q", hub)
+			}
+			if hub == "related" && !c.UsesPlex() {
+				return fmt.Errorf("PLEX_SIMILAR_HUBS 'related' is only available with Plex")
+			}
+		}
+		if strings.Count(c.PlexSimilarLabelFormat, "%s") != 1 {
+			return fmt.Errorf("PLEX_SIMILAR_LABEL_FORMAT must contain %%s exactly once")
+		}
+	}
+
+	for _, category := range c.MALLabels {
+		if category != "genres" && category != "themes" && category != "demographics" {
+			return fmt.Errorf("MAL_LABELS must contain 'genres', 'themes' or 'demographics', got %q", category)
+		}
+	}
+
+	if len(c.OMDbScoreLabels) > 0 && c.OMDbAPIKey == "" {
+		return fmt.Errorf("OMDB_API_KEY is required when OMDB_SCORE_LABELS is set")
+	}
+	for rule := range c.OMDbScoreLabels {
+		if _, _, err := ParseScoreRule(rule); err != nil {
+			return fmt.Errorf("OMDB_SCORE_LABELS: %w", err)
+		}
+	}
+
+	if len(c.TraktLists) > 0 {
+		if c.TraktClientID == "" {
+			return fmt.Errorf("TRAKT_CLIENT_ID is required when TRAKT_LISTS is set")
+		}
+		if c.DataDir == "" {
+			return fmt.Errorf("DATA_DIR is required when TRAKT_LISTS is set")
+		}
+		for list := range c.TraktLists {
+			if list == "watchlist" {
+				if c.TraktClientSecret == "" {
+					return fmt.Errorf("TRAKT_CLIENT_SECRET is required to label the Trakt watchlist")
+				}
+			} else if user, slug, ok := strings.Cut(list, "/"); !ok || user == "" || slug == "" {
+				return fmt.Errorf("TRAKT_LISTS entries must be 'watchlist' or 'user/list-slug', got %q", list)
+			}
+		}
+	}
+
+	if len(c.LetterboxdLists) > 0 && c.DataDir == "" {
+		return fmt.Errorf("DATA_DIR is required when LETTERBOXD_LISTS is set")
+	}
+
+	if len(c.MDBListLists) > 0 && c.DataDir == "" {
+		return fmt.Errorf("DATA_DIR is required when MDBLIST_LISTS is set")
+	}
+
+	switch c.AvailabilitySource {
+	case "":
+	case "tmdb", "justwatch":
+		if c.DataDir == "" {
+			return fmt.Errorf("DATA_DIR is required when AVAILABILITY_SOURCE is set")
+		}
+		if strings.Count(c.AvailabilityLabelFormat, "%s") != 1 || strings.Count(c.LeavingSoonLabelFormat, "%s") != 1 {
+			return fmt.Errorf("AVAILABILITY_LABEL_FORMAT and LEAVING_SOON_LABEL_FORMAT must contain %%s exactly once")
+		}
+	default:
+		return fmt.Errorf("AVAILABILITY_SOURCE must be 'tmdb' or 'justwatch'")
+	}
+
+	if c.UsesTautulli() {
+		if c.TautulliURL == "" || c.TautulliAPIKey == "" {
+			return fmt.Errorf("TAUTULLI_URL and TAUTULLI_API_KEY are required for Tautulli labels")
+		}
+		if c.DataDir == "" {
+			return fmt.Errorf("DATA_DIR is required for Tautulli labels")
+		}
+		if !c.UsesPlex() {
+			return fmt.Errorf("TAUTULLI_* labels are only available with Plex")
+		}
+	}
+
+	if c.OverseerrLabels {
+		if c.OverseerrURL == "" || c.OverseerrAPIKey == "" {
+			return fmt.Errorf("OVERSEERR_URL and OVERSEERR_API_KEY are required for Overseerr labels")
+		}
+		if strings.Count(c.OverseerrLabelFormat, "%s") > 1 {
+			return fmt.Errorf("OVERSEERR_LABEL_FORMAT must contain %%s at most once")
 		}
 	}
 
+	if c.BazarrMissingLabel != "" {
+		if c.BazarrURL == "" || c.BazarrAPIKey == "" {
+			return fmt.Errorf("BAZARR_URL and BAZARR_API_KEY are required for Bazarr labels")
+		}
+		// Bazarr identifies items by their Radarr/Sonarr IDs.
+		if !c.UseRadarr && !c.UseSonarr {
+			return fmt.Errorf("BAZARR_MISSING_LABEL requires USE_RADARR=true or USE_SONARR=true")
+		}
+	}
+
+	if c.TMDbRateLimit < 0 {
+		return fmt.Errorf("TMDB_RATE_LIMIT must be 0 or greater")
+	}
+
+	if c.TMDbSearchMinConfidence < 0 || c.TMDbSearchMinConfidence > 1 {
+		return fmt.Errorf("TMDB_SEARCH_MIN_CONFIDENCE must be between 0 and 1")
+	}
+
 	// Validate batch processing configuration
 	if c.BatchSize <= 0 {
 		return fmt.Errorf("BATCH_SIZE must be greater than 0")
@@ -213,6 +1132,9 @@ func (c *Config) Validate() error {
 	if c.ItemDelay < 0 {
 		return fmt.Errorf("ITEM_DELAY must be 0 or greater")
 	}
+	if c.AdaptiveDelay && c.MaxItemDelay <= 0 {
+		return fmt.Errorf("MAX_ITEM_DELAY must be greater than 0")
+	}
 
 	return nil
 }
@@ -224,6 +1146,15 @@ func getEnvWithDefault(envVar, defaultValue string) string {
 	return defaultValue
 }
 
+// getSetEnvWithDefault is getEnvWithDefault for variables where an
+// explicitly empty value turns the default off.
+func getSetEnvWithDefault(envVar, defaultValue string) string {
+	if value, ok := os.LookupEnv(envVar); ok {
+		return value
+	}
+	return defaultValue
+}
+
 func getBoolEnvWithDefault(envVar string, defaultValue bool) bool {
 	value := os.Getenv(envVar)
 	if value == "" {
@@ -252,6 +1183,65 @@ func getIntEnvWithDefault(envVar string, defaultValue int) int {
 	return result
 }
 
+// ParseScoreRule parses an OMDB_SCORE_LABELS key such as "rt:90" into its
+// score source (rt, metacritic, or imdb) and minimum score.
+func ParseScoreRule(rule string) (string, float64, error) {
+	source, threshold, ok := strings.Cut(rule, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("rule %q must be source:minimum (e.g. rt:90)", rule)
+	}
+	switch source {
+	case "rt", "metacritic", "imdb":
+	default:
+		return "", 0, fmt.Errorf("rule %q has unknown source %q (use rt, metacritic, or imdb)", rule, source)
+	}
+	minScore, err := strconv.ParseFloat(threshold, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("rule %q has a non-numeric minimum", rule)
+	}
+	return source, minScore, nil
+}
+
+// CompileKeywordPattern compiles a KEYWORD_PATTERN or KEYWORD_EXCLUDE_PATTERN
+// regular expression, case-insensitively. An empty pattern returns nil.
+func CompileKeywordPattern(pattern string) (*regexp.Regexp, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// ParseSizeRule parses a SIZE_LABELS key such as "size>60" into its metric
+// (size in GB or bitrate in Mbps), whether the value must be above or below
+// the threshold, and the threshold.
+func ParseSizeRule(rule string) (string, bool, float64, error) {
+	index := strings.IndexAny(rule, "<>")
+	if index < 0 {
+		return "", false, 0, fmt.Errorf("rule %q must be metric>value or metric<value (e.g. size>60)", rule)
+	}
+	metric := strings.TrimSpace(rule[:index])
+	if metric != "size" && metric != "bitrate" {
+		return "", false, 0, fmt.Errorf("rule %q has unknown metric %q (use size or bitrate)", rule, metric)
+	}
+	threshold, err := strconv.ParseFloat(strings.TrimSpace(rule[index+1:]), 64)
+	if err != nil {
+		return "", false, 0, fmt.Errorf("rule %q has a non-numeric threshold", rule)
+	}
+	return metric, rule[index] == '>', threshold, nil
+}
+
+func getFloatEnvWithDefault(envVar string, defaultValue float64) float64 {
+	value := os.Getenv(envVar)
+	if value == "" {
+		return defaultValue
+	}
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return result
+}
+
 func getDurationEnvWithDefault(envVar string, defaultValue string) time.Duration {
 	value := getEnvWithDefault(envVar, defaultValue)
 	duration, err := time.ParseDuration(value)
@@ -277,7 +1267,139 @@ func parseCSV(s string) []string {
 	return out
 }
 
+// parseScheduleList splits SCHEDULE into cron expressions. Expressions are
+// separated by semicolons, since commas are part of cron syntax.
+func parseScheduleList(s string) []string {
+	var out []string
+	for _, expr := range strings.Split(s, ";") {
+		if expr = strings.TrimSpace(expr); expr != "" {
+			out = append(out, expr)
+		}
+	}
+	return out
+}
+
+// ArrInstance is one configured Radarr or Sonarr server
+type ArrInstance struct {
+	Name     string // env var prefix, e.g. "RADARR" or "RADARR_2"
+	URL      string // includes <prefix>_URL_BASE, if set
+	APIKey   string
+	Headers  map[string]string // <prefix>_HEADERS, sent with every request
+	Username string            // <prefix>_USERNAME, for reverse-proxy basic auth
+	Password string
+}
+
+// parseArrInstances reads the <prefix>_URL instance followed by every
+// numbered <prefix>_<n>_URL instance in the environment, in numeric order.
+// Gaps in the numbering are allowed.
+func parseArrInstances(prefix string) []ArrInstance {
+	var instances []ArrInstance
+	if instance, ok := parseArrInstance(prefix); ok {
+		instances = append(instances, instance)
+	}
+	var numbers []int
+	for _, entry := range os.Environ() {
+		key, _, _ := strings.Cut(entry, "=")
+		rest, ok := strings.CutPrefix(key, prefix+"_")
+		if !ok {
+			continue
+		}
+		digits, ok := strings.CutSuffix(rest, "_URL")
+		if !ok {
+			continue
+		}
+		// Only plain numbers, so RADARR_01_URL doesn't shadow RADARR_1_URL
+		if n, err := strconv.Atoi(digits); err == nil && n > 0 && strconv.Itoa(n) == digits {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	for _, n := range numbers {
+		if instance, ok := parseArrInstance(fmt.Sprintf("%s_%d", prefix, n)); ok {
+			instances = append(instances, instance)
+		}
+	}
+	return instances
+}
+
+// parseArrInstance reads one instance's settings; ok is false when
+// <name>_URL is unset. A URL base such as "/radarr" is joined onto the URL.
+func parseArrInstance(name string) (ArrInstance, bool) {
+	url := os.Getenv(name + "_URL")
+	if url == "" {
+		return ArrInstance{}, false
+	}
+	if base := strings.Trim(os.Getenv(name+"_URL_BASE"), "/"); base != "" {
+		url = strings.TrimRight(url, "/") + "/" + base
+	}
+	instance := ArrInstance{
+		Name:     name,
+		URL:      url,
+		APIKey:   os.Getenv(name + "_API_KEY"),
+		Username: os.Getenv(name + "_USERNAME"),
+		Password: os.Getenv(name + "_PASSWORD"),
+	}
+	if headers := parseHeaderCSV(os.Getenv(name + "_HEADERS")); len(headers) > 0 {
+		instance.Headers = headers
+	}
+	return instance, true
+}
+
+// parseHeaderCSV parses "Name=Value,Name2=Value2" into a map of HTTP
+// headers. Unlike parseKeyValueCSV the names keep their case.
+func parseHeaderCSV(s string) map[string]string {
+	out := make(map[string]string)
+	for _, entry := range parseCSV(s) {
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			continue
+		}
+		out[name] = value
+	}
+	return out
+}
+
+// parseKeyValueCSV parses "key=value,key2=value2" into a map. Keys are
+// lowercased; entries without "=" or with an empty key/value are ignored.
+func parseKeyValueCSV(s string) map[string]string {
+	out := make(map[string]string)
+	for _, entry := range parseCSV(s) {
+		key, value, ok := strings.Cut(entry, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			continue
+		}
+		out[key] = value
+	}
+	return out
+}
+
+// parseOptionalLabelCSV parses "ref=Label,ref" into a map of ref -> label,
+// where the label may be empty. Unlike parseKeyValueCSV the refs keep their
+// case, since they may be URLs or file paths.
+func parseOptionalLabelCSV(s string) map[string]string {
+	out := make(map[string]string)
+	for _, entry := range parseCSV(s) {
+		ref, label, _ := strings.Cut(entry, "=")
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			continue
+		}
+		out[ref] = strings.TrimSpace(label)
+	}
+	return out
+}
+
+// HasExportUploadEnabled returns true if export files should be uploaded to
+// S3-compatible storage after they are written
+func (c *Config) HasExportUploadEnabled() bool {
+	return c.ExportS3Bucket != ""
+}
+
 // HasExportEnabled returns true if export functionality is enabled
 func (c *Config) HasExportEnabled() bool {
-	return len(c.ExportLabels) > 0 && c.ExportLocation != ""
+	return (len(c.ExportLabels) > 0 || len(c.ExportExclude) > 0) && c.ExportLocation != ""
 }
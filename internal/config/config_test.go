@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -195,3 +196,142 @@ func TestBackwardCompatibility(t *testing.T) {
 		t.Errorf("Expected default ProcessTimer 1h, got %v", config.ProcessTimer)
 	}
 }
+
+func TestExcludeLabelsCanBeEmptied(t *testing.T) {
+	t.Setenv("EXCLUDE_LABELS", "")
+	if labels := parseCSV(getSetEnvWithDefault("EXCLUDE_LABELS", "labelarr-skip")); len(labels) != 0 {
+		t.Errorf("empty EXCLUDE_LABELS = %v, want none", labels)
+	}
+	os.Unsetenv("EXCLUDE_LABELS")
+	if labels := parseCSV(getSetEnvWithDefault("EXCLUDE_LABELS", "labelarr-skip")); len(labels) != 1 || labels[0] != "labelarr-skip" {
+		t.Errorf("unset EXCLUDE_LABELS = %v, want the default", labels)
+	}
+}
+
+func TestProtectManualLabelsDefault(t *testing.T) {
+	os.Unsetenv("PROTECT_MANUAL_LABELS")
+	t.Setenv("DATA_DIR", t.TempDir())
+	if Load().ProtectManualLabels {
+		t.Error("PROTECT_MANUAL_LABELS defaulted to true with DATA_DIR")
+	}
+	t.Setenv("PROTECT_MANUAL_LABELS", "true")
+	if !Load().ProtectManualLabels {
+		t.Error("PROTECT_MANUAL_LABELS=true was ignored")
+	}
+}
+
+func TestParseKeyValueCSV(t *testing.T) {
+	got := parseKeyValueCSV(" KO = Korean Cinema ,fr=French Cinema,broken,=empty,de=")
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 entries, got %d: %v", len(got), got)
+	}
+	if got["ko"] != "Korean Cinema" {
+		t.Errorf("Expected ko -> 'Korean Cinema', got %q", got["ko"])
+	}
+	if got["fr"] != "French Cinema" {
+		t.Errorf("Expected fr -> 'French Cinema', got %q", got["fr"])
+	}
+}
+
+func TestParseScoreRule(t *testing.T) {
+	source, minScore, err := ParseScoreRule("metacritic:80")
+	if err != nil || source != "metacritic" || minScore != 80 {
+		t.Errorf("ParseScoreRule(metacritic:80) = %q, %v, %v", source, minScore, err)
+	}
+
+	source, minScore, err = ParseScoreRule("imdb:7.5")
+	if err != nil || source != "imdb" || minScore != 7.5 {
+		t.Errorf("ParseScoreRule(imdb:7.5) = %q, %v, %v", source, minScore, err)
+	}
+
+	for _, bad := range []string{"rt", "tomatoes:90", "rt:fresh"} {
+		if _, _, err := ParseScoreRule(bad); err == nil {
+			t.Errorf("Expected error for rule %q", bad)
+		}
+	}
+}
+
+func TestParseSizeRule(t *testing.T) {
+	metric, above, threshold, err := ParseSizeRule("size>60")
+	if err != nil || metric != "size" || !above || threshold != 60 {
+		t.Errorf("ParseSizeRule(size>60) = %q, %v, %v, %v", metric, above, threshold, err)
+	}
+
+	metric, above, threshold, err = ParseSizeRule("bitrate<4.5")
+	if err != nil || metric != "bitrate" || above || threshold != 4.5 {
+		t.Errorf("ParseSizeRule(bitrate<4.5) = %q, %v, %v, %v", metric, above, threshold, err)
+	}
+
+	for _, bad := range []string{"size", "runtime>90", "size>big"} {
+		if _, _, _, err := ParseSizeRule(bad); err == nil {
+			t.Errorf("Expected error for rule %q", bad)
+		}
+	}
+}
+
+func TestCompileKeywordPattern(t *testing.T) {
+	if re, err := CompileKeywordPattern(""); re != nil || err != nil {
+		t.Errorf("CompileKeywordPattern(\"\") = %v, %v, want nil, nil", re, err)
+	}
+
+	re, err := CompileKeywordPattern("relationship")
+	if err != nil {
+		t.Fatalf("CompileKeywordPattern() error = %v", err)
+	}
+	if !re.MatchString("Father Son Relationship") || re.MatchString("Heist") {
+		t.Errorf("pattern %q should match case-insensitively", re)
+	}
+
+	if _, err := CompileKeywordPattern("(unclosed"); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestParseArrInstances(t *testing.T) {
+	t.Setenv("RADARR_URL", "http://radarr:7878")
+	t.Setenv("RADARR_API_KEY", "key")
+	t.Setenv("RADARR_1_URL", "http://radarr4k:7878")
+	t.Setenv("RADARR_1_API_KEY", "key4k")
+	t.Setenv("RADARR_3_URL", "http://radarr-anime:7878")
+	t.Setenv("RADARR_3_URL_BASE", "")
+
+	got := parseArrInstances("RADARR")
+	want := []ArrInstance{
+		{Name: "RADARR", URL: "http://radarr:7878", APIKey: "key"},
+		{Name: "RADARR_1", URL: "http://radarr4k:7878", APIKey: "key4k"},
+		{Name: "RADARR_3", URL: "http://radarr-anime:7878"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseArrInstances() = %v, want %v", got, want)
+	}
+}
+
+func TestParseArrInstanceProxySettings(t *testing.T) {
+	t.Setenv("SONARR_URL", "https://example.com/")
+	t.Setenv("SONARR_API_KEY", "key")
+	t.Setenv("SONARR_URL_BASE", "/sonarr/")
+	t.Setenv("SONARR_HEADERS", "Remote-User=labelarr, X-Forwarded-Proto=https,broken")
+	t.Setenv("SONARR_USERNAME", "user")
+	t.Setenv("SONARR_PASSWORD", "pass")
+
+	got := parseArrInstances("SONARR")
+	want := []ArrInstance{{
+		Name:     "SONARR",
+		URL:      "https://example.com/sonarr",
+		APIKey:   "key",
+		Headers:  map[string]string{"Remote-User": "labelarr", "X-Forwarded-Proto": "https"},
+		Username: "user",
+		Password: "pass",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseArrInstances() = %v, want %v", got, want)
+	}
+}
+
+func TestParseScheduleList(t *testing.T) {
+	got := parseScheduleList(" 0 3 * * * ; ;30 13 * * sat,sun")
+	want := []string{"0 3 * * *", "30 13 * * sat,sun"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("parseScheduleList() = %q, want %q", got, want)
+	}
+}
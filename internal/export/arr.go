@@ -0,0 +1,75 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ArrItem is one movie or show in an EXPORT_MODE=arr list
+type ArrItem struct {
+	TMDbID int    `json:"tmdbId"`
+	Title  string `json:"title"`
+	Year   int    `json:"year,omitempty"`
+}
+
+// flushArr writes <library>/<label>.json per library and export label: the
+// items' TMDb IDs, for Radarr/Sonarr lists and exclusions.
+func (e *Exporter) flushArr() error {
+	for _, libraryName := range e.libraryNames() {
+		libraryPath, err := e.safeJoin(libraryName)
+		if err != nil {
+			return fmt.Errorf("invalid library path: %w", err)
+		}
+		if err := os.MkdirAll(libraryPath, 0755); err != nil {
+			return fmt.Errorf("failed to create library directory %s: %w", libraryPath, err)
+		}
+
+		for _, label := range e.exportLabels {
+			fileInfos, err := e.entries(libraryName, label)
+			if err != nil {
+				return err
+			}
+			data, err := json.MarshalIndent(arrItems(fileInfos), "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode arr list: %w", err)
+			}
+
+			filePath := filepath.Join(libraryPath, sanitizeFilename(label)+".json")
+			file, err := e.create(filePath)
+			if err != nil {
+				return fmt.Errorf("failed to create arr list %s: %w", filePath, err)
+			}
+			_, err = file.Write(append(data, '\n'))
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to write arr list %s: %w", filePath, err)
+			}
+		}
+	}
+
+	// Clear accumulated data after successful write
+	e.accumulated = make(map[string]map[string][]FileInfo)
+
+	return nil
+}
+
+// arrItems returns one ArrItem per TMDb ID in fileInfos, in order. Files of
+// items without a TMDb ID are left out.
+func arrItems(fileInfos []FileInfo) []ArrItem {
+	items := []ArrItem{}
+	seen := make(map[int]bool)
+	for _, fi := range fileInfos {
+		id, err := strconv.Atoi(fi.TMDbID)
+		if err != nil || id <= 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		items = append(items, ArrItem{TMDbID: id, Title: fi.Title, Year: fi.Year})
+	}
+	return items
+}
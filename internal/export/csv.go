@@ -0,0 +1,84 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvHeader is the header row of export.csv.
+var csvHeader = []string{"library", "title", "year", "label", "path", "size"}
+
+// csvMetadataHeader holds the extra columns written with EXPORT_METADATA.
+var csvMetadataHeader = []string{"rating_key", "tmdb_id", "added_at", "labels"}
+
+// flushCSV writes all accumulated data to export.csv in dir, one row per
+// file and label. A file whose item has several export labels appears once
+// per label.
+func (e *Exporter) flushCSV(dir string) error {
+	csvPath, err := e.safeJoin(dir, "export.csv")
+	if err != nil {
+		return fmt.Errorf("invalid CSV export path: %w", err)
+	}
+	file, err := e.create(csvPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV export file: %w", err)
+	}
+	defer file.Close()
+
+	if err := e.writeCSV(file); err != nil {
+		return fmt.Errorf("failed to write CSV export file: %w", err)
+	}
+
+	// Clear accumulated data after successful write
+	e.accumulated = make(map[string]map[string][]FileInfo)
+
+	return nil
+}
+
+// writeCSV writes the accumulated rows, libraries sorted by name and labels
+// in EXPORT_LABELS order.
+func (e *Exporter) writeCSV(w io.Writer) error {
+	header := csvHeader
+	if e.withMetadata {
+		header = append(append([]string(nil), csvHeader...), csvMetadataHeader...)
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, libraryName := range e.libraryNames() {
+		for _, label := range e.exportLabels {
+			fileInfos, err := e.entries(libraryName, label)
+			if err != nil {
+				return err
+			}
+			for _, fi := range fileInfos {
+				year := ""
+				if fi.Year > 0 {
+					year = strconv.Itoa(fi.Year)
+				}
+				row := []string{libraryName, fi.Title, year, label, fi.Path, strconv.FormatInt(fi.Size, 10)}
+				if e.withMetadata {
+					row = append(row, csvMetadataRow(fi.Metadata)...)
+				}
+				if err := writer.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvMetadataRow returns the csvMetadataHeader columns for an entry. All
+// labels go in one column, separated by "; ".
+func csvMetadataRow(metadata *ItemMetadata) []string {
+	if metadata == nil {
+		return make([]string, len(csvMetadataHeader))
+	}
+	return []string{metadata.RatingKey, metadata.TMDbID, metadata.AddedAt, strings.Join(metadata.Labels, "; ")}
+}
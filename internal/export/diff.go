@@ -0,0 +1,158 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// diffStateFile holds the paths of the last flush, per library and label,
+// for EXPORT_DIFF. It is not uploaded.
+const diffStateFile = ".export-state.json"
+
+// diffState maps library -> label -> sorted paths.
+type diffState map[string]map[string][]string
+
+// SetDiff turns on EXPORT_DIFF: each flush also writes the paths added and
+// removed per label since the previous flush.
+func (e *Exporter) SetDiff(enabled bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.diff = enabled
+}
+
+// MarkIncomplete marks the accumulated data as partial, e.g. after a scan
+// was stopped, so the next flush removes the old diff files without writing
+// new ones and keeps the previous state, rather than report every unscanned
+// item as removed.
+func (e *Exporter) MarkIncomplete() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.incomplete = true
+}
+
+// writeDiff writes diff/<library>/<label>/added.txt and removed.txt for
+// every library in this flush, then saves the new state. Libraries not in
+// this flush keep their previous state.
+func (e *Exporter) writeDiff() error {
+	statePath, err := e.safeJoin(diffStateFile)
+	if err != nil {
+		return fmt.Errorf("invalid diff state path: %w", err)
+	}
+	previous := diffState{}
+	data, err := os.ReadFile(statePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read diff state: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &previous); err != nil {
+			return fmt.Errorf("failed to parse diff state: %w", err)
+		}
+	}
+
+	e.lastDiff = make(map[string]map[string]LabelDiff)
+	for _, libraryName := range e.libraryNames() {
+		current := make(map[string][]string, len(e.exportLabels))
+		e.lastDiff[libraryName] = make(map[string]LabelDiff, len(e.exportLabels))
+		for _, label := range e.exportLabels {
+			fileInfos, err := e.entries(libraryName, label)
+			if err != nil {
+				return err
+			}
+			paths := uniquePaths(fileInfos)
+			current[label] = paths
+			added, removed := diffPaths(previous[libraryName][label], paths)
+			e.lastDiff[libraryName][label] = LabelDiff{
+				Added:   append([]string{}, added...),
+				Removed: append([]string{}, removed...),
+			}
+
+			labelDir, err := e.safeJoin("diff", libraryName, sanitizeFilename(label))
+			if err != nil {
+				return fmt.Errorf("invalid diff path: %w", err)
+			}
+			if err := os.MkdirAll(labelDir, 0755); err != nil {
+				return fmt.Errorf("failed to create diff directory %s: %w", labelDir, err)
+			}
+			if err := e.writeLines(filepath.Join(labelDir, "added.txt"), added); err != nil {
+				return err
+			}
+			if err := e.writeLines(filepath.Join(labelDir, "removed.txt"), removed); err != nil {
+				return err
+			}
+		}
+		previous[libraryName] = current
+	}
+
+	data, err = json.MarshalIndent(previous, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode diff state: %w", err)
+	}
+	if err := os.WriteFile(statePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to save diff state: %w", err)
+	}
+	return nil
+}
+
+// clearDiff removes the diff files of the previous flush.
+func (e *Exporter) clearDiff() error {
+	diffDir, err := e.safeJoin("diff")
+	if err != nil {
+		return fmt.Errorf("invalid diff path: %w", err)
+	}
+	if err := os.RemoveAll(diffDir); err != nil {
+		return fmt.Errorf("failed to clear diff directory: %w", err)
+	}
+	return nil
+}
+
+// writeLines writes one line per entry to path, recording it as written.
+func (e *Exporter) writeLines(path string, lines []string) error {
+	file, err := e.create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create diff file %s: %w", path, err)
+	}
+	defer file.Close()
+	for _, line := range lines {
+		if _, err := fmt.Fprintf(file, "%s\n", line); err != nil {
+			return fmt.Errorf("failed to write diff file %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// uniquePaths returns the sorted, de-duplicated paths of fileInfos.
+func uniquePaths(fileInfos []FileInfo) []string {
+	seen := make(map[string]bool, len(fileInfos))
+	paths := make([]string, 0, len(fileInfos))
+	for _, fi := range fileInfos {
+		if !seen[fi.Path] {
+			seen[fi.Path] = true
+			paths = append(paths, fi.Path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// diffPaths returns the paths in current but not previous, and in previous
+// but not current. Both inputs and outputs are sorted.
+func diffPaths(previous, current []string) (added, removed []string) {
+	i, j := 0, 0
+	for i < len(previous) || j < len(current) {
+		switch {
+		case j == len(current) || (i < len(previous) && previous[i] < current[j]):
+			removed = append(removed, previous[i])
+			i++
+		case i == len(previous) || current[j] < previous[i]:
+			added = append(added, current[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return added, removed
+}
@@ -1,19 +1,49 @@
 package export
 
 import (
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
 )
 
 // FileInfo represents a file with its path and size
 type FileInfo struct {
 	Path string `json:"path"`
 	Size int64  `json:"size"`
+
+	// Title, Year and TMDbID identify the item the file belongs to. They are
+	// set by ExportItemWithSizes for the CSV and arr exports and left out of
+	// the JSON.
+	Title  string `json:"-"`
+	Year   int    `json:"-"`
+	TMDbID string `json:"-"`
+
+	// Metadata describes the item in full; set only with EXPORT_METADATA.
+	Metadata *ItemMetadata `json:"metadata,omitempty"`
+}
+
+// ItemMetadata describes the item an exported file belongs to, so an export
+// can double as a library inventory
+type ItemMetadata struct {
+	Title     string   `json:"title"`
+	Year      int      `json:"year,omitempty"`
+	RatingKey string   `json:"rating_key"`
+	TMDbID    string   `json:"tmdb_id,omitempty"`
+	Labels    []string `json:"labels"`
+	AddedAt   string   `json:"added_at,omitempty"` // RFC 3339, UTC
+
+	// Match holds the values matched against the export labels, when they
+	// come from another field than Labels (EXPORT_MATCH_FIELD)
+	Match []string `json:"-"`
 }
 
 // JSONExportData represents the complete export data in JSON format
@@ -51,25 +81,54 @@ type JSONLabelStats struct {
 // Exporter handles exporting file paths based on labels
 type Exporter struct {
 	exportLocation string
-	exportLabels   []string
+	exportLabels   []string          // export lists, including inverse ones
+	inverse        map[string]string // inverse list name -> excluded label
 	exportMode     string
 	currentLibrary string                           // Current library being processed
 	accumulated    map[string]map[string][]FileInfo // library -> label -> list of file info
+	written        []string                         // files written by the last flush, relative to exportLocation
+	template       *template.Template               // EXPORT_TEMPLATE, for template mode
+	templateExt    string                           // extension of template output files
+	withMetadata   bool                             // attach ItemMetadata to every entry
+	pathMappings   map[string]string                // path rewrites for every exported path
+	playlistPaths  map[string]string                // path rewrites for m3u playlists
+	diff           bool                             // EXPORT_DIFF
+	incomplete     bool                             // accumulated data is partial; skip the diff
+	linkType       string                           // "symlink" or "hardlink", for links mode
+	snapshots      int                              // snapshots to keep; 0 disables them
+	gzip           bool                             // EXPORT_GZIP
+	splitLibraries bool                             // one export file per library, for json, csv and yaml
+	sortBy         string                           // "path", "title" or "" for processing order
+	stream         bool                             // EXPORT_STREAM: spool entries to disk instead of accumulated
+	spooled        map[string]map[string]*spoolFile // library -> label -> spool, with EXPORT_STREAM
+	spoolCount     int                              // spool files created, for naming them
+
+	// The last flush, for WebhookPayload
+	lastExport     *JSONExportData
+	lastDiff       map[string]map[string]LabelDiff
+	lastIncomplete bool
 	mutex          sync.Mutex
 }
 
-// NewExporter creates a new Exporter instance
-func NewExporter(exportLocation string, exportLabels []string, exportMode string) (*Exporter, error) {
+// InverseListName returns the name of the export list holding every item
+// without label, for EXPORT_EXCLUDE_LABELS
+func InverseListName(label string) string {
+	return "not-" + label
+}
+
+// NewExporter creates a new Exporter instance. Each of excludeLabels adds an
+// inverse list, named by InverseListName, of the items without that label.
+func NewExporter(exportLocation string, exportLabels, excludeLabels []string, exportMode string) (*Exporter, error) {
 	if exportLocation == "" {
 		return nil, fmt.Errorf("export location cannot be empty")
 	}
 
-	if len(exportLabels) == 0 {
+	if len(exportLabels) == 0 && len(excludeLabels) == 0 {
 		return nil, fmt.Errorf("export labels cannot be empty")
 	}
 
-	if exportMode != "txt" && exportMode != "json" {
-		return nil, fmt.Errorf("export mode must be 'txt' or 'json'")
+	if exportMode != "txt" && exportMode != "json" && exportMode != "csv" && exportMode != "yaml" && exportMode != "template" && exportMode != "m3u" && exportMode != "links" && exportMode != "prom" && exportMode != "arr" {
+		return nil, fmt.Errorf("export mode must be 'txt', 'json', 'csv', 'yaml', 'template', 'm3u', 'links', 'prom' or 'arr'")
 	}
 
 	// Create the export directory if it doesn't exist
@@ -77,11 +136,21 @@ func NewExporter(exportLocation string, exportLabels []string, exportMode string
 		return nil, fmt.Errorf("failed to create export directory: %w", err)
 	}
 
+	lists := append([]string(nil), exportLabels...)
+	inverse := make(map[string]string, len(excludeLabels))
+	for _, label := range excludeLabels {
+		name := InverseListName(label)
+		lists = append(lists, name)
+		inverse[name] = label
+	}
+
 	return &Exporter{
 		exportLocation: exportLocation,
-		exportLabels:   exportLabels,
+		exportLabels:   lists,
+		inverse:        inverse,
 		exportMode:     exportMode,
 		accumulated:    make(map[string]map[string][]FileInfo),
+		spooled:        make(map[string]map[string]*spoolFile),
 	}, nil
 }
 
@@ -110,15 +179,35 @@ func (e *Exporter) SetCurrentLibrary(libraryName string) error {
 	}
 
 	// Initialize accumulated map for this library if it doesn't exist
-	if e.accumulated[sanitizedName] == nil {
+	if e.stream {
+		if e.spooled[sanitizedName] == nil {
+			e.spooled[sanitizedName] = make(map[string]*spoolFile)
+		}
+	} else if e.accumulated[sanitizedName] == nil {
 		e.accumulated[sanitizedName] = make(map[string][]FileInfo)
 	}
 
 	return nil
 }
 
-// ExportItemWithSizes checks if an item has any of the export labels and accumulates its file info
-func (e *Exporter) ExportItemWithSizes(title string, itemLabels []string, fileInfos []FileInfo) error {
+// SetPathMappings sets the prefix rewrites (from -> to) applied to every
+// exported path as it is accumulated
+func (e *Exporter) SetPathMappings(mappings map[string]string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.pathMappings = mappings
+}
+
+// SetIncludeMetadata turns per-entry item metadata on or off
+func (e *Exporter) SetIncludeMetadata(include bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.withMetadata = include
+}
+
+// ExportItemWithSizes checks if an item has any of the export labels
+// (item.Labels) and accumulates its file info
+func (e *Exporter) ExportItemWithSizes(item ItemMetadata, fileInfos []FileInfo) error {
 	if len(fileInfos) == 0 {
 		return nil // Nothing to export
 	}
@@ -131,15 +220,24 @@ func (e *Exporter) ExportItemWithSizes(title string, itemLabels []string, fileIn
 	}
 
 	// Convert item labels to lowercase for case-insensitive comparison
+	match := item.Labels
+	if item.Match != nil {
+		match = item.Match
+	}
 	itemLabelsMap := make(map[string]bool)
-	for _, label := range itemLabels {
+	for _, label := range match {
 		itemLabelsMap[strings.ToLower(strings.TrimSpace(label))] = true
 	}
 
-	// Check which export labels this item has
+	// Check which export labels this item has, and which excluded labels
+	// it lacks
 	var matchingLabels []string
 	for _, exportLabel := range e.exportLabels {
-		if itemLabelsMap[strings.ToLower(strings.TrimSpace(exportLabel))] {
+		if excluded, ok := e.inverse[exportLabel]; ok {
+			if !itemLabelsMap[strings.ToLower(strings.TrimSpace(excluded))] {
+				matchingLabels = append(matchingLabels, exportLabel)
+			}
+		} else if itemLabelsMap[strings.ToLower(strings.TrimSpace(exportLabel))] {
 			matchingLabels = append(matchingLabels, exportLabel)
 		}
 	}
@@ -148,6 +246,28 @@ func (e *Exporter) ExportItemWithSizes(title string, itemLabels []string, fileIn
 		return nil // Item doesn't have any of the export labels
 	}
 
+	var metadata *ItemMetadata
+	if e.withMetadata {
+		metadata = &item
+		metadata.Labels = append([]string(nil), item.Labels...)
+	}
+	stamped := make([]FileInfo, len(fileInfos))
+	for i, fi := range fileInfos {
+		fi.Title, fi.Year, fi.TMDbID, fi.Metadata = item.Title, item.Year, item.TMDbID, metadata
+		fi.Path = utils.RewritePath(fi.Path, e.pathMappings)
+		stamped[i] = fi
+	}
+	fileInfos = stamped
+
+	if e.stream {
+		for _, label := range matchingLabels {
+			if err := e.spool(label, fileInfos); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// Ensure library exists in accumulated map
 	if e.accumulated[e.currentLibrary] == nil {
 		e.accumulated[e.currentLibrary] = make(map[string][]FileInfo)
@@ -176,7 +296,7 @@ func (e *Exporter) ExportItem(title string, itemLabels []string, filePaths []str
 		fileInfos[i] = FileInfo{Path: path, Size: 0}
 	}
 
-	return e.ExportItemWithSizes(title, itemLabels, fileInfos)
+	return e.ExportItemWithSizes(ItemMetadata{Title: title, Labels: itemLabels}, fileInfos)
 }
 
 // FlushAll writes all accumulated file paths to their respective files based on export mode
@@ -185,20 +305,76 @@ func (e *Exporter) FlushAll() error {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 
+	e.written = nil
+	// With EXPORT_STREAM the full export is never in memory, so the webhook
+	// goes without it
+	e.lastExport, e.lastDiff = nil, nil
+	if e.stream {
+		if err := e.normalizeSpool(); err != nil {
+			return err
+		}
+	} else {
+		e.normalize()
+		lastExport := e.buildJSONExportData()
+		e.lastExport = &lastExport
+	}
+	e.lastIncomplete, e.incomplete = e.incomplete, false
+	if e.diff {
+		if err := e.clearDiff(); err != nil {
+			return fmt.Errorf("failed to write export diff: %w", err)
+		}
+		if !e.lastIncomplete {
+			if err := e.writeDiff(); err != nil {
+				return fmt.Errorf("failed to write export diff: %w", err)
+			}
+		}
+	}
+
+	var err error
 	switch e.exportMode {
 	case "txt":
-		return e.flushTxt()
+		err = e.flushTxt()
 	case "json":
-		return e.flushJSON()
+		err = e.flushSplit(e.flushJSON)
+	case "csv":
+		err = e.flushSplit(e.flushCSV)
+	case "yaml":
+		err = e.flushSplit(e.flushYAML)
+	case "template":
+		err = e.flushTemplate()
+	case "m3u":
+		err = e.flushM3U()
+	case "links":
+		err = e.flushLinks()
+	case "prom":
+		err = e.flushProm()
+	case "arr":
+		err = e.flushArr()
 	default:
-		return fmt.Errorf("unsupported export mode: %s", e.exportMode)
+		err = fmt.Errorf("unsupported export mode: %s", e.exportMode)
+	}
+	if err != nil {
+		return err
+	}
+	if e.stream {
+		if err := e.clearSpool(); err != nil {
+			return err
+		}
 	}
+
+	// A partial export would push a complete snapshot out of retention
+	if e.snapshots > 0 && !e.lastIncomplete {
+		if err := e.snapshot(time.Now()); err != nil {
+			return fmt.Errorf("failed to write export snapshot: %w", err)
+		}
+	}
+	return nil
 }
 
 // flushTxt writes all accumulated file paths to library-specific txt files
 func (e *Exporter) flushTxt() error {
 	// Process each library
-	for libraryName, libraryData := range e.accumulated {
+	for _, libraryName := range e.libraryNames() {
 		libraryPath, err := e.safeJoin(libraryName)
 		if err != nil {
 			return fmt.Errorf("invalid library path: %w", err)
@@ -215,10 +391,13 @@ func (e *Exporter) flushTxt() error {
 			filePath := filepath.Join(libraryPath, filename)
 
 			// Get accumulated file info for this label in this library
-			fileInfos := libraryData[label]
+			fileInfos, err := e.entries(libraryName, label)
+			if err != nil {
+				return err
+			}
 			if len(fileInfos) == 0 {
 				// Create empty file for labels with no matches
-				file, err := os.Create(filePath)
+				file, err := e.create(filePath)
 				if err != nil {
 					return fmt.Errorf("failed to create export file %s: %w", filePath, err)
 				}
@@ -227,7 +406,7 @@ func (e *Exporter) flushTxt() error {
 			}
 
 			// Create/overwrite file and write all paths at once
-			file, err := os.Create(filePath)
+			file, err := e.create(filePath)
 			if err != nil {
 				return fmt.Errorf("failed to create export file %s: %w", filePath, err)
 			}
@@ -254,24 +433,27 @@ func (e *Exporter) flushTxt() error {
 	return nil
 }
 
-// flushJSON writes all accumulated data as a single JSON file
-func (e *Exporter) flushJSON() error {
-	jsonData := e.buildJSONExportData()
-
+// flushJSON writes all accumulated data as a single export.json in dir
+func (e *Exporter) flushJSON(dir string) error {
 	// Write JSON file
-	jsonPath, err := e.safeJoin("export.json")
+	jsonPath, err := e.safeJoin(dir, "export.json")
 	if err != nil {
 		return fmt.Errorf("invalid JSON export path: %w", err)
 	}
-	file, err := os.Create(jsonPath)
+	file, err := e.create(jsonPath)
 	if err != nil {
 		return fmt.Errorf("failed to create JSON export file: %w", err)
 	}
 	defer file.Close()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(jsonData); err != nil {
+	if e.stream {
+		err = e.writeJSONStream(file)
+	} else {
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(e.buildJSONExportData())
+	}
+	if err != nil {
 		return fmt.Errorf("failed to write JSON export file: %w", err)
 	}
 
@@ -288,7 +470,7 @@ func (e *Exporter) writeSummary() error {
 		return fmt.Errorf("invalid summary path: %w", err)
 	}
 
-	file, err := os.Create(summaryPath)
+	file, err := e.create(summaryPath)
 	if err != nil {
 		return fmt.Errorf("failed to create summary file: %w", err)
 	}
@@ -301,38 +483,17 @@ func (e *Exporter) writeSummary() error {
 	// Calculate totals
 	totalFiles := 0
 	totalSize := int64(0)
-	libraryStats := make(map[string]map[string]struct {
-		Count int
-		Size  int64
-	})
-
-	// Collect statistics
-	for libraryName, libraryData := range e.accumulated {
-		libraryStats[libraryName] = make(map[string]struct {
-			Count int
-			Size  int64
-		})
-
-		for label, fileInfos := range libraryData {
-			count := len(fileInfos)
-			size := int64(0)
-			for _, fi := range fileInfos {
-				size += fi.Size
-			}
-
-			libraryStats[libraryName][label] = struct {
-				Count int
-				Size  int64
-			}{Count: count, Size: size}
-
-			totalFiles += count
-			totalSize += size
+	libraryStats := e.labelStats()
+	for _, labelStats := range libraryStats {
+		for _, stats := range labelStats {
+			totalFiles += stats.Count
+			totalSize += stats.Size
 		}
 	}
 
 	// Write export file list
 	fmt.Fprintf(file, "[STORAGE] Export Files Generated:\n")
-	for libraryName := range libraryStats {
+	for _, libraryName := range e.libraryNames() {
 		for _, label := range e.exportLabels {
 			if stats, exists := libraryStats[libraryName][label]; exists && stats.Count > 0 {
 				fmt.Fprintf(file, "  %s/%s.txt\n", libraryName, sanitizeFilename(label))
@@ -349,7 +510,8 @@ func (e *Exporter) writeSummary() error {
 
 	// Write per-library breakdown
 	fmt.Fprintf(file, "[INFO] Library Breakdown:\n")
-	for libraryName, labelStats := range libraryStats {
+	for _, libraryName := range e.libraryNames() {
+		labelStats := libraryStats[libraryName]
 		fmt.Fprintf(file, "\n  %s:\n", libraryName)
 
 		libraryTotal := 0
@@ -376,18 +538,12 @@ func (e *Exporter) writeSummary() error {
 
 	// Write per-label totals across all libraries
 	fmt.Fprintf(file, "\n[LABEL] Label Totals (All Libraries):\n")
-	labelTotals := make(map[string]struct {
-		Count int
-		Size  int64
-	})
-
-	for _, libraryData := range e.accumulated {
-		for label, fileInfos := range libraryData {
+	labelTotals := make(map[string]labelStat)
+	for _, labelStats := range libraryStats {
+		for label, stats := range labelStats {
 			existing := labelTotals[label]
-			existing.Count += len(fileInfos)
-			for _, fi := range fileInfos {
-				existing.Size += fi.Size
-			}
+			existing.Count += stats.Count
+			existing.Size += stats.Size
 			labelTotals[label] = existing
 		}
 	}
@@ -404,6 +560,38 @@ func (e *Exporter) writeSummary() error {
 	return nil
 }
 
+// create creates or truncates path and records it as written by the current
+// flush. With EXPORT_GZIP the file is path + ".gz" and written compressed.
+func (e *Exporter) create(path string) (io.WriteCloser, error) {
+	if e.gzip {
+		path += ".gz"
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if rel, err := filepath.Rel(e.exportLocation, path); err == nil {
+		e.written = append(e.written, filepath.ToSlash(rel))
+	}
+	if e.gzip {
+		return &gzipFile{Writer: gzip.NewWriter(file), file: file}, nil
+	}
+	return file, nil
+}
+
+// WrittenFiles returns the files written by the last FlushAll, as
+// slash-separated paths relative to the export location.
+func (e *Exporter) WrittenFiles() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return append([]string(nil), e.written...)
+}
+
+// ExportLocation returns the directory export files are written to.
+func (e *Exporter) ExportLocation() string {
+	return e.exportLocation
+}
+
 // formatFileSize converts bytes to human-readable format
 func formatFileSize(bytes int64) string {
 	const unit = 1024
@@ -425,7 +613,7 @@ func (e *Exporter) ClearExportFiles() error {
 	defer e.mutex.Unlock()
 
 	// Remove all library subdirectories and their contents
-	for libraryName := range e.accumulated {
+	for _, libraryName := range e.libraryNames() {
 		libraryPath, err := e.safeJoin(libraryName)
 		if err != nil {
 			return fmt.Errorf("invalid library path: %w", err)
@@ -447,7 +635,7 @@ func (e *Exporter) ClearExportFiles() error {
 	// Clear accumulated data
 	e.accumulated = make(map[string]map[string][]FileInfo)
 
-	return nil
+	return e.clearSpool()
 }
 
 // GetExportSummary returns a summary of accumulated file counts (before flushing)
@@ -458,10 +646,9 @@ func (e *Exporter) GetExportSummary() (map[string]int, error) {
 	summary := make(map[string]int)
 
 	// Aggregate totals across all libraries for each label
-	for _, libraryData := range e.accumulated {
+	for _, labelStats := range e.labelStats() {
 		for _, label := range e.exportLabels {
-			fileInfos := libraryData[label]
-			summary[label] += len(fileInfos)
+			summary[label] += labelStats[label].Count
 		}
 	}
 
@@ -476,11 +663,10 @@ func (e *Exporter) GetLibraryExportSummary() (map[string]map[string]int, error)
 	summary := make(map[string]map[string]int)
 
 	// Process each library
-	for libraryName, libraryData := range e.accumulated {
+	for libraryName, labelStats := range e.labelStats() {
 		summary[libraryName] = make(map[string]int)
 		for _, label := range e.exportLabels {
-			fileInfos := libraryData[label]
-			summary[libraryName][label] = len(fileInfos)
+			summary[libraryName][label] = labelStats[label].Count
 		}
 	}
 
@@ -493,9 +679,9 @@ func (e *Exporter) GetAccumulatedCount() int {
 	defer e.mutex.Unlock()
 
 	total := 0
-	for _, libraryData := range e.accumulated {
-		for _, fileInfos := range libraryData {
-			total += len(fileInfos)
+	for _, labelStats := range e.labelStats() {
+		for _, stats := range labelStats {
+			total += stats.Count
 		}
 	}
 	return total
@@ -553,51 +739,24 @@ func (e *Exporter) buildJSONExportData() JSONExportData {
 	return jsonData
 }
 
-// buildJSONSummary builds a JSONSummary struct from the accumulated data
+// buildJSONSummary builds a JSONSummary struct from the data in this flush
 func (e *Exporter) buildJSONSummary() JSONSummary {
 	totalFiles := 0
 	totalSize := int64(0)
-	libraryStats := make(map[string]map[string]struct {
-		Count int
-		Size  int64
-	})
-
-	// Collect statistics
-	for libraryName, libraryData := range e.accumulated {
-		libraryStats[libraryName] = make(map[string]struct {
-			Count int
-			Size  int64
-		})
-
-		for label, fileInfos := range libraryData {
-			count := len(fileInfos)
-			size := int64(0)
-			for _, fi := range fileInfos {
-				size += fi.Size
-			}
-
-			libraryStats[libraryName][label] = struct {
-				Count int
-				Size  int64
-			}{Count: count, Size: size}
-
-			totalFiles += count
-			totalSize += size
+	libraryStats := e.labelStats()
+	for _, labelStats := range libraryStats {
+		for _, stats := range labelStats {
+			totalFiles += stats.Count
+			totalSize += stats.Size
 		}
 	}
 
-	labelTotals := make(map[string]struct {
-		Count int
-		Size  int64
-	})
-
-	for _, libraryData := range e.accumulated {
-		for label, fileInfos := range libraryData {
+	labelTotals := make(map[string]labelStat)
+	for _, labelStats := range libraryStats {
+		for label, stats := range labelStats {
 			existing := labelTotals[label]
-			existing.Count += len(fileInfos)
-			for _, fi := range fileInfos {
-				existing.Size += fi.Size
-			}
+			existing.Count += stats.Count
+			existing.Size += stats.Size
 			labelTotals[label] = existing
 		}
 	}
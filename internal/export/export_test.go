@@ -0,0 +1,693 @@
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestWriteCSV(t *testing.T) {
+	e, err := NewExporter(t.TempDir(), []string{"Keep", "4K"}, nil, "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, lib := range []string{"Movies", "Anime"} {
+		if err := e.SetCurrentLibrary(lib); err != nil {
+			t.Fatal(err)
+		}
+		files := []FileInfo{{Path: "/" + lib + "/a, b.mkv", Size: 10}}
+		if err := e.ExportItemWithSizes(ItemMetadata{Title: "A, B", Year: 1999, Labels: []string{"keep", "4k"}}, files); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.ExportItemWithSizes(ItemMetadata{Title: "Untitled", Labels: []string{"Keep"}}, []FileInfo{{Path: "/Anime/u.mkv"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := e.writeCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `library,title,year,label,path,size
+Anime,"A, B",1999,Keep,"/Anime/a, b.mkv",10
+Anime,Untitled,,Keep,/Anime/u.mkv,0
+Anime,"A, B",1999,4K,"/Anime/a, b.mkv",10
+Movies,"A, B",1999,Keep,"/Movies/a, b.mkv",10
+Movies,"A, B",1999,4K,"/Movies/a, b.mkv",10
+`
+	if got := buf.String(); got != want {
+		t.Errorf("writeCSV() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestJSONToYAML(t *testing.T) {
+	input := `{"generated_at":"2025-01-02 03:04:05","libraries":{"TV Shows":{"true":[{"path":"/tv/a: \"b\".mkv","size":10},{"path":"/tv/c.mkv","size":0}]},"Empty":{}},"tags":[["x","y"],[]],"total":1.5,"ok":true,"none":null}`
+	want := `generated_at: "2025-01-02 03:04:05"
+libraries:
+  TV Shows:
+    "true":
+      - path: "/tv/a: \"b\".mkv"
+        size: 10
+      - path: "/tv/c.mkv"
+        size: 0
+  Empty: {}
+tags:
+  - - "x"
+    - "y"
+  - []
+total: 1.5
+ok: true
+none: null
+`
+	var buf bytes.Buffer
+	if err := jsonToYAML([]byte(input), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("jsonToYAML() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWrittenFiles(t *testing.T) {
+	e, err := NewExporter(t.TempDir(), []string{"Keep", "4K"}, nil, "txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetCurrentLibrary("Movies"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.ExportItem("A", []string{"keep"}, []string{"/movies/a.mkv"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Movies/Keep.txt", "Movies/4K.txt", "summary.txt"}
+	got := e.WrittenFiles()
+	if len(got) != len(want) {
+		t.Fatalf("WrittenFiles() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("WrittenFiles() = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestFlushTemplate(t *testing.T) {
+	dir := t.TempDir()
+	tmplPath := filepath.Join(dir, "include.sh.tmpl")
+	tmpl := `# {{.Library}} / {{.Label}}
+{{range .Files}}cp {{shellQuote .Path}} /backup/ # {{.Title}} ({{.Year}}), {{size .Size}}
+{{end}}`
+	if err := os.WriteFile(tmplPath, []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out")
+	e, err := NewExporter(out, []string{"Keep"}, nil, "template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetTemplate(tmplPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetCurrentLibrary("Movies"); err != nil {
+		t.Fatal(err)
+	}
+	files := []FileInfo{{Path: "/movies/Ocean's Eleven.mkv", Size: 2048}}
+	if err := e.ExportItemWithSizes(ItemMetadata{Title: "Ocean's Eleven", Year: 2001, Labels: []string{"keep"}}, files); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(out, "Movies", "Keep.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Movies / Keep
+cp '/movies/Ocean'\''s Eleven.mkv' /backup/ # Ocean's Eleven (2001), 2.0 KB
+`
+	if string(got) != want {
+		t.Errorf("template output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExportMetadata(t *testing.T) {
+	e, err := NewExporter(t.TempDir(), []string{"Keep"}, nil, "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.SetIncludeMetadata(true)
+	if err := e.SetCurrentLibrary("Movies"); err != nil {
+		t.Fatal(err)
+	}
+	item := ItemMetadata{
+		Title:     "Heat",
+		Year:      1995,
+		RatingKey: "123",
+		TMDbID:    "949",
+		Labels:    []string{"heist", "keep"},
+		AddedAt:   "2024-05-01T10:00:00Z",
+	}
+	if err := e.ExportItemWithSizes(item, []FileInfo{{Path: "/movies/heat.mkv", Size: 5}}); err != nil {
+		t.Fatal(err)
+	}
+	item.Labels[0] = "changed"
+
+	var buf bytes.Buffer
+	if err := e.writeCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `library,title,year,label,path,size,rating_key,tmdb_id,added_at,labels
+Movies,Heat,1995,Keep,/movies/heat.mkv,5,123,949,2024-05-01T10:00:00Z,heist; keep
+`
+	if got := buf.String(); got != want {
+		t.Errorf("writeCSV() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteM3U(t *testing.T) {
+	e, err := NewExporter(t.TempDir(), []string{"Keep"}, nil, "m3u")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.SetPlaylistPathMappings(map[string]string{"/data/movies": "/mnt/media/movies"})
+
+	files := []FileInfo{
+		{Path: "/data/movies/Heat (1995)/Heat.mkv", Title: "Heat", Year: 1995},
+		{Path: "/other/untitled.mkv"},
+	}
+	var buf bytes.Buffer
+	if err := e.writeM3U(&buf, files); err != nil {
+		t.Fatal(err)
+	}
+	want := `#EXTM3U
+#EXTINF:-1,Heat (1995)
+/mnt/media/movies/Heat (1995)/Heat.mkv
+#EXTINF:-1,untitled.mkv
+/other/untitled.mkv
+`
+	if got := buf.String(); got != want {
+		t.Errorf("writeM3U() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	dir := t.TempDir()
+	e, err := NewExporter(dir, []string{"Keep"}, nil, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.SetDiff(true)
+
+	flush := func(paths ...string) {
+		t.Helper()
+		if err := e.SetCurrentLibrary("Movies"); err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range paths {
+			if err := e.ExportItem(path, []string{"keep"}, []string{path}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := e.FlushAll(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, "diff", "Movies", "Keep", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	flush("/m/a.mkv", "/m/b.mkv")
+	if got := read("added.txt"); got != "/m/a.mkv\n/m/b.mkv\n" {
+		t.Errorf("first added.txt = %q", got)
+	}
+
+	flush("/m/b.mkv", "/m/c.mkv")
+	if got := read("added.txt"); got != "/m/c.mkv\n" {
+		t.Errorf("added.txt = %q, want /m/c.mkv", got)
+	}
+	if got := read("removed.txt"); got != "/m/a.mkv\n" {
+		t.Errorf("removed.txt = %q, want /m/a.mkv", got)
+	}
+
+	// A partial flush writes no diff and leaves the state alone
+	e.MarkIncomplete()
+	flush("/m/b.mkv")
+	if _, err := os.Stat(filepath.Join(dir, "diff")); !os.IsNotExist(err) {
+		t.Errorf("diff directory after incomplete flush: %v, want not exist", err)
+	}
+	flush("/m/b.mkv", "/m/c.mkv")
+	if got := read("added.txt") + read("removed.txt"); got != "" {
+		t.Errorf("diff after incomplete flush = %q, want empty", got)
+	}
+}
+
+func TestPostWebhook(t *testing.T) {
+	var got WebhookPayload
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	e, err := NewExporter(t.TempDir(), []string{"Keep"}, nil, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.SetDiff(true)
+	if err := e.SetCurrentLibrary("Movies"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.ExportItem("A", []string{"keep"}, []string{"/m/a.mkv"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	headers := map[string]string{"Authorization": "Bearer secret"}
+	if err := PostWebhook(server.URL, headers, e.WebhookPayload()); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want Bearer secret", auth)
+	}
+	if got.Event != "export.diff" || got.Export != nil {
+		t.Errorf("payload event = %q, export = %v; want export.diff without export", got.Event, got.Export)
+	}
+	diff := got.Diff["Movies"]["Keep"]
+	if len(diff.Added) != 1 || diff.Added[0] != "/m/a.mkv" || len(diff.Removed) != 0 {
+		t.Errorf("payload diff = %+v, want /m/a.mkv added", diff)
+	}
+}
+
+func TestFlushLinks(t *testing.T) {
+	dir := t.TempDir()
+	media := filepath.Join(dir, "media")
+	if err := os.MkdirAll(media, 0755); err != nil {
+		t.Fatal(err)
+	}
+	heat := filepath.Join(media, "Heat.mkv")
+	ronin := filepath.Join(media, "Ronin.mkv")
+	for _, path := range []string{heat, ronin} {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := filepath.Join(dir, "out")
+	e, err := NewExporter(out, []string{"Keep"}, nil, "links")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.SetLinkType("symlink")
+	flush := func(items map[string]string) {
+		t.Helper()
+		if err := e.SetCurrentLibrary("Movies"); err != nil {
+			t.Fatal(err)
+		}
+		for title, path := range items {
+			files := []FileInfo{{Path: path}}
+			if err := e.ExportItemWithSizes(ItemMetadata{Title: title, Year: 1995, Labels: []string{"keep"}}, files); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := e.FlushAll(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	linked := func(title, name string) bool {
+		target, err := os.Readlink(filepath.Join(out, "Movies", "Keep", title+" (1995)", name))
+		return err == nil && target != ""
+	}
+
+	flush(map[string]string{"Heat": heat, "Ronin": ronin})
+	if !linked("Heat", "Heat.mkv") || !linked("Ronin", "Ronin.mkv") {
+		t.Fatal("expected links for Heat and Ronin")
+	}
+
+	// An incomplete flush only adds links
+	e.MarkIncomplete()
+	flush(map[string]string{"Heat": heat})
+	if !linked("Ronin", "Ronin.mkv") {
+		t.Error("incomplete flush removed the Ronin link")
+	}
+
+	// Files the user keeps in the tree survive a rebuild
+	notes := filepath.Join(out, "Movies", "Keep", "notes.txt")
+	poster := filepath.Join(out, "Movies", "Keep", "Ronin (1995)", "poster.jpg")
+	for _, path := range []string{notes, poster} {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A complete flush rebuilds the tree
+	flush(map[string]string{"Heat": heat})
+	if linked("Ronin", "Ronin.mkv") {
+		t.Error("Ronin link survived a complete flush without it")
+	}
+	if _, err := os.Stat(ronin); err != nil {
+		t.Errorf("original file removed: %v", err)
+	}
+	for _, path := range []string{notes, poster} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("user file removed: %v", err)
+		}
+	}
+
+	flush(map[string]string{"Ronin": ronin})
+	if _, err := os.Stat(filepath.Join(out, "Movies", "Keep", "Heat (1995)")); !os.IsNotExist(err) {
+		t.Errorf("empty item directory left behind: %v", err)
+	}
+}
+
+func TestPathMappings(t *testing.T) {
+	e, err := NewExporter(t.TempDir(), []string{"Keep"}, nil, "m3u")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.SetPathMappings(map[string]string{"/data/movies": "/mnt/user/movies"})
+	e.SetPlaylistPathMappings(map[string]string{"/mnt/user": "smb://nas"})
+	if err := e.SetCurrentLibrary("Movies"); err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{"/data/movies/Heat.mkv", "/data/moviesextra/b.mkv"}
+	if err := e.ExportItem("Heat", []string{"keep"}, paths); err != nil {
+		t.Fatal(err)
+	}
+
+	files := e.accumulated["Movies"]["Keep"]
+	if files[0].Path != "/mnt/user/movies/Heat.mkv" || files[1].Path != "/data/moviesextra/b.mkv" {
+		t.Errorf("accumulated paths = %q, %q", files[0].Path, files[1].Path)
+	}
+
+	var buf bytes.Buffer
+	if err := e.writeM3U(&buf, files[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if want := "#EXTM3U\n#EXTINF:-1,Heat\nsmb://nas/movies/Heat.mkv\n"; buf.String() != want {
+		t.Errorf("writeM3U() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestExcludeLabels(t *testing.T) {
+	e, err := NewExporter(t.TempDir(), nil, []string{"Keep"}, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetCurrentLibrary("Movies"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.ExportItem("Kept", []string{"keep", "4k"}, []string{"/m/kept.mkv"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.ExportItem("Unlabeled", nil, []string{"/m/unlabeled.mkv"}); err != nil {
+		t.Fatal(err)
+	}
+
+	files := e.accumulated["Movies"][InverseListName("Keep")]
+	if len(files) != 1 || files[0].Path != "/m/unlabeled.mkv" {
+		t.Errorf("not-Keep list = %+v, want only /m/unlabeled.mkv", files)
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	e, err := NewExporter(dir, []string{"Keep"}, nil, "txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.SetSnapshots(2)
+
+	start := time.Date(2025, 1, 15, 3, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if err := e.SetCurrentLibrary("Movies"); err != nil {
+			t.Fatal(err)
+		}
+		path := fmt.Sprintf("/m/%d.mkv", i)
+		if err := e.ExportItem(path, []string{"keep"}, []string{path}); err != nil {
+			t.Fatal(err)
+		}
+		e.mutex.Lock()
+		err := e.flushTxt()
+		if err == nil {
+			err = e.snapshot(start.Add(time.Duration(i) * time.Hour))
+		}
+		e.mutex.Unlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "snapshots"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{"2025-01-15T040000", "2025-01-15T050000"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("snapshots = %v, want %v", names, want)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "snapshots", "2025-01-15T040000", "Movies", "Keep.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "/m/1.mkv\n" {
+		t.Errorf("snapshot Keep.txt = %q, want /m/1.mkv", got)
+	}
+}
+
+func TestGzipSplitLibraries(t *testing.T) {
+	dir := t.TempDir()
+	e, err := NewExporter(dir, []string{"Keep"}, nil, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.SetGzip(true)
+	e.SetSplitLibraries(true)
+
+	for _, library := range []string{"Movies", "Shows"} {
+		if err := e.SetCurrentLibrary(library); err != nil {
+			t.Fatal(err)
+		}
+		path := "/" + library + "/a.mkv"
+		if err := e.ExportItem(path, []string{"keep"}, []string{path}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	written := e.WrittenFiles()
+	sort.Strings(written)
+	if want := []string{"Movies/export.json.gz", "Shows/export.json.gz"}; !reflect.DeepEqual(written, want) {
+		t.Fatalf("WrittenFiles() = %v, want %v", written, want)
+	}
+
+	file, err := os.Open(filepath.Join(dir, "Movies", "export.json.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data JSONExportData
+	if err := json.NewDecoder(zr).Decode(&data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Libraries) != 1 || len(data.Libraries["Movies"]["Keep"]) != 1 {
+		t.Errorf("Movies export libraries = %v, want only Movies", data.Libraries)
+	}
+	if data.Summary.TotalFiles != 1 {
+		t.Errorf("Movies export total files = %d, want 1", data.Summary.TotalFiles)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	e, err := NewExporter(t.TempDir(), []string{"Keep"}, nil, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.SetSort("title")
+	if err := e.SetCurrentLibrary("Movies"); err != nil {
+		t.Fatal(err)
+	}
+	export := func(title string, year int, path string) {
+		t.Helper()
+		item := ItemMetadata{Title: title, Year: year, Labels: []string{"keep"}}
+		if err := e.ExportItemWithSizes(item, []FileInfo{{Path: path, Size: int64(year)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	export("Ronin", 1998, "/m/ronin.mkv")
+	export("Heat", 1995, "/m/heat.mkv")
+	export("Heat", 1986, "/m/heat-1986.mkv")
+	export("Ronin", 1999, "/m/ronin.mkv")
+
+	e.normalize()
+	var got []string
+	for _, fi := range e.accumulated["Movies"]["Keep"] {
+		got = append(got, fmt.Sprintf("%s %d", fi.Path, fi.Size))
+	}
+	want := []string{"/m/heat-1986.mkv 1986", "/m/heat.mkv 1995", "/m/ronin.mkv 1999"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+}
+
+func TestStreamMatchesMemory(t *testing.T) {
+	generated := regexp.MustCompile(`(?m)^\s*"?generated_at"?: .*$|^Generated: .*$`)
+	flush := func(mode string, stream bool) map[string]string {
+		t.Helper()
+		dir := t.TempDir()
+		e, err := NewExporter(dir, []string{"Keep", "4K"}, []string{"Keep"}, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.SetStream(stream)
+		e.SetIncludeMetadata(mode != "txt" && mode != "arr")
+		e.SetSort("path")
+
+		for _, library := range []string{"Movies", "Shows", "Empty"} {
+			if err := e.SetCurrentLibrary(library); err != nil {
+				t.Fatal(err)
+			}
+			if library == "Empty" {
+				continue
+			}
+			for i, labels := range [][]string{{"keep"}, {"keep", "4k"}, {"other"}, {"keep"}} {
+				item := ItemMetadata{Title: fmt.Sprintf("%s <%d>", library, i%3), Year: 2000 + i, RatingKey: fmt.Sprint(i), TMDbID: fmt.Sprint(100 + i%3), Labels: labels}
+				path := fmt.Sprintf("/%s/%d.mkv", library, 3-i%3)
+				if err := e.ExportItemWithSizes(item, []FileInfo{{Path: path, Size: int64(i + 1)}}); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		if err := e.FlushAll(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, spoolDir)); !os.IsNotExist(err) {
+			t.Errorf("%s: spool directory after flush: %v, want not exist", mode, err)
+		}
+
+		files := make(map[string]string)
+		for _, name := range e.WrittenFiles() {
+			data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			files[name] = generated.ReplaceAllString(string(data), "")
+		}
+		return files
+	}
+
+	for _, mode := range []string{"txt", "json", "csv", "yaml", "arr"} {
+		memory, streamed := flush(mode, false), flush(mode, true)
+		if len(memory) == 0 {
+			t.Fatalf("%s: no files written", mode)
+		}
+		for name, want := range memory {
+			if got := streamed[name]; got != want {
+				t.Errorf("%s: streamed %s =\n%s\nwant\n%s", mode, name, got, want)
+			}
+		}
+		if len(streamed) != len(memory) {
+			t.Errorf("%s: streamed %d files, want %d", mode, len(streamed), len(memory))
+		}
+	}
+}
+
+func TestFlushProm(t *testing.T) {
+	dir := t.TempDir()
+	e, err := NewExporter(dir, []string{"Keep", `4K "HDR"`}, nil, "prom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, library := range []string{"Shows", "Movies"} {
+		if err := e.SetCurrentLibrary(library); err != nil {
+			t.Fatal(err)
+		}
+	}
+	item := ItemMetadata{Title: "Heat", Labels: []string{"keep"}}
+	if err := e.ExportItemWithSizes(item, []FileInfo{{Path: "/m/heat.mkv", Size: 100}, {Path: "/m/heat.srt", Size: 5}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "labelarr.prom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# TYPE labelarr_export_files gauge\n" +
+			"labelarr_export_files{library=\"Movies\",label=\"Keep\"} 2\n" +
+			"labelarr_export_files{library=\"Movies\",label=\"4K \\\"HDR\\\"\"} 0\n" +
+			"labelarr_export_files{library=\"Shows\",label=\"Keep\"} 0\n",
+		"labelarr_export_bytes{library=\"Movies\",label=\"Keep\"} 105\n",
+		"# TYPE labelarr_export_timestamp_seconds gauge\n",
+	} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("labelarr.prom missing %q in\n%s", want, data)
+		}
+	}
+	if got := e.WrittenFiles(); !reflect.DeepEqual(got, []string{"labelarr.prom"}) {
+		t.Errorf("WrittenFiles() = %v, want [labelarr.prom]", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "labelarr.prom.tmp")); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
+
+func TestArrItems(t *testing.T) {
+	got := arrItems([]FileInfo{
+		{Path: "/m/heat.mkv", Title: "Heat", Year: 1995, TMDbID: "949"},
+		{Path: "/m/heat.srt", Title: "Heat", Year: 1995, TMDbID: "949"},
+		{Path: "/m/unknown.mkv", Title: "Unknown"},
+		{Path: "/m/ronin.mkv", Title: "Ronin", Year: 1998, TMDbID: "8195"},
+	})
+	want := []ArrItem{{TMDbID: 949, Title: "Heat", Year: 1995}, {TMDbID: 8195, Title: "Ronin", Year: 1998}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("arrItems() = %+v, want %+v", got, want)
+	}
+
+	data, err := json.Marshal(arrItems(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "[]" {
+		t.Errorf("empty list = %s, want []", data)
+	}
+}
@@ -0,0 +1,29 @@
+package export
+
+import (
+	"compress/gzip"
+	"os"
+)
+
+// SetGzip compresses every export file with gzip, appending ".gz" to its
+// name. Large JSON exports shrink to a fraction of their size.
+func (e *Exporter) SetGzip(enabled bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.gzip = enabled
+}
+
+// gzipFile is an export file written through a gzip stream. Close flushes
+// the stream before closing the file.
+type gzipFile struct {
+	*gzip.Writer
+	file *os.File
+}
+
+func (f *gzipFile) Close() error {
+	err := f.Writer.Close()
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
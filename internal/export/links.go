@@ -0,0 +1,204 @@
+package export
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// linkManifest lists, one relative path per line, the links labelarr created
+// in a label tree, so a rebuild removes those and nothing else.
+const linkManifest = ".labelarr-links"
+
+// SetLinkType sets how EXPORT_MODE=links links files: "symlink" (the
+// default) or "hardlink".
+func (e *Exporter) SetLinkType(linkType string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.linkType = linkType
+}
+
+// flushLinks builds <library>/<label>/<Title (Year)>/<file> link trees. The
+// links labelarr created in the trees of every library in this flush are
+// replaced, so items that lost a label lose their links; other files in the
+// trees are left alone. After an incomplete scan links are only added. A
+// file that can't be linked doesn't stop the rest; the first failure is
+// returned once the trees are done.
+func (e *Exporter) flushLinks() error {
+	total, failed := 0, 0
+	var firstErr error
+
+	for _, libraryName := range e.libraryNames() {
+		for _, label := range e.exportLabels {
+			labelPath, err := e.safeJoin(libraryName, sanitizeFilename(label))
+			if err != nil {
+				return fmt.Errorf("invalid label path: %w", err)
+			}
+			owned, err := readLinkManifest(labelPath)
+			if err != nil {
+				return fmt.Errorf("failed to read link manifest in %s: %w", labelPath, err)
+			}
+			if !e.lastIncomplete {
+				if err := removeLinks(labelPath, owned); err != nil {
+					return fmt.Errorf("failed to clear link directory %s: %w", labelPath, err)
+				}
+				owned = nil
+			}
+			if err := os.MkdirAll(labelPath, 0755); err != nil {
+				return fmt.Errorf("failed to create link directory %s: %w", labelPath, err)
+			}
+
+			fileInfos, err := e.entries(libraryName, label)
+			if err != nil {
+				return err
+			}
+			for _, fi := range fileInfos {
+				total++
+				name, created, err := e.link(labelPath, fi)
+				if err != nil {
+					failed++
+					if firstErr == nil {
+						firstErr = err
+					}
+					continue
+				}
+				if created {
+					owned = append(owned, name)
+				}
+			}
+			if err := writeLinkManifest(labelPath, owned); err != nil {
+				return fmt.Errorf("failed to write link manifest in %s: %w", labelPath, err)
+			}
+		}
+	}
+
+	// Clear accumulated data after the trees are written
+	e.accumulated = make(map[string]map[string][]FileInfo)
+
+	if failed > 0 {
+		return fmt.Errorf("failed to link %d of %d files: %w", failed, total, firstErr)
+	}
+	return nil
+}
+
+// link creates the link for fi under labelPath and returns its path
+// relative to labelPath. A file already there, e.g. linked by an earlier
+// incomplete flush, is left alone and created is false.
+func (e *Exporter) link(labelPath string, fi FileInfo) (name string, created bool, err error) {
+	folder := fi.Title
+	if fi.Year > 0 {
+		folder += " (" + strconv.Itoa(fi.Year) + ")"
+	}
+	name = filepath.Join(sanitizeFilename(folder), sanitizeFilename(filepath.Base(fi.Path)))
+	linkPath := filepath.Join(labelPath, name)
+	if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+		return "", false, err
+	}
+
+	if _, err := os.Lstat(linkPath); err == nil {
+		return name, false, nil
+	}
+	if e.linkType == "hardlink" {
+		err = os.Link(fi.Path, linkPath)
+	} else {
+		err = os.Symlink(fi.Path, linkPath)
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return name, true, nil
+}
+
+// readLinkManifest returns the links recorded in labelPath's manifest. A
+// tree without one, written before manifests existed, is treated as owning
+// its symlinks.
+func readLinkManifest(labelPath string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(labelPath, linkManifest))
+	if os.IsNotExist(err) {
+		return symlinksIn(labelPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, line := range strings.Split(string(data), "\n") {
+		// Anything pointing outside the tree wasn't written by labelarr
+		if name := filepath.FromSlash(line); line != "" && filepath.IsLocal(name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// symlinksIn lists the symlinks under dir relative to it.
+func symlinksIn(dir string) ([]string, error) {
+	var names []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			name, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			names = append(names, name)
+		}
+		return nil
+	})
+	return names, err
+}
+
+// writeLinkManifest records names as the links labelarr owns in labelPath.
+func writeLinkManifest(labelPath string, names []string) error {
+	sorted := make([]string, len(names))
+	for i, name := range names {
+		sorted[i] = filepath.ToSlash(name)
+	}
+	sort.Strings(sorted)
+	var content string
+	if len(sorted) > 0 {
+		content = strings.Join(sorted, "\n") + "\n"
+	}
+	return os.WriteFile(filepath.Join(labelPath, linkManifest), []byte(content), 0644)
+}
+
+// removeLinks removes the given links from labelPath, then any directories
+// left empty. Removing a symlink or hardlink leaves the original file in
+// place.
+func removeLinks(labelPath string, names []string) error {
+	dirs := make(map[string]bool)
+	for _, name := range names {
+		if err := os.Remove(filepath.Join(labelPath, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for dir := filepath.Dir(name); dir != "."; dir = filepath.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+
+	// Deepest first, so parents are empty by the time they come up
+	ordered := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		ordered = append(ordered, dir)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return strings.Count(ordered[i], string(filepath.Separator)) > strings.Count(ordered[j], string(filepath.Separator))
+	})
+	for _, dir := range ordered {
+		path := filepath.Join(labelPath, dir)
+		if entries, err := os.ReadDir(path); err == nil && len(entries) == 0 {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
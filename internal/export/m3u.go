@@ -0,0 +1,76 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+// SetPlaylistPathMappings sets the prefix rewrites (from -> to) applied to
+// paths written to m3u playlists, for players that mount media elsewhere.
+// They apply after the EXPORT_PATH_MAPPINGS rewrite.
+func (e *Exporter) SetPlaylistPathMappings(mappings map[string]string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.playlistPaths = mappings
+}
+
+// flushM3U writes one extended M3U playlist per library and export label.
+func (e *Exporter) flushM3U() error {
+	for _, libraryName := range e.libraryNames() {
+		libraryPath, err := e.safeJoin(libraryName)
+		if err != nil {
+			return fmt.Errorf("invalid library path: %w", err)
+		}
+		if err := os.MkdirAll(libraryPath, 0755); err != nil {
+			return fmt.Errorf("failed to create library directory %s: %w", libraryPath, err)
+		}
+
+		for _, label := range e.exportLabels {
+			filePath := filepath.Join(libraryPath, sanitizeFilename(label)+".m3u")
+			file, err := e.create(filePath)
+			if err != nil {
+				return fmt.Errorf("failed to create playlist %s: %w", filePath, err)
+			}
+			fileInfos, err := e.entries(libraryName, label)
+			if err == nil {
+				err = e.writeM3U(file, fileInfos)
+			}
+			file.Close()
+			if err != nil {
+				return fmt.Errorf("failed to write playlist %s: %w", filePath, err)
+			}
+		}
+	}
+
+	// Clear accumulated data after successful write
+	e.accumulated = make(map[string]map[string][]FileInfo)
+
+	return nil
+}
+
+// writeM3U writes an extended M3U playlist of fileInfos, each entry titled
+// "Title (Year)" and its path rewritten with the playlist path mappings.
+func (e *Exporter) writeM3U(w io.Writer, fileInfos []FileInfo) error {
+	buf := bufio.NewWriter(w)
+	buf.WriteString("#EXTM3U\n")
+	for _, fi := range fileInfos {
+		title := fi.Title
+		if title == "" {
+			title = filepath.Base(fi.Path)
+		}
+		if fi.Year > 0 {
+			title += " (" + strconv.Itoa(fi.Year) + ")"
+		}
+		// Line breaks would end the entry early
+		title = strings.NewReplacer("\r", " ", "\n", " ").Replace(title)
+		fmt.Fprintf(buf, "#EXTINF:-1,%s\n%s\n", title, utils.RewritePath(fi.Path, e.playlistPaths))
+	}
+	return buf.Flush()
+}
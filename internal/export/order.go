@@ -0,0 +1,63 @@
+package export
+
+import (
+	"sort"
+)
+
+// SetSort sets the order of the entries of each export label: "path",
+// "title" (then year and path) or "" to keep the order items were
+// processed in.
+func (e *Exporter) SetSort(sortBy string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.sortBy = sortBy
+}
+
+// normalize removes duplicate paths within each export label and sorts the
+// entries. A path exported more than once, e.g. because a webhook
+// reprocessed its item during a scan, keeps its first position and its
+// latest details.
+func (e *Exporter) normalize() {
+	for _, libraryData := range e.accumulated {
+		for label, fileInfos := range libraryData {
+			libraryData[label] = normalizeEntries(fileInfos, e.sortBy)
+		}
+	}
+}
+
+// normalizeEntries de-duplicates and sorts the entries of one label, reusing
+// the fileInfos array.
+func normalizeEntries(fileInfos []FileInfo, sortBy string) []FileInfo {
+	index := make(map[string]int, len(fileInfos))
+	unique := fileInfos[:0]
+	for _, fi := range fileInfos {
+		if i, ok := index[fi.Path]; ok {
+			unique[i] = fi
+			continue
+		}
+		index[fi.Path] = len(unique)
+		unique = append(unique, fi)
+	}
+	sortFileInfos(unique, sortBy)
+	return unique
+}
+
+func sortFileInfos(fileInfos []FileInfo, sortBy string) {
+	switch sortBy {
+	case "path":
+		sort.SliceStable(fileInfos, func(i, j int) bool {
+			return fileInfos[i].Path < fileInfos[j].Path
+		})
+	case "title":
+		sort.SliceStable(fileInfos, func(i, j int) bool {
+			a, b := fileInfos[i], fileInfos[j]
+			if a.Title != b.Title {
+				return a.Title < b.Title
+			}
+			if a.Year != b.Year {
+				return a.Year < b.Year
+			}
+			return a.Path < b.Path
+		})
+	}
+}
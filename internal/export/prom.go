@@ -0,0 +1,77 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// promFile is the file EXPORT_MODE=prom writes, for node_exporter's
+// textfile collector.
+const promFile = "labelarr.prom"
+
+// promEscaper escapes Prometheus label values.
+var promEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// flushProm writes per-library and per-label file counts and byte totals in
+// the Prometheus text format. The file is written under a temporary name and
+// renamed, so the collector never reads a partial file.
+func (e *Exporter) flushProm() error {
+	promPath, err := e.safeJoin(promFile)
+	if err != nil {
+		return fmt.Errorf("invalid prom export path: %w", err)
+	}
+	tmpPath := promPath + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create prom export file: %w", err)
+	}
+
+	err = e.writeProm(file, time.Now())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, promPath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write prom export file: %w", err)
+	}
+	e.written = append(e.written, filepath.ToSlash(promFile))
+
+	// Clear accumulated data after successful write
+	e.accumulated = make(map[string]map[string][]FileInfo)
+
+	return nil
+}
+
+// writeProm writes the metrics, libraries sorted by name and labels in
+// EXPORT_LABELS order. Every export label gets a sample in every library.
+func (e *Exporter) writeProm(file *os.File, now time.Time) error {
+	buf := bufio.NewWriter(file)
+	stats := e.labelStats()
+	libraries := e.libraryNames()
+
+	metric := func(name, help string, value func(labelStat) int64) {
+		fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, libraryName := range libraries {
+			for _, label := range e.exportLabels {
+				fmt.Fprintf(buf, "%s{library=\"%s\",label=\"%s\"} %d\n", name,
+					promEscaper.Replace(libraryName), promEscaper.Replace(label), value(stats[libraryName][label]))
+			}
+		}
+	}
+	metric("labelarr_export_files", "Number of exported files per library and label.",
+		func(s labelStat) int64 { return int64(s.Count) })
+	metric("labelarr_export_bytes", "Total size in bytes of the exported files per library and label.",
+		func(s labelStat) int64 { return s.Size })
+
+	fmt.Fprintf(buf, "# HELP labelarr_export_timestamp_seconds Unix time of the last export.\n")
+	fmt.Fprintf(buf, "# TYPE labelarr_export_timestamp_seconds gauge\n")
+	fmt.Fprintf(buf, "labelarr_export_timestamp_seconds %d\n", now.Unix())
+	return buf.Flush()
+}
@@ -0,0 +1,85 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// snapshotLayout names snapshot directories; it sorts chronologically and
+// avoids characters that are invalid in Windows or SMB paths.
+const snapshotLayout = "2006-01-02T150405"
+
+// SetSnapshots keeps a copy of each flush's files in snapshots/<timestamp>,
+// pruning all but the newest keep snapshots. 0 disables snapshots.
+func (e *Exporter) SetSnapshots(keep int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.snapshots = keep
+}
+
+// snapshot copies the files written by the current flush into a new
+// snapshot directory, then prunes old snapshots.
+func (e *Exporter) snapshot(now time.Time) error {
+	snapshotsDir, err := e.safeJoin("snapshots")
+	if err != nil {
+		return fmt.Errorf("invalid snapshot path: %w", err)
+	}
+	dir := filepath.Join(snapshotsDir, now.Format(snapshotLayout))
+	for _, name := range e.written {
+		src := filepath.Join(e.exportLocation, filepath.FromSlash(name))
+		dst := filepath.Join(dir, filepath.FromSlash(name))
+		if err := copyFile(src, dst); err != nil {
+			return fmt.Errorf("failed to snapshot %s: %w", name, err)
+		}
+	}
+	return pruneSnapshots(snapshotsDir, e.snapshots)
+}
+
+// pruneSnapshots removes all but the newest keep snapshot directories.
+func pruneSnapshots(snapshotsDir string, keep int) error {
+	entries, err := os.ReadDir(snapshotsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if _, err := time.Parse(snapshotLayout, entry.Name()); entry.IsDir() && err == nil {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for len(names) > keep {
+		if err := os.RemoveAll(filepath.Join(snapshotsDir, names[0])); err != nil {
+			return fmt.Errorf("failed to remove snapshot %s: %w", names[0], err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,51 @@
+package export
+
+import (
+	"fmt"
+	"os"
+)
+
+// SetSplitLibraries writes the json, csv and yaml exports as one file per
+// library, <library>/export.<ext>, instead of a single file for all
+// libraries. Each file's summary covers its own library.
+func (e *Exporter) SetSplitLibraries(enabled bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.splitLibraries = enabled
+}
+
+// flushSplit runs flush, which writes one export file into dir (relative to
+// the export location), for the whole export, or once per library when
+// libraries are split. The accumulated data is kept if any run fails.
+func (e *Exporter) flushSplit(flush func(dir string) error) error {
+	if !e.splitLibraries {
+		return flush("")
+	}
+
+	all, allSpooled := e.accumulated, e.spooled
+	restore := func() { e.accumulated, e.spooled = all, allSpooled }
+	for _, libraryName := range e.libraryNames() {
+		libraryPath, err := e.safeJoin(libraryName)
+		if err != nil {
+			restore()
+			return fmt.Errorf("invalid library path: %w", err)
+		}
+		if err := os.MkdirAll(libraryPath, 0755); err != nil {
+			restore()
+			return fmt.Errorf("failed to create library directory %s: %w", libraryPath, err)
+		}
+		// Narrow the flush to this library
+		e.accumulated = map[string]map[string][]FileInfo{libraryName: all[libraryName]}
+		e.spooled = map[string]map[string]*spoolFile{libraryName: allSpooled[libraryName]}
+		if err := flush(libraryName); err != nil {
+			restore()
+			return err
+		}
+	}
+	e.spooled = allSpooled
+
+	// Clear accumulated data after successful write
+	e.accumulated = make(map[string]map[string][]FileInfo)
+
+	return nil
+}
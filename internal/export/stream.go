@@ -0,0 +1,399 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// spoolDir holds the per-label spool files of EXPORT_STREAM, relative to
+// the export location. It is removed after every successful flush.
+const spoolDir = ".spool"
+
+// spoolEntry is a FileInfo as written to a spool file; it keeps the fields
+// FileInfo leaves out of its JSON.
+type spoolEntry struct {
+	FileInfo
+	Title  string `json:"title"`
+	Year   int    `json:"year"`
+	TMDbID string `json:"tmdb_id"`
+}
+
+// spoolFile is the spool of one library and export label.
+type spoolFile struct {
+	path  string
+	file  *os.File // open for appending during a scan; nil once closed
+	buf   *bufio.Writer
+	count int
+	size  int64
+}
+
+// labelStat counts the files and bytes of one export label.
+type labelStat struct {
+	Count int
+	Size  int64
+}
+
+// SetStream turns on EXPORT_STREAM: entries are appended to spool files on
+// disk as items are processed, instead of held in memory until the flush,
+// which then loads one library and label at a time.
+func (e *Exporter) SetStream(enabled bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.stream = enabled
+}
+
+// spool appends fileInfos to the spool of the current library and label.
+func (e *Exporter) spool(label string, fileInfos []FileInfo) error {
+	dir, err := e.safeJoin(spoolDir)
+	if err != nil {
+		return fmt.Errorf("invalid spool path: %w", err)
+	}
+	if e.spoolCount == 0 {
+		// Leftovers of an earlier process that never flushed
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to clear spool directory: %w", err)
+		}
+	}
+	if e.spooled[e.currentLibrary] == nil {
+		e.spooled[e.currentLibrary] = make(map[string]*spoolFile)
+	}
+	sf := e.spooled[e.currentLibrary][label]
+	if sf == nil {
+		e.spoolCount++
+		sf = &spoolFile{path: filepath.Join(dir, strconv.Itoa(e.spoolCount)+".jsonl")}
+		e.spooled[e.currentLibrary][label] = sf
+	}
+	if sf.file == nil {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create spool directory: %w", err)
+		}
+		file, err := os.OpenFile(sf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open spool file: %w", err)
+		}
+		sf.file, sf.buf = file, bufio.NewWriter(file)
+	}
+
+	encoder := json.NewEncoder(sf.buf)
+	for _, fi := range fileInfos {
+		if err := encoder.Encode(spoolEntry{FileInfo: fi, Title: fi.Title, Year: fi.Year, TMDbID: fi.TMDbID}); err != nil {
+			return fmt.Errorf("failed to write spool file: %w", err)
+		}
+		sf.count++
+		sf.size += fi.Size
+	}
+	return nil
+}
+
+// close flushes and closes the spool file, if it is open.
+func (sf *spoolFile) close() error {
+	if sf.file == nil {
+		return nil
+	}
+	err := sf.buf.Flush()
+	if closeErr := sf.file.Close(); err == nil {
+		err = closeErr
+	}
+	sf.file, sf.buf = nil, nil
+	return err
+}
+
+// read loads every entry of the spool file.
+func (sf *spoolFile) read() ([]FileInfo, error) {
+	if err := sf.close(); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(sf.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var fileInfos []FileInfo
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for {
+		var entry spoolEntry
+		if err := decoder.Decode(&entry); err == io.EOF {
+			return fileInfos, nil
+		} else if err != nil {
+			return nil, err
+		}
+		fi := entry.FileInfo
+		fi.Title, fi.Year, fi.TMDbID = entry.Title, entry.Year, entry.TMDbID
+		fileInfos = append(fileInfos, fi)
+	}
+}
+
+// rewrite replaces the spool file's entries with fileInfos.
+func (sf *spoolFile) rewrite(fileInfos []FileInfo) error {
+	file, err := os.Create(sf.path)
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriter(file)
+	encoder := json.NewEncoder(buf)
+	sf.count, sf.size = 0, 0
+	for _, fi := range fileInfos {
+		if err := encoder.Encode(spoolEntry{FileInfo: fi, Title: fi.Title, Year: fi.Year, TMDbID: fi.TMDbID}); err != nil {
+			file.Close()
+			return err
+		}
+		sf.count++
+		sf.size += fi.Size
+	}
+	if err := buf.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// normalizeSpool is normalize for EXPORT_STREAM, one spool file at a time.
+func (e *Exporter) normalizeSpool() error {
+	for _, libraryData := range e.spooled {
+		for _, sf := range libraryData {
+			fileInfos, err := sf.read()
+			if err != nil {
+				return fmt.Errorf("failed to read spool file: %w", err)
+			}
+			if err := sf.rewrite(normalizeEntries(fileInfos, e.sortBy)); err != nil {
+				return fmt.Errorf("failed to write spool file: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// clearSpool removes the spool files once they have been flushed.
+func (e *Exporter) clearSpool() error {
+	for _, libraryData := range e.spooled {
+		for _, sf := range libraryData {
+			sf.close()
+		}
+	}
+	e.spooled = make(map[string]map[string]*spoolFile)
+	dir, err := e.safeJoin(spoolDir)
+	if err != nil {
+		return fmt.Errorf("invalid spool path: %w", err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove spool directory: %w", err)
+	}
+	return nil
+}
+
+// libraryNames returns the libraries in this flush, sorted by name.
+func (e *Exporter) libraryNames() []string {
+	var names []string
+	if e.stream {
+		for libraryName := range e.spooled {
+			names = append(names, libraryName)
+		}
+	} else {
+		for libraryName := range e.accumulated {
+			names = append(names, libraryName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// entries returns the entries of one library and label, from memory or,
+// with EXPORT_STREAM, from its spool file.
+func (e *Exporter) entries(libraryName, label string) ([]FileInfo, error) {
+	if !e.stream {
+		return e.accumulated[libraryName][label], nil
+	}
+	sf := e.spooled[libraryName][label]
+	if sf == nil {
+		return nil, nil
+	}
+	fileInfos, err := sf.read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool file: %w", err)
+	}
+	return fileInfos, nil
+}
+
+// labelStats returns the file count and size of every label with entries,
+// per library. Every library in this flush is included.
+func (e *Exporter) labelStats() map[string]map[string]labelStat {
+	stats := make(map[string]map[string]labelStat)
+	if e.stream {
+		for libraryName, libraryData := range e.spooled {
+			stats[libraryName] = make(map[string]labelStat)
+			for label, sf := range libraryData {
+				stats[libraryName][label] = labelStat{Count: sf.count, Size: sf.size}
+			}
+		}
+		return stats
+	}
+	for libraryName, libraryData := range e.accumulated {
+		stats[libraryName] = make(map[string]labelStat)
+		for label, fileInfos := range libraryData {
+			stat := labelStat{Count: len(fileInfos)}
+			for _, fi := range fileInfos {
+				stat.Size += fi.Size
+			}
+			stats[libraryName][label] = stat
+		}
+	}
+	return stats
+}
+
+// sortedLabels returns the labels of a library's stats, sorted like the keys
+// of a JSON object.
+func sortedLabels(labelStats map[string]labelStat) []string {
+	labels := make([]string, 0, len(labelStats))
+	for label := range labelStats {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// writeJSONStream writes the same document as flushJSON's encoder, one
+// label at a time.
+func (e *Exporter) writeJSONStream(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	field := func(name, value string) {
+		quoted, _ := json.Marshal(value)
+		fmt.Fprintf(buf, "  %q: %s,\n", name, quoted)
+	}
+	buf.WriteString("{\n")
+	field("generated_at", time.Now().Format("2006-01-02 15:04:05"))
+	field("export_mode", e.exportMode)
+
+	stats := e.labelStats()
+	libraries := e.libraryNames()
+	if len(libraries) == 0 {
+		buf.WriteString("  \"libraries\": {},\n")
+	} else {
+		buf.WriteString("  \"libraries\": {\n")
+	}
+	for i, libraryName := range libraries {
+		key, _ := json.Marshal(libraryName)
+		labels := sortedLabels(stats[libraryName])
+		if len(labels) == 0 {
+			fmt.Fprintf(buf, "    %s: {}", key)
+		} else {
+			fmt.Fprintf(buf, "    %s: {\n", key)
+		}
+		for j, label := range labels {
+			fileInfos, err := e.entries(libraryName, label)
+			if err != nil {
+				return err
+			}
+			key, _ := json.Marshal(label)
+			fmt.Fprintf(buf, "      %s: [\n", key)
+			for k, fi := range fileInfos {
+				entry, err := json.MarshalIndent(fi, "        ", "  ")
+				if err != nil {
+					return err
+				}
+				buf.WriteString("        ")
+				buf.Write(entry)
+				if k < len(fileInfos)-1 {
+					buf.WriteString(",")
+				}
+				buf.WriteString("\n")
+			}
+			buf.WriteString("      ]")
+			if j < len(labels)-1 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		if len(labels) > 0 {
+			buf.WriteString("    }")
+		}
+		if i < len(libraries)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	if len(libraries) > 0 {
+		buf.WriteString("  },\n")
+	}
+
+	summary, err := json.MarshalIndent(e.buildJSONSummary(), "  ", "  ")
+	if err != nil {
+		return err
+	}
+	buf.WriteString("  \"summary\": ")
+	buf.Write(summary)
+	buf.WriteString("\n}\n")
+	return buf.Flush()
+}
+
+// writeYAMLStream writes the same document as flushYAML, one label at a
+// time.
+func (e *Exporter) writeYAMLStream(w io.Writer) error {
+	var buf bytes.Buffer
+	scalar := func(value string) string {
+		quoted, _ := json.Marshal(value)
+		return string(quoted)
+	}
+	buf.WriteString("generated_at: " + scalar(time.Now().Format("2006-01-02 15:04:05")) + "\n")
+	buf.WriteString("export_mode: " + scalar(e.exportMode) + "\n")
+
+	stats := e.labelStats()
+	libraries := e.libraryNames()
+	if len(libraries) == 0 {
+		buf.WriteString("libraries: {}\n")
+	} else {
+		buf.WriteString("libraries:\n")
+	}
+	for _, libraryName := range libraries {
+		labels := sortedLabels(stats[libraryName])
+		if len(labels) == 0 {
+			buf.WriteString("  " + yamlKey(libraryName) + ": {}\n")
+			continue
+		}
+		buf.WriteString("  " + yamlKey(libraryName) + ":\n")
+		for _, label := range labels {
+			fileInfos, err := e.entries(libraryName, label)
+			if err != nil {
+				return err
+			}
+			node, err := yamlValue(fileInfos)
+			if err != nil {
+				return err
+			}
+			buf.WriteString("    " + yamlKey(label) + ":\n")
+			writeYAML(&buf, node, 6)
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return err
+			}
+			buf.Reset()
+		}
+	}
+
+	node, err := yamlValue(e.buildJSONSummary())
+	if err != nil {
+		return err
+	}
+	buf.WriteString("summary:\n")
+	writeYAML(&buf, node, 2)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// yamlValue decodes the JSON encoding of v into a yamlNode.
+func yamlValue(v any) (*yamlNode, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeYAMLNode(dec)
+}
@@ -0,0 +1,105 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateData is the data an EXPORT_TEMPLATE is executed with, once per
+// library and export label.
+type TemplateData struct {
+	Library     string
+	Label       string
+	Files       []FileInfo
+	GeneratedAt time.Time
+}
+
+// templateFuncs are the functions available to export templates in addition
+// to the text/template builtins.
+var templateFuncs = template.FuncMap{
+	"size":       formatFileSize,
+	"shellQuote": shellQuote,
+	"dir":        filepath.Dir,
+	"base":       filepath.Base,
+}
+
+// SetTemplate parses the Go text/template at path for EXPORT_MODE=template.
+// Output files take the template's extension once a trailing ".tmpl" is
+// removed, so "rsync.filter.tmpl" writes "<library>/<label>.filter".
+func (e *Exporter) SetTemplate(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read export template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse export template: %w", err)
+	}
+
+	ext := filepath.Ext(strings.TrimSuffix(filepath.Base(path), ".tmpl"))
+	if ext == "" {
+		ext = ".txt"
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.template = tmpl
+	e.templateExt = ext
+	return nil
+}
+
+// flushTemplate executes the export template for every library and export
+// label, writing one file per pair like txt mode does.
+func (e *Exporter) flushTemplate() error {
+	if e.template == nil {
+		return fmt.Errorf("no export template set")
+	}
+
+	generatedAt := time.Now()
+	for _, libraryName := range e.libraryNames() {
+		libraryPath, err := e.safeJoin(libraryName)
+		if err != nil {
+			return fmt.Errorf("invalid library path: %w", err)
+		}
+		if err := os.MkdirAll(libraryPath, 0755); err != nil {
+			return fmt.Errorf("failed to create library directory %s: %w", libraryPath, err)
+		}
+
+		for _, label := range e.exportLabels {
+			filePath := filepath.Join(libraryPath, sanitizeFilename(label)+e.templateExt)
+			fileInfos, err := e.entries(libraryName, label)
+			if err != nil {
+				return err
+			}
+			file, err := e.create(filePath)
+			if err != nil {
+				return fmt.Errorf("failed to create export file %s: %w", filePath, err)
+			}
+			data := TemplateData{
+				Library:     libraryName,
+				Label:       label,
+				Files:       fileInfos,
+				GeneratedAt: generatedAt,
+			}
+			err = e.template.Execute(file, data)
+			file.Close()
+			if err != nil {
+				return fmt.Errorf("failed to write export file %s: %w", filePath, err)
+			}
+		}
+	}
+
+	// Clear accumulated data after successful write
+	e.accumulated = make(map[string]map[string][]FileInfo)
+
+	return nil
+}
+
+// shellQuote quotes s for a POSIX shell by wrapping it in single quotes.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
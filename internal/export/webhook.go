@@ -0,0 +1,81 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+// LabelDiff lists the paths added to and removed from one export label
+type LabelDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// WebhookPayload is the body POSTed to EXPORT_WEBHOOK_URL after a flush.
+// It carries the diff with EXPORT_DIFF and the full export otherwise.
+type WebhookPayload struct {
+	Event       string                          `json:"event"` // "export" or "export.diff"
+	GeneratedAt string                          `json:"generated_at"`
+	Incomplete  bool                            `json:"incomplete,omitempty"` // the scan was stopped early
+	Files       []string                        `json:"files"`
+	Export      *JSONExportData                 `json:"export,omitempty"`
+	Diff        map[string]map[string]LabelDiff `json:"diff,omitempty"` // library -> label -> changes
+}
+
+// WebhookPayload returns the payload describing the last FlushAll
+func (e *Exporter) WebhookPayload() WebhookPayload {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	payload := WebhookPayload{
+		Event:       "export",
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Incomplete:  e.lastIncomplete,
+		Files:       append([]string{}, e.written...),
+	}
+	if e.diff {
+		payload.Event = "export.diff"
+		payload.Diff = e.lastDiff
+		return payload
+	}
+	payload.Export = e.lastExport
+	return payload
+}
+
+// PostWebhook POSTs payload as JSON to url with the given extra headers,
+// e.g. "Authorization". Any 2xx response counts as delivered.
+func PostWebhook(url string, headers map[string]string, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	client := utils.NewRetryableHTTPClient(&http.Client{Timeout: 30 * time.Second}, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error posting export webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("export webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
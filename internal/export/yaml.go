@@ -0,0 +1,167 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// flushYAML writes all accumulated data as export.yaml in dir, with the same
+// structure as export.json.
+func (e *Exporter) flushYAML(dir string) error {
+	yamlPath, err := e.safeJoin(dir, "export.yaml")
+	if err != nil {
+		return fmt.Errorf("invalid YAML export path: %w", err)
+	}
+	file, err := e.create(yamlPath)
+	if err != nil {
+		return fmt.Errorf("failed to create YAML export file: %w", err)
+	}
+	defer file.Close()
+
+	if e.stream {
+		err = e.writeYAMLStream(file)
+	} else {
+		var data []byte
+		data, err = json.Marshal(e.buildJSONExportData())
+		if err == nil {
+			err = jsonToYAML(data, file)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write YAML export file: %w", err)
+	}
+
+	// Clear accumulated data after successful write
+	e.accumulated = make(map[string]map[string][]FileInfo)
+
+	return nil
+}
+
+// yamlNode is a decoded JSON value that keeps object keys in document order.
+type yamlNode struct {
+	scalar string // encoded scalar; empty for objects and arrays
+	keys   []string
+	values []*yamlNode // object values, or array items when keys is nil
+	object bool
+}
+
+// jsonToYAML converts a JSON document to block-style YAML. Strings are
+// written as JSON string literals, which are valid double-quoted YAML
+// scalars, so no value can change type on the way.
+func jsonToYAML(data []byte, w io.Writer) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root, err := decodeYAMLNode(dec)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if root.scalar != "" || len(root.values) == 0 {
+		buf.WriteString(inlineYAML(root) + "\n")
+	} else {
+		writeYAML(&buf, root, 0)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func decodeYAMLNode(dec *json.Decoder) (*yamlNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		node := &yamlNode{object: t == '{'}
+		for dec.More() {
+			if node.object {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, keyTok.(string))
+			}
+			value, err := decodeYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.values = append(node.values, value)
+		}
+		if _, err := dec.Token(); err != nil { // closing delimiter
+			return nil, err
+		}
+		return node, nil
+	case string:
+		quoted, _ := json.Marshal(t)
+		return &yamlNode{scalar: string(quoted)}, nil
+	case json.Number:
+		return &yamlNode{scalar: t.String()}, nil
+	case bool:
+		return &yamlNode{scalar: fmt.Sprint(t)}, nil
+	default:
+		return &yamlNode{scalar: "null"}, nil
+	}
+}
+
+// inlineYAML renders scalars and empty containers, which fit on one line.
+func inlineYAML(node *yamlNode) string {
+	switch {
+	case node.scalar != "":
+		return node.scalar
+	case node.object:
+		return "{}"
+	default:
+		return "[]"
+	}
+}
+
+// writeYAML writes a non-empty object or array at the given indent.
+func writeYAML(buf *bytes.Buffer, node *yamlNode, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for i, value := range node.values {
+		prefix := pad + "- "
+		if node.object {
+			prefix = pad + yamlKey(node.keys[i]) + ":"
+		}
+		if value.scalar != "" || len(value.values) == 0 {
+			if node.object {
+				prefix += " "
+			}
+			buf.WriteString(prefix + inlineYAML(value) + "\n")
+			continue
+		}
+		if node.object {
+			buf.WriteString(prefix + "\n")
+			writeYAML(buf, value, indent+2)
+			continue
+		}
+		// A nested block starts on the item's own line: "- path: ...".
+		var nested bytes.Buffer
+		writeYAML(&nested, value, indent+2)
+		buf.WriteString(prefix)
+		buf.Write(nested.Bytes()[indent+2:])
+	}
+}
+
+// plainYAMLKey matches keys that can be written unquoted.
+var plainYAMLKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_ ./()-]*$`)
+
+// yamlKey returns key as a YAML mapping key, quoting it unless it is plain
+// text that YAML can't mistake for another type.
+func yamlKey(key string) string {
+	switch strings.ToLower(key) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null":
+		quoted, _ := json.Marshal(key)
+		return string(quoted)
+	}
+	if plainYAMLKey.MatchString(key) && !strings.HasSuffix(key, " ") {
+		return key
+	}
+	quoted, _ := json.Marshal(key)
+	return string(quoted)
+}
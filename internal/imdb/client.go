@@ -0,0 +1,119 @@
+package imdb
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+// IMDb has no keyword API and its public datasets don't include keywords, so
+// keywords are scraped from the title's keywords page. Pages are cached (in
+// DATA_DIR when set) so each title is fetched at most once per cache TTL.
+
+const keywordsURL = "https://www.imdb.com/title/%s/keywords/"
+
+// keywordLink matches a keyword entry on the keywords page. Keyword links
+// point at the keyword search; the link text is the keyword itself.
+var keywordLink = regexp.MustCompile(`<a[^>]+href="/search/keyword/?\?keywords=[^"]*"[^>]*>([^<]+)</a>`)
+
+type Client struct {
+	httpClient  *http.Client
+	retryClient *utils.RetryableHTTPClient
+	maxKeywords int
+	cache       *utils.FileCache[[]string]
+}
+
+// NewClient creates an IMDb keyword client. maxKeywords caps how many
+// keywords are kept per title (IMDb orders them by relevance). When dataDir
+// is non-empty the keyword cache is persisted to imdb_keywords.json there.
+func NewClient(dataDir string, maxKeywords int, cacheTTL time.Duration) *Client {
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	cachePath := ""
+	if dataDir != "" {
+		cachePath = filepath.Join(dataDir, "imdb_keywords.json")
+	}
+	return &Client{
+		httpClient:  httpClient,
+		retryClient: utils.NewRetryableHTTPClient(httpClient, nil),
+		maxKeywords: maxKeywords,
+		cache:       utils.NewFileCache[[]string](cachePath, cacheTTL),
+	}
+}
+
+// GetKeywords returns the normalized keywords for an IMDb title ID
+// ("tt0133093"), served from cache while the entry is younger than the TTL.
+func (c *Client) GetKeywords(imdbID string) ([]string, error) {
+	if !strings.HasPrefix(imdbID, "tt") {
+		imdbID = "tt" + imdbID
+	}
+
+	if keywords, ok := c.cache.Get(imdbID); ok {
+		return keywords, nil
+	}
+
+	keywords, err := c.fetchKeywords(imdbID)
+	if err != nil {
+		return nil, err
+	}
+	if len(keywords) > c.maxKeywords {
+		keywords = keywords[:c.maxKeywords]
+	}
+	keywords = utils.NormalizeKeywords(keywords)
+
+	if err := c.cache.Set(imdbID, keywords); err != nil {
+		fmt.Printf("[WARN] Failed to save IMDb keyword cache: %v\n", err)
+	}
+
+	return keywords, nil
+}
+
+func (c *Client) fetchKeywords(imdbID string) ([]string, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf(keywordsURL, imdbID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	// IMDb rejects requests without a browser-like user agent.
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; labelarr)")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+
+	resp, err := c.retryClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("imdb returned status %d for %s", resp.StatusCode, imdbID)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	return parseKeywords(string(body)), nil
+}
+
+// parseKeywords extracts keywords from a keywords page in page order,
+// skipping duplicates.
+func parseKeywords(page string) []string {
+	seen := make(map[string]bool)
+	var keywords []string
+	for _, match := range keywordLink.FindAllStringSubmatch(page, -1) {
+		keyword := strings.TrimSpace(html.UnescapeString(match[1]))
+		if keyword == "" || seen[strings.ToLower(keyword)] {
+			continue
+		}
+		seen[strings.ToLower(keyword)] = true
+		keywords = append(keywords, keyword)
+	}
+	return keywords
+}
@@ -0,0 +1,21 @@
+package imdb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeywords(t *testing.T) {
+	page := `<li><a class="ipc-metadata-list-summary-item__t" href="/search/keyword/?keywords=artificial-reality&amp;ref_=kw_1">artificial reality</a></li>
+<li><a class="ipc-metadata-list-summary-item__t" href="/search/keyword/?keywords=simulated-reality&amp;ref_=kw_2">simulated reality</a></li>
+<li><a href="/search/keyword?keywords=man-vs.-machine">man vs. machine</a></li>
+<li><a href="/search/keyword/?keywords=artificial-reality">Artificial Reality</a></li>
+<li><a href="/title/tt0133093/">The Matrix</a></li>
+<li><a href="/search/keyword/?keywords=rock-%26-roll">rock &amp; roll</a></li>`
+
+	got := parseKeywords(page)
+	want := []string{"artificial reality", "simulated reality", "man vs. machine", "rock & roll"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseKeywords() = %v, want %v", got, want)
+	}
+}
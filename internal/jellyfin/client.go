@@ -0,0 +1,486 @@
+package jellyfin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/plex"
+)
+
+// itemFields are the optional BaseItemDto fields requested with every item
+// query.
+const itemFields = "ProviderIds,Tags,Genres,MediaSources,ProductionYear,DateCreated"
+
+// Client is a Jellyfin API client. Emby serves the same API, so it works
+// with both. Items are converted to the plex types the processor works with:
+// Jellyfin item IDs stand in for rating keys, provider IDs for GUIDs, and
+// tags for labels.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	userID string
+}
+
+// NewClient creates a Jellyfin client. Item queries run as userID, or as
+// the first administrator when userID is empty.
+func NewClient(baseURL, apiKey, userID string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		userID:     userID,
+	}
+}
+
+func (c *Client) do(method, path string, params url.Values, body io.Reader) (*http.Response, error) {
+	requestURL := c.baseURL + path
+	if len(params) > 0 {
+		requestURL += "?" + params.Encode()
+	}
+	req, err := http.NewRequest(method, requestURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Emby-Token", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.httpClient.Do(req)
+}
+
+func (c *Client) getJSON(path string, params url.Values, out interface{}) error {
+	resp, err := c.do("GET", path, params, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("jellyfin API rejected the key (status 401) - check JELLYFIN_API_KEY")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jellyfin API returned status %d for %s", resp.StatusCode, path)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", path, err)
+	}
+	return nil
+}
+
+// getUserID returns the user item queries run as, resolving the first
+// administrator on first use.
+func (c *Client) getUserID() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.userID != "" {
+		return c.userID, nil
+	}
+
+	var users []User
+	if err := c.getJSON("/Users", nil, &users); err != nil {
+		return "", err
+	}
+	for _, user := range users {
+		if user.Policy.IsAdministrator {
+			c.userID = user.ID
+			return c.userID, nil
+		}
+	}
+	return "", fmt.Errorf("no administrator account found - set JELLYFIN_USER_ID")
+}
+
+// TestConnection verifies the URL and API key and resolves the user.
+func (c *Client) TestConnection() error {
+	_, err := c.getUserID()
+	return err
+}
+
+// GetAllLibraries fetches all libraries, reporting movie and TV libraries
+// with Plex's "movie" and "show" types
+func (c *Client) GetAllLibraries() ([]plex.Library, error) {
+	var folders []VirtualFolder
+	if err := c.getJSON("/Library/VirtualFolders", nil, &folders); err != nil {
+		return nil, err
+	}
+	libraries := make([]plex.Library, 0, len(folders))
+	for _, folder := range folders {
+		libraryType := folder.CollectionType
+		switch folder.CollectionType {
+		case "movies":
+			libraryType = "movie"
+		case "tvshows":
+			libraryType = "show"
+		}
+		libraries = append(libraries, plex.Library{Key: folder.ItemID, Title: folder.Name, Type: libraryType, Agent: "jellyfin"})
+	}
+	return libraries, nil
+}
+
+// getItems runs an item query as the configured user
+func (c *Client) getItems(params url.Values) ([]Item, error) {
+	userID, err := c.getUserID()
+	if err != nil {
+		return nil, err
+	}
+	params.Set("Fields", itemFields)
+	var response ItemsResponse
+	if err := c.getJSON(fmt.Sprintf("/Users/%s/Items", userID), params, &response); err != nil {
+		return nil, err
+	}
+	return response.Items, nil
+}
+
+func (c *Client) getLibraryItems(libraryID, itemType string) ([]Item, error) {
+	return c.getItems(url.Values{
+		"ParentId":         {libraryID},
+		"IncludeItemTypes": {itemType},
+		"Recursive":        {"true"},
+	})
+}
+
+func (c *Client) getItem(itemID string) (*Item, error) {
+	items, err := c.getItems(url.Values{"Ids": {itemID}})
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: ID %s", plex.ErrNotFound, itemID)
+	}
+	return &items[0], nil
+}
+
+// GetMoviesFromLibrary fetches all movies from a library
+func (c *Client) GetMoviesFromLibrary(libraryID string) ([]plex.Movie, error) {
+	items, err := c.getLibraryItems(libraryID, "Movie")
+	if err != nil {
+		return nil, err
+	}
+	movies := make([]plex.Movie, 0, len(items))
+	for _, item := range items {
+		movies = append(movies, toMovie(item))
+	}
+	return movies, nil
+}
+
+// GetMovieDetails fetches a single movie
+func (c *Client) GetMovieDetails(ratingKey string) (*plex.Movie, error) {
+	item, err := c.getItem(ratingKey)
+	if err != nil {
+		return nil, err
+	}
+	movie := toMovie(*item)
+	return &movie, nil
+}
+
+// GetTVShowsFromLibrary fetches all series from a library
+func (c *Client) GetTVShowsFromLibrary(libraryID string) ([]plex.TVShow, error) {
+	items, err := c.getLibraryItems(libraryID, "Series")
+	if err != nil {
+		return nil, err
+	}
+	shows := make([]plex.TVShow, 0, len(items))
+	for _, item := range items {
+		shows = append(shows, plex.TVShow(toMovie(item)))
+	}
+	return shows, nil
+}
+
+// GetTVShowDetails fetches a single series
+func (c *Client) GetTVShowDetails(ratingKey string) (*plex.TVShow, error) {
+	item, err := c.getItem(ratingKey)
+	if err != nil {
+		return nil, err
+	}
+	show := plex.TVShow(toMovie(*item))
+	return &show, nil
+}
+
+// GetTVShowEpisodes fetches the first episodes of a series (for TMDb ID
+// extraction from file paths)
+func (c *Client) GetTVShowEpisodes(ratingKey string) ([]plex.Episode, error) {
+	return c.getEpisodes(ratingKey, url.Values{"Limit": {"10"}})
+}
+
+// GetAllTVShowEpisodes fetches every episode of a series
+func (c *Client) GetAllTVShowEpisodes(ratingKey string) ([]plex.Episode, error) {
+	return c.getEpisodes(ratingKey, url.Values{})
+}
+
+func (c *Client) getEpisodes(seriesID string, params url.Values) ([]plex.Episode, error) {
+	userID, err := c.getUserID()
+	if err != nil {
+		return nil, err
+	}
+	params.Set("UserId", userID)
+	params.Set("Fields", itemFields)
+	var response ItemsResponse
+	if err := c.getJSON(fmt.Sprintf("/Shows/%s/Episodes", seriesID), params, &response); err != nil {
+		return nil, err
+	}
+	episodes := make([]plex.Episode, 0, len(response.Items))
+	for _, item := range response.Items {
+		episodes = append(episodes, plex.Episode{RatingKey: item.ID, Title: item.Name, Media: toMedia(item.MediaSources)})
+	}
+	return episodes, nil
+}
+
+// GetItemMedia fetches the media sources of any item, including streams
+func (c *Client) GetItemMedia(ratingKey string) ([]plex.Media, error) {
+	item, err := c.getItem(ratingKey)
+	if err != nil {
+		return nil, err
+	}
+	return toMedia(item.MediaSources), nil
+}
+
+// GetItemRef fetches the title and type of any item
+func (c *Client) GetItemRef(ratingKey string) (*plex.ItemRef, error) {
+	item, err := c.getItem(ratingKey)
+	if err != nil {
+		return nil, err
+	}
+	return &plex.ItemRef{RatingKey: item.ID, Title: item.Name, Type: strings.ToLower(item.Type)}, nil
+}
+
+// GetSimilarItems fetches the items Jellyfin lists as similar to an item
+func (c *Client) GetSimilarItems(ratingKey string) ([]plex.ItemRef, error) {
+	userID, err := c.getUserID()
+	if err != nil {
+		return nil, err
+	}
+	var response ItemsResponse
+	if err := c.getJSON(fmt.Sprintf("/Items/%s/Similar", ratingKey), url.Values{"UserId": {userID}}, &response); err != nil {
+		return nil, err
+	}
+	refs := make([]plex.ItemRef, 0, len(response.Items))
+	for _, item := range response.Items {
+		refs = append(refs, plex.ItemRef{RatingKey: item.ID, Title: item.Name, Type: strings.ToLower(item.Type)})
+	}
+	return refs, nil
+}
+
+// GetRelatedItems is not supported: Jellyfin has no equivalent of Plex's
+// related hubs
+func (c *Client) GetRelatedItems(ratingKey string) ([]plex.ItemRef, error) {
+	return nil, fmt.Errorf("related items are not available from Jellyfin")
+}
+
+// UpdateMediaField replaces an item's tags (UPDATE_FIELD=label) or genres
+// (UPDATE_FIELD=genre) with keywords and locks the field
+func (c *Client) UpdateMediaField(mediaID, libraryID string, keywords []string, updateField string, mediaType string) error {
+	field := itemField(updateField)
+	return c.updateItem(mediaID, func(dto map[string]interface{}) {
+		dto[field] = keywords
+		setLocked(dto, field, true)
+	})
+}
+
+// SetMediaField replaces an item's tags or genres with values, locking or
+// unlocking the field as requested
+func (c *Client) SetMediaField(mediaID, libraryID string, values []string, updateField string, lockField bool, mediaType string) error {
+	field := itemField(updateField)
+	return c.updateItem(mediaID, func(dto map[string]interface{}) {
+		dto[field] = values
+		setLocked(dto, field, lockField)
+	})
+}
+
+// RemoveMediaFieldKeywords removes values from an item's tags or genres
+func (c *Client) RemoveMediaFieldKeywords(mediaID, libraryID string, valuesToRemove []string, updateField string, lockField bool, mediaType string) error {
+	remove := make(map[string]bool, len(valuesToRemove))
+	for _, value := range valuesToRemove {
+		remove[strings.ToLower(value)] = true
+	}
+
+	field := itemField(updateField)
+	return c.updateItem(mediaID, func(dto map[string]interface{}) {
+		kept := []string{}
+		for _, value := range stringSlice(dto[field]) {
+			if !remove[strings.ToLower(value)] {
+				kept = append(kept, value)
+			}
+		}
+		dto[field] = kept
+		setLocked(dto, field, lockField)
+	})
+}
+
+// updateItem applies modify to the item's full BaseItemDto and posts it
+// back. Jellyfin replaces the item's metadata with the posted DTO, so the
+// DTO has to be complete rather than just the changed field.
+func (c *Client) updateItem(itemID string, modify func(dto map[string]interface{})) error {
+	userID, err := c.getUserID()
+	if err != nil {
+		return err
+	}
+	var dto map[string]interface{}
+	if err := c.getJSON(fmt.Sprintf("/Users/%s/Items/%s", userID, itemID), nil, &dto); err != nil {
+		return err
+	}
+	modify(dto)
+
+	body, err := json.Marshal(dto)
+	if err != nil {
+		return fmt.Errorf("failed to encode item: %w", err)
+	}
+	resp, err := c.do("POST", "/Items/"+itemID, nil, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to update item: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("jellyfin API returned status %d when updating item - Response: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// itemField maps UPDATE_FIELD to the BaseItemDto field it writes
+func itemField(updateField string) string {
+	if updateField == "genre" {
+		return "Genres"
+	}
+	return "Tags"
+}
+
+// setLocked adds field to or removes it from the DTO's LockedFields, which
+// stops metadata refreshes from overwriting it
+func setLocked(dto map[string]interface{}, field string, locked bool) {
+	fields := []string{}
+	for _, value := range stringSlice(dto["LockedFields"]) {
+		if value != field {
+			fields = append(fields, value)
+		}
+	}
+	if locked {
+		fields = append(fields, field)
+	}
+	dto["LockedFields"] = fields
+}
+
+// stringSlice reads a DTO string array, whether decoded from JSON or set by
+// an earlier modification
+func stringSlice(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// toMovie converts a Jellyfin item to the plex shape, mapping provider IDs
+// to "tmdb://", "imdb://" and "tvdb://" GUIDs
+func toMovie(item Item) plex.Movie {
+	movie := plex.Movie{
+		RatingKey: item.ID,
+		Title:     item.Name,
+		Year:      item.ProductionYear,
+		Media:     toMedia(item.MediaSources),
+	}
+	if created, err := time.Parse(time.RFC3339Nano, item.DateCreated); err == nil {
+		movie.AddedAt = created.Unix()
+	}
+	for provider, id := range item.ProviderIDs {
+		switch p := strings.ToLower(provider); p {
+		case "tmdb", "imdb", "tvdb":
+			if id != "" {
+				movie.Guid = append(movie.Guid, plex.Guid{ID: p + "://" + id})
+			}
+		}
+	}
+	sort.Slice(movie.Guid, func(i, j int) bool { return movie.Guid[i].ID < movie.Guid[j].ID })
+	for _, tag := range item.Tags {
+		movie.Label = append(movie.Label, plex.Label{Tag: tag})
+	}
+	for _, genre := range item.Genres {
+		movie.Genre = append(movie.Genre, plex.Genre{Tag: genre})
+	}
+	return movie
+}
+
+func toMedia(sources []MediaSource) []plex.Media {
+	media := make([]plex.Media, 0, len(sources))
+	for _, source := range sources {
+		m := plex.Media{}
+		part := plex.Part{File: source.Path, Size: source.Size, Duration: source.RunTimeTicks / 10000}
+		for _, stream := range source.MediaStreams {
+			streamType, ok := streamTypes[stream.Type]
+			if !ok {
+				continue
+			}
+			converted := plex.Stream{StreamType: streamType, LanguageCode: stream.Language}
+			if streamType == plex.StreamTypeVideo {
+				if m.VideoResolution == "" {
+					m.VideoResolution = videoResolution(stream.Width, stream.Height)
+				}
+				setDynamicRange(&converted, stream.VideoRangeType)
+			}
+			part.Stream = append(part.Stream, converted)
+		}
+		m.Part = []plex.Part{part}
+		media = append(media, m)
+	}
+	return media
+}
+
+// videoResolution buckets a video size into Plex's videoResolution values.
+// Widths are checked too so letterboxed films keep their class.
+func videoResolution(width, height int) string {
+	switch {
+	case width == 0 && height == 0:
+		return ""
+	case width >= 3200 || height >= 2000:
+		return "4k"
+	case width >= 1800 || height >= 1000:
+		return "1080"
+	case width >= 1200 || height >= 700:
+		return "720"
+	default:
+		return "sd"
+	}
+}
+
+// setDynamicRange translates Jellyfin's VideoRangeType into the attributes
+// Plex reports for HDR video streams
+func setDynamicRange(stream *plex.Stream, rangeType string) {
+	if strings.HasPrefix(rangeType, "DOVI") {
+		stream.DOVIPresent = true
+	}
+	switch rangeType {
+	case "HDR10", "DOVIWithHDR10":
+		stream.ColorTrc = "smpte2084"
+	case "HDR10Plus", "DOVIWithHDR10Plus":
+		stream.ColorTrc = "smpte2084"
+		stream.DisplayTitle = "HDR10+"
+	case "HLG", "DOVIWithHLG":
+		stream.ColorTrc = "arib-std-b67"
+	}
+}
+
+// streamTypes maps Jellyfin stream types to Plex's
+var streamTypes = map[string]int{
+	"Video":    plex.StreamTypeVideo,
+	"Audio":    plex.StreamTypeAudio,
+	"Subtitle": plex.StreamTypeSubtitle,
+}
@@ -0,0 +1,55 @@
+package jellyfin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToMovie(t *testing.T) {
+	item := Item{
+		ID:             "f27caa37e5142225cceded48f6553502",
+		Name:           "The Matrix",
+		ProductionYear: 1999,
+		ProviderIDs:    map[string]string{"Tmdb": "603", "Imdb": "tt0133093", "TmdbCollection": "2344", "Tvdb": ""},
+		Tags:           []string{"cyberpunk"},
+		Genres:         []string{"Action"},
+		MediaSources:   []MediaSource{{Path: "/movies/The Matrix (1999)/The Matrix.mkv", Size: 1024}},
+	}
+
+	movie := toMovie(item)
+
+	if movie.RatingKey != item.ID || movie.Title != "The Matrix" || movie.Year != 1999 {
+		t.Errorf("unexpected identity fields: %+v", movie)
+	}
+	var guids []string
+	for _, guid := range movie.Guid {
+		guids = append(guids, guid.ID)
+	}
+	if want := []string{"imdb://tt0133093", "tmdb://603"}; !reflect.DeepEqual(guids, want) {
+		t.Errorf("GUIDs = %v, want %v", guids, want)
+	}
+	if len(movie.Label) != 1 || movie.Label[0].Tag != "cyberpunk" {
+		t.Errorf("expected tags as labels, got %+v", movie.Label)
+	}
+	if len(movie.Genre) != 1 || movie.Genre[0].Tag != "Action" {
+		t.Errorf("expected genres, got %+v", movie.Genre)
+	}
+	if len(movie.Media) != 1 || movie.Media[0].Part[0].File != item.MediaSources[0].Path {
+		t.Errorf("expected media source as a part, got %+v", movie.Media)
+	}
+}
+
+func TestSetLocked(t *testing.T) {
+	dto := map[string]interface{}{"LockedFields": []interface{}{"Genres", "Tags"}}
+
+	setLocked(dto, "Tags", false)
+	if got := dto["LockedFields"]; !reflect.DeepEqual(got, []string{"Genres"}) {
+		t.Errorf("after unlock LockedFields = %v", got)
+	}
+
+	setLocked(dto, "Tags", true)
+	setLocked(dto, "Tags", true)
+	if got := dto["LockedFields"]; !reflect.DeepEqual(got, []string{"Genres", "Tags"}) {
+		t.Errorf("after lock LockedFields = %v", got)
+	}
+}
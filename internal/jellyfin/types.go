@@ -0,0 +1,53 @@
+package jellyfin
+
+// VirtualFolder is a Jellyfin library as listed by /Library/VirtualFolders
+type VirtualFolder struct {
+	Name           string `json:"Name"`
+	ItemID         string `json:"ItemId"`
+	CollectionType string `json:"CollectionType"` // "movies", "tvshows", ...
+}
+
+// MediaStream is a video, audio or subtitle stream of a media source
+type MediaStream struct {
+	Type           string `json:"Type"`           // "Video", "Audio", "Subtitle"
+	Language       string `json:"Language"`       // ISO 639-2, e.g. "jpn"
+	Width          int    `json:"Width"`          // video only
+	Height         int    `json:"Height"`         // video only
+	VideoRangeType string `json:"VideoRangeType"` // "SDR", "HDR10", "HDR10Plus", "HLG", "DOVI", "DOVIWithHDR10", ...
+}
+
+// MediaSource is a playable version of an item
+type MediaSource struct {
+	Path         string        `json:"Path"`
+	Size         int64         `json:"Size"`
+	RunTimeTicks int64         `json:"RunTimeTicks"` // 100ns ticks
+	MediaStreams []MediaStream `json:"MediaStreams"`
+}
+
+// Item is the subset of Jellyfin's BaseItemDto that labelarr reads
+type Item struct {
+	ID             string            `json:"Id"`
+	Name           string            `json:"Name"`
+	Type           string            `json:"Type"` // "Movie", "Series", "Episode"
+	ProductionYear int               `json:"ProductionYear"`
+	ProviderIDs    map[string]string `json:"ProviderIds"`
+	Tags           []string          `json:"Tags"`
+	Genres         []string          `json:"Genres"`
+	MediaSources   []MediaSource     `json:"MediaSources"`
+	DateCreated    string            `json:"DateCreated"`
+}
+
+// ItemsResponse is the paged item list returned by item queries
+type ItemsResponse struct {
+	Items            []Item `json:"Items"`
+	TotalRecordCount int    `json:"TotalRecordCount"`
+}
+
+// User is a Jellyfin user account
+type User struct {
+	ID     string `json:"Id"`
+	Name   string `json:"Name"`
+	Policy struct {
+		IsAdministrator bool `json:"IsAdministrator"`
+	} `json:"Policy"`
+}
@@ -0,0 +1,127 @@
+package justwatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+// JustWatch has no public API; this uses the GraphQL endpoint behind its
+// website, which may change without notice. Unlike TMDb's watch provider
+// data it includes when an offer expires, which drives "leaving soon"
+// labels.
+const apiURL = "https://apis.justwatch.com/graphql"
+
+const searchQuery = `query GetSearchTitles($country: Country!, $language: Language!, $first: Int!, $filter: TitleFilter) {
+  popularTitles(country: $country, first: $first, filter: $filter) {
+    edges {
+      node {
+        objectType
+        content(country: $country, language: $language) {
+          externalIds { tmdbId }
+        }
+        offers(country: $country, platform: WEB) {
+          monetizationType
+          availableToTime
+          package { clearName }
+        }
+      }
+    }
+  }
+}`
+
+// Client is a JustWatch client for one region
+type Client struct {
+	region      string
+	httpClient  *http.Client
+	retryClient *utils.RetryableHTTPClient
+}
+
+// NewClient creates a JustWatch client for a region (ISO 3166-1, e.g. "US")
+func NewClient(region string) *Client {
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	return &Client{
+		region:      strings.ToUpper(region),
+		httpClient:  httpClient,
+		retryClient: utils.NewRetryableHTTPClient(httpClient, nil),
+	}
+}
+
+// GetOffers searches JustWatch for the title and returns the offers of the
+// result whose TMDb ID matches. mediaType is "movie" or "tv". A title
+// JustWatch doesn't know returns no offers and no error.
+func (c *Client) GetOffers(title, tmdbID, mediaType string) ([]Offer, error) {
+	objectType := "MOVIE"
+	if mediaType == "tv" {
+		objectType = "SHOW"
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"query": searchQuery,
+		"variables": map[string]interface{}{
+			"country":  c.region,
+			"language": "en",
+			"first":    5,
+			"filter": map[string]interface{}{
+				"searchQuery": title,
+				"objectTypes": []string{objectType},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding query: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.retryClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("JustWatch returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result searchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("JustWatch API error: %s", result.Errors[0].Message)
+	}
+	return matchOffers(&result, tmdbID), nil
+}
+
+// matchOffers returns the offers of the search result with the given TMDb ID
+func matchOffers(result *searchResponse, tmdbID string) []Offer {
+	for _, edge := range result.Data.PopularTitles.Edges {
+		node := edge.Node
+		if node.Content.ExternalIDs.TMDbID != tmdbID {
+			continue
+		}
+		var offers []Offer
+		for _, o := range node.Offers {
+			offer := Offer{Provider: o.Package.ClearName, MonetizationType: o.MonetizationType}
+			if o.AvailableToTime != "" {
+				offer.AvailableTo, _ = time.Parse(time.RFC3339, o.AvailableToTime)
+			}
+			offers = append(offers, offer)
+		}
+		return offers
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package justwatch
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMatchOffers(t *testing.T) {
+	body := `{"data": {"popularTitles": {"edges": [
+		{"node": {"objectType": "MOVIE", "content": {"externalIds": {"tmdbId": "1"}}, "offers": [
+			{"monetizationType": "RENT", "package": {"clearName": "Apple TV"}}
+		]}},
+		{"node": {"objectType": "MOVIE", "content": {"externalIds": {"tmdbId": "949"}}, "offers": [
+			{"monetizationType": "FLATRATE", "availableToTime": "2026-11-01T00:00:00Z", "package": {"clearName": "Netflix"}},
+			{"monetizationType": "ADS", "package": {"clearName": "Tubi"}}
+		]}}
+	]}}}`
+	var result searchResponse
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	offers := matchOffers(&result, "949")
+	if len(offers) != 2 {
+		t.Fatalf("matchOffers() returned %d offers, want 2: %+v", len(offers), offers)
+	}
+	if offers[0].Provider != "Netflix" || offers[0].AvailableTo.IsZero() {
+		t.Errorf("expected Netflix offer with an end date, got %+v", offers[0])
+	}
+	if offers[1].Provider != "Tubi" || !offers[1].AvailableTo.IsZero() {
+		t.Errorf("expected open-ended Tubi offer, got %+v", offers[1])
+	}
+
+	if offers := matchOffers(&result, "603"); offers != nil {
+		t.Errorf("expected no offers for an unmatched TMDb ID, got %+v", offers)
+	}
+}
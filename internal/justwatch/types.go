@@ -0,0 +1,38 @@
+package justwatch
+
+import "time"
+
+// Offer is a way to watch a title on a streaming service
+type Offer struct {
+	Provider         string
+	MonetizationType string    // FLATRATE, FREE, ADS, RENT, BUY
+	AvailableTo      time.Time // zero when no end date is announced
+}
+
+// searchResponse represents the GraphQL popularTitles response
+type searchResponse struct {
+	Data struct {
+		PopularTitles struct {
+			Edges []struct {
+				Node struct {
+					ObjectType string `json:"objectType"`
+					Content    struct {
+						ExternalIDs struct {
+							TMDbID string `json:"tmdbId"`
+						} `json:"externalIds"`
+					} `json:"content"`
+					Offers []struct {
+						MonetizationType string `json:"monetizationType"`
+						AvailableToTime  string `json:"availableToTime"`
+						Package          struct {
+							ClearName string `json:"clearName"`
+						} `json:"package"`
+					} `json:"offers"`
+				} `json:"node"`
+			} `json:"edges"`
+		} `json:"popularTitles"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
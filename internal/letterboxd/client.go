@@ -0,0 +1,247 @@
+package letterboxd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+// Letterboxd has no public API, so lists are scraped from their pages (or
+// read from a list's CSV export) and each film is mapped to its TMDb ID via
+// the data-tmdb-id attribute on its film page. List contents are cached for
+// the refresh interval; film-to-TMDb mappings practically never change and
+// are cached for a year.
+
+const (
+	baseURL        = "https://letterboxd.com"
+	filmMappingTTL = 365 * 24 * time.Hour
+)
+
+var (
+	// filmSlug matches poster entries on list and watchlist pages.
+	filmSlug = regexp.MustCompile(`data-(?:film|item)-slug="([^"]+)"|data-target-link="/film/([^/"]+)/"`)
+	// nextPage matches the pagination link to the following page.
+	nextPage = regexp.MustCompile(`<a[^>]+class="next"`)
+	// ogTitle matches the page title used as the default label.
+	ogTitle  = regexp.MustCompile(`<meta property="og:title" content="([^"]*)"`)
+	tmdbID   = regexp.MustCompile(`data-tmdb-id="(\d+)"`)
+	tmdbType = regexp.MustCompile(`data-tmdb-type="(movie|tv)"`)
+)
+
+// List is a resolved Letterboxd list
+type List struct {
+	Title   string   `json:"title"`
+	Members []string `json:"members"` // "movie:<tmdbID>" / "tv:<tmdbID>"
+}
+
+type Client struct {
+	httpClient  *http.Client
+	retryClient *utils.RetryableHTTPClient
+	lists       *utils.FileCache[List]
+	films       *utils.FileCache[string]
+}
+
+// NewClient creates a Letterboxd client. Lists are re-fetched after refresh;
+// when dataDir is non-empty both caches are persisted there.
+func NewClient(dataDir string, refresh time.Duration) *Client {
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	listsPath, filmsPath := "", ""
+	if dataDir != "" {
+		listsPath = filepath.Join(dataDir, "letterboxd_lists.json")
+		filmsPath = filepath.Join(dataDir, "letterboxd_films.json")
+	}
+	return &Client{
+		httpClient:  httpClient,
+		retryClient: utils.NewRetryableHTTPClient(httpClient, nil),
+		lists:       utils.NewFileCache[List](listsPath, refresh),
+		films:       utils.NewFileCache[string](filmsPath, filmMappingTTL),
+	}
+}
+
+// GetList resolves a list reference: a list URL
+// (https://letterboxd.com/user/list/slug/), a bare username (that user's
+// watchlist), or the path to a list's CSV export.
+func (c *Client) GetList(ref string) (List, error) {
+	if list, ok := c.lists.Get(ref); ok {
+		return list, nil
+	}
+
+	var (
+		list List
+		err  error
+	)
+	switch {
+	case strings.HasSuffix(strings.ToLower(ref), ".csv"):
+		list, err = c.importCSV(ref)
+	case strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://"):
+		list, err = c.scrapeList(ref)
+	default:
+		list, err = c.scrapeList(fmt.Sprintf("%s/%s/watchlist/", baseURL, ref))
+		if err == nil && list.Title == "" {
+			list.Title = ref + "'s Watchlist"
+		}
+	}
+	// Films resolved so far are saved even if the list failed part way
+	if err := c.films.Flush(); err != nil {
+		fmt.Printf("[WARN] Failed to save Letterboxd film cache: %v\n", err)
+	}
+	if err != nil {
+		return List{}, err
+	}
+
+	if err := c.lists.Set(ref, list); err != nil {
+		fmt.Printf("[WARN] Failed to save Letterboxd list cache: %v\n", err)
+	}
+	return list, nil
+}
+
+// scrapeList walks every page of a list and resolves each film.
+func (c *Client) scrapeList(listURL string) (List, error) {
+	listURL = strings.TrimRight(listURL, "/") + "/"
+	var list List
+
+	for page := 1; ; page++ {
+		pageURL := listURL
+		if page > 1 {
+			pageURL = fmt.Sprintf("%spage/%d/", listURL, page)
+		}
+		body, err := c.get(pageURL)
+		if err != nil {
+			return List{}, err
+		}
+
+		if page == 1 {
+			if m := ogTitle.FindStringSubmatch(body); m != nil {
+				list.Title = strings.TrimSpace(html.UnescapeString(m[1]))
+			}
+		}
+		for _, m := range filmSlug.FindAllStringSubmatch(body, -1) {
+			slug := m[1] + m[2]
+			if member := c.resolveFilm(fmt.Sprintf("%s/film/%s/", baseURL, slug)); member != "" {
+				list.Members = append(list.Members, member)
+			}
+		}
+		if !nextPage.MatchString(body) {
+			break
+		}
+	}
+	return list, nil
+}
+
+// importCSV reads a list's CSV export. List exports start with a list
+// header (Date,Name,...,URL,...) and its values, followed by the film rows
+// under a Position,Name,Year,URL header; the watchlist export (watchlist.csv)
+// has only film rows under Date,Name,Year,Letterboxd URI.
+func (c *Client) importCSV(path string) (List, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return List{}, fmt.Errorf("failed to open Letterboxd export: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return List{}, fmt.Errorf("failed to read Letterboxd export: %w", err)
+	}
+
+	list := List{Title: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+	for _, url := range exportFilmURLs(records, &list.Title) {
+		if member := c.resolveFilm(url); member != "" {
+			list.Members = append(list.Members, member)
+		}
+	}
+	return list, nil
+}
+
+// exportFilmURLs extracts the film URLs from the records of a CSV export,
+// updating title from the list header when the export has one.
+func exportFilmURLs(records [][]string, title *string) []string {
+	var urls []string
+	urlColumn := -1
+	for i, record := range records {
+		switch {
+		case column(record, "Position") >= 0:
+			urlColumn = column(record, "URL")
+		case column(record, "Letterboxd URI") >= 0:
+			urlColumn = column(record, "Letterboxd URI")
+		case urlColumn < 0 && column(record, "Date") >= 0 && column(record, "Name") >= 0:
+			name := column(record, "Name")
+			if i+1 < len(records) && name < len(records[i+1]) && records[i+1][name] != "" {
+				*title = records[i+1][name]
+			}
+		case urlColumn >= 0 && urlColumn < len(record) && record[urlColumn] != "":
+			urls = append(urls, record[urlColumn])
+		}
+	}
+	return urls
+}
+
+func column(record []string, name string) int {
+	for i, field := range record {
+		if strings.EqualFold(strings.TrimSpace(field), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// resolveFilm maps a film page URL (or boxd.it short link) to a
+// "movie:<id>" / "tv:<id>" key, or "" if the page has no TMDb link. The
+// mapping is cached, and GetList saves the cache once per list.
+func (c *Client) resolveFilm(filmURL string) string {
+	if member, ok := c.films.Get(filmURL); ok {
+		return member
+	}
+
+	body, err := c.get(filmURL)
+	if err != nil {
+		return ""
+	}
+	member := ""
+	if id := tmdbID.FindStringSubmatch(body); id != nil {
+		kind := "movie"
+		if t := tmdbType.FindStringSubmatch(body); t != nil {
+			kind = t[1]
+		}
+		member = kind + ":" + id[1]
+	}
+
+	c.films.Put(filmURL, member)
+	return member
+}
+
+func (c *Client) get(pageURL string) (string, error) {
+	req, err := http.NewRequest("GET", pageURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; labelarr)")
+
+	resp, err := c.retryClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("letterboxd returned status %d for %s", resp.StatusCode, pageURL)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading response: %w", err)
+	}
+	return string(body), nil
+}
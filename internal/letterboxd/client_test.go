@@ -0,0 +1,48 @@
+package letterboxd
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestExportFilmURLs(t *testing.T) {
+	listExport := `Letterboxd list export v7
+Date,Name,Tags,URL,Description
+2024-03-01,Comfort Movies,,https://letterboxd.com/someone/list/comfort-movies/,
+
+Position,Name,Year,URL,Description
+1,Paddington 2,2017,https://letterboxd.com/film/paddington-2/,
+2,Amélie,2001,https://letterboxd.com/film/amelie/,
+`
+	watchlistExport := `Date,Name,Year,Letterboxd URI
+2024-01-02,Heat,1995,https://boxd.it/29Oe
+`
+
+	tests := []struct {
+		name      string
+		input     string
+		wantTitle string
+		wantURLs  []string
+	}{
+		{"list", listExport, "Comfort Movies", []string{"https://letterboxd.com/film/paddington-2/", "https://letterboxd.com/film/amelie/"}},
+		{"watchlist", watchlistExport, "watchlist", []string{"https://boxd.it/29Oe"}},
+	}
+	for _, tt := range tests {
+		reader := csv.NewReader(strings.NewReader(tt.input))
+		reader.FieldsPerRecord = -1
+		records, err := reader.ReadAll()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		title := "watchlist"
+		urls := exportFilmURLs(records, &title)
+		if title != tt.wantTitle {
+			t.Errorf("%s: title = %q, want %q", tt.name, title, tt.wantTitle)
+		}
+		if strings.Join(urls, " ") != strings.Join(tt.wantURLs, " ") {
+			t.Errorf("%s: urls = %v, want %v", tt.name, urls, tt.wantURLs)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package mal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+const (
+	baseURL = "https://api.jikan.moe/v4"
+
+	// Jikan allows 3 requests per second and 60 per minute.
+	requestsPerSecond   = 1
+	maxRateLimitRetries = 5
+)
+
+// Client reads MyAnimeList data through the Jikan API, which needs no
+// authentication.
+type Client struct {
+	httpClient *http.Client
+	limiter    *utils.RateLimiter
+	cache      *utils.FileCache[Categories]
+}
+
+// NewClient creates a Jikan client caching results in dataDir (in memory if
+// empty) for cacheTTL.
+func NewClient(dataDir string, cacheTTL time.Duration) *Client {
+	cachePath := ""
+	if dataDir != "" {
+		cachePath = filepath.Join(dataDir, "mal_categories.json")
+	}
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		limiter:    utils.NewRateLimiter(requestsPerSecond),
+		cache:      utils.NewFileCache[Categories](cachePath, cacheTTL),
+	}
+}
+
+// GetCategories returns the genres, themes and demographics of a MAL anime
+func (c *Client) GetCategories(malID string) (Categories, error) {
+	if categories, ok := c.cache.Get(malID); ok {
+		return categories, nil
+	}
+
+	resp, err := c.get(fmt.Sprintf("%s/anime/%s", baseURL, malID))
+	if err != nil {
+		return Categories{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return Categories{}, fmt.Errorf("jikan API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result animeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Categories{}, fmt.Errorf("error decoding response: %w", err)
+	}
+	categories := animeCategories(result.Data)
+
+	if err := c.cache.Set(malID, categories); err != nil {
+		fmt.Printf("[WARN] Failed to save MAL cache: %v\n", err)
+	}
+	return categories, nil
+}
+
+// animeCategories flattens an anime record into label names. Explicit
+// genres are listed with the regular ones.
+func animeCategories(anime Anime) Categories {
+	names := func(entities ...[]Entity) []string {
+		var out []string
+		for _, list := range entities {
+			for _, entity := range list {
+				out = append(out, entity.Name)
+			}
+		}
+		return out
+	}
+	return Categories{
+		Genres:       names(anime.Genres, anime.ExplicitGenres),
+		Themes:       names(anime.Themes),
+		Demographics: names(anime.Demographics),
+	}
+}
+
+// get sends a request through the rate limiter, backing off and retrying
+// when Jikan answers 429.
+func (c *Client) get(url string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(context.Background()); err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error making request: %w", err)
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= maxRateLimitRetries {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		backoff := 2 * time.Second
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			backoff = time.Duration(seconds) * time.Second
+		}
+		c.limiter.Pause(backoff)
+	}
+}
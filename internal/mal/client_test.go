@@ -0,0 +1,25 @@
+package mal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnimeCategories(t *testing.T) {
+	anime := Anime{
+		Genres:         []Entity{{Name: "Action"}, {Name: "Drama"}},
+		ExplicitGenres: []Entity{{Name: "Hentai"}},
+		Themes:         []Entity{{Name: "Military"}},
+		Demographics:   []Entity{{Name: "Shounen"}},
+	}
+
+	got := animeCategories(anime)
+	want := Categories{
+		Genres:       []string{"Action", "Drama", "Hentai"},
+		Themes:       []string{"Military"},
+		Demographics: []string{"Shounen"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("animeCategories() = %+v, want %+v", got, want)
+	}
+}
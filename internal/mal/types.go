@@ -0,0 +1,28 @@
+package mal
+
+// Entity represents a named MyAnimeList genre, theme or demographic
+type Entity struct {
+	MalID int    `json:"mal_id"`
+	Name  string `json:"name"`
+}
+
+// Anime represents the fields labelarr reads from a Jikan anime record
+type Anime struct {
+	MalID          int      `json:"mal_id"`
+	Genres         []Entity `json:"genres"`
+	ExplicitGenres []Entity `json:"explicit_genres"`
+	Themes         []Entity `json:"themes"`
+	Demographics   []Entity `json:"demographics"`
+}
+
+// Categories holds an anime's label names per category
+type Categories struct {
+	Genres       []string `json:"genres"`
+	Themes       []string `json:"themes"`
+	Demographics []string `json:"demographics"`
+}
+
+// animeResponse represents the Jikan /anime/{id} response
+type animeResponse struct {
+	Data Anime `json:"data"`
+}
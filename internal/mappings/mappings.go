@@ -0,0 +1,193 @@
+package mappings
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// File is a user-maintained mapping file from item keys to labels. Keys are
+// "movie:<tmdbID>", "tv:<tmdbID>", "plex:<ratingKey>" or an IMDb ID, matched
+// case-insensitively. The file is re-read whenever it changes on disk, so
+// edits apply on the next run without a restart.
+type File struct {
+	path    string
+	mu      sync.Mutex
+	modTime time.Time
+	labels  map[string][]string
+}
+
+// Open loads a mapping file. The format follows the extension: .csv, or
+// .yaml/.yml.
+func Open(path string) (*File, error) {
+	f := &File{path: path}
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Labels returns the labels mapped to any of the keys, in file order per key
+func (f *File) Labels(keys ...string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.reloadLocked(); err != nil {
+		fmt.Printf("[WARN] Keeping previous mappings, failed to reload %s: %v\n", f.path, err)
+	}
+
+	var labels []string
+	for _, key := range keys {
+		labels = append(labels, f.labels[strings.ToLower(key)]...)
+	}
+	return labels
+}
+
+// All returns every key with its labels. The map is shared; callers must not
+// modify it.
+func (f *File) All() map[string][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.reloadLocked(); err != nil {
+		fmt.Printf("[WARN] Keeping previous mappings, failed to reload %s: %v\n", f.path, err)
+	}
+	return f.labels
+}
+
+func (f *File) reload() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reloadLocked()
+}
+
+func (f *File) reloadLocked() error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	if f.labels != nil && info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var labels map[string][]string
+	switch strings.ToLower(filepath.Ext(f.path)) {
+	case ".csv":
+		labels, err = parseCSV(file)
+	case ".yaml", ".yml":
+		labels, err = parseYAML(file)
+	default:
+		return fmt.Errorf("unsupported mapping file type %q (use .csv or .yaml)", filepath.Ext(f.path))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.path, err)
+	}
+
+	f.labels = labels
+	f.modTime = info.ModTime()
+	return nil
+}
+
+// parseCSV reads "key,label[,label...]" rows. A key may appear on several
+// rows; lines starting with # are comments.
+func parseCSV(r io.Reader) (map[string][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	labels := make(map[string][]string)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return labels, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		key := strings.ToLower(strings.TrimSpace(record[0]))
+		if key == "" {
+			continue
+		}
+		for _, label := range record[1:] {
+			if label = strings.TrimSpace(label); label != "" {
+				labels[key] = append(labels[key], label)
+			}
+		}
+	}
+}
+
+// parseYAML reads the subset of YAML a mapping needs:
+//
+//	movie:603:
+//	  - Favorites
+//	  - Rewatch
+//	plex:12345: [Kids, Christmas]
+//	tt0113277: Heist Classics
+func parseYAML(r io.Reader) (map[string][]string, error) {
+	labels := make(map[string][]string)
+	scanner := bufio.NewScanner(r)
+	current := ""
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t")
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if current == "" {
+				return nil, fmt.Errorf("line %d: list item without a key", line)
+			}
+			if label := unquote(strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))); label != "" {
+				labels[current] = append(labels[current], label)
+			}
+			continue
+		}
+		if text != trimmed {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line)
+		}
+
+		// Keys contain colons themselves (movie:603), so split on the last
+		// ": " or a trailing colon.
+		key, value := trimmed, ""
+		if i := strings.LastIndex(trimmed, ": "); i >= 0 {
+			key, value = trimmed[:i], strings.TrimSpace(trimmed[i+2:])
+		} else if strings.HasSuffix(trimmed, ":") {
+			key = strings.TrimSuffix(trimmed, ":")
+		} else {
+			return nil, fmt.Errorf("line %d: expected 'key:'", line)
+		}
+		current = strings.ToLower(unquote(strings.TrimSpace(key)))
+
+		switch {
+		case value == "":
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			for _, label := range strings.Split(value[1:len(value)-1], ",") {
+				if label = unquote(strings.TrimSpace(label)); label != "" {
+					labels[current] = append(labels[current], label)
+				}
+			}
+		default:
+			labels[current] = append(labels[current], unquote(value))
+		}
+	}
+	return labels, scanner.Err()
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,43 @@
+package mappings
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	doc := `# curated labels
+movie:603:
+  - Favorites
+  - "Rewatch: Yearly"
+plex:12345: [Kids, 'Christmas']
+TT0113277: Heist Classics
+`
+	got, err := parseYAML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("parseYAML() error: %v", err)
+	}
+	want := map[string][]string{
+		"movie:603":  {"Favorites", "Rewatch: Yearly"},
+		"plex:12345": {"Kids", "Christmas"},
+		"tt0113277":  {"Heist Classics"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseYAML() = %v, want %v", got, want)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	got, err := parseCSV(strings.NewReader("# key,labels...\nmovie:603,Favorites,Rewatch\nmovie:603, Keanu\ntv:1399,\"Epic, Fantasy\"\n"))
+	if err != nil {
+		t.Fatalf("parseCSV() error: %v", err)
+	}
+	want := map[string][]string{
+		"movie:603": {"Favorites", "Rewatch", "Keanu"},
+		"tv:1399":   {"Epic, Fantasy"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCSV() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+package mdblist
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+const baseURL = "https://mdblist.com/lists/"
+
+// ListItem represents an entry of an MDBList list's JSON export. ID is the
+// TMDb ID.
+type ListItem struct {
+	ID        int    `json:"id"`
+	Title     string `json:"title"`
+	IMDbID    string `json:"imdb_id"`
+	MediaType string `json:"mediatype"` // "movie" or "show"
+}
+
+// Client reads public MDBList lists through their JSON export, which needs
+// no API key.
+type Client struct {
+	httpClient  *http.Client
+	retryClient *utils.RetryableHTTPClient
+}
+
+// NewClient creates an MDBList client
+func NewClient() *Client {
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	return &Client{
+		httpClient:  httpClient,
+		retryClient: utils.NewRetryableHTTPClient(httpClient, nil),
+	}
+}
+
+// ListURL returns the canonical URL of a list given either its full URL or
+// "user/list-slug".
+func ListURL(list string) string {
+	list = strings.TrimRight(strings.TrimSpace(list), "/")
+	if strings.HasPrefix(list, "http://") || strings.HasPrefix(list, "https://") {
+		return list
+	}
+	return baseURL + list
+}
+
+// ListName derives a readable name from a list's slug
+// ("top-rated-horror" -> "Top Rated Horror").
+func ListName(list string) string {
+	slug := ListURL(list)
+	slug = slug[strings.LastIndex(slug, "/")+1:]
+	words := strings.FieldsFunc(slug, func(r rune) bool { return r == '-' || r == '_' })
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// GetListItems returns the members of a list as "movie:<tmdbID>" and
+// "tv:<tmdbID>" keys.
+func (c *Client) GetListItems(list string) (map[string]bool, error) {
+	req, err := http.NewRequest("GET", ListURL(list)+"/json", nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.retryClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("MDBList returned status %d for list %s: %s", resp.StatusCode, list, string(body))
+	}
+
+	var items []ListItem
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, fmt.Errorf("error decoding list %s: %w", list, err)
+	}
+	return listMembers(items), nil
+}
+
+func listMembers(items []ListItem) map[string]bool {
+	members := make(map[string]bool, len(items))
+	for _, item := range items {
+		if item.ID <= 0 {
+			continue
+		}
+		switch item.MediaType {
+		case "movie":
+			members[fmt.Sprintf("movie:%d", item.ID)] = true
+		case "show":
+			members[fmt.Sprintf("tv:%d", item.ID)] = true
+		}
+	}
+	return members
+}
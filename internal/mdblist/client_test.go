@@ -0,0 +1,33 @@
+package mdblist
+
+import "testing"
+
+func TestListURLAndName(t *testing.T) {
+	tests := []struct {
+		list     string
+		wantURL  string
+		wantName string
+	}{
+		{"garycrawfordgc/top-rated-horror", "https://mdblist.com/lists/garycrawfordgc/top-rated-horror", "Top Rated Horror"},
+		{"https://mdblist.com/lists/linaspurinis/top-watched-movies-of-the-week/", "https://mdblist.com/lists/linaspurinis/top-watched-movies-of-the-week", "Top Watched Movies Of The Week"},
+	}
+	for _, tt := range tests {
+		if got := ListURL(tt.list); got != tt.wantURL {
+			t.Errorf("ListURL(%q) = %q, want %q", tt.list, got, tt.wantURL)
+		}
+		if got := ListName(tt.list); got != tt.wantName {
+			t.Errorf("ListName(%q) = %q, want %q", tt.list, got, tt.wantName)
+		}
+	}
+}
+
+func TestListMembers(t *testing.T) {
+	members := listMembers([]ListItem{
+		{ID: 694, MediaType: "movie"},
+		{ID: 1399, MediaType: "show"},
+		{ID: 0, MediaType: "movie"},
+	})
+	if len(members) != 2 || !members["movie:694"] || !members["tv:1399"] {
+		t.Errorf("listMembers() = %v", members)
+	}
+}
@@ -0,0 +1,143 @@
+package media
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nullable-eth/labelarr/internal/tmdb"
+)
+
+// Age-recommendation labels ("Age 10+") come from a chain of providers
+// (AGE_PROVIDERS); the first one with an answer for an item wins. New
+// providers only need to implement ageProvider and be added to
+// newAgeProviders.
+
+// ageProvider supplies a minimum recommended age for an item.
+type ageProvider interface {
+	minimumAge(item MediaItem, tmdbID string, mediaType MediaType, details *tmdb.Details) (int, bool)
+}
+
+// newAgeProviders builds the configured provider chain in order.
+func (p *Processor) newAgeProviders() ([]ageProvider, error) {
+	var providers []ageProvider
+	for _, name := range p.config.AgeProviders {
+		switch name {
+		case "override":
+			f, err := os.Open(p.config.AgeOverrideFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open AGE_OVERRIDE_FILE: %w", err)
+			}
+			ages, err := parseAgeOverrides(f)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("AGE_OVERRIDE_FILE: %w", err)
+			}
+			providers = append(providers, overrideAgeProvider(ages))
+		case "tmdb":
+			providers = append(providers, &tmdbAgeProvider{p: p})
+		default:
+			return nil, fmt.Errorf("unknown age provider %q", name)
+		}
+	}
+	return providers, nil
+}
+
+// ageLabel returns the age label for the item, or "" when no provider knows.
+func (p *Processor) ageLabel(item MediaItem, tmdbID string, mediaType MediaType, details *tmdb.Details) string {
+	for _, provider := range p.ageProviders {
+		if age, ok := provider.minimumAge(item, tmdbID, mediaType, details); ok {
+			if age == 0 {
+				return "All Ages"
+			}
+			return fmt.Sprintf(p.config.AgeLabelFormat, age)
+		}
+	}
+	return ""
+}
+
+// overrideAgeProvider answers from the local override file, keyed by IMDb
+// ID ("tt0133093") or TMDb key ("movie:603", "tv:1399").
+type overrideAgeProvider map[string]int
+
+func (o overrideAgeProvider) minimumAge(item MediaItem, tmdbID string, mediaType MediaType, details *tmdb.Details) (int, bool) {
+	if tmdbID != "" {
+		if age, ok := o[tmdbMediaType(mediaType)+":"+tmdbID]; ok {
+			return age, true
+		}
+	}
+	if imdbID := itemIMDbID(item, details); imdbID != "" {
+		if age, ok := o[imdbID]; ok {
+			return age, true
+		}
+	}
+	return 0, false
+}
+
+// parseAgeOverrides reads "id,age" lines; blank lines and lines starting
+// with # are ignored.
+func parseAgeOverrides(r io.Reader) (map[string]int, error) {
+	ages := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		id, ageText, ok := strings.Cut(text, ",")
+		age, err := strconv.Atoi(strings.TrimSpace(ageText))
+		if !ok || err != nil || age < 0 {
+			return nil, fmt.Errorf("line %d: expected 'id,age', got %q", line, text)
+		}
+		ages[strings.ToLower(strings.TrimSpace(id))] = age
+	}
+	return ages, scanner.Err()
+}
+
+// tmdbAgeProvider derives the age from the TMDb certification for
+// AGE_CERTIFICATION_COUNTRY.
+type tmdbAgeProvider struct {
+	p *Processor
+}
+
+func (t *tmdbAgeProvider) minimumAge(item MediaItem, tmdbID string, mediaType MediaType, details *tmdb.Details) (int, bool) {
+	if details == nil {
+		return 0, false
+	}
+	country := t.p.config.AgeCertificationCountry
+	certification, ok, err := t.p.tmdbClient.CertificationFromDetails(details, country)
+	if err != nil || !ok {
+		return 0, false
+	}
+	return certificationAge(country, certification)
+}
+
+// certificationAges maps rating-board certifications to minimum ages for
+// countries whose ratings aren't plain ages. Countries such as DE, NL and FR
+// use the age itself ("12") and need no table.
+var certificationAges = map[string]map[string]int{
+	"US": {
+		"G": 0, "PG": 8, "PG-13": 13, "R": 17, "NC-17": 18,
+		"TV-Y": 0, "TV-G": 0, "TV-Y7": 7, "TV-PG": 8, "TV-14": 14, "TV-MA": 17,
+	},
+	"GB": {"U": 0, "PG": 8, "12A": 12, "12": 12, "15": 15, "18": 18, "R18": 18},
+	"CA": {"G": 0, "PG": 8, "14A": 14, "18A": 18, "R": 18, "C": 0, "C8": 8, "14+": 14, "18+": 18},
+	"AU": {"G": 0, "PG": 8, "M": 15, "MA15+": 15, "R18+": 18, "X18+": 18},
+}
+
+// certificationAge returns the minimum age for a certification, or false if
+// it is unknown.
+func certificationAge(country, certification string) (int, bool) {
+	certification = strings.ToUpper(strings.TrimSpace(certification))
+	if age, ok := certificationAges[country][certification]; ok {
+		return age, true
+	}
+	age, err := strconv.Atoi(strings.TrimSuffix(certification, "+"))
+	if err != nil || age < 0 || age > 21 {
+		return 0, false
+	}
+	return age, true
+}
@@ -0,0 +1,56 @@
+package media
+
+import (
+	"slices"
+	"strings"
+)
+
+// KEYWORD_ALIAS_FILE renames keywords after normalization. It uses the
+// MAPPING_FILE format with an old keyword as the key and its replacement as
+// the label, e.g. "superhero: Superheroes", so several spellings can be
+// merged into one.
+
+// applyKeywordAliases replaces aliased keywords with their target.
+func (p *Processor) applyKeywordAliases(keywords []string) []string {
+	if p.keywordAliases == nil {
+		return keywords
+	}
+	return aliasKeywords(keywords, p.keywordAliases.All())
+}
+
+// dropAliasedValues removes values that are the old name of a keyword being
+// applied, so items labelled before an alias was added are migrated to the
+// new name. KEYWORD_PREFIX is ignored when looking the old name up.
+func (p *Processor) dropAliasedValues(values, keywords []string) []string {
+	if p.keywordAliases == nil {
+		return values
+	}
+	aliases := p.keywordAliases.All()
+	applied := make(map[string]bool, len(keywords))
+	for _, kw := range keywords {
+		applied[strings.ToLower(kw)] = true
+	}
+
+	return slices.DeleteFunc(values, func(value string) bool {
+		name := strings.TrimPrefix(value, p.config.KeywordPrefix)
+		targets := aliases[strings.ToLower(name)]
+		if len(targets) == 0 || strings.EqualFold(name, targets[0]) {
+			return false
+		}
+		return applied[strings.ToLower(p.config.KeywordPrefix+targets[0])]
+	})
+}
+
+// aliasKeywords maps each keyword through aliases (lowercased old name ->
+// replacement), dropping duplicates the renaming creates. An alias with
+// several labels uses the first.
+func aliasKeywords(keywords []string, aliases map[string][]string) []string {
+	var out []string
+	for _, kw := range keywords {
+		if targets := aliases[strings.ToLower(kw)]; len(targets) > 0 {
+			kw = targets[0]
+		}
+		out = appendUnique(out, kw)
+	}
+	return out
+}
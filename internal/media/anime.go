@@ -0,0 +1,143 @@
+package media
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nullable-eth/labelarr/internal/animelists"
+	"github.com/nullable-eth/labelarr/internal/mal"
+)
+
+// Anime libraries can take their keywords from AniList instead of TMDb
+// (LIBRARY_SOURCE_OVERRIDE=<library ID>=anilist), and anime anywhere can get
+// MyAnimeList genre/theme/demographic labels (MAL_LABELS). Items are matched
+// to AniList and MAL through the anime ID mapping, starting from the
+// AniDB/TVDB IDs in HAMA agent GUIDs or the tvdb://, tmdb:// and imdb://
+// GUIDs of the Plex agents.
+
+const hamaPrefix = "com.plexapp.agents.hama://"
+
+// librarySource returns the keyword source for a library: "tmdb" unless
+// LIBRARY_SOURCE_OVERRIDE says otherwise.
+func (p *Processor) librarySource(libraryID string) string {
+	if source, ok := p.config.LibrarySourceOverride[libraryID]; ok {
+		return source
+	}
+	return "tmdb"
+}
+
+// animeEntry looks the item up in the anime ID mapping.
+func (p *Processor) animeEntry(item MediaItem, mediaType MediaType) (animelists.Entry, bool) {
+	if p.animeMapping == nil {
+		return animelists.Entry{}, false
+	}
+	for _, ref := range animeRefs(item, mediaType) {
+		entry, ok, err := p.animeMapping.Lookup(ref[0], ref[1])
+		if err != nil {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [WARN] Could not load anime ID mapping: %v\n", err)
+			}
+			return animelists.Entry{}, false
+		}
+		if ok {
+			return entry, true
+		}
+	}
+	return animelists.Entry{}, false
+}
+
+// animeRefs returns the item's (source, id) pairs usable with the anime ID
+// mapping, most specific first: a HAMA anidb GUID identifies the exact
+// entry, the others may only identify the show.
+func animeRefs(item MediaItem, mediaType MediaType) [][2]string {
+	var refs [][2]string
+	for _, guid := range item.GetGuid() {
+		if !strings.HasPrefix(guid.ID, hamaPrefix) {
+			continue
+		}
+		// e.g. com.plexapp.agents.hama://anidb-4563?lang=en or tvdb3-76885
+		ref, _, _ := strings.Cut(strings.TrimPrefix(guid.ID, hamaPrefix), "?")
+		source, id, ok := strings.Cut(ref, "-")
+		if !ok || id == "" {
+			continue
+		}
+		switch source = strings.TrimRight(source, "0123456789"); source {
+		case "anidb", "tvdb", "tmdb", "imdb":
+			refs = append(refs, [2]string{source, id})
+		case "tsdb":
+			refs = append(refs, [2]string{"tmdb", id})
+		}
+	}
+	for _, guid := range item.GetGuid() {
+		switch {
+		case mediaType == MediaTypeTV && strings.HasPrefix(guid.ID, "tvdb://"):
+			refs = append(refs, [2]string{"tvdb", strings.TrimPrefix(guid.ID, "tvdb://")})
+		case mediaType == MediaTypeMovie && strings.HasPrefix(guid.ID, "tmdb://"):
+			refs = append(refs, [2]string{"tmdb", strings.TrimPrefix(guid.ID, "tmdb://")})
+		case strings.HasPrefix(guid.ID, "imdb://"):
+			refs = append(refs, [2]string{"imdb", strings.TrimPrefix(guid.ID, "imdb://")})
+		}
+	}
+	return refs
+}
+
+// anilistKeywords returns AniList genres and tags for an item in an AniList
+// library. ok is false when the library uses TMDb or the item has no AniList
+// match, in which case TMDb keywords are used as usual.
+func (p *Processor) anilistKeywords(item MediaItem, libraryID string, mediaType MediaType) ([]string, bool) {
+	if p.anilistClient == nil || p.librarySource(libraryID) != "anilist" {
+		return nil, false
+	}
+	entry, ok := p.animeEntry(item, mediaType)
+	if !ok || entry.AniList == "" {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [KEY] No AniList match for %s, using TMDb keywords\n", item.GetTitle())
+		}
+		return nil, false
+	}
+
+	keywords, err := p.anilistClient.GetKeywords(string(entry.AniList))
+	if err != nil {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Could not fetch AniList keywords: %v\n", err)
+		}
+		return nil, false
+	}
+	if p.config.VerboseLogging {
+		fmt.Printf("   [KEY] AniList %s genres/tags: %d\n", entry.AniList, len(keywords))
+	}
+	return keywords, true
+}
+
+// malLabels returns the item's MyAnimeList genres, themes and demographics
+// (whichever MAL_LABELS selects), or nil when the item has no MAL match.
+func (p *Processor) malLabels(item MediaItem, mediaType MediaType) []string {
+	entry, ok := p.animeEntry(item, mediaType)
+	if !ok || entry.MAL == "" {
+		return nil
+	}
+	categories, err := p.malClient.GetCategories(string(entry.MAL))
+	if err != nil {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Could not fetch MyAnimeList data: %v\n", err)
+		}
+		return nil
+	}
+	return selectCategories(categories, p.config.MALLabels)
+}
+
+// selectCategories returns the names from the selected MAL_LABELS categories
+func selectCategories(categories mal.Categories, selected []string) []string {
+	var labels []string
+	for _, category := range selected {
+		switch category {
+		case "genres":
+			labels = append(labels, categories.Genres...)
+		case "themes":
+			labels = append(labels, categories.Themes...)
+		case "demographics":
+			labels = append(labels, categories.Demographics...)
+		}
+	}
+	return labels
+}
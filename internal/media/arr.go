@@ -0,0 +1,91 @@
+package media
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/nullable-eth/labelarr/internal/radarr"
+	"github.com/nullable-eth/labelarr/internal/sonarr"
+)
+
+// Several Radarr and Sonarr instances can be configured (RADARR_URL,
+// RADARR_1_URL, ...), e.g. one for 1080p and one for 4K. Lookups query them
+// in configuration order; the helpers below return a movie or series from
+// every instance that has it, so labels read from the *arrs are merged
+// across instances and follow-ups such as tag sync reach each copy.
+
+// radarrEntry is a movie as known to one Radarr instance.
+type radarrEntry struct {
+	client *radarr.Client
+	movie  *radarr.Movie
+}
+
+// sonarrEntry is a series as known to one Sonarr instance.
+type sonarrEntry struct {
+	client *sonarr.Client
+	series *sonarr.Series
+}
+
+// radarrMovies returns the movie with the given TMDb ID from every Radarr
+// instance that has it, in configuration order.
+func (p *Processor) radarrMovies(tmdbID string) []radarrEntry {
+	id, err := strconv.Atoi(tmdbID)
+	if err != nil {
+		return nil
+	}
+	var entries []radarrEntry
+	for _, client := range p.radarrClients {
+		if movie, err := client.GetMovieByTMDbID(id); err == nil {
+			entries = append(entries, radarrEntry{client: client, movie: movie})
+		}
+	}
+	return entries
+}
+
+// arrLookupErr reports whether every Radarr (movies) or Sonarr (TV shows)
+// instance can be queried for the item, so that radarrMovies or sonarrSeries
+// coming back empty means the item isn't there rather than an outage.
+func (p *Processor) arrLookupErr(tmdbID string, mediaType MediaType) error {
+	switch mediaType {
+	case MediaTypeMovie:
+		id, err := strconv.Atoi(tmdbID)
+		if err != nil {
+			return nil
+		}
+		for _, client := range p.radarrClients {
+			if _, err := client.HasMovie(id); err != nil {
+				return fmt.Errorf("Radarr: %w", err)
+			}
+		}
+	case MediaTypeTV:
+		for _, client := range p.sonarrClients {
+			if _, err := client.GetAllSeries(); err != nil {
+				return fmt.Errorf("Sonarr: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// sonarrSeries returns the show's series from every Sonarr instance that
+// has it, found by TMDb ID or, failing that, the show's tvdb:// GUID.
+func (p *Processor) sonarrSeries(item MediaItem, tmdbID string) []sonarrEntry {
+	tmdbNum, tmdbErr := strconv.Atoi(tmdbID)
+	tvdbNum, tvdbErr := strconv.Atoi(tvdbGUID(item))
+
+	var entries []sonarrEntry
+	for _, client := range p.sonarrClients {
+		if tmdbErr == nil {
+			if series, err := client.GetSeriesByTMDbID(tmdbNum); err == nil {
+				entries = append(entries, sonarrEntry{client: client, series: series})
+				continue
+			}
+		}
+		if tvdbErr == nil {
+			if series, err := client.GetSeriesByTVDbID(tvdbNum); err == nil {
+				entries = append(entries, sonarrEntry{client: client, series: series})
+			}
+		}
+	}
+	return entries
+}
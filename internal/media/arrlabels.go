@@ -0,0 +1,609 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nullable-eth/labelarr/internal/plex"
+	"github.com/nullable-eth/labelarr/internal/radarr"
+	"github.com/nullable-eth/labelarr/internal/sonarr"
+)
+
+// radarrLabels returns the labels read from the movie's Radarr entries: their
+// quality profile and the quality of their file, per RADARR_QUALITY_LABELS,
+// and their collection with RADARR_COLLECTION_LABELS. Movies Radarr doesn't
+// manage get none.
+func (p *Processor) radarrLabels(tmdbID string) []string {
+	entries := p.radarrMovies(tmdbID)
+	if len(entries) == 0 {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] No Radarr entry for labels\n")
+		}
+		return nil
+	}
+
+	var labels []string
+	for _, entry := range entries {
+		var profiles map[int]string
+		if slices.Contains(p.config.RadarrQualityLabels, "profile") {
+			var err error
+			profiles, err = entry.client.GetQualityProfiles()
+			if err != nil && p.config.VerboseLogging {
+				fmt.Printf("   [WARN] Could not fetch Radarr quality profiles: %v\n", err)
+			}
+		}
+
+		for _, quality := range radarrQualities(entry.movie, p.config.RadarrQualityLabels, profiles) {
+			labels = appendUnique(labels, fmt.Sprintf(p.config.RadarrQualityLabelFormat, quality))
+		}
+		if name := entry.movie.CollectionName(); p.config.RadarrCollectionLabels && name != "" {
+			labels = appendUnique(labels, fmt.Sprintf(p.config.RadarrCollectionLabelFormat, name))
+		}
+	}
+	return labels
+}
+
+// radarrLanguageLabel returns the RADARR_LANGUAGE_LABEL_FORMAT label for the
+// movie's original language as Radarr reports it, if any.
+func (p *Processor) radarrLanguageLabel(tmdbID string) string {
+	for _, entry := range p.radarrMovies(tmdbID) {
+		if entry.movie.OriginalLanguage == nil {
+			continue
+		}
+		name := radarrLanguageName(entry.movie.OriginalLanguage.Name, p.config.LanguageLabelMap, p.config.LanguageLabelExclude)
+		if name == "" {
+			return ""
+		}
+		return fmt.Sprintf(p.config.RadarrLanguageLabelFormat, name)
+	}
+	return ""
+}
+
+// radarrLanguageName turns a Radarr language name into a label name. Names
+// of languages in the built-in table go through their ISO 639-1 code, so
+// LANGUAGE_LABEL_MAP and LANGUAGE_LABEL_EXCLUDE apply as they do to TMDb
+// languages; other names are used as is.
+func radarrLanguageName(name string, overrides map[string]string, exclude []string) string {
+	switch strings.ToLower(name) {
+	case "", "unknown", "any", "original":
+		return ""
+	}
+	code := ""
+	for c, n := range languageNames {
+		if strings.EqualFold(n, name) {
+			code = c
+			break
+		}
+	}
+	for _, excluded := range exclude {
+		if strings.EqualFold(excluded, code) || strings.EqualFold(excluded, name) {
+			return ""
+		}
+	}
+	if code == "" {
+		return name
+	}
+	return languageLabel(code, overrides)
+}
+
+// radarrQualities returns the movie's quality profile name and file quality
+// name for the requested sources, skipping any that are unknown.
+func radarrQualities(movie *radarr.Movie, sources []string, profiles map[int]string) []string {
+	var qualities []string
+	for _, source := range sources {
+		switch source {
+		case "profile":
+			profileID := movie.QualityProfileID
+			if profileID == 0 {
+				profileID = movie.ProfileID
+			}
+			if name := profiles[profileID]; name != "" {
+				qualities = append(qualities, name)
+			}
+		case "file":
+			if movie.HasFile && movie.MovieFile.Quality.Quality.Name != "" {
+				qualities = append(qualities, movie.MovieFile.Quality.Quality.Name)
+			}
+		}
+	}
+	return qualities
+}
+
+// syncRadarr runs the per-movie Radarr follow-ups once an item has been
+// processed: mirroring its labels as Radarr tags (RADARR_TAG_SYNC) and adding
+// it to the Plex collection named after its Radarr collection
+// (RADARR_COLLECTIONS). details carries the item as read this cycle and is
+// fetched when nil; applied holds labels just written to it.
+func (p *Processor) syncRadarr(item, details MediaItem, libraryID, tmdbID string, mediaType MediaType, applied []string) {
+	if !p.config.RadarrTagSync && !p.config.RadarrCollections {
+		return
+	}
+	if len(p.radarrClients) == 0 || mediaType != MediaTypeMovie || tmdbID == "" {
+		return
+	}
+	entries := p.radarrMovies(tmdbID)
+	if len(entries) == 0 {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] No Radarr entry to sync\n")
+		}
+		return
+	}
+	if details == nil {
+		var err error
+		details, err = p.getItemDetails(item.GetRatingKey(), mediaType)
+		if err != nil {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [WARN] Could not fetch item for Radarr sync: %v\n", err)
+			}
+			return
+		}
+	}
+
+	if p.config.RadarrTagSync {
+		for _, entry := range entries {
+			p.pushRadarrTags(entry.client, entry.movie, details, applied)
+		}
+	}
+	if p.config.RadarrCollections {
+		// Every instance tracks the same TMDb collection; the first will do.
+		p.addRadarrCollection(entries[0].movie, details, item.GetRatingKey(), libraryID)
+	}
+}
+
+// pushRadarrTags mirrors a movie's labels as Radarr tags, creating any tag
+// Radarr doesn't have yet. With RADARR_TAG_SYNC_LABELS set, only those
+// labels are mirrored, and their tags are also removed from movies that lost
+// the label.
+func (p *Processor) pushRadarrTags(client *radarr.Client, movie *radarr.Movie, details MediaItem, applied []string) {
+	var labels []string
+	for _, label := range details.GetLabel() {
+		labels = append(labels, label.Tag)
+	}
+	if strings.EqualFold(p.config.UpdateField, "label") {
+		labels = append(labels, applied...)
+	}
+
+	keep, drop := radarrTagNames(labels, p.config.RadarrTagSyncLabels)
+	has := make(map[int]bool, len(movie.Tags))
+	for _, tagID := range movie.Tags {
+		has[tagID] = true
+	}
+
+	var add []int
+	var names []string
+	for _, name := range keep {
+		tagID, err := client.EnsureTag(name)
+		if err != nil {
+			fmt.Printf("[WARN] Could not create Radarr tag %q: %v\n", name, err)
+			continue
+		}
+		if !has[tagID] {
+			add = append(add, tagID)
+			names = append(names, name)
+		}
+	}
+	if len(add) > 0 {
+		if err := client.EditMovieTags(movie.ID, add, "add"); err != nil {
+			fmt.Printf("[WARN] Could not tag %s in Radarr: %v\n", movie.Title, err)
+		} else {
+			fmt.Printf("[RADARR] Tagged %s: %s\n", movie.Title, strings.Join(names, ", "))
+		}
+	}
+
+	var remove []int
+	names = nil
+	if len(drop) > 0 {
+		tags, err := client.GetTags()
+		if err != nil {
+			fmt.Printf("[WARN] Could not fetch Radarr tags: %v\n", err)
+			return
+		}
+		for _, name := range drop {
+			if tagID, ok := tags[name]; ok && has[tagID] {
+				remove = append(remove, tagID)
+				names = append(names, name)
+			}
+		}
+	}
+	if len(remove) > 0 {
+		if err := client.EditMovieTags(movie.ID, remove, "remove"); err != nil {
+			fmt.Printf("[WARN] Could not untag %s in Radarr: %v\n", movie.Title, err)
+			return
+		}
+		fmt.Printf("[RADARR] Removed tags from %s: %s\n", movie.Title, strings.Join(names, ", "))
+	}
+}
+
+// addRadarrCollection adds a movie to the Plex collection named after its
+// Radarr collection, creating the collection if needed. Plex replaces the
+// whole collection field on update, so the movie's other collections are
+// sent along.
+func (p *Processor) addRadarrCollection(movie *radarr.Movie, details MediaItem, ratingKey, libraryID string) {
+	name := movie.CollectionName()
+	plexMovie, ok := details.(plex.Movie)
+	if name == "" || !ok {
+		return
+	}
+
+	var collections []string
+	for _, collection := range plexMovie.Collection {
+		if strings.EqualFold(collection.Tag, name) {
+			return
+		}
+		collections = append(collections, collection.Tag)
+	}
+	if err := p.server.UpdateMediaField(ratingKey, libraryID, append(collections, name), "collection", "movie"); err != nil {
+		fmt.Printf("[WARN] Could not add %s to collection %q: %v\n", movie.Title, name, err)
+		return
+	}
+	fmt.Printf("[COLLECTION] Added %s to collection %q\n", movie.Title, name)
+}
+
+// radarrTagNames returns the Radarr tags to add for the given labels and,
+// when only a subset of labels is mirrored, the tags of subset labels the
+// item doesn't have.
+func radarrTagNames(labels, subset []string) ([]string, []string) {
+	var keep, drop []string
+	if len(subset) == 0 {
+		for _, label := range labels {
+			if name := radarrTagName(label); name != "" {
+				keep = appendUnique(keep, name)
+			}
+		}
+		return keep, nil
+	}
+
+	present := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		present[strings.ToLower(label)] = true
+	}
+	for _, label := range subset {
+		name := radarrTagName(label)
+		if name == "" {
+			continue
+		}
+		if present[strings.ToLower(label)] {
+			keep = appendUnique(keep, name)
+		} else {
+			drop = appendUnique(drop, name)
+		}
+	}
+	// Two labels can share a tag name; keeping wins.
+	drop = slices.DeleteFunc(drop, func(name string) bool {
+		return slices.Contains(keep, name)
+	})
+	return keep, drop
+}
+
+// radarrTagName turns a label into a valid Radarr tag: lowercase letters,
+// digits and single hyphens, e.g. "Sci-Fi Classics" -> "sci-fi-classics".
+func radarrTagName(label string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(label) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	return b.String()
+}
+
+// sonarrTagLabels returns a label for every tag on the show's Sonarr series,
+// prefixed with SONARR_TAG_LABEL_PREFIX. The series is found by TMDb ID or,
+// failing that, the show's tvdb:// GUID. err is set when Sonarr can't be
+// read.
+func (p *Processor) sonarrTagLabels(item MediaItem, tmdbID string) ([]string, error) {
+	if err := p.arrLookupErr(tmdbID, MediaTypeTV); err != nil {
+		return nil, err
+	}
+	entries := p.sonarrSeries(item, tmdbID)
+	if len(entries) == 0 {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] No Sonarr entry for tag labels\n")
+		}
+		return nil, nil
+	}
+
+	names, err := p.sonarrTagNames(entries)
+	var labels []string
+	for _, name := range names {
+		labels = appendUnique(labels, p.config.SonarrTagLabelPrefix+name)
+	}
+	return labels, err
+}
+
+// sonarrTagNames returns the names of the tags on the given Sonarr series.
+// An instance whose tags can't be fetched is skipped and reported in err.
+func (p *Processor) sonarrTagNames(entries []sonarrEntry) ([]string, error) {
+	var names []string
+	var errs []error
+	for _, entry := range entries {
+		tags, err := entry.client.GetTags()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Sonarr tags: %w", err))
+			continue
+		}
+		for _, name := range tagLabels(entry.series.Tags, tags, "") {
+			names = appendUnique(names, name)
+		}
+	}
+	return names, errors.Join(errs...)
+}
+
+// arrStatusLabels returns the ARR_STATUS_LABELS labels for the monitored
+// flag and status of the item's Radarr movies or Sonarr series. Items the
+// matching *arr doesn't have get none. err is set when Radarr or Sonarr
+// can't be read.
+func (p *Processor) arrStatusLabels(item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+	if err := p.arrLookupErr(tmdbID, mediaType); err != nil {
+		return nil, err
+	}
+	var labels []string
+	switch mediaType {
+	case MediaTypeMovie:
+		for _, entry := range p.radarrMovies(tmdbID) {
+			for _, label := range statusLabels(entry.movie.Monitored, entry.movie.Status, p.config.ArrStatusLabels) {
+				labels = appendUnique(labels, label)
+			}
+		}
+	case MediaTypeTV:
+		for _, entry := range p.sonarrSeries(item, tmdbID) {
+			for _, label := range statusLabels(entry.series.Monitored, entry.series.Status, p.config.ArrStatusLabels) {
+				labels = appendUnique(labels, label)
+			}
+		}
+	}
+	return labels, nil
+}
+
+// statusLabels maps a monitored flag and *arr status (e.g. "ended",
+// "released") to labels through rules keyed by "monitored", "unmonitored"
+// or a lowercased status.
+func statusLabels(monitored bool, status string, rules map[string]string) []string {
+	var labels []string
+	key := "unmonitored"
+	if monitored {
+		key = "monitored"
+	}
+	if label := rules[key]; label != "" {
+		labels = append(labels, label)
+	}
+	if label := rules[strings.ToLower(status)]; label != "" {
+		labels = appendUnique(labels, label)
+	}
+	return labels
+}
+
+// arrMissingLabel returns RADARR_MISSING_LABEL or SONARR_MISSING_LABEL when
+// no configured instance has the item. Items without an ID to look up get
+// nothing. While an instance can't be reached err is set and no label is
+// returned, so an outage doesn't flag the whole library.
+func (p *Processor) arrMissingLabel(item MediaItem, tmdbID string, mediaType MediaType) (string, error) {
+	switch mediaType {
+	case MediaTypeMovie:
+		id, err := strconv.Atoi(tmdbID)
+		if p.config.RadarrMissingLabel == "" || len(p.radarrClients) == 0 || err != nil {
+			return "", nil
+		}
+		for _, client := range p.radarrClients {
+			found, err := client.HasMovie(id)
+			if err != nil {
+				return "", fmt.Errorf("Radarr: %w", err)
+			}
+			if found {
+				return "", nil
+			}
+		}
+		return p.config.RadarrMissingLabel, nil
+	case MediaTypeTV:
+		if p.config.SonarrMissingLabel == "" || len(p.sonarrClients) == 0 || (tmdbID == "" && tvdbGUID(item) == "") {
+			return "", nil
+		}
+		if err := p.arrLookupErr(tmdbID, mediaType); err != nil {
+			return "", err
+		}
+		if len(p.sonarrSeries(item, tmdbID)) == 0 {
+			return p.config.SonarrMissingLabel, nil
+		}
+	}
+	return "", nil
+}
+
+// importListLabels returns a RADARR_IMPORT_LIST_LABEL_FORMAT label for every
+// Radarr import list that currently provides the movie. err is set when the
+// lists can't be fetched.
+func (p *Processor) importListLabels(tmdbID string) ([]string, error) {
+	id, err := strconv.Atoi(tmdbID)
+	if err != nil {
+		return nil, nil
+	}
+	membership, err := p.importLists()
+	if err != nil {
+		return nil, fmt.Errorf("Radarr import lists: %w", err)
+	}
+
+	var labels []string
+	for _, list := range membership[id] {
+		labels = appendUnique(labels, fmt.Sprintf(p.config.RadarrImportListLabelFormat, list))
+	}
+	return labels, nil
+}
+
+// importLists returns the import list names of every movie across all
+// Radarr instances, fetched once per processing cycle.
+func (p *Processor) importLists() (map[int][]string, error) {
+	p.cacheMu.RLock()
+	membership := p.importListCache
+	p.cacheMu.RUnlock()
+	if membership != nil {
+		return membership, nil
+	}
+
+	membership = make(map[int][]string)
+	for _, client := range p.radarrClients {
+		lists, err := client.GetImportListMembership()
+		if err != nil {
+			return nil, err
+		}
+		for id, names := range lists {
+			for _, name := range names {
+				membership[id] = appendUnique(membership[id], name)
+			}
+		}
+	}
+
+	p.cacheMu.Lock()
+	p.importListCache = membership
+	p.cacheMu.Unlock()
+	return membership, nil
+}
+
+// seriesTypeLabel returns the SONARR_SERIES_TYPE_LABELS label for the
+// show's Sonarr series type ("standard", "daily" or "anime"), if any. The
+// first Sonarr instance with the show decides.
+func (p *Processor) seriesTypeLabel(item MediaItem, tmdbID string) string {
+	entries := p.sonarrSeries(item, tmdbID)
+	if len(entries) == 0 {
+		return ""
+	}
+	return p.config.SonarrSeriesTypeLabels[strings.ToLower(entries[0].series.SeriesType)]
+}
+
+// completionLabels returns the SONARR_COMPLETION_LABELS labels for whether
+// the show has a file for every aired, monitored episode and whether
+// episodes are in Sonarr's download queue. The first Sonarr instance with
+// the show decides. err is set when Sonarr can't be read.
+func (p *Processor) completionLabels(item MediaItem, tmdbID string) ([]string, error) {
+	if err := p.arrLookupErr(tmdbID, MediaTypeTV); err != nil {
+		return nil, err
+	}
+	entries := p.sonarrSeries(item, tmdbID)
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	entry := entries[0]
+	rules := p.config.SonarrCompletionLabels
+
+	var labels []string
+	if complete, ok := seasonsComplete(entry.series.Seasons); ok {
+		state := "incomplete"
+		if complete {
+			state = "complete"
+		}
+		if label := rules[state]; label != "" {
+			labels = append(labels, label)
+		}
+	}
+
+	if label := rules["downloading"]; label != "" {
+		queue, err := entry.client.GetQueueBySeries(entry.series.ID)
+		if err != nil {
+			return labels, fmt.Errorf("Sonarr queue: %w", err)
+		}
+		if len(queue) > 0 {
+			labels = appendUnique(labels, label)
+		}
+	}
+	return labels, nil
+}
+
+// seasonsComplete reports whether every regular season has a file for each
+// of its aired, monitored episodes. Specials and seasons with nothing aired
+// or monitored are ignored; ok is false when no season is left to judge.
+func seasonsComplete(seasons []sonarr.Season) (complete, ok bool) {
+	complete = true
+	for _, season := range seasons {
+		stats := season.Statistics
+		if season.SeasonNumber == 0 || stats == nil || stats.EpisodeCount == 0 {
+			continue
+		}
+		ok = true
+		if stats.EpisodeFileCount < stats.EpisodeCount {
+			complete = false
+		}
+	}
+	return complete && ok, ok
+}
+
+// tagLabels names the given tag IDs, skipping IDs with no known tag.
+func tagLabels(ids []int, tags map[int]string, prefix string) []string {
+	var labels []string
+	for _, id := range ids {
+		if name := tags[id]; name != "" {
+			labels = appendUnique(labels, prefix+name)
+		}
+	}
+	return labels
+}
+
+// isFilteredByArrTags reports whether RADARR_TAG_FILTER or SONARR_TAG_FILTER
+// rules the item out, given its tags across all instances. Items the
+// matching *arr doesn't have are treated as carrying no tags.
+func (p *Processor) isFilteredByArrTags(item MediaItem, tmdbID string, mediaType MediaType) bool {
+	switch {
+	case mediaType == MediaTypeMovie && len(p.radarrClients) > 0 && len(p.config.RadarrTagFilter) > 0:
+		return !tagFilterAllows(p.radarrMovieTags(tmdbID), p.config.RadarrTagFilter)
+	case mediaType == MediaTypeTV && len(p.sonarrClients) > 0 && len(p.config.SonarrTagFilter) > 0:
+		names, err := p.sonarrTagNames(p.sonarrSeries(item, tmdbID))
+		if err != nil && p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Could not fetch Sonarr tags: %v\n", err)
+		}
+		return !tagFilterAllows(names, p.config.SonarrTagFilter)
+	}
+	return false
+}
+
+// radarrMovieTags returns the tag labels on the movie's Radarr entries.
+func (p *Processor) radarrMovieTags(tmdbID string) []string {
+	var names []string
+	for _, entry := range p.radarrMovies(tmdbID) {
+		tags, err := entry.client.GetTags()
+		if err != nil {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [WARN] Could not fetch Radarr tags: %v\n", err)
+			}
+			continue
+		}
+		for name, tagID := range tags {
+			if slices.Contains(entry.movie.Tags, tagID) {
+				names = appendUnique(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// tagFilterAllows applies a tag filter to an item's tags. Entries prefixed
+// with "!" exclude items carrying that tag; if any plain entries are given,
+// the item must also carry at least one of them. Matching is
+// case-insensitive.
+func tagFilterAllows(tags []string, filter []string) bool {
+	have := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		have[strings.ToLower(tag)] = true
+	}
+
+	required, matched := false, false
+	for _, entry := range filter {
+		if excluded, ok := strings.CutPrefix(entry, "!"); ok {
+			if have[strings.ToLower(excluded)] {
+				return false
+			}
+			continue
+		}
+		required = true
+		if have[strings.ToLower(entry)] {
+			matched = true
+		}
+	}
+	return matched || !required
+}
@@ -0,0 +1,262 @@
+package media
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nullable-eth/labelarr/internal/radarr"
+	"github.com/nullable-eth/labelarr/internal/sonarr"
+)
+
+// maxTitleCandidates caps how many Radarr/Sonarr title-match candidates are
+// checked against TMDb alternative titles for a single item.
+const maxTitleCandidates = 5
+
+// arrCandidate is a Radarr movie or Sonarr series returned by title matching.
+type arrCandidate struct {
+	tmdbID string
+	title  string
+	year   int
+	titles []string // every title Radarr/Sonarr knows for the entry
+}
+
+func radarrCandidate(movie *radarr.Movie) arrCandidate {
+	titles := []string{movie.Title, movie.OriginalTitle}
+	for _, alt := range movie.AlternateTitles {
+		titles = append(titles, alt.Title)
+	}
+	c := arrCandidate{title: movie.Title, year: movie.Year, titles: titles}
+	if movie.TMDbID > 0 {
+		c.tmdbID = strconv.Itoa(movie.TMDbID)
+	}
+	return c
+}
+
+func sonarrCandidate(series *sonarr.Series) arrCandidate {
+	titles := []string{series.Title}
+	for _, alt := range series.AlternateTitles {
+		titles = append(titles, alt.Title)
+	}
+	c := arrCandidate{title: series.Title, year: series.Year, titles: titles}
+	if series.TMDBID > 0 {
+		c.tmdbID = strconv.Itoa(series.TMDBID)
+	}
+	return c
+}
+
+// radarrTitleMatch resolves the item through Radarr's title/year matching and
+// returns the TMDb ID and Radarr title of the match from the first Radarr
+// instance that has one. With TMDB_ALTERNATIVE_TITLES enabled, a match whose
+// titles don't equal the Plex title must be confirmed against TMDb
+// alternative titles first.
+func (p *Processor) radarrTitleMatch(item MediaItem) (string, string) {
+	for _, client := range p.radarrClients {
+		if tmdbID, title := p.radarrTitleMatchIn(client, item); tmdbID != "" {
+			return tmdbID, title
+		}
+	}
+	return "", ""
+}
+
+func (p *Processor) radarrTitleMatchIn(client *radarr.Client, item MediaItem) (string, string) {
+	movie, err := client.FindMovieMatch(item.GetTitle(), item.GetYear())
+	if err != nil || movie == nil {
+		return "", ""
+	}
+	if !p.config.TMDbAlternativeTitles {
+		return client.GetTMDbIDFromMovie(movie), movie.Title
+	}
+
+	candidates := []arrCandidate{radarrCandidate(movie)}
+	if movies, err := client.SearchMovieByTitle(item.GetTitle()); err == nil {
+		for i := range movies {
+			if movies[i].ID != movie.ID {
+				candidates = append(candidates, radarrCandidate(&movies[i]))
+			}
+		}
+	}
+	match, ok := p.confirmTitleMatch(item, MediaTypeMovie, "Radarr", candidates)
+	if !ok {
+		return "", ""
+	}
+	return match.tmdbID, match.title
+}
+
+// sonarrTitleMatch is radarrTitleMatch for Sonarr series.
+func (p *Processor) sonarrTitleMatch(item MediaItem) (string, string) {
+	for _, client := range p.sonarrClients {
+		if tmdbID, title := p.sonarrTitleMatchIn(client, item); tmdbID != "" {
+			return tmdbID, title
+		}
+	}
+	return "", ""
+}
+
+func (p *Processor) sonarrTitleMatchIn(client *sonarr.Client, item MediaItem) (string, string) {
+	series, err := client.FindSeriesMatch(item.GetTitle(), item.GetYear())
+	if err != nil || series == nil {
+		return "", ""
+	}
+	if !p.config.TMDbAlternativeTitles {
+		return client.GetTMDbIDFromSeries(series), series.Title
+	}
+
+	candidates := []arrCandidate{sonarrCandidate(series)}
+	if matches, err := client.SearchSeriesByTitle(item.GetTitle()); err == nil {
+		for i := range matches {
+			if matches[i].ID != series.ID {
+				candidates = append(candidates, sonarrCandidate(&matches[i]))
+			}
+		}
+	}
+	match, ok := p.confirmTitleMatch(item, MediaTypeTV, "Sonarr", candidates)
+	if !ok {
+		return "", ""
+	}
+	return match.tmdbID, match.title
+}
+
+// confirmTitleMatch returns the first candidate (best guess first) whose
+// Radarr/Sonarr titles or TMDb titles - primary, original, and alternative -
+// equal the Plex title. Candidates more than a year away from the Plex year
+// are ignored, except the best guess itself. A Plex title with no letters or
+// digits can't be confirmed either way, so the best guess is kept.
+func (p *Processor) confirmTitleMatch(item MediaItem, mediaType MediaType, source string, candidates []arrCandidate) (arrCandidate, bool) {
+	title := cleanTitle(item.GetTitle())
+	year := item.GetYear()
+	if title == "" {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [INFO] Can't confirm %s title match for %q, keeping %s\n", source, item.GetTitle(), candidates[0].title)
+		}
+		return candidates[0], true
+	}
+
+	checked := 0
+	for i, c := range candidates {
+		if checked >= maxTitleCandidates {
+			break
+		}
+		if i > 0 && year > 0 && (c.year < year-1 || c.year > year+1) {
+			continue
+		}
+		checked++
+
+		if titleIn(title, c.titles) {
+			return c, true
+		}
+		if c.tmdbID == "" {
+			continue
+		}
+		titles, err := p.tmdbTitles(c.tmdbID, mediaType)
+		if err != nil {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [WARN] Could not fetch TMDb alternative titles for %s: %v\n", c.tmdbID, err)
+			}
+			continue
+		}
+		if titleIn(title, titles) {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [OK] %s candidate %s confirmed by TMDb alternative title\n", source, c.title)
+			}
+			return c, true
+		}
+	}
+
+	if p.config.VerboseLogging {
+		fmt.Printf("   [SKIP] %s title match %s rejected: %q is not one of its titles\n", source, candidates[0].title, item.GetTitle())
+	}
+	return arrCandidate{}, false
+}
+
+// tmdbTitles returns every TMDb title for an item: primary, original, and
+// alternative titles from all regions.
+func (p *Processor) tmdbTitles(tmdbID string, mediaType MediaType) ([]string, error) {
+	details, err := p.getDetails(tmdbID, mediaType)
+	if err != nil {
+		return nil, err
+	}
+	titles := []string{details.Title, details.OriginalTitle, details.Name, details.OriginalName}
+	alternatives, _, err := p.tmdbClient.AlternativeTitlesFromDetails(details)
+	if err != nil {
+		return nil, err
+	}
+	return append(titles, alternatives...), nil
+}
+
+// titleIn reports whether any of titles equals the cleaned title.
+func titleIn(clean string, titles []string) bool {
+	if clean == "" {
+		return false
+	}
+	for _, t := range titles {
+		if cleanTitle(t) == clean {
+			return true
+		}
+	}
+	return false
+}
+
+// ArrItem identifies a movie or series named in a Radarr/Sonarr webhook.
+type ArrItem struct {
+	Title  string
+	Year   int
+	TMDbID int
+	TVDbID int
+	IMDbID string
+	Path   string // imported file (movies only)
+}
+
+// FindItem returns the rating key of the library item matching an
+// imported Radarr/Sonarr entry, or "" if the media server doesn't list it
+// yet. Items are matched by external ID GUID first, then by file path, then
+// by exact title and year.
+func (p *Processor) FindItem(libraryID string, mediaType MediaType, arr ArrItem) (string, error) {
+	items, err := p.fetchItems(libraryID, mediaType)
+	if err != nil {
+		return "", err
+	}
+	if item := matchArrItem(items, arr); item != nil {
+		return item.GetRatingKey(), nil
+	}
+	return "", nil
+}
+
+// matchArrItem picks the item matching arr; see FindItem.
+func matchArrItem(items []MediaItem, arr ArrItem) MediaItem {
+	var guids []string
+	if arr.TMDbID > 0 {
+		guids = append(guids, fmt.Sprintf("tmdb://%d", arr.TMDbID))
+	}
+	if arr.TVDbID > 0 {
+		guids = append(guids, fmt.Sprintf("tvdb://%d", arr.TVDbID))
+	}
+	if arr.IMDbID != "" {
+		guids = append(guids, "imdb://"+arr.IMDbID)
+	}
+	for _, item := range items {
+		for _, guid := range item.GetGuid() {
+			if slices.Contains(guids, guid.ID) {
+				return item
+			}
+		}
+	}
+
+	if arr.Path != "" {
+		for _, item := range items {
+			for _, file := range mediaFiles(item.GetMedia()) {
+				if file == arr.Path {
+					return item
+				}
+			}
+		}
+	}
+
+	for _, item := range items {
+		if strings.EqualFold(item.GetTitle(), arr.Title) && item.GetYear() == arr.Year {
+			return item
+		}
+	}
+	return nil
+}
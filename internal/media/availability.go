@@ -0,0 +1,85 @@
+package media
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// availabilityLabels returns an "available on" label for every streaming
+// service offering the item in AVAILABILITY_REGION by subscription, for free
+// or with ads. With the JustWatch source, services whose offer ends within
+// LEAVING_SOON_DAYS also get a "leaving soon" label. Availability changes
+// constantly, so these are lifecycle-managed labels. err is set when the
+// source can't be read.
+func (p *Processor) availabilityLabels(item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+	var providers, leaving []string
+
+	switch p.config.AvailabilitySource {
+	case "tmdb":
+		names, err := p.tmdbWatchProviders(tmdbID, mediaType)
+		if err != nil {
+			return nil, fmt.Errorf("TMDb watch providers: %w", err)
+		}
+		providers = names
+	case "justwatch":
+		offers, err := p.justwatchClient.GetOffers(item.GetTitle(), tmdbID, tmdbMediaType(mediaType))
+		if err != nil {
+			return nil, fmt.Errorf("JustWatch offers: %w", err)
+		}
+		leavingBy := time.Now().AddDate(0, 0, p.config.LeavingSoonDays)
+		for _, offer := range offers {
+			switch offer.MonetizationType {
+			case "FLATRATE", "FREE", "ADS":
+			default:
+				continue
+			}
+			providers = append(providers, offer.Provider)
+			if p.config.LeavingSoonDays > 0 && !offer.AvailableTo.IsZero() && offer.AvailableTo.Before(leavingBy) {
+				leaving = append(leaving, offer.Provider)
+			}
+		}
+	}
+
+	var labels []string
+	for _, provider := range providers {
+		if name, ok := p.allowedProvider(provider); ok {
+			labels = appendUnique(labels, fmt.Sprintf(p.config.AvailabilityLabelFormat, name))
+		}
+	}
+	for _, provider := range leaving {
+		if name, ok := p.allowedProvider(provider); ok {
+			labels = appendUnique(labels, fmt.Sprintf(p.config.LeavingSoonLabelFormat, name))
+		}
+	}
+	return labels, nil
+}
+
+// tmdbWatchProviders returns the item's TMDb watch providers from its
+// details record, or from a separate request if they weren't appended.
+func (p *Processor) tmdbWatchProviders(tmdbID string, mediaType MediaType) ([]string, error) {
+	details, err := p.getDetails(tmdbID, mediaType)
+	if err != nil {
+		return nil, err
+	}
+	names, ok, err := p.tmdbClient.WatchProvidersFromDetails(details, p.config.AvailabilityRegion)
+	if err != nil || ok {
+		return names, err
+	}
+	return p.tmdbClient.GetWatchProviders(tmdbID, tmdbMediaType(mediaType), p.config.AvailabilityRegion)
+}
+
+// allowedProvider checks a service against AVAILABILITY_PROVIDERS. Matching
+// is case-insensitive and the allowlist spelling is used for the label; with
+// no allowlist every service is allowed.
+func (p *Processor) allowedProvider(provider string) (string, bool) {
+	if len(p.config.AvailabilityProviders) == 0 {
+		return provider, true
+	}
+	for _, allowed := range p.config.AvailabilityProviders {
+		if strings.EqualFold(allowed, provider) {
+			return allowed, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,78 @@
+package media
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/storage"
+)
+
+// keywordsChanged reports whether TMDb recorded a keyword change for an
+// already-processed item since it was last processed. The lookback is capped
+// at CHANGE_DETECTION_LOOKBACK. A per-cycle change list acts as a prefilter
+// so only items TMDb touched at all cost a per-item request.
+func (p *Processor) keywordsChanged(processed *storage.ProcessedItem, mediaType MediaType) bool {
+	if !p.config.ChangeDetection || processed.TMDbID == "" {
+		return false
+	}
+
+	now := time.Now()
+	since := processed.LastProcessed
+	if earliest := now.Add(-p.config.ChangeDetectionLookback); since.Before(earliest) {
+		since = earliest
+	}
+
+	changed, err := p.changedIDs(mediaType, now)
+	if err != nil {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Could not fetch TMDb change list: %v\n", err)
+		}
+		return false
+	}
+	id, err := strconv.Atoi(processed.TMDbID)
+	if err != nil || !changed[id] {
+		return false
+	}
+
+	keys, err := p.tmdbClient.GetChangedKeys(processed.TMDbID, tmdbMediaType(mediaType), since, now)
+	if err != nil {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Could not fetch TMDb changes for %s: %v\n", processed.TMDbID, err)
+		}
+		return false
+	}
+	for _, key := range keys {
+		if key == "keywords" {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [CHANGES] TMDb keywords for %s (TMDb %s) changed since %s, reprocessing\n", processed.Title, processed.TMDbID, since.Format("2006-01-02"))
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// changedIDs returns the TMDb IDs changed within the lookback window,
+// fetched once per processing cycle.
+func (p *Processor) changedIDs(mediaType MediaType, now time.Time) (map[int]bool, error) {
+	p.cacheMu.RLock()
+	ids, ok := p.changesCache[mediaType]
+	p.cacheMu.RUnlock()
+	if ok {
+		return ids, nil
+	}
+
+	ids, err := p.tmdbClient.GetChangedIDs(tmdbMediaType(mediaType), now.Add(-p.config.ChangeDetectionLookback), now)
+	if err != nil {
+		return nil, err
+	}
+	if p.config.VerboseLogging {
+		fmt.Printf("[CHANGES] TMDb reports %d changed %s IDs in the last %v\n", len(ids), tmdbMediaType(mediaType), p.config.ChangeDetectionLookback)
+	}
+
+	p.cacheMu.Lock()
+	p.changesCache[mediaType] = ids
+	p.cacheMu.Unlock()
+	return ids, nil
+}
@@ -0,0 +1,83 @@
+package media
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/storage"
+)
+
+// resumeIndex returns the index of the first item a library scan should
+// process: just past the item its checkpoint recorded when the previous scan
+// was interrupted, or 0 when there is nothing to resume. With exports enabled
+// the scan starts over, since the export files must cover every item.
+func (p *Processor) resumeIndex(libraryID string, items []MediaItem) int {
+	if p.checkpoints == nil {
+		return 0
+	}
+	cp, ok := p.checkpoints.Get(libraryID)
+	if !ok {
+		return 0
+	}
+	if p.exporter != nil {
+		fmt.Printf("[CHECKPOINT] Ignoring checkpoint for library %s: exports need a full scan\n", libraryID)
+		return 0
+	}
+
+	start := checkpointIndex(cp, items)
+	if start > 0 {
+		fmt.Printf("[CHECKPOINT] Resuming interrupted scan at item %d/%d (checkpoint from %s)\n", start+1, len(items), cp.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+	return start
+}
+
+// checkpointIndex returns the index just past the checkpoint's item. If that
+// item is gone, the recorded offset is used instead, capped to the list.
+func checkpointIndex(cp *storage.Checkpoint, items []MediaItem) int {
+	for i, item := range items {
+		if item.GetRatingKey() == cp.RatingKey {
+			return i + 1
+		}
+	}
+	if cp.Offset > len(items) {
+		return len(items)
+	}
+	if cp.Offset < 0 {
+		return 0
+	}
+	return cp.Offset
+}
+
+// saveCheckpoint records that a library scan has finished offset of total
+// items, the last being ratingKey. Storage changes still waiting for a
+// batched save are written first, so a resumed scan never skips items whose
+// processing wasn't saved; if they can't be, no checkpoint is recorded.
+func (p *Processor) saveCheckpoint(libraryID, ratingKey string, offset, total int) {
+	if p.checkpoints == nil || ratingKey == "" {
+		return
+	}
+	if err := p.FlushStorage(); err != nil {
+		fmt.Printf("[WARN] Failed to save storage, skipping checkpoint: %v\n", err)
+		return
+	}
+	err := p.checkpoints.Set(&storage.Checkpoint{
+		LibraryID: libraryID,
+		RatingKey: ratingKey,
+		Offset:    offset,
+		Total:     total,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		fmt.Printf("[WARN] Failed to save checkpoint: %v\n", err)
+	}
+}
+
+// clearCheckpoint forgets a library's checkpoint after a complete scan.
+func (p *Processor) clearCheckpoint(libraryID string) {
+	if p.checkpoints == nil {
+		return
+	}
+	if err := p.checkpoints.Delete(libraryID); err != nil {
+		fmt.Printf("[WARN] Failed to clear checkpoint: %v\n", err)
+	}
+}
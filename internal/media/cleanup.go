@@ -0,0 +1,89 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/nullable-eth/labelarr/internal/plex"
+)
+
+// markSeen records the library and the items a scan found, so PruneDeleted
+// knows they still exist and which stored entries it may check. Stored
+// entries that name no or another library are moved to this one.
+func (p *Processor) markSeen(libraryID string, items []MediaItem) {
+	p.cacheMu.Lock()
+	p.scanned[libraryID] = true
+	for _, item := range items {
+		p.seen[item.GetRatingKey()] = true
+	}
+	p.cacheMu.Unlock()
+
+	if p.storage == nil {
+		return
+	}
+	for _, item := range items {
+		processed, ok := p.storage.Get(item.GetRatingKey())
+		if !ok || processed.LibraryID == libraryID {
+			continue
+		}
+		updated := *processed
+		updated.LibraryID = libraryID
+		if err := p.storage.Set(&updated); err != nil {
+			fmt.Printf("[WARN] Failed to record library of %s in storage: %v\n", item.GetTitle(), err)
+			return
+		}
+	}
+}
+
+// PruneDeleted removes storage entries for items the media server no longer
+// has, so state doesn't grow forever as the library changes. Call it after
+// every library has been scanned in a cycle: entries seen during the cycle
+// are kept as is, and the rest of those from libraries scanned this cycle
+// are looked up one by one and dropped only if the server reports them
+// missing. Entries from other libraries are left alone without a lookup,
+// and a lookup error keeps the entry until next cycle. Entries from older
+// versions that don't record their library are always looked up.
+// Radarr/Sonarr deletions are picked up once the media server drops the item.
+func (p *Processor) PruneDeleted() {
+	if p.storage == nil || !p.config.PruneDeleted {
+		return
+	}
+
+	p.cacheMu.RLock()
+	var unseen []string
+	for key, processed := range p.storage.GetAll() {
+		if !p.seen[key] && (processed.LibraryID == "" || p.scanned[processed.LibraryID]) {
+			unseen = append(unseen, key)
+		}
+	}
+	p.cacheMu.RUnlock()
+	sort.Strings(unseen)
+
+	var deleted []string
+	for _, key := range unseen {
+		_, err := p.server.GetItemRef(key)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, plex.ErrNotFound) {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [WARN] Could not check whether item %s still exists: %v\n", key, err)
+			}
+			continue
+		}
+		if processed, ok := p.storage.Get(key); ok && p.config.VerboseLogging {
+			fmt.Printf("   [CLEANUP] %s (rating key %s) no longer exists\n", processed.Title, key)
+		}
+		deleted = append(deleted, key)
+	}
+	if len(deleted) == 0 {
+		return
+	}
+
+	if err := p.storage.Delete(deleted...); err != nil {
+		fmt.Printf("[WARN] Failed to prune deleted items from storage: %v\n", err)
+		return
+	}
+	fmt.Printf("[CLEANUP] Removed %d deleted item(s) from storage\n", len(deleted))
+}
@@ -0,0 +1,47 @@
+package media
+
+import "github.com/nullable-eth/labelarr/internal/plex"
+
+// showEpisodes returns the first episodes of a TV show, as listed by
+// GetTVShowEpisodes. Path lookup, NFO, stream and keyword provider sources
+// all read them, so the listing is cached per show for the cycle.
+func (p *Processor) showEpisodes(ratingKey string) ([]plex.Episode, error) {
+	return p.cachedEpisodes(ratingKey, func() ([]plex.Episode, error) {
+		return p.server.GetTVShowEpisodes(ratingKey)
+	})
+}
+
+// allShowEpisodes returns every episode of a TV show, as listed by
+// GetAllTVShowEpisodes, cached per show for the cycle like showEpisodes.
+func (p *Processor) allShowEpisodes(ratingKey string) ([]plex.Episode, error) {
+	return p.cachedEpisodes("all:"+ratingKey, func() ([]plex.Episode, error) {
+		return p.server.GetAllTVShowEpisodes(ratingKey)
+	})
+}
+
+func (p *Processor) cachedEpisodes(cacheKey string, fetch func() ([]plex.Episode, error)) ([]plex.Episode, error) {
+	p.cacheMu.RLock()
+	episodes, ok := p.episodeCache[cacheKey]
+	p.cacheMu.RUnlock()
+	if ok {
+		return episodes, nil
+	}
+
+	episodes, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	p.cacheMu.Lock()
+	p.episodeCache[cacheKey] = episodes
+	p.cacheMu.Unlock()
+	return episodes, nil
+}
+
+// forgetEpisodes drops a show's cached episode listings, so an item
+// processed again after an import sees its new episodes.
+func (p *Processor) forgetEpisodes(ratingKey string) {
+	p.cacheMu.Lock()
+	delete(p.episodeCache, ratingKey)
+	delete(p.episodeCache, "all:"+ratingKey)
+	p.cacheMu.Unlock()
+}
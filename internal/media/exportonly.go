@@ -0,0 +1,74 @@
+package media
+
+import (
+	"fmt"
+)
+
+// ExportLibrary accumulates export entries for every item in the library
+// from the values already on the server, for EXPORT_TIMER. Unlike
+// ProcessAllItems it fetches no keywords and writes nothing back, so file
+// lists can be refreshed far more often than keywords are synced. The
+// caller writes the exports.
+func (p *Processor) ExportLibrary(libraryID, libraryName string, mediaType MediaType) error {
+	if p.exporter == nil {
+		return nil
+	}
+
+	p.processingMu.Lock()
+	if p.processing[libraryID] {
+		p.processingMu.Unlock()
+		fmt.Printf("[INFO] Library %s is already being processed, skipping export\n", libraryName)
+		return nil
+	}
+	p.processing[libraryID] = true
+	p.processingMu.Unlock()
+	defer func() {
+		p.processingMu.Lock()
+		delete(p.processing, libraryID)
+		p.processingMu.Unlock()
+	}()
+
+	if err := p.exporter.SetCurrentLibrary(libraryName); err != nil {
+		return fmt.Errorf("failed to set current library for export: %w", err)
+	}
+	items, err := p.fetchItems(libraryID, mediaType)
+	if err != nil {
+		return fmt.Errorf("error fetching items: %w", err)
+	}
+
+	exported := 0
+	for _, item := range items {
+		if p.Stopped() {
+			return ErrStopped
+		}
+		details, err := p.getItemDetails(item.GetRatingKey(), mediaType)
+		if err != nil {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [WARN] Could not fetch details for %s: %v\n", item.GetTitle(), err)
+			}
+			continue
+		}
+		fileInfos, err := p.extractFileInfos(details, mediaType)
+		if err != nil || len(fileInfos) == 0 {
+			continue
+		}
+
+		tmdbID := ""
+		if p.storage != nil {
+			if processed, ok := p.storage.Get(item.GetRatingKey()); ok {
+				tmdbID = processed.TMDbID
+			}
+		}
+		if tmdbID == "" {
+			tmdbID = p.guidTMDbID(details)
+		}
+		if err := p.exportItem(item, details, tmdbID, p.extractCurrentValues(details), fileInfos); err != nil {
+			fmt.Printf("[WARN] Export failed for %s: %v\n", item.GetTitle(), err)
+			continue
+		}
+		exported++
+	}
+
+	fmt.Printf("[EXPORT] Checked %d items in %s for export (%d with files)\n", len(items), libraryName, exported)
+	return nil
+}
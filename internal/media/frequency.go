@@ -0,0 +1,156 @@
+package media
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KEYWORD_MIN_ITEMS keeps hyper-specific TMDb keywords out of the label
+// list: before a library is processed, a pre-pass counts on how many of its
+// items each keyword appears, and keywords below the threshold are skipped.
+// The counts are kept per library until the next full pass, so webhook
+// items are judged against the last one. KEYWORD_PRIORITY=frequency reuses
+// them to rank keywords for MAX_KEYWORDS_PER_ITEM.
+
+// countLibraryKeywords runs the keyword frequency pre-pass over a library.
+// Only items whose TMDb ID is known without a lookup, from storage or a
+// tmdb:// GUID, are counted; keywords come from the per-cycle cache, so the
+// main pass doesn't fetch them again.
+func (p *Processor) countLibraryKeywords(libraryID string, items []MediaItem, mediaType MediaType) {
+	var perItem [][]string
+	for _, item := range items {
+		tmdbID := p.knownTMDbID(item)
+		if tmdbID == "" {
+			continue
+		}
+		keywords, err := p.getKeywords(tmdbID, mediaType)
+		if err != nil {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [WARN] Could not fetch keywords of %s for keyword frequency: %v\n", item.GetTitle(), err)
+			}
+			continue
+		}
+		perItem = append(perItem, keywords)
+	}
+
+	counts := keywordFrequencies(perItem)
+	if p.config.KeywordMinItems > 1 {
+		kept := 0
+		for _, count := range counts {
+			if count >= p.config.KeywordMinItems {
+				kept++
+			}
+		}
+		fmt.Printf("[STATS] Keyword frequency: %d of %d keywords across %d items appear on at least %d items\n", kept, len(counts), len(perItem), p.config.KeywordMinItems)
+	} else {
+		fmt.Printf("[STATS] Keyword frequency: counted %d keywords across %d items\n", len(counts), len(perItem))
+	}
+
+	p.cacheMu.Lock()
+	p.keywordCounts[libraryID] = counts
+	p.cacheMu.Unlock()
+}
+
+// knownTMDbID returns the item's TMDb ID from storage or its tmdb:// GUID,
+// without any lookups.
+func (p *Processor) knownTMDbID(item MediaItem) string {
+	if p.storage != nil {
+		if processed, ok := p.storage.Get(item.GetRatingKey()); ok && processed.TMDbID != "" {
+			return processed.TMDbID
+		}
+	}
+	for _, guid := range item.GetGuid() {
+		if strings.HasPrefix(guid.ID, "tmdb://") {
+			return strings.TrimPrefix(guid.ID, "tmdb://")
+		}
+	}
+	return ""
+}
+
+// applyKeywordMinItems drops keywords that appear on fewer than
+// KEYWORD_MIN_ITEMS items of the library. Libraries without a pre-pass yet
+// are left unfiltered.
+func (p *Processor) applyKeywordMinItems(libraryID string, keywords []string) []string {
+	if p.config.KeywordMinItems <= 1 {
+		return keywords
+	}
+	p.cacheMu.RLock()
+	counts, ok := p.keywordCounts[libraryID]
+	p.cacheMu.RUnlock()
+	if !ok {
+		return keywords
+	}
+
+	kept := make([]string, 0, len(keywords))
+	for _, kw := range keywords {
+		if counts[strings.ToLower(kw)] >= p.config.KeywordMinItems {
+			kept = append(kept, kw)
+		}
+	}
+	if p.config.VerboseLogging && len(kept) < len(keywords) {
+		fmt.Printf("   [FILTER] Dropped %d of %d TMDb keywords below KEYWORD_MIN_ITEMS=%d\n", len(keywords)-len(kept), len(keywords), p.config.KeywordMinItems)
+	}
+	return kept
+}
+
+// rankByFrequency reports whether MAX_KEYWORDS_PER_ITEM keeps the most
+// common keywords, which needs the pre-pass counts.
+func (p *Processor) rankByFrequency() bool {
+	return p.config.MaxKeywords > 0 && p.config.KeywordPriority == "frequency"
+}
+
+// capKeywords applies MAX_KEYWORDS_PER_ITEM. With KEYWORD_PRIORITY=relevance
+// the first keywords win: TMDb's own order, then IMDb, TVDB and the other
+// sources. With frequency the keywords most common in the library win, falling
+// back to relevance for ties and for libraries without a pre-pass yet.
+func (p *Processor) capKeywords(libraryID string, keywords []string) []string {
+	if p.config.MaxKeywords <= 0 || len(keywords) <= p.config.MaxKeywords {
+		return keywords
+	}
+	var counts map[string]int
+	if p.rankByFrequency() {
+		p.cacheMu.RLock()
+		counts = p.keywordCounts[libraryID]
+		p.cacheMu.RUnlock()
+	}
+
+	kept := selectKeywords(keywords, p.config.MaxKeywords, counts)
+	if p.config.VerboseLogging {
+		fmt.Printf("   [FILTER] Kept %d of %d keywords by MAX_KEYWORDS_PER_ITEM (%s)\n", len(kept), len(keywords), p.config.KeywordPriority)
+	}
+	return kept
+}
+
+// selectKeywords returns the first max keywords, ordered by descending count
+// when counts is non-nil. Keywords without a count rank last, and the sort is
+// stable so equal counts keep their original order.
+func selectKeywords(keywords []string, max int, counts map[string]int) []string {
+	ranked := append([]string(nil), keywords...)
+	if counts != nil {
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return counts[strings.ToLower(ranked[i])] > counts[strings.ToLower(ranked[j])]
+		})
+	}
+	if len(ranked) > max {
+		ranked = ranked[:max]
+	}
+	return ranked
+}
+
+// keywordFrequencies counts, for each lowercased keyword, how many items
+// carry it. A keyword listed twice on one item counts once.
+func keywordFrequencies(perItem [][]string) map[string]int {
+	counts := make(map[string]int)
+	for _, keywords := range perItem {
+		seen := make(map[string]bool, len(keywords))
+		for _, kw := range keywords {
+			key := strings.ToLower(kw)
+			if !seen[key] {
+				seen[key] = true
+				counts[key]++
+			}
+		}
+	}
+	return counts
+}
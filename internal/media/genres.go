@@ -0,0 +1,72 @@
+package media
+
+import (
+	"fmt"
+	"strings"
+)
+
+// seedGenres implements SEED_GENRES: before keywords are applied to labels,
+// it replaces the item's Plex genres with TMDb's official genres when the two
+// differ. The genre field is locked or unlocked per SEED_GENRES_LOCK,
+// independently of the keyword field. details is the item as the server
+// reported it. Failures are logged and never block the keyword sync.
+func (p *Processor) seedGenres(details MediaItem, libraryID, tmdbID string, mediaType MediaType) {
+	if !p.config.SeedGenres || tmdbID == "" {
+		return
+	}
+
+	tmdbDetails, err := p.getDetails(tmdbID, mediaType)
+	if err != nil {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Could not fetch TMDb genres: %v\n", err)
+		}
+		return
+	}
+	genres := make([]string, 0, len(tmdbDetails.Genres))
+	for _, genre := range tmdbDetails.Genres {
+		genres = append(genres, genre.Name)
+	}
+	if len(genres) == 0 {
+		return
+	}
+
+	current := make([]string, 0, len(details.GetGenre()))
+	for _, genre := range details.GetGenre() {
+		current = append(current, genre.Tag)
+	}
+	if sameValues(current, genres) {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [GENRE] Genres already match TMDb: %v\n", genres)
+		}
+		return
+	}
+
+	plexMediaType, err := p.toPlexMediaType(mediaType)
+	if err != nil {
+		return
+	}
+	if err := p.server.SetMediaField(details.GetRatingKey(), libraryID, genres, "genre", p.config.SeedGenresLock, plexMediaType); err != nil {
+		fmt.Printf("[WARN] Failed to seed genres for %s: %v\n", details.GetTitle(), err)
+		return
+	}
+	if p.config.VerboseLogging {
+		fmt.Printf("   [GENRE] Seeded genres from TMDb: %v (was %v)\n", genres, current)
+	}
+}
+
+// sameValues reports whether a and b hold the same values, ignoring case and
+// order.
+func sameValues(a, b []string) bool {
+	set := make(map[string]bool, len(a))
+	for _, v := range a {
+		set[strings.ToLower(v)] = true
+	}
+	other := make(map[string]bool, len(b))
+	for _, v := range b {
+		if !set[strings.ToLower(v)] {
+			return false
+		}
+		other[strings.ToLower(v)] = true
+	}
+	return len(set) == len(other)
+}
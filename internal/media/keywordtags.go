@@ -0,0 +1,140 @@
+package media
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// pushKeywordTags writes the item's keywords to its Radarr movies or Sonarr
+// series as tags named ARR_KEYWORD_TAG_PREFIX + keyword (ARR_KEYWORD_TAGS),
+// limited to ARR_KEYWORD_TAG_ALLOWLIST when set. Prefixed tags whose keyword
+// the item no longer has are removed; tags without the prefix are never
+// touched.
+func (p *Processor) pushKeywordTags(item MediaItem, tmdbID string, mediaType MediaType, keywords []string) {
+	if !p.config.ArrKeywordTags {
+		return
+	}
+	want := keywordTagNames(keywords, p.config.ArrKeywordTagAllowlist, p.config.ArrKeywordTagPrefix)
+	prefix := keywordTagPrefix(p.config.ArrKeywordTagPrefix)
+
+	switch mediaType {
+	case MediaTypeMovie:
+		if len(p.radarrClients) == 0 || tmdbID == "" {
+			return
+		}
+		for _, entry := range p.radarrMovies(tmdbID) {
+			client, movie := entry.client, entry.movie
+			tags, err := client.GetTags()
+			if err != nil {
+				fmt.Printf("[WARN] Could not fetch Radarr tags: %v\n", err)
+				continue
+			}
+			current := make(map[int]string, len(movie.Tags))
+			for name, id := range tags {
+				if slices.Contains(movie.Tags, id) {
+					current[id] = name
+				}
+			}
+			applyKeywordTags("Radarr", movie.Title, current, want, prefix, client.EnsureTag, func(ids []int, applyTags string) error {
+				return client.EditMovieTags(movie.ID, ids, applyTags)
+			})
+		}
+	case MediaTypeTV:
+		if len(p.sonarrClients) == 0 {
+			return
+		}
+		for _, entry := range p.sonarrSeries(item, tmdbID) {
+			client, series := entry.client, entry.series
+			tags, err := client.GetTags()
+			if err != nil {
+				fmt.Printf("[WARN] Could not fetch Sonarr tags: %v\n", err)
+				continue
+			}
+			current := make(map[int]string, len(series.Tags))
+			for _, id := range series.Tags {
+				if name := tags[id]; name != "" {
+					current[id] = name
+				}
+			}
+			applyKeywordTags("Sonarr", series.Title, current, want, prefix, client.EnsureTag, func(ids []int, applyTags string) error {
+				return client.EditSeriesTags(series.ID, ids, applyTags)
+			})
+		}
+	}
+}
+
+// applyKeywordTags brings one movie's or series' prefixed tags in line with
+// want. current maps the tag IDs it carries to their names; ensure and edit
+// are the matching client's EnsureTag and bulk tag editor.
+func applyKeywordTags(service, title string, current map[int]string, want []string, prefix string, ensure func(string) (int, error), edit func([]int, string) error) {
+	has := make(map[string]bool, len(current))
+	for _, name := range current {
+		has[strings.ToLower(name)] = true
+	}
+
+	var add []int
+	var names []string
+	for _, name := range want {
+		if has[name] {
+			continue
+		}
+		tagID, err := ensure(name)
+		if err != nil {
+			fmt.Printf("[WARN] Could not create %s tag %q: %v\n", service, name, err)
+			continue
+		}
+		add = append(add, tagID)
+		names = append(names, name)
+	}
+	if len(add) > 0 {
+		if err := edit(add, "add"); err != nil {
+			fmt.Printf("[WARN] Could not tag %s in %s: %v\n", title, service, err)
+		} else {
+			fmt.Printf("[%s] Tagged %s: %s\n", strings.ToUpper(service), title, strings.Join(names, ", "))
+		}
+	}
+
+	var remove []int
+	for tagID, name := range current {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, prefix) && !slices.Contains(want, name) {
+			remove = append(remove, tagID)
+		}
+	}
+	if len(remove) == 0 {
+		return
+	}
+	sort.Ints(remove)
+	names = nil
+	for _, tagID := range remove {
+		names = append(names, current[tagID])
+	}
+	if err := edit(remove, "remove"); err != nil {
+		fmt.Printf("[WARN] Could not untag %s in %s: %v\n", title, service, err)
+		return
+	}
+	fmt.Printf("[%s] Removed tags from %s: %s\n", strings.ToUpper(service), title, strings.Join(names, ", "))
+}
+
+// keywordTagNames turns keywords into prefixed tag names, keeping only
+// keywords on the allowlist when one is given. The allowlist is lowercased.
+func keywordTagNames(keywords, allowlist []string, prefix string) []string {
+	var names []string
+	for _, keyword := range keywords {
+		if len(allowlist) > 0 && !slices.Contains(allowlist, strings.ToLower(keyword)) {
+			continue
+		}
+		if name := radarrTagName(prefix + keyword); name != "" {
+			names = appendUnique(names, name)
+		}
+	}
+	return names
+}
+
+// keywordTagPrefix returns the prefix as it appears in tag names, with a
+// trailing separator kept: "kw-" and "KW_" both become "kw-".
+func keywordTagPrefix(prefix string) string {
+	return strings.TrimSuffix(radarrTagName(prefix+"x"), "x")
+}
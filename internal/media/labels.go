@@ -0,0 +1,577 @@
+package media
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/nullable-eth/labelarr/internal/config"
+	"github.com/nullable-eth/labelarr/internal/nfo"
+	"github.com/nullable-eth/labelarr/internal/plex"
+	"github.com/nullable-eth/labelarr/internal/provider"
+	"github.com/nullable-eth/labelarr/internal/tmdb"
+	"github.com/nullable-eth/labelarr/internal/utils"
+	"github.com/nullable-eth/labelarr/internal/wikidata"
+)
+
+// buildLabels returns the full set of values labelarr wants on the item:
+// TMDb keywords (AniList genres and tags in AniList libraries) merged with
+// IMDb and TVDB keywords (with KEYWORD_PREFIX applied), followed by labels
+// from any enabled optional sources. Duplicates are dropped
+// case-insensitively. tmdbID is empty for TVDB_KEYWORDS=only items, which
+// then rely on TVDB alone, and for anime matched only through AniList.
+func (p *Processor) buildLabels(item MediaItem, libraryID, tmdbID string, mediaType MediaType) ([]string, error) {
+	keywords, fromAniList := p.anilistKeywords(item, libraryID, mediaType)
+	if !fromAniList && tmdbID == "" && !p.tvdbOnly(item, mediaType) && len(p.config.PlexSimilarSeeds) == 0 {
+		return nil, fmt.Errorf("no AniList keywords available for %s", item.GetTitle())
+	}
+	if !fromAniList && tmdbID != "" {
+		tmdbKeywords, err := p.getKeywords(tmdbID, mediaType)
+		if err != nil {
+			return nil, err
+		}
+		keywords = append(keywords, p.applyKeywordMinItems(libraryID, tmdbKeywords)...)
+	}
+
+	tvdbKeywords, err := p.tvdbKeywords(item, mediaType)
+	if err != nil {
+		if tmdbID == "" && !fromAniList && p.tvdbOnly(item, mediaType) {
+			return nil, err
+		}
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Could not fetch TVDB keywords: %v\n", err)
+		}
+	}
+
+	for _, keyword := range append(append(p.imdbKeywords(item), tvdbKeywords...), p.nfoKeywords(item, mediaType)...) {
+		keywords = appendUnique(keywords, keyword)
+	}
+	for _, keyword := range p.providerKeywords(item, libraryID, tmdbID, mediaType, keywords) {
+		keywords = appendUnique(keywords, keyword)
+	}
+	labels := p.applyKeywordPrefix(p.capKeywords(libraryID, p.filterKeywords(p.applyKeywordAliases(keywords))))
+
+	for _, extra := range p.extraLabels(item, tmdbID, mediaType) {
+		labels = appendUnique(labels, extra)
+	}
+	return labels, nil
+}
+
+// imdbKeywords returns IMDb keywords for the item's imdb:// GUID, or nil when
+// IMDB_KEYWORDS is off or the item has no IMDb GUID.
+func (p *Processor) imdbKeywords(item MediaItem) []string {
+	if p.imdbClient == nil {
+		return nil
+	}
+	for _, guid := range item.GetGuid() {
+		if !strings.HasPrefix(guid.ID, "imdb://") {
+			continue
+		}
+		keywords, err := p.imdbClient.GetKeywords(strings.TrimPrefix(guid.ID, "imdb://"))
+		if err != nil {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [WARN] Could not fetch IMDb keywords: %v\n", err)
+			}
+			return nil
+		}
+		if p.config.VerboseLogging {
+			fmt.Printf("   [KEY] IMDb keywords: %d\n", len(keywords))
+		}
+		return keywords
+	}
+	return nil
+}
+
+// nfoKeywords returns the <tag> and <genre> values of the item's local NFO
+// file, or nil when NFO_KEYWORDS is off or no NFO is found. Plex file paths
+// are translated with NFO_PATH_MAPPINGS first.
+func (p *Processor) nfoKeywords(item MediaItem, mediaType MediaType) []string {
+	if !p.config.NFOKeywords {
+		return nil
+	}
+
+	var mediaFile string
+	if mediaType == MediaTypeTV {
+		episodes, err := p.showEpisodes(item.GetRatingKey())
+		if err != nil {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [WARN] Could not fetch episodes to locate tvshow.nfo: %v\n", err)
+			}
+			return nil
+		}
+		for _, episode := range episodes {
+			if files := mediaFiles(episode.Media); len(files) > 0 {
+				mediaFile = files[0]
+				break
+			}
+		}
+	} else if files := mediaFiles(item.GetMedia()); len(files) > 0 {
+		mediaFile = files[0]
+	}
+	if mediaFile == "" {
+		return nil
+	}
+
+	mediaFile = utils.RewritePath(mediaFile, p.config.NFOPathMappings)
+	keywords, found, err := nfo.Read(nfo.Candidates(mediaFile, mediaType == MediaTypeTV))
+	if err != nil {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Could not read NFO: %v\n", err)
+		}
+		return nil
+	}
+	if p.config.VerboseLogging && found {
+		fmt.Printf("   [KEY] NFO tags/genres: %d\n", len(keywords))
+	}
+	return keywords
+}
+
+// providerKeywords asks the KEYWORD_PROVIDER_URL endpoint for extra
+// keywords, passing it the item's metadata and the keywords found so far.
+func (p *Processor) providerKeywords(item MediaItem, libraryID, tmdbID string, mediaType MediaType, keywords []string) []string {
+	if p.keywordProvider == nil {
+		return nil
+	}
+
+	request := provider.Request{
+		RatingKey: item.GetRatingKey(),
+		LibraryID: libraryID,
+		MediaType: tmdbMediaType(mediaType),
+		Title:     item.GetTitle(),
+		Year:      item.GetYear(),
+		TMDbID:    tmdbID,
+		IMDbID:    itemIMDbID(item, nil),
+		TVDbID:    tvdbGUID(item),
+		Paths:     mediaFiles(item.GetMedia()),
+		Labels:    append([]string{}, keywords...),
+	}
+	if mediaType == MediaTypeTV {
+		if episodes, err := p.showEpisodes(item.GetRatingKey()); err == nil {
+			for _, episode := range episodes {
+				request.Paths = append(request.Paths, mediaFiles(episode.Media)...)
+			}
+		}
+	}
+
+	extra, err := p.keywordProvider.GetKeywords(request)
+	if err != nil {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Keyword provider failed: %v\n", err)
+		}
+		return nil
+	}
+	if p.config.VerboseLogging {
+		fmt.Printf("   [KEY] Keyword provider keywords: %d\n", len(extra))
+	}
+	return extra
+}
+
+// mediaFiles returns the file paths of all parts of the given media.
+func mediaFiles(media []plex.Media) []string {
+	var files []string
+	for _, m := range media {
+		for _, part := range m.Part {
+			if part.File != "" {
+				files = append(files, part.File)
+			}
+		}
+	}
+	return files
+}
+
+// tvdbKeywords returns TVDB genres and tags for a TV show's tvdb:// GUID, or
+// nil when TVDB_KEYWORDS is off or the show has no TVDB GUID.
+func (p *Processor) tvdbKeywords(item MediaItem, mediaType MediaType) ([]string, error) {
+	if p.tvdbClient == nil || mediaType != MediaTypeTV {
+		return nil, nil
+	}
+	tvdbID := tvdbGUID(item)
+	if tvdbID == "" {
+		return nil, nil
+	}
+	keywords, err := p.tvdbClient.GetSeriesKeywords(tvdbID)
+	if err != nil {
+		return nil, err
+	}
+	if p.config.VerboseLogging {
+		fmt.Printf("   [KEY] TVDB genres/tags: %d\n", len(keywords))
+	}
+	return keywords, nil
+}
+
+// tvdbOnly reports whether a TV show takes its keywords from TVDB alone
+// (TVDB_KEYWORDS=only), skipping the TVDb-to-TMDb lookup.
+func (p *Processor) tvdbOnly(item MediaItem, mediaType MediaType) bool {
+	return p.tvdbClient != nil && p.config.TVDbKeywords == "only" && mediaType == MediaTypeTV && tvdbGUID(item) != ""
+}
+
+// tvdbGUID returns the numeric ID from the item's tvdb:// GUID, if any.
+func tvdbGUID(item MediaItem) string {
+	for _, guid := range item.GetGuid() {
+		if strings.HasPrefix(guid.ID, "tvdb://") {
+			return strings.TrimPrefix(guid.ID, "tvdb://")
+		}
+	}
+	return ""
+}
+
+// extraLabels collects labels from the optional non-keyword sources. Source
+// failures are logged under VERBOSE_LOGGING and never block keyword syncing.
+func (p *Processor) extraLabels(item MediaItem, tmdbID string, mediaType MediaType) []string {
+	var labels []string
+	var details *tmdb.Details
+
+	if tmdbID != "" && p.needsDetails() {
+		var err error
+		details, err = p.getDetails(tmdbID, mediaType)
+		if err != nil {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [WARN] Could not fetch TMDb details for extra labels: %v\n", err)
+			}
+		} else {
+			if p.config.StudioLabels {
+				labels = append(labels, p.studioLabels(details)...)
+			}
+			if p.config.LanguageLabels {
+				labels = append(labels, p.languageLabels(details)...)
+			}
+			if p.config.CountryLabels {
+				labels = append(labels, p.countryLabels(details)...)
+			}
+			if p.config.AdultLabel != "" && details.Adult {
+				labels = append(labels, p.config.AdultLabel)
+			}
+		}
+	}
+
+	if len(p.radarrClients) > 0 && mediaType == MediaTypeMovie && tmdbID != "" && (len(p.config.RadarrQualityLabels) > 0 || p.config.RadarrCollectionLabels) {
+		labels = append(labels, p.radarrLabels(tmdbID)...)
+	}
+
+	if len(p.radarrClients) > 0 && mediaType == MediaTypeMovie && tmdbID != "" && p.config.RadarrLanguageLabels {
+		if label := p.radarrLanguageLabel(tmdbID); label != "" {
+			labels = append(labels, label)
+		}
+	}
+
+	if len(p.sonarrClients) > 0 && len(p.config.SonarrSeriesTypeLabels) > 0 && mediaType == MediaTypeTV {
+		if label := p.seriesTypeLabel(item, tmdbID); label != "" {
+			labels = append(labels, label)
+		}
+	}
+
+	labels = append(labels, p.unmanagedLabels(item, tmdbID, mediaType)...)
+
+	if p.needsStreams() {
+		labels = append(labels, p.streamLabels(item, mediaType)...)
+	}
+
+	if len(p.ageProviders) > 0 {
+		if label := p.ageLabel(item, tmdbID, mediaType, details); label != "" {
+			labels = append(labels, label)
+		}
+	}
+
+	if p.malClient != nil {
+		labels = append(labels, p.malLabels(item, mediaType)...)
+	}
+
+	if p.wikidataClient != nil {
+		labels = append(labels, p.awardLabels(item, tmdbID, mediaType, details)...)
+	}
+
+	if tmdbID != "" && len(p.config.TMDbListLabels) > 0 {
+		labels = append(labels, p.listLabels(tmdbID, mediaType)...)
+	}
+
+	if p.mappingFile != nil {
+		keys := []string{"plex:" + item.GetRatingKey()}
+		if tmdbID != "" {
+			keys = append(keys, tmdbMediaType(mediaType)+":"+tmdbID)
+		}
+		if imdbID := itemIMDbID(item, details); imdbID != "" {
+			keys = append(keys, imdbID)
+		}
+		labels = append(labels, p.mappingFile.Labels(keys...)...)
+	}
+
+	if len(p.config.PlexSimilarSeeds) > 0 {
+		labels = append(labels, p.similarLabels(item)...)
+	}
+
+	if p.config.DecadeLabels {
+		year := item.GetYear()
+		if details != nil && details.Year() > 0 {
+			year = details.Year()
+		}
+		if label := decadeLabel(year); label != "" {
+			labels = append(labels, label)
+		}
+	}
+
+	return labels
+}
+
+// needsDetails reports whether any enabled source reads the TMDb details
+// record. Alternative-title matching counts because it fetches details for
+// match candidates, and keywords then come along in the same request.
+func (p *Processor) needsDetails() bool {
+	return p.config.StudioLabels || p.config.LanguageLabels || p.config.CountryLabels || p.config.DecadeLabels ||
+		p.config.AdultLabel != "" || p.config.TMDbAlternativeTitles || slices.Contains(p.config.AgeProviders, "tmdb") ||
+		p.config.SeedGenres || p.config.AvailabilitySource == "tmdb"
+}
+
+// getDetails fetches TMDb details for an item, cached per processing cycle.
+// Keywords, and the alternative titles, release dates or content ratings and
+// watch providers of the sources that use them, ride along via
+// append_to_response so each item costs a single TMDb request (see
+// getKeywords).
+func (p *Processor) getDetails(tmdbID string, mediaType MediaType) (*tmdb.Details, error) {
+	if cached := p.cachedDetails(tmdbID, mediaType); cached != nil {
+		return cached, nil
+	}
+
+	appends := []string{"keywords"}
+	if p.config.TMDbAlternativeTitles {
+		appends = append(appends, "alternative_titles")
+	}
+	if slices.Contains(p.config.AgeProviders, "tmdb") {
+		if mediaType == MediaTypeTV {
+			appends = append(appends, "content_ratings")
+		} else {
+			appends = append(appends, "release_dates")
+		}
+	}
+	if p.config.AvailabilitySource == "tmdb" {
+		appends = append(appends, "watch/providers")
+	}
+	details, err := p.tmdbClient.GetDetails(tmdbID, tmdbMediaType(mediaType), appends...)
+	if err != nil {
+		return nil, err
+	}
+
+	p.cacheMu.Lock()
+	p.detailsCache[string(mediaType)+":"+tmdbID] = details
+	p.cacheMu.Unlock()
+	return details, nil
+}
+
+// studioLabels returns production company names, restricted to
+// STUDIO_LABEL_ALLOWLIST when one is configured. Allowlist matching is
+// case-insensitive and the allowlist spelling is used for the label.
+func (p *Processor) studioLabels(details *tmdb.Details) []string {
+	var labels []string
+	for _, company := range details.ProductionCompanies {
+		name := strings.TrimSpace(company.Name)
+		if name == "" {
+			continue
+		}
+		if len(p.config.StudioLabelAllowlist) == 0 {
+			labels = appendUnique(labels, name)
+			continue
+		}
+		for _, allowed := range p.config.StudioLabelAllowlist {
+			if strings.EqualFold(allowed, name) {
+				labels = appendUnique(labels, allowed)
+				break
+			}
+		}
+	}
+	return labels
+}
+
+// languageLabels returns the original-language label unless the language is
+// listed in LANGUAGE_LABEL_EXCLUDE.
+func (p *Processor) languageLabels(details *tmdb.Details) []string {
+	for _, excluded := range p.config.LanguageLabelExclude {
+		if strings.EqualFold(excluded, details.OriginalLanguage) {
+			return nil
+		}
+	}
+	if label := languageLabel(details.OriginalLanguage, p.config.LanguageLabelMap); label != "" {
+		return []string{label}
+	}
+	return nil
+}
+
+// countryLabels returns one label per production country (movies) or origin
+// country (TV shows).
+func (p *Processor) countryLabels(details *tmdb.Details) []string {
+	var labels []string
+	for _, country := range details.ProductionCountries {
+		if label := countryLabel(country.ISO31661, country.Name, p.config.CountryLabelMap); label != "" {
+			labels = appendUnique(labels, label)
+		}
+	}
+	for _, code := range details.OriginCountry {
+		if label := countryLabel(code, "", p.config.CountryLabelMap); label != "" {
+			labels = appendUnique(labels, label)
+		}
+	}
+	return labels
+}
+
+// itemIMDbID returns the item's IMDb ID from its imdb:// GUID, or from TMDb
+// movie details when those were fetched anyway.
+func itemIMDbID(item MediaItem, details *tmdb.Details) string {
+	for _, guid := range item.GetGuid() {
+		if strings.HasPrefix(guid.ID, "imdb://") {
+			return strings.TrimPrefix(guid.ID, "imdb://")
+		}
+	}
+	if details != nil {
+		return details.IMDbID
+	}
+	return ""
+}
+
+// cachedDetails returns the item's TMDb details if they were already fetched
+// this cycle, without making a request.
+func (p *Processor) cachedDetails(tmdbID string, mediaType MediaType) *tmdb.Details {
+	p.cacheMu.RLock()
+	defer p.cacheMu.RUnlock()
+	return p.detailsCache[string(mediaType)+":"+tmdbID]
+}
+
+// scoreLabels returns the OMDB_SCORE_LABELS labels whose minimum score the
+// item meets. Movies without an imdb:// GUID are looked up by the IMDb ID in
+// their TMDb details. err is set when OMDb or TMDb can't be read, including
+// when the OMDb daily request limit has been reached.
+func (p *Processor) scoreLabels(item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+	imdbID := itemIMDbID(item, nil)
+	if imdbID == "" && tmdbID != "" && mediaType == MediaTypeMovie {
+		details, err := p.getDetails(tmdbID, mediaType)
+		if err != nil {
+			return nil, fmt.Errorf("TMDb details for IMDb ID: %w", err)
+		}
+		imdbID = details.IMDbID
+	}
+	if imdbID == "" {
+		return nil, nil
+	}
+
+	scores, err := p.omdbClient.GetScores(imdbID)
+	if err != nil {
+		return nil, fmt.Errorf("OMDb scores: %w", err)
+	}
+
+	rules := make([]string, 0, len(p.config.OMDbScoreLabels))
+	for rule := range p.config.OMDbScoreLabels {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	var labels []string
+	for _, rule := range rules {
+		source, minScore, err := config.ParseScoreRule(rule)
+		if err != nil {
+			continue
+		}
+		score := scores.IMDb
+		switch source {
+		case "rt":
+			score = scores.RottenTomatoes
+		case "metacritic":
+			score = scores.Metacritic
+		}
+		if score >= 0 && score >= minScore {
+			labels = appendUnique(labels, p.config.OMDbScoreLabels[rule])
+		}
+	}
+	return labels, nil
+}
+
+// awardLabels returns the WIKIDATA_AWARDS labels of awards the item has
+// received, looked up by IMDb ID or, failing that, by TMDb ID.
+func (p *Processor) awardLabels(item MediaItem, tmdbID string, mediaType MediaType, details *tmdb.Details) []string {
+	prop, id := wikidata.PropIMDb, itemIMDbID(item, details)
+	if id == "" && tmdbID != "" {
+		prop, id = wikidata.PropTMDbMovie, tmdbID
+		if mediaType == MediaTypeTV {
+			prop = wikidata.PropTMDbTV
+		}
+	}
+	if id == "" {
+		return nil
+	}
+
+	labels, err := p.wikidataClient.GetAwardLabels(prop, id)
+	if err != nil {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Could not fetch Wikidata awards: %v\n", err)
+		}
+		return nil
+	}
+	return labels
+}
+
+// listLabels returns the TMDB_LIST_LABELS label of every configured list
+// that contains the item. Lists are sorted by ID so label order is stable.
+func (p *Processor) listLabels(tmdbID string, mediaType MediaType) []string {
+	listIDs := make([]string, 0, len(p.config.TMDbListLabels))
+	for id := range p.config.TMDbListLabels {
+		listIDs = append(listIDs, id)
+	}
+	sort.Strings(listIDs)
+
+	key := tmdbMediaType(mediaType) + ":" + tmdbID
+	var labels []string
+	for _, listID := range listIDs {
+		members, err := p.listMembers("tmdb:"+listID, func() (map[string]bool, error) {
+			return p.tmdbClient.GetListItems(listID)
+		})
+		if err != nil {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [WARN] Could not fetch TMDb list %s: %v\n", listID, err)
+			}
+			continue
+		}
+		if members[key] {
+			labels = appendUnique(labels, p.config.TMDbListLabels[listID])
+		}
+	}
+	return labels
+}
+
+// listMembers returns the members of a TMDb or Trakt list, fetched once per
+// processing cycle. key identifies the list in listCache; failed fetches
+// are not cached so the next item retries.
+func (p *Processor) listMembers(key string, fetch func() (map[string]bool, error)) (map[string]bool, error) {
+	p.cacheMu.RLock()
+	members, ok := p.listCache[key]
+	p.cacheMu.RUnlock()
+	if ok {
+		return members, nil
+	}
+
+	members, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	p.cacheMu.Lock()
+	p.listCache[key] = members
+	p.cacheMu.Unlock()
+	return members, nil
+}
+
+// decadeLabel returns the decade label for a year (1987 -> "1980s"), or ""
+// when the year is unknown.
+func decadeLabel(year int) string {
+	if year <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%ds", year/10*10)
+}
+
+// appendUnique appends value unless an equal value (case-insensitive) is
+// already present.
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return values
+		}
+	}
+	return append(values, value)
+}
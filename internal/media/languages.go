@@ -0,0 +1,167 @@
+package media
+
+import "strings"
+
+// languageNames maps ISO 639-1 codes (as returned in TMDb original_language)
+// to display names. Override or extend via LANGUAGE_LABEL_MAP.
+var languageNames = map[string]string{
+	"ar": "Arabic",
+	"bn": "Bengali",
+	"cn": "Cantonese",
+	"cs": "Czech",
+	"da": "Danish",
+	"de": "German",
+	"el": "Greek",
+	"en": "English",
+	"es": "Spanish",
+	"fa": "Persian",
+	"fi": "Finnish",
+	"fr": "French",
+	"he": "Hebrew",
+	"hi": "Hindi",
+	"hu": "Hungarian",
+	"id": "Indonesian",
+	"is": "Icelandic",
+	"it": "Italian",
+	"ja": "Japanese",
+	"kn": "Kannada",
+	"ko": "Korean",
+	"ml": "Malayalam",
+	"nl": "Dutch",
+	"no": "Norwegian",
+	"pl": "Polish",
+	"pt": "Portuguese",
+	"ro": "Romanian",
+	"ru": "Russian",
+	"sv": "Swedish",
+	"ta": "Tamil",
+	"te": "Telugu",
+	"th": "Thai",
+	"tl": "Tagalog",
+	"tr": "Turkish",
+	"uk": "Ukrainian",
+	"vi": "Vietnamese",
+	"zh": "Mandarin",
+}
+
+// iso6392Codes maps ISO 639-2 codes (as reported for Plex and Jellyfin
+// audio streams) to the ISO 639-1 codes of languageNames, including both
+// bibliographic and terminology forms.
+var iso6392Codes = map[string]string{
+	"ara": "ar",
+	"ben": "bn",
+	"yue": "cn",
+	"cze": "cs",
+	"ces": "cs",
+	"dan": "da",
+	"ger": "de",
+	"deu": "de",
+	"gre": "el",
+	"ell": "el",
+	"eng": "en",
+	"spa": "es",
+	"per": "fa",
+	"fas": "fa",
+	"fin": "fi",
+	"fre": "fr",
+	"fra": "fr",
+	"heb": "he",
+	"hin": "hi",
+	"hun": "hu",
+	"ind": "id",
+	"ice": "is",
+	"isl": "is",
+	"ita": "it",
+	"jpn": "ja",
+	"kan": "kn",
+	"kor": "ko",
+	"mal": "ml",
+	"dut": "nl",
+	"nld": "nl",
+	"nor": "no",
+	"nob": "no",
+	"nno": "no",
+	"pol": "pl",
+	"por": "pt",
+	"rum": "ro",
+	"ron": "ro",
+	"rus": "ru",
+	"swe": "sv",
+	"tam": "ta",
+	"tel": "te",
+	"tha": "th",
+	"tgl": "tl",
+	"tur": "tr",
+	"ukr": "uk",
+	"vie": "vi",
+	"chi": "zh",
+	"zho": "zh",
+	"cmn": "zh",
+}
+
+// countryNames maps ISO 3166-1 codes to display names. TMDb includes names
+// for movie production_countries, but TV origin_country is codes only.
+var countryNames = map[string]string{
+	"AR": "Argentina",
+	"AU": "Australia",
+	"BE": "Belgium",
+	"BR": "Brazil",
+	"CA": "Canada",
+	"CN": "China",
+	"DE": "Germany",
+	"DK": "Denmark",
+	"ES": "Spain",
+	"FI": "Finland",
+	"FR": "France",
+	"GB": "United Kingdom",
+	"HK": "Hong Kong",
+	"IE": "Ireland",
+	"IN": "India",
+	"IS": "Iceland",
+	"IT": "Italy",
+	"JP": "Japan",
+	"KR": "South Korea",
+	"MX": "Mexico",
+	"NL": "Netherlands",
+	"NO": "Norway",
+	"NZ": "New Zealand",
+	"PL": "Poland",
+	"RU": "Russia",
+	"SE": "Sweden",
+	"TH": "Thailand",
+	"TR": "Turkey",
+	"TW": "Taiwan",
+	"US": "United States of America",
+}
+
+// languageLabel resolves an ISO 639-1 code to its label, preferring the user
+// override map. Unknown codes are returned uppercased.
+func languageLabel(code string, overrides map[string]string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" || code == "xx" {
+		return ""
+	}
+	if name, ok := overrides[code]; ok {
+		return name
+	}
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return strings.ToUpper(code)
+}
+
+// countryLabel resolves an ISO 3166-1 code to its label, preferring the user
+// override map, then the TMDb-provided name, then the built-in table.
+func countryLabel(code, tmdbName string, overrides map[string]string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if name, ok := overrides[strings.ToLower(code)]; ok {
+		return name
+	}
+	if tmdbName != "" {
+		return tmdbName
+	}
+	if name, ok := countryNames[code]; ok {
+		return name
+	}
+	return code
+}
@@ -0,0 +1,583 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/mdblist"
+	"github.com/nullable-eth/labelarr/internal/storage"
+	"github.com/nullable-eth/labelarr/internal/tautulli"
+)
+
+// managedSource is a label source whose labels reflect conditions that
+// change over time.
+type managedSource struct {
+	name string
+	// storageOnly sources are never added without storage; Validate requires
+	// DATA_DIR for them.
+	storageOnly bool
+	enabled     func(p *Processor) bool
+	// labels returns the source's labels that currently apply to the item.
+	// err is set when the source can't be read.
+	labels func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error)
+}
+
+// managedSources are the lifecycle-managed label sources: an item trending
+// this week, sitting on a Trakt or Letterboxd list, an OMDb score crossing a
+// threshold, a file being replaced by a smaller one, a Sonarr tag being
+// removed, an Overseerr request being deleted, or going unwatched, for
+// example.
+// Unlike keywords, which are only ever added, with storage these are
+// re-evaluated every cycle: labelarr adds them while they apply and removes
+// them once they don't. Which labels labelarr added is recorded in storage
+// (ProcessedItem.ManagedLabels), so removal never touches a label that was
+// already on the item for another reason. A source that can't be read is
+// not taken to mean its labels no longer apply: nothing is removed from the
+// item until every source answers again. Without storage, extraLabels adds
+// the labels of sources that aren't storageOnly like any other extra label.
+var managedSources = []managedSource{
+	{
+		name:        "trending",
+		storageOnly: true,
+		enabled:     func(p *Processor) bool { return p.config.TrendingLabel != "" },
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			if tmdbID == "" {
+				return nil, nil
+			}
+			trending, err := p.trendingIDs(mediaType)
+			if err != nil {
+				return nil, fmt.Errorf("TMDb trending list: %w", err)
+			}
+			if id, err := strconv.Atoi(tmdbID); err == nil && trending[id] {
+				return []string{p.config.TrendingLabel}, nil
+			}
+			return nil, nil
+		},
+	},
+	{
+		name:        "trakt",
+		storageOnly: true,
+		enabled:     func(p *Processor) bool { return p.traktClient != nil },
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			if tmdbID == "" {
+				return nil, nil
+			}
+			return p.traktLabels(tmdbID, mediaType)
+		},
+	},
+	{
+		name:        "letterboxd",
+		storageOnly: true,
+		enabled:     func(p *Processor) bool { return p.letterboxdClient != nil },
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			if tmdbID == "" {
+				return nil, nil
+			}
+			return p.letterboxdLabels(tmdbID, mediaType)
+		},
+	},
+	{
+		name:        "mdblist",
+		storageOnly: true,
+		enabled:     func(p *Processor) bool { return p.mdblistClient != nil },
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			if tmdbID == "" {
+				return nil, nil
+			}
+			return p.mdblistLabels(tmdbID, mediaType)
+		},
+	},
+	{
+		name:        "availability",
+		storageOnly: true,
+		enabled:     func(p *Processor) bool { return p.config.AvailabilitySource != "" },
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			if tmdbID == "" {
+				return nil, nil
+			}
+			return p.availabilityLabels(item, tmdbID, mediaType)
+		},
+	},
+	{
+		name:        "tautulli",
+		storageOnly: true,
+		enabled:     func(p *Processor) bool { return p.tautulliClient != nil },
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			return p.watchLabels(item)
+		},
+	},
+	{
+		name:    "radarr import lists",
+		enabled: func(p *Processor) bool { return len(p.radarrClients) > 0 && p.config.RadarrImportListLabels },
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			if mediaType != MediaTypeMovie {
+				return nil, nil
+			}
+			return p.importListLabels(tmdbID)
+		},
+	},
+	{
+		name: "arr missing",
+		enabled: func(p *Processor) bool {
+			return p.config.RadarrMissingLabel != "" || p.config.SonarrMissingLabel != ""
+		},
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			label, err := p.arrMissingLabel(item, tmdbID, mediaType)
+			if label == "" {
+				return nil, err
+			}
+			return []string{label}, err
+		},
+	},
+	{
+		name:    "arr status",
+		enabled: func(p *Processor) bool { return len(p.config.ArrStatusLabels) > 0 },
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			return p.arrStatusLabels(item, tmdbID, mediaType)
+		},
+	},
+	{
+		name: "sonarr completion",
+		enabled: func(p *Processor) bool {
+			return len(p.sonarrClients) > 0 && len(p.config.SonarrCompletionLabels) > 0
+		},
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			if mediaType != MediaTypeTV {
+				return nil, nil
+			}
+			return p.completionLabels(item, tmdbID)
+		},
+	},
+	{
+		name:    "sonarr tags",
+		enabled: func(p *Processor) bool { return len(p.sonarrClients) > 0 && p.config.SonarrTagLabels },
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			if mediaType != MediaTypeTV {
+				return nil, nil
+			}
+			return p.sonarrTagLabels(item, tmdbID)
+		},
+	},
+	{
+		name:    "overseerr requests",
+		enabled: func(p *Processor) bool { return p.overseerrClient != nil },
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			if tmdbID == "" {
+				return nil, nil
+			}
+			return p.requestLabels(tmdbID, mediaType)
+		},
+	},
+	{
+		name:    "bazarr subtitles",
+		enabled: func(p *Processor) bool { return p.bazarrClient != nil },
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			return p.subtitleLabels(item, tmdbID, mediaType)
+		},
+	},
+	{
+		name:    "sizes",
+		enabled: func(p *Processor) bool { return len(p.config.SizeLabels) > 0 },
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			return p.sizeLabels(item, mediaType)
+		},
+	},
+	{
+		name:    "omdb scores",
+		enabled: func(p *Processor) bool { return p.omdbClient != nil },
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			return p.scoreLabels(item, tmdbID, mediaType)
+		},
+	},
+}
+
+// hasDynamicSources reports whether any lifecycle-managed source is enabled.
+// Without storage only storageOnly sources count, since the others are then
+// added as extra labels.
+func (p *Processor) hasDynamicSources() bool {
+	for _, source := range managedSources {
+		if (p.storage != nil || source.storageOnly) && source.enabled(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// dynamicLabels returns the lifecycle-managed labels that currently apply to
+// the item. err joins the failures of sources that couldn't be read; labels
+// then holds only what the other sources returned.
+func (p *Processor) dynamicLabels(item MediaItem, tmdbID string, mediaType MediaType) (labels []string, err error) {
+	var errs []error
+	for _, source := range managedSources {
+		if !source.enabled(p) {
+			continue
+		}
+		found, err := source.labels(p, item, tmdbID, mediaType)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		labels = append(labels, found...)
+	}
+	return labels, errors.Join(errs...)
+}
+
+// unmanagedLabels returns the labels of the managed sources that extraLabels
+// adds when there is no storage to track them. Source failures are logged
+// under VERBOSE_LOGGING.
+func (p *Processor) unmanagedLabels(item MediaItem, tmdbID string, mediaType MediaType) []string {
+	if p.storage != nil {
+		return nil
+	}
+	var labels []string
+	for _, source := range managedSources {
+		if source.storageOnly || !source.enabled(p) {
+			continue
+		}
+		found, err := source.labels(p, item, tmdbID, mediaType)
+		if err != nil && p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Could not read %s labels: %v\n", source.name, err)
+		}
+		labels = append(labels, found...)
+	}
+	return labels
+}
+
+// traktLabels returns the TRAKT_LISTS label of every configured Trakt list
+// that contains the item. Lists are sorted so label order is stable. A list
+// that can't be fetched is skipped and reported in err.
+func (p *Processor) traktLabels(tmdbID string, mediaType MediaType) ([]string, error) {
+	lists := make([]string, 0, len(p.config.TraktLists))
+	for list := range p.config.TraktLists {
+		lists = append(lists, list)
+	}
+	sort.Strings(lists)
+
+	key := tmdbMediaType(mediaType) + ":" + tmdbID
+	var labels []string
+	var errs []error
+	for _, list := range lists {
+		members, err := p.listMembers("trakt:"+list, func() (map[string]bool, error) {
+			return p.traktClient.GetListItems(list)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Trakt list %s: %w", list, err))
+			continue
+		}
+		if members[key] {
+			labels = appendUnique(labels, p.config.TraktLists[list])
+		}
+	}
+	return labels, errors.Join(errs...)
+}
+
+// letterboxdLabels returns a label for every configured Letterboxd list that
+// contains the item: the LETTERBOXD_LISTS label if one was given, otherwise
+// the list's own title. A list that can't be fetched is skipped and reported
+// in err.
+func (p *Processor) letterboxdLabels(tmdbID string, mediaType MediaType) ([]string, error) {
+	refs := make([]string, 0, len(p.config.LetterboxdLists))
+	for ref := range p.config.LetterboxdLists {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	key := tmdbMediaType(mediaType) + ":" + tmdbID
+	var labels []string
+	var errs []error
+	for _, ref := range refs {
+		members, err := p.listMembers("letterboxd:"+ref, func() (map[string]bool, error) {
+			list, err := p.letterboxdClient.GetList(ref)
+			if err != nil {
+				return nil, err
+			}
+			members := make(map[string]bool, len(list.Members))
+			for _, member := range list.Members {
+				members[member] = true
+			}
+			return members, nil
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Letterboxd list %s: %w", ref, err))
+			continue
+		}
+		if !members[key] {
+			continue
+		}
+
+		label := p.config.LetterboxdLists[ref]
+		if label == "" {
+			// GetList is served from the client's cache at this point.
+			if list, err := p.letterboxdClient.GetList(ref); err == nil {
+				label = list.Title
+			}
+		}
+		if label != "" {
+			labels = appendUnique(labels, label)
+		}
+	}
+	return labels, errors.Join(errs...)
+}
+
+// mdblistLabels returns a label for every configured MDBList list that
+// contains the item: the MDBLIST_LISTS label if one was given, otherwise a
+// name derived from the list's slug. A list that can't be fetched is skipped
+// and reported in err.
+func (p *Processor) mdblistLabels(tmdbID string, mediaType MediaType) ([]string, error) {
+	lists := make([]string, 0, len(p.config.MDBListLists))
+	for list := range p.config.MDBListLists {
+		lists = append(lists, list)
+	}
+	sort.Strings(lists)
+
+	key := tmdbMediaType(mediaType) + ":" + tmdbID
+	var labels []string
+	var errs []error
+	for _, list := range lists {
+		members, err := p.listMembers("mdblist:"+list, func() (map[string]bool, error) {
+			return p.mdblistClient.GetListItems(list)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("MDBList list %s: %w", list, err))
+			continue
+		}
+		if !members[key] {
+			continue
+		}
+		label := p.config.MDBListLists[list]
+		if label == "" {
+			label = mdblist.ListName(list)
+		}
+		labels = appendUnique(labels, label)
+	}
+	return labels, errors.Join(errs...)
+}
+
+// watchLabels returns the Tautulli watch-history labels that apply to the
+// item: never watched, not watched within TAUTULLI_STALE_AFTER, and one label
+// per TAUTULLI_USER_LABELS user who has watched it. err is set when the
+// history can't be fetched.
+func (p *Processor) watchLabels(item MediaItem) ([]string, error) {
+	history, err := p.watchHistory()
+	if err != nil {
+		return nil, fmt.Errorf("Tautulli watch history: %w", err)
+	}
+
+	stats, watched := history[item.GetRatingKey()]
+	if !watched {
+		if p.config.TautulliNeverWatchedLabel != "" {
+			return []string{p.config.TautulliNeverWatchedLabel}, nil
+		}
+		return nil, nil
+	}
+
+	var labels []string
+	if p.config.TautulliStaleLabel != "" && time.Since(stats.LastWatched) > p.config.TautulliStaleAfter {
+		labels = append(labels, p.config.TautulliStaleLabel)
+	}
+
+	users := make([]string, 0, len(p.config.TautulliUserLabels))
+	for user := range p.config.TautulliUserLabels {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	for _, user := range users {
+		if stats.Users[user] {
+			labels = appendUnique(labels, p.config.TautulliUserLabels[user])
+		}
+	}
+	return labels, nil
+}
+
+// watchHistory returns the Tautulli watch stats, fetched once per processing
+// cycle.
+func (p *Processor) watchHistory() (map[string]*tautulli.WatchStats, error) {
+	p.cacheMu.RLock()
+	history := p.watchCache
+	p.cacheMu.RUnlock()
+	if history != nil {
+		return history, nil
+	}
+
+	history, err := p.tautulliClient.GetWatchStats()
+	if err != nil {
+		return nil, err
+	}
+
+	p.cacheMu.Lock()
+	p.watchCache = history
+	p.cacheMu.Unlock()
+	return history, nil
+}
+
+// reconcileDynamicLabels brings the item's lifecycle-managed labels in line
+// with dynamicLabels: newly applicable labels are added, and labels labelarr
+// added earlier that no longer apply are removed. When a source fails, its
+// labels can't be told apart from the others', so the item's managed labels
+// are all kept and only additions are made.
+func (p *Processor) reconcileDynamicLabels(item MediaItem, libraryID, tmdbID string, mediaType MediaType) {
+	if p.storage == nil || !p.hasDynamicSources() {
+		return
+	}
+
+	processed, ok := p.storage.Get(item.GetRatingKey())
+	if !ok {
+		return
+	}
+
+	desired, err := p.dynamicLabels(item, tmdbID, mediaType)
+	if err != nil {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Keeping managed labels, a source failed: %v\n", err)
+		}
+		for _, label := range processed.ManagedLabels {
+			desired = appendUnique(desired, label)
+		}
+	}
+	toAdd := missingFrom(desired, processed.ManagedLabels)
+	toRemove := missingFrom(processed.ManagedLabels, desired)
+	if len(toAdd) == 0 && len(toRemove) == 0 {
+		return
+	}
+
+	var currentValues []string
+	if len(toAdd) > 0 {
+		details, err := p.getItemDetails(item.GetRatingKey(), mediaType)
+		if err != nil {
+			fmt.Printf("[ERROR] Error fetching details for %s: %v\n", item.GetTitle(), err)
+			return
+		}
+		currentValues = p.extractCurrentValues(details)
+		if p.config.ProtectManualLabels {
+			// A label already on the item isn't labelarr's to remove later.
+			manual := missingFrom(toAdd, missingFrom(toAdd, currentValues))
+			toAdd = missingFrom(toAdd, manual)
+			desired = missingFrom(desired, manual)
+		}
+		merged := append([]string{}, currentValues...)
+		for _, label := range toAdd {
+			merged = appendUnique(merged, label)
+		}
+		if len(merged) != len(currentValues) {
+			if err := p.updateItemField(item.GetRatingKey(), libraryID, merged, mediaType); err != nil {
+				fmt.Printf("[ERROR] Error adding %v to %s: %v\n", toAdd, item.GetTitle(), err)
+				return
+			}
+		}
+	}
+
+	if len(toAdd) == 0 && len(toRemove) == 0 {
+		return
+	}
+
+	if len(toRemove) > 0 {
+		if err := p.removeItemFieldKeywords(item.GetRatingKey(), libraryID, toRemove, p.lockField(), mediaType); err != nil {
+			fmt.Printf("[ERROR] Error removing %v from %s: %v\n", toRemove, item.GetTitle(), err)
+			return
+		}
+	}
+
+	fmt.Printf("[LIFECYCLE] %s: added %v, removed %v\n", item.GetTitle(), toAdd, toRemove)
+
+	updated := *processed
+	updated.ManagedLabels = desired
+	// The field is only read when labels are added
+	var prior []string
+	if currentValues != nil {
+		prior = append([]string{}, currentValues...)
+	}
+	updated.AddHistory(storage.LabelChange{
+		At:      time.Now(),
+		Field:   p.config.UpdateField,
+		Prior:   prior,
+		Added:   missingFrom(toAdd, currentValues),
+		Removed: toRemove,
+	})
+	if err := p.storage.Set(&updated); err != nil {
+		fmt.Printf("[WARN] Failed to save managed labels to storage: %v\n", err)
+	}
+}
+
+// trendingIDs returns the TMDb trending set for the media type, fetched once
+// per processing cycle.
+func (p *Processor) trendingIDs(mediaType MediaType) (map[int]bool, error) {
+	p.cacheMu.RLock()
+	ids, ok := p.trendingCache[mediaType]
+	p.cacheMu.RUnlock()
+	if ok {
+		return ids, nil
+	}
+
+	ids, err := p.tmdbClient.GetTrendingIDs(tmdbMediaType(mediaType), p.config.TrendingWindow, p.config.TrendingPages)
+	if err != nil {
+		return nil, err
+	}
+
+	p.cacheMu.Lock()
+	p.trendingCache[mediaType] = ids
+	p.cacheMu.Unlock()
+	return ids, nil
+}
+
+// saveProcessed records the item as processed, carrying over lifecycle state
+// and history from any existing storage entry. currentValues are the field's
+// values before keywords were synced, used to tell which keywords labelarr
+// applied; removed are the values cleanup and SYNC_MODE=exact removed. For
+// untracked entries from older versions, the keywords already on the item
+// are first recorded as applied, since the version that processed it most
+// likely added them, unless PROTECT_MANUAL_LABELS is set.
+func (p *Processor) saveProcessed(item MediaItem, libraryID, tmdbID string, currentValues, keywords, removed []string) error {
+	if p.storage == nil {
+		return nil
+	}
+	processedItem := &storage.ProcessedItem{
+		RatingKey:      item.GetRatingKey(),
+		Title:          item.GetTitle(),
+		TMDbID:         tmdbID,
+		LibraryID:      libraryID,
+		LastProcessed:  time.Now(),
+		KeywordsSynced: true,
+		UpdateField:    p.config.UpdateField,
+	}
+	if existing, ok := p.storage.Get(item.GetRatingKey()); ok {
+		processedItem.ManagedLabels = existing.ManagedLabels
+		processedItem.History = existing.History
+		if existing.Untracked && !p.config.ProtectManualLabels {
+			processedItem.AddHistory(storage.LabelChange{
+				At:    existing.LastProcessed,
+				Field: p.config.UpdateField,
+				Added: missingFrom(keywords, missingFrom(keywords, currentValues)),
+			})
+		}
+	}
+	var added []string
+	for _, kw := range missingFrom(keywords, currentValues) {
+		added = appendUnique(added, kw)
+	}
+	processedItem.AddHistory(storage.LabelChange{
+		At:      processedItem.LastProcessed,
+		Field:   p.config.UpdateField,
+		Prior:   append([]string{}, currentValues...),
+		Added:   added,
+		Removed: removed,
+	})
+	return p.storage.Set(processedItem)
+}
+
+// missingFrom returns the values in a that are not in b (case-insensitive).
+func missingFrom(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, v := range b {
+		in[strings.ToLower(v)] = true
+	}
+	var out []string
+	for _, v := range a {
+		if !in[strings.ToLower(v)] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
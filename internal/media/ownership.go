@@ -0,0 +1,33 @@
+package media
+
+// PROTECT_MANUAL_LABELS restricts every operation that removes values from
+// the field (duplicate and alias cleanup, SYNC_MODE=exact, lifecycle labels
+// and REMOVE) to values storage records labelarr as having applied. Anything
+// else on the item is treated as the user's and left untouched.
+
+// ownedLabels returns the values labelarr applied to the item: those its
+// history records labelarr as having added, and its lifecycle-managed
+// labels.
+func (p *Processor) ownedLabels(ratingKey string) []string {
+	if p.storage == nil {
+		return nil
+	}
+	processed, ok := p.storage.Get(ratingKey)
+	if !ok {
+		return nil
+	}
+	owned := processed.NetChange().Added
+	return append(owned, processed.ManagedLabels...)
+}
+
+// restoreUnowned adds back the current values that cleanup dropped from
+// cleaned but that labelarr doesn't own (case-insensitive).
+func restoreUnowned(current, cleaned, owned []string) []string {
+	restored := cleaned
+	for _, value := range current {
+		if !containsFold(restored, value) && !containsFold(owned, value) {
+			restored = append(restored, value)
+		}
+	}
+	return restored
+}
@@ -0,0 +1,130 @@
+package media
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ItemLibrary is a library PROCESS_ITEM searches for its item.
+type ItemLibrary struct {
+	ID        string
+	Name      string
+	MediaType MediaType
+}
+
+// itemMatch is an item found by PROCESS_ITEM, with the library it is in.
+type itemMatch struct {
+	item    MediaItem
+	library ItemLibrary
+}
+
+// ProcessItemByQuery implements PROCESS_ITEM: it finds exactly one item in
+// libraries, processes it like a webhook event and prints which values were
+// added to and removed from the field. The query is a rating key
+// ("12345" or "plex:12345"), a TMDb ID ("tmdb:603") or a title, optionally
+// followed by the year ("The Matrix (1999)"). TMDb IDs are matched through
+// storage and tmdb:// GUIDs only, so nothing is looked up per item.
+func (p *Processor) ProcessItemByQuery(query string, libraries []ItemLibrary) error {
+	matches, err := p.findItems(query, libraries)
+	if err != nil {
+		return err
+	}
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("no item matches PROCESS_ITEM=%q", query)
+	case 1:
+	default:
+		fmt.Printf("[ERROR] PROCESS_ITEM=%q matches %d items:\n", query, len(matches))
+		for _, m := range matches {
+			fmt.Printf("  plex:%s - %s (%d) in %s\n", m.item.GetRatingKey(), m.item.GetTitle(), m.item.GetYear(), m.library.Name)
+		}
+		return fmt.Errorf("PROCESS_ITEM=%q is ambiguous; use one of the plex: keys above", query)
+	}
+
+	m := matches[0]
+	ratingKey := m.item.GetRatingKey()
+	fmt.Printf("[INFO] Found %s (%d) in %s (rating key %s)\n", m.item.GetTitle(), m.item.GetYear(), m.library.Name, ratingKey)
+
+	before, err := p.getItemDetails(ratingKey, m.library.MediaType)
+	if err != nil {
+		return fmt.Errorf("failed to fetch item details: %w", err)
+	}
+	beforeValues := p.extractCurrentValues(before)
+
+	if err := p.ProcessSingleItem(ratingKey, m.library.ID, m.library.MediaType); err != nil {
+		return err
+	}
+
+	after, err := p.getItemDetails(ratingKey, m.library.MediaType)
+	if err != nil {
+		return fmt.Errorf("failed to fetch item details after processing: %w", err)
+	}
+	afterValues := p.extractCurrentValues(after)
+
+	fmt.Printf("\n[DIFF] %s field of %s:\n", p.config.UpdateField, m.item.GetTitle())
+	fmt.Printf("  Before (%d): %v\n", len(beforeValues), beforeValues)
+	fmt.Printf("  After  (%d): %v\n", len(afterValues), afterValues)
+	fmt.Printf("  + Added:   %v\n", missingFrom(afterValues, beforeValues))
+	fmt.Printf("  - Removed: %v\n", missingFrom(beforeValues, afterValues))
+	return nil
+}
+
+// findItems returns the items in libraries that match a PROCESS_ITEM query.
+func (p *Processor) findItems(query string, libraries []ItemLibrary) ([]itemMatch, error) {
+	query = strings.TrimSpace(query)
+	lower := strings.ToLower(query)
+
+	var match func(MediaItem) bool
+	switch {
+	case strings.HasPrefix(lower, "tmdb:"):
+		id := strings.TrimSpace(query[len("tmdb:"):])
+		match = func(item MediaItem) bool { return p.knownTMDbID(item) == id }
+	case strings.HasPrefix(lower, "plex:") || isDigits(query):
+		key := strings.TrimSpace(strings.TrimPrefix(lower, "plex:"))
+		match = func(item MediaItem) bool { return item.GetRatingKey() == key }
+	default:
+		title, year := splitTitleYear(query)
+		match = func(item MediaItem) bool {
+			return strings.EqualFold(item.GetTitle(), title) && (year == 0 || item.GetYear() == year)
+		}
+	}
+
+	var matches []itemMatch
+	for _, library := range libraries {
+		items, err := p.fetchItems(library.ID, library.MediaType)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching library %s: %w", library.Name, err)
+		}
+		for _, item := range items {
+			if match(item) {
+				matches = append(matches, itemMatch{item: item, library: library})
+			}
+		}
+	}
+	return matches, nil
+}
+
+// splitTitleYear splits "Title (1999)" into its title and year. Without a
+// trailing year the whole string is the title and the year is 0.
+func splitTitleYear(s string) (string, int) {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, " ("); i > 0 && len(s)-i == len(" (1999)") && strings.HasSuffix(s, ")") {
+		if year, err := strconv.Atoi(s[i+2 : len(s)-1]); err == nil {
+			return strings.TrimSpace(s[:i]), year
+		}
+	}
+	return s, 0
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
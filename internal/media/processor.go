@@ -1,6 +1,7 @@
 package media
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"sort"
@@ -8,14 +9,30 @@ import (
 	"sync"
 	"time"
 
+	"github.com/nullable-eth/labelarr/internal/anilist"
+	"github.com/nullable-eth/labelarr/internal/animelists"
+	"github.com/nullable-eth/labelarr/internal/bazarr"
 	"github.com/nullable-eth/labelarr/internal/config"
 	"github.com/nullable-eth/labelarr/internal/export"
+	"github.com/nullable-eth/labelarr/internal/imdb"
+	"github.com/nullable-eth/labelarr/internal/justwatch"
+	"github.com/nullable-eth/labelarr/internal/letterboxd"
+	"github.com/nullable-eth/labelarr/internal/mal"
+	"github.com/nullable-eth/labelarr/internal/mappings"
+	"github.com/nullable-eth/labelarr/internal/mdblist"
+	"github.com/nullable-eth/labelarr/internal/omdb"
+	"github.com/nullable-eth/labelarr/internal/overseerr"
 	"github.com/nullable-eth/labelarr/internal/plex"
+	"github.com/nullable-eth/labelarr/internal/provider"
 	"github.com/nullable-eth/labelarr/internal/radarr"
 	"github.com/nullable-eth/labelarr/internal/sonarr"
 	"github.com/nullable-eth/labelarr/internal/storage"
+	"github.com/nullable-eth/labelarr/internal/tautulli"
 	"github.com/nullable-eth/labelarr/internal/tmdb"
+	"github.com/nullable-eth/labelarr/internal/trakt"
+	"github.com/nullable-eth/labelarr/internal/tvdb"
 	"github.com/nullable-eth/labelarr/internal/utils"
+	"github.com/nullable-eth/labelarr/internal/wikidata"
 )
 
 // MediaType represents the type of media being processed
@@ -29,10 +46,49 @@ const (
 
 // Clients groups external API clients for the processor.
 type Clients struct {
-	Plex   *plex.Client
-	TMDb   *tmdb.Client
-	Radarr *radarr.Client
-	Sonarr *sonarr.Client
+	Server     MediaServer
+	TMDb       *tmdb.Client
+	Radarr     []*radarr.Client
+	Sonarr     []*sonarr.Client
+	IMDb       *imdb.Client
+	Trakt      *trakt.Client
+	TVDb       *tvdb.Client
+	OMDb       *omdb.Client
+	Letterboxd *letterboxd.Client
+	AniList    *anilist.Client
+	AnimeMap   *animelists.Mapping
+	MAL        *mal.Client
+	MDBList    *mdblist.Client
+	Wikidata   *wikidata.Client
+	Provider   *provider.Client
+	Mappings   *mappings.File
+	Aliases    *mappings.File
+	JustWatch  *justwatch.Client
+	Tautulli   *tautulli.Client
+	Overseerr  *overseerr.Client
+	Bazarr     *bazarr.Client
+	Throttle   *utils.AdaptiveDelay
+}
+
+// MediaServer is the media-server API the processor reads items from and
+// writes labels to. Items are exchanged in Plex's shapes: *plex.Client
+// implements it directly, and other servers (see the jellyfin package)
+// convert their items to match.
+type MediaServer interface {
+	GetAllLibraries() ([]plex.Library, error)
+	GetMoviesFromLibrary(libraryID string) ([]plex.Movie, error)
+	GetMovieDetails(ratingKey string) (*plex.Movie, error)
+	GetTVShowsFromLibrary(libraryID string) ([]plex.TVShow, error)
+	GetTVShowDetails(ratingKey string) (*plex.TVShow, error)
+	GetTVShowEpisodes(ratingKey string) ([]plex.Episode, error)
+	GetAllTVShowEpisodes(ratingKey string) ([]plex.Episode, error)
+	GetItemMedia(ratingKey string) ([]plex.Media, error)
+	GetItemRef(ratingKey string) (*plex.ItemRef, error)
+	GetSimilarItems(ratingKey string) ([]plex.ItemRef, error)
+	GetRelatedItems(ratingKey string) ([]plex.ItemRef, error)
+	UpdateMediaField(mediaID, libraryID string, keywords []string, updateField string, mediaType string) error
+	SetMediaField(mediaID, libraryID string, values []string, field string, lockField bool, mediaType string) error
+	RemoveMediaFieldKeywords(mediaID, libraryID string, valuesToRemove []string, updateField string, lockField bool, mediaType string) error
 }
 
 // MediaItem interface for common media operations
@@ -44,41 +100,100 @@ type MediaItem interface {
 	GetMedia() []plex.Media
 	GetLabel() []plex.Label
 	GetGenre() []plex.Genre
+	GetCollection() []plex.Label
+	GetAddedAt() int64
 }
 
 // Processor handles media processing operations for any media type
 type Processor struct {
-	config       *config.Config
-	plexClient   *plex.Client
-	tmdbClient   *tmdb.Client
-	radarrClient *radarr.Client
-	sonarrClient *sonarr.Client
-	storage      *storage.Storage
-	exporter     *export.Exporter
-	keywordCache map[string][]string
-	cacheMu      sync.RWMutex
-	processingMu sync.Mutex
-	processing   map[string]bool
+	config           *config.Config
+	server           MediaServer
+	tmdbClient       *tmdb.Client
+	radarrClients    []*radarr.Client
+	sonarrClients    []*sonarr.Client
+	imdbClient       *imdb.Client
+	traktClient      *trakt.Client
+	tvdbClient       *tvdb.Client
+	omdbClient       *omdb.Client
+	letterboxdClient *letterboxd.Client
+	anilistClient    *anilist.Client
+	animeMapping     *animelists.Mapping
+	malClient        *mal.Client
+	mdblistClient    *mdblist.Client
+	wikidataClient   *wikidata.Client
+	ageProviders     []ageProvider
+	keywordProvider  *provider.Client
+	mappingFile      *mappings.File
+	keywordAliases   *mappings.File
+	justwatchClient  *justwatch.Client
+	tautulliClient   *tautulli.Client
+	overseerrClient  *overseerr.Client
+	bazarrClient     *bazarr.Client
+	storage          *storage.Storage
+	checkpoints      *storage.Checkpoints
+	throttle         *utils.AdaptiveDelay
+	exporter         *export.Exporter
+	keywordCache     map[string][]string
+	findCache        map[string]string
+	detailsCache     map[string]*tmdb.Details
+	changesCache     map[MediaType]map[int]bool
+	trendingCache    map[MediaType]map[int]bool
+	listCache        map[string]map[string]bool // "<source>:<list>" -> "movie:<id>" / "tv:<id>"
+	similarCache     map[string]*similarCluster // seed rating key -> cluster
+	watchCache       map[string]*tautulli.WatchStats
+	requestCache     map[string][]string // "movie:<id>" / "tv:<id>" -> requesters
+	subtitleCache    map[MediaType]map[int]bool
+	importListCache  map[int][]string          // TMDb ID -> Radarr import list names
+	episodeCache     map[string][]plex.Episode // show rating key / "all:<rating key>" -> episodes
+	keywordCounts    map[string]map[string]int // library ID -> lowercased keyword -> items; kept across cycles
+	seen             map[string]bool           // rating keys listed by the server this cycle
+	scanned          map[string]bool           // library IDs listed this cycle
+	cacheMu          sync.RWMutex
+	processingMu     sync.Mutex
+	processing       map[string]bool
 
 	// excludeLabels is the lowercased set of Plex labels that mark items as opted-out.
 	// Built once from config.ExcludeLabels in NewProcessor.
 	excludeLabels map[string]struct{}
+
+	// keywordWhitelist and keywordBlacklist are compiled from
+	// KEYWORD_WHITELIST and KEYWORD_BLACKLIST in NewProcessor, keywordPattern
+	// and keywordExclude from KEYWORD_PATTERN and KEYWORD_EXCLUDE_PATTERN.
+	keywordWhitelist *utils.PatternList
+	keywordBlacklist *utils.PatternList
+	keywordPattern   *regexp.Regexp
+	keywordExclude   *regexp.Regexp
+
+	// quietHours holds the NO_PROCESS_WINDOW windows.
+	quietHours utils.TimeWindows
+
+	// stopCh is closed by Stop so in-flight runs end after the current item.
+	stopCh   chan struct{}
+	stopOnce sync.Once
 }
 
 // NewProcessor creates a new generic media processor
 func NewProcessor(cfg *config.Config, clients Clients) (*Processor, error) {
-	plexClient := clients.Plex
+	server := clients.Server
 	tmdbClient := clients.TMDb
-	radarrClient := clients.Radarr
-	sonarrClient := clients.Sonarr
+	utils.SetFileCacheBatching(cfg.SaveEvery, cfg.SaveInterval)
+
 	// Initialize persistent storage only if DATA_DIR is set
 	var stor *storage.Storage
+	var checkpoints *storage.Checkpoints
 	if cfg.DataDir != "" {
 		var err error
 		stor, err = storage.NewStorage(cfg.DataDir)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize storage: %w", err)
 		}
+		stor.SetBatching(cfg.SaveEvery, cfg.SaveInterval)
+		if cfg.ResumeRuns {
+			checkpoints, err = storage.NewCheckpoints(cfg.DataDir)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize checkpoints: %w", err)
+			}
+		}
 	}
 
 	excludeLabels := make(map[string]struct{}, len(cfg.ExcludeLabels))
@@ -90,27 +205,109 @@ func NewProcessor(cfg *config.Config, clients Clients) (*Processor, error) {
 		excludeLabels[t] = struct{}{}
 	}
 
+	keywordWhitelist, err := utils.NewPatternList(cfg.KeywordWhitelist)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KEYWORD_WHITELIST: %w", err)
+	}
+	keywordBlacklist, err := utils.NewPatternList(cfg.KeywordBlacklist)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KEYWORD_BLACKLIST: %w", err)
+	}
+	keywordPattern, err := config.CompileKeywordPattern(cfg.KeywordPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KEYWORD_PATTERN: %w", err)
+	}
+	keywordExclude, err := config.CompileKeywordPattern(cfg.KeywordExclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KEYWORD_EXCLUDE_PATTERN: %w", err)
+	}
+	quietHours, err := utils.ParseTimeWindows(cfg.NoProcessWindows)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NO_PROCESS_WINDOW: %w", err)
+	}
+
 	processor := &Processor{
-		config:        cfg,
-		plexClient:    plexClient,
-		tmdbClient:    tmdbClient,
-		radarrClient:  radarrClient,
-		sonarrClient:  sonarrClient,
-		storage:       stor,
-		keywordCache:  make(map[string][]string),
-		processing:    make(map[string]bool),
-		excludeLabels: excludeLabels,
+		config:           cfg,
+		server:           server,
+		tmdbClient:       tmdbClient,
+		radarrClients:    clients.Radarr,
+		sonarrClients:    clients.Sonarr,
+		imdbClient:       clients.IMDb,
+		traktClient:      clients.Trakt,
+		tvdbClient:       clients.TVDb,
+		omdbClient:       clients.OMDb,
+		letterboxdClient: clients.Letterboxd,
+		anilistClient:    clients.AniList,
+		animeMapping:     clients.AnimeMap,
+		malClient:        clients.MAL,
+		mdblistClient:    clients.MDBList,
+		wikidataClient:   clients.Wikidata,
+		keywordProvider:  clients.Provider,
+		mappingFile:      clients.Mappings,
+		keywordAliases:   clients.Aliases,
+		justwatchClient:  clients.JustWatch,
+		tautulliClient:   clients.Tautulli,
+		overseerrClient:  clients.Overseerr,
+		bazarrClient:     clients.Bazarr,
+		storage:          stor,
+		checkpoints:      checkpoints,
+		throttle:         clients.Throttle,
+		keywordCache:     make(map[string][]string),
+		findCache:        make(map[string]string),
+		detailsCache:     make(map[string]*tmdb.Details),
+		changesCache:     make(map[MediaType]map[int]bool),
+		trendingCache:    make(map[MediaType]map[int]bool),
+		listCache:        make(map[string]map[string]bool),
+		similarCache:     make(map[string]*similarCluster),
+		subtitleCache:    make(map[MediaType]map[int]bool),
+		episodeCache:     make(map[string][]plex.Episode),
+		keywordCounts:    make(map[string]map[string]int),
+		seen:             make(map[string]bool),
+		scanned:          make(map[string]bool),
+		processing:       make(map[string]bool),
+		excludeLabels:    excludeLabels,
+
+		keywordWhitelist: keywordWhitelist,
+		keywordBlacklist: keywordBlacklist,
+		keywordPattern:   keywordPattern,
+		keywordExclude:   keywordExclude,
+		quietHours:       quietHours,
+		stopCh:           make(chan struct{}),
+	}
+
+	ageProviders, err := processor.newAgeProviders()
+	if err != nil {
+		return nil, err
 	}
+	processor.ageProviders = ageProviders
 
 	// Initialize exporter if export is enabled
 	if cfg.HasExportEnabled() {
-		exporter, err := export.NewExporter(cfg.ExportLocation, cfg.ExportLabels, cfg.ExportMode)
+		exporter, err := export.NewExporter(cfg.ExportLocation, cfg.ExportLabels, cfg.ExportExclude, cfg.ExportMode)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize exporter: %w", err)
 		}
+		if cfg.ExportTemplate != "" {
+			if err := exporter.SetTemplate(cfg.ExportTemplate); err != nil {
+				return nil, fmt.Errorf("failed to initialize exporter: %w", err)
+			}
+		}
+		exporter.SetIncludeMetadata(cfg.ExportMetadata)
+		exporter.SetPathMappings(cfg.ExportPathMappings)
+		exporter.SetPlaylistPathMappings(cfg.ExportM3UPathMappings)
+		exporter.SetDiff(cfg.ExportDiff)
+		exporter.SetLinkType(cfg.ExportLinkType)
+		exporter.SetSnapshots(cfg.ExportKeep)
+		exporter.SetGzip(cfg.ExportGzip)
+		exporter.SetSplitLibraries(cfg.ExportSplit)
+		exporter.SetSort(cfg.ExportSort)
+		exporter.SetStream(cfg.ExportStream)
 		processor.exporter = exporter
 
 		fmt.Printf("[EXPORT] Export enabled: Writing file paths for labels %v to %s\n", cfg.ExportLabels, cfg.ExportLocation)
+		if len(cfg.ExportExclude) > 0 {
+			fmt.Printf("[EXPORT] Also writing file paths for items without labels %v\n", cfg.ExportExclude)
+		}
 	}
 
 	// Log storage initialization
@@ -123,6 +320,10 @@ func NewProcessor(cfg *config.Config, clients Clients) (*Processor, error) {
 		fmt.Printf("[SYNC] Running in ephemeral mode - no persistent storage (set DATA_DIR to enable)\n")
 	}
 
+	if processor.hasDynamicSources() && stor == nil {
+		fmt.Printf("[WARN] Lifecycle-managed labels (e.g. TRENDING_LABEL) require DATA_DIR to track what labelarr applied; they will not be added or expired\n")
+	}
+
 	if len(excludeLabels) > 0 {
 		labels := make([]string, 0, len(excludeLabels))
 		for l := range excludeLabels {
@@ -150,6 +351,15 @@ func (p *Processor) isExcludedByLabel(item MediaItem) (string, bool) {
 	return "", false
 }
 
+// itemDelay returns the pause between items: the ADAPTIVE_DELAY delay when
+// one is set, otherwise ITEM_DELAY.
+func (p *Processor) itemDelay() time.Duration {
+	if p.throttle != nil {
+		return p.throttle.Delay()
+	}
+	return p.config.ItemDelay
+}
+
 // GetExporter returns the exporter instance if export is enabled
 func (p *Processor) GetExporter() *export.Exporter {
 	return p.exporter
@@ -198,23 +408,65 @@ func (p *Processor) pauseAfterBatch(b batch, label string) {
 	if b.total > 1 && b.num < b.total-1 {
 		fmt.Printf("%s batch %d complete. Pausing %v before next batch...\n",
 			label, b.num+1, p.config.BatchDelay)
-		time.Sleep(p.config.BatchDelay)
+		p.sleep(p.config.BatchDelay)
 	}
 }
 
-// ClearCaches resets the TMDb keyword cache and Radarr/Sonarr library caches.
+// ClearCaches resets the TMDb keyword/details caches and Radarr/Sonarr library caches.
 // Call at the start of each processing cycle so data is refreshed periodically.
 func (p *Processor) ClearCaches() {
 	p.cacheMu.Lock()
 	p.keywordCache = make(map[string][]string)
+	p.findCache = make(map[string]string)
+	p.detailsCache = make(map[string]*tmdb.Details)
+	p.changesCache = make(map[MediaType]map[int]bool)
+	p.trendingCache = make(map[MediaType]map[int]bool)
+	p.listCache = make(map[string]map[string]bool)
+	p.similarCache = make(map[string]*similarCluster)
+	p.watchCache = nil
+	p.requestCache = nil
+	p.importListCache = nil
+	p.subtitleCache = make(map[MediaType]map[int]bool)
+	p.episodeCache = make(map[string][]plex.Episode)
+	p.seen = make(map[string]bool)
+	p.scanned = make(map[string]bool)
 	p.cacheMu.Unlock()
 
-	if p.radarrClient != nil {
-		p.radarrClient.ClearCache()
+	for _, client := range p.radarrClients {
+		client.ClearCache()
+	}
+	for _, client := range p.sonarrClients {
+		client.ClearCache()
+	}
+}
+
+// filterKeywords drops keywords that KEYWORD_WHITELIST or KEYWORD_PATTERN
+// don't match, when set, and keywords that KEYWORD_BLACKLIST or
+// KEYWORD_EXCLUDE_PATTERN match.
+func (p *Processor) filterKeywords(keywords []string) []string {
+	if p.keywordWhitelist.Len() == 0 && p.keywordBlacklist.Len() == 0 && p.keywordPattern == nil && p.keywordExclude == nil {
+		return keywords
+	}
+	kept := make([]string, 0, len(keywords))
+	for _, kw := range keywords {
+		if p.keywordWhitelist.Len() > 0 && !p.keywordWhitelist.Match(kw) {
+			continue
+		}
+		if p.keywordBlacklist.Match(kw) {
+			continue
+		}
+		if p.keywordPattern != nil && !p.keywordPattern.MatchString(kw) {
+			continue
+		}
+		if p.keywordExclude != nil && p.keywordExclude.MatchString(kw) {
+			continue
+		}
+		kept = append(kept, kw)
 	}
-	if p.sonarrClient != nil {
-		p.sonarrClient.ClearCache()
+	if p.config.VerboseLogging && len(kept) < len(keywords) {
+		fmt.Printf("   [FILTER] Dropped %d of %d keywords by keyword filters\n", len(keywords)-len(kept), len(keywords))
 	}
+	return kept
 }
 
 func (p *Processor) applyKeywordPrefix(keywords []string) []string {
@@ -264,17 +516,18 @@ func (p *Processor) ProcessSingleItem(ratingKey, libraryID string, mediaType Med
 
 	switch mediaType {
 	case MediaTypeMovie:
-		movie, err := p.plexClient.GetMovieDetails(ratingKey)
+		movie, err := p.server.GetMovieDetails(ratingKey)
 		if err != nil {
 			return fmt.Errorf("failed to fetch movie %s: %w", ratingKey, err)
 		}
 		item = movie
 	case MediaTypeTV:
-		show, err := p.plexClient.GetTVShowDetails(ratingKey)
+		show, err := p.server.GetTVShowDetails(ratingKey)
 		if err != nil {
 			return fmt.Errorf("failed to fetch TV show %s: %w", ratingKey, err)
 		}
 		item = show
+		p.forgetEpisodes(ratingKey)
 	default:
 		return fmt.Errorf("unsupported media type: %s", mediaType)
 	}
@@ -286,24 +539,28 @@ func (p *Processor) ProcessSingleItem(ratingKey, libraryID string, mediaType Med
 		return nil
 	}
 
-	tmdbID := p.extractTMDbID(item, mediaType)
-	if tmdbID == "" {
+	tmdbID, source, ok := p.resolveItemID(item, libraryID, mediaType)
+	if !ok {
 		fmt.Printf("[SKIP] No TMDb ID found for: %s\n", item.GetTitle())
 		return nil
 	}
 
-	keywords, err := p.getKeywords(tmdbID, mediaType)
+	if p.isFilteredByArrTags(item, tmdbID, mediaType) {
+		fmt.Printf("[SKIP] %s (%d) filtered out by *arr tags (RADARR_TAG_FILTER/SONARR_TAG_FILTER)\n", item.GetTitle(), item.GetYear())
+		return nil
+	}
+
+	keywords, err := p.buildLabels(item, libraryID, tmdbID, mediaType)
 	if err != nil {
 		return fmt.Errorf("failed to fetch keywords for TMDb ID %s: %w", tmdbID, err)
 	}
 
-	keywords = p.applyKeywordPrefix(keywords)
-
 	details, err := p.getItemDetails(item.GetRatingKey(), mediaType)
 	if err != nil {
 		return fmt.Errorf("failed to fetch item details: %w", err)
 	}
 
+	p.seedGenres(details, libraryID, tmdbID, mediaType)
 	currentValues := p.extractCurrentValues(details)
 
 	currentValuesMap := make(map[string]bool)
@@ -319,16 +576,20 @@ func (p *Processor) ProcessSingleItem(ratingKey, libraryID string, mediaType Med
 		}
 	}
 
-	if allExist && !p.config.ForceUpdate {
+	stale := p.staleKeywords(item, currentValues, keywords)
+
+	if allExist && len(stale) == 0 && !p.config.ForceUpdate {
 		fmt.Printf("[OK] %s already has all %d keywords\n", item.GetTitle(), len(keywords))
+		p.syncRadarr(item, details, libraryID, tmdbID, mediaType, nil)
+		p.pushKeywordTags(item, tmdbID, mediaType, keywords)
 		return nil
 	}
 
-	source := p.getTMDbIDSource(item, mediaType, tmdbID)
-	fmt.Printf("[KEY] TMDb ID: %s (source: %s)\n", tmdbID, source)
+	fmt.Println(p.idSourceLine(item, libraryID, mediaType, tmdbID, source))
 	fmt.Printf("[SYNC] Applying %d keywords to %s field for %s\n", len(keywords), p.config.UpdateField, item.GetTitle())
 
-	if err := p.syncFieldWithKeywords(item.GetRatingKey(), libraryID, currentValues, keywords, mediaType); err != nil {
+	cleaned, err := p.syncFieldWithKeywords(item.GetRatingKey(), libraryID, currentValues, keywords, mediaType)
+	if err != nil {
 		return fmt.Errorf("failed to sync keywords for %s: %w", item.GetTitle(), err)
 	}
 
@@ -339,29 +600,31 @@ func (p *Processor) ProcessSingleItem(ratingKey, libraryID string, mediaType Med
 		mergedLabels := append(currentValues, keywords...)
 		fileInfos, err := p.extractFileInfos(details, mediaType)
 		if err == nil && len(fileInfos) > 0 {
-			if err := p.exporter.ExportItemWithSizes(item.GetTitle(), mergedLabels, fileInfos); err != nil {
+			if err := p.exportItem(item, details, tmdbID, mergedLabels, fileInfos); err != nil {
 				fmt.Printf("[WARN] Export failed for %s: %v\n", item.GetTitle(), err)
 			}
 		}
 	}
 
-	if p.storage != nil {
-		processedItem := &storage.ProcessedItem{
-			RatingKey:      item.GetRatingKey(),
-			Title:          item.GetTitle(),
-			TMDbID:         tmdbID,
-			LastProcessed:  time.Now(),
-			KeywordsSynced: true,
-			UpdateField:    p.config.UpdateField,
-		}
-		if err := p.storage.Set(processedItem); err != nil {
-			fmt.Printf("[WARN] Failed to save processed item to storage: %v\n", err)
-		}
+	removed := append(cleaned, p.removeStaleKeywords(item, libraryID, stale, mediaType)...)
+
+	if err := p.saveProcessed(item, libraryID, tmdbID, currentValues, keywords, removed); err != nil {
+		fmt.Printf("[WARN] Failed to save processed item to storage: %v\n", err)
 	}
 
+	p.reconcileDynamicLabels(item, libraryID, tmdbID, mediaType)
+	p.syncRadarr(item, details, libraryID, tmdbID, mediaType, keywords)
+	p.pushKeywordTags(item, tmdbID, mediaType, keywords)
+
 	return nil
 }
 
+// ErrItemsFailed is returned (wrapped) by ProcessAllItems when some items
+// could not be synced; the rest of the library was still processed.
+var ErrItemsFailed = errors.New("items failed to sync")
+
+// ProcessAllItems syncs every item of a library. It returns ErrStopped if
+// shutdown ended the run early, and ErrItemsFailed if any item failed.
 func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaType MediaType) error {
 	// Unlike ProcessSingleItem, this path skips when the library is busy:
 	// the timer will re-fire on the next cycle, so waiting here would only
@@ -405,6 +668,8 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 		return fmt.Errorf("error fetching %s: %w", displayName, err)
 	}
 
+	p.markSeen(libraryID, items)
+
 	if len(items) == 0 {
 		fmt.Printf("[ERROR] No %s found in library!\n", displayName)
 		return nil
@@ -413,8 +678,14 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 	totalCount := len(items)
 	fmt.Printf("[OK] Found %d %s in library\n", totalCount, displayName)
 
+	if p.config.KeywordMinItems > 1 || p.rankByFrequency() {
+		p.countLibraryKeywords(libraryID, items, mediaType)
+	}
+
 	if p.config.ForceUpdate {
 		fmt.Printf("[SYNC] FORCE UPDATE MODE: All items will be reprocessed regardless of previous processing\n")
+	} else if p.config.ChangeDetection && p.storage != nil {
+		fmt.Printf("[CHANGES] Change detection enabled: processed items are rechecked when TMDb reports keyword changes\n")
 	}
 
 	if p.config.VerboseLogging {
@@ -427,16 +698,29 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 	updatedItems := 0
 	skippedItems := 0
 	skippedAlreadyExist := 0
+	failedItems := 0
 
 	// Progress tracking
-	processedCount := 0
+	start := p.resumeIndex(libraryID, items)
+	progress := newRunProgress(totalCount)
+	progress.resume(start)
 	lastProgressReport := 0
+	lastRatingKey := ""
+	stopped := false
+	idSources := make(map[string]int)
 
-	for _, b := range p.makeBatches(items) {
-		b.logStart(emoji+" Processing", len(items))
+batches:
+	for _, b := range p.makeBatches(items[start:]) {
+		b.logStart(emoji+" Processing", len(items)-start)
 
 		for _, item := range b.items {
-			processedCount++
+			if p.Stopped() {
+				stopped = true
+				p.saveCheckpoint(libraryID, lastRatingKey, progress.done, totalCount)
+				break batches
+			}
+			progress.done++
+			lastRatingKey = item.GetRatingKey()
 
 			if tag, skipExcl := p.isExcludedByLabel(item); skipExcl {
 				if p.config.VerboseLogging {
@@ -447,16 +731,24 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 			}
 
 			if totalCount > 100 {
-				progress := (processedCount * 100) / totalCount
-				if progress >= lastProgressReport+10 {
-					fmt.Printf("[STATS] Progress: %d%% (%d/%d %s processed)\n", progress, processedCount, totalCount, displayName)
-					lastProgressReport = progress
+				percent := (progress.done * 100) / totalCount
+				if percent >= lastProgressReport+10 {
+					fmt.Printf("[STATS] Progress: %s\n", progress.progressLine(displayName))
+					lastProgressReport = percent
 				}
 			}
 			var exists bool
 			if p.storage != nil {
 				processed, storageExists := p.storage.Get(item.GetRatingKey())
-				if storageExists && processed.KeywordsSynced && processed.UpdateField == p.config.UpdateField && !p.config.ForceUpdate {
+				if storageExists && processed.KeywordsSynced && processed.UpdateField == p.config.UpdateField && !p.config.ForceUpdate && !p.keywordsChanged(processed, mediaType) {
+					if p.isFilteredByArrTags(item, processed.TMDbID, mediaType) {
+						if p.config.VerboseLogging {
+							fmt.Printf("   [SKIP] %s (%d) filtered out by *arr tags\n", item.GetTitle(), item.GetYear())
+						}
+						skippedItems++
+						continue
+					}
+
 					if p.exporter != nil {
 						details, err := p.getItemDetails(item.GetRatingKey(), mediaType)
 						if err == nil {
@@ -464,7 +756,7 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 
 							fileInfos, err := p.extractFileInfos(details, mediaType)
 							if err == nil && len(fileInfos) > 0 {
-								if err := p.exporter.ExportItemWithSizes(item.GetTitle(), currentLabels, fileInfos); err == nil {
+								if err := p.exportItem(item, details, processed.TMDbID, currentLabels, fileInfos); err == nil {
 									if p.config.VerboseLogging {
 										fmt.Printf("   [EXPORT] Accumulated %d file paths for %s (already processed)\n", len(fileInfos), item.GetTitle())
 									}
@@ -473,6 +765,9 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 						}
 					}
 
+					p.reconcileDynamicLabels(item, libraryID, processed.TMDbID, mediaType)
+					p.syncRadarr(item, nil, libraryID, processed.TMDbID, mediaType, nil)
+
 					skippedItems++
 					skippedAlreadyExist++
 					continue
@@ -480,8 +775,9 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 				exists = storageExists
 			}
 
-			tmdbID := p.extractTMDbID(item, mediaType)
-			if tmdbID == "" {
+			tmdbID, source, ok := p.resolveItemID(item, libraryID, mediaType)
+			idSources[source]++
+			if !ok {
 				if p.exporter != nil {
 					details, err := p.getItemDetails(item.GetRatingKey(), mediaType)
 					if err == nil {
@@ -489,7 +785,7 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 
 						fileInfos, err := p.extractFileInfos(details, mediaType)
 						if err == nil && len(fileInfos) > 0 {
-							if err := p.exporter.ExportItemWithSizes(item.GetTitle(), currentLabels, fileInfos); err == nil {
+							if err := p.exportItem(item, details, "", currentLabels, fileInfos); err == nil {
 								if p.config.VerboseLogging {
 									fmt.Printf("   [EXPORT] Accumulated %d file paths for %s (no TMDb ID)\n", len(fileInfos), item.GetTitle())
 								}
@@ -505,30 +801,40 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 				continue
 			}
 
-			keywords, err := p.getKeywords(tmdbID, mediaType)
+			if p.isFilteredByArrTags(item, tmdbID, mediaType) {
+				if p.config.VerboseLogging {
+					fmt.Printf("   [SKIP] %s (%d) filtered out by *arr tags\n", item.GetTitle(), item.GetYear())
+				}
+				skippedItems++
+				continue
+			}
+
+			fetchStart := time.Now()
+			keywords, err := p.buildLabels(item, libraryID, tmdbID, mediaType)
+			progress.timeFetch(fetchStart)
 			if err != nil {
 				if p.config.VerboseLogging {
 					fmt.Printf("   [ERROR] Error fetching keywords for TMDb ID %s: %v\n", tmdbID, err)
 				}
-				skippedItems++
+				failedItems++
 				continue
 			}
 
 			if p.config.VerboseLogging {
-				fmt.Printf("   [FETCH] Fetched %d keywords from TMDb: %v\n", len(keywords), keywords)
+				fmt.Printf("   [FETCH] Fetched %d keywords/labels: %v\n", len(keywords), keywords)
 			}
 
-			keywords = p.applyKeywordPrefix(keywords)
-
 			details, err := p.getItemDetails(item.GetRatingKey(), mediaType)
 			if err != nil {
 				if p.config.VerboseLogging {
 					fmt.Printf("   [ERROR] Error fetching item details: %v\n", err)
 				}
-				skippedItems++
+				failedItems++
 				continue
 			}
 
+			p.seedGenres(details, libraryID, tmdbID, mediaType)
+
 			currentValues := p.extractCurrentValues(details)
 			if p.config.VerboseLogging {
 				fmt.Printf("   [INFO] Current %ss in Plex: %v\n", p.config.UpdateField, currentValues)
@@ -548,7 +854,9 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 				}
 			}
 
-			if allKeywordsExist && !p.config.ForceUpdate {
+			stale := p.staleKeywords(item, currentValues, keywords)
+
+			if allKeywordsExist && len(stale) == 0 && !p.config.ForceUpdate {
 				// Silently skip - no verbose output
 				if p.config.VerboseLogging {
 					fmt.Printf("   [OK] Already has all keywords, skipping\n")
@@ -564,7 +872,7 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 							fmt.Printf("   [WARN] Warning: Could not extract file paths for export: %v\n", err)
 						}
 					} else if len(fileInfos) > 0 {
-						if err := p.exporter.ExportItemWithSizes(item.GetTitle(), currentLabels, fileInfos); err != nil {
+						if err := p.exportItem(item, details, tmdbID, currentLabels, fileInfos); err != nil {
 							if p.config.VerboseLogging {
 								fmt.Printf("   [WARN] Warning: Export accumulation failed for %s: %v\n", item.GetTitle(), err)
 							}
@@ -574,6 +882,9 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 					}
 				}
 
+				p.syncRadarr(item, details, libraryID, tmdbID, mediaType, nil)
+				p.pushKeywordTags(item, tmdbID, mediaType, keywords)
+
 				skippedItems++
 				skippedAlreadyExist++
 				continue
@@ -593,8 +904,7 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 				fmt.Printf("\n%s Processing new %s: %s (%d)\n", emoji, strings.TrimSuffix(displayName, "s"), item.GetTitle(), item.GetYear())
 
 				// Show source of TMDb ID
-				source := p.getTMDbIDSource(item, mediaType, tmdbID)
-				fmt.Printf("[KEY] TMDb ID: %s (source: %s)\n", tmdbID, source)
+				fmt.Println(p.idSourceLine(item, libraryID, mediaType, tmdbID, source))
 				fmt.Printf("[LABEL] Found %d TMDb keywords\n", len(keywords))
 			}
 
@@ -606,13 +916,15 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 				}
 			}
 
-			err = p.syncFieldWithKeywords(item.GetRatingKey(), libraryID, currentValues, keywords, mediaType)
+			writeStart := time.Now()
+			cleaned, err := p.syncFieldWithKeywords(item.GetRatingKey(), libraryID, currentValues, keywords, mediaType)
+			progress.timeWrite(writeStart)
 			if err != nil {
 				// Show error even for existing items since it's important
 				if exists {
 					fmt.Printf("[ERROR] Error syncing %s for %s: %v\n", p.config.UpdateField, item.GetTitle(), err)
 				}
-				skippedItems++
+				failedItems++
 				continue
 			}
 
@@ -628,7 +940,7 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 						fmt.Printf("   [WARN] Could not extract file paths for export: %v\n", err)
 					}
 				} else if len(fileInfos) > 0 {
-					if err := p.exporter.ExportItemWithSizes(item.GetTitle(), mergedLabels, fileInfos); err != nil {
+					if err := p.exportItem(item, details, tmdbID, mergedLabels, fileInfos); err != nil {
 						if p.config.VerboseLogging {
 							fmt.Printf("   [WARN] Export accumulation failed for %s: %v\n", item.GetTitle(), err)
 						}
@@ -638,21 +950,16 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 				}
 			}
 
-			if p.storage != nil {
-				processedItem := &storage.ProcessedItem{
-					RatingKey:      item.GetRatingKey(),
-					Title:          item.GetTitle(),
-					TMDbID:         tmdbID,
-					LastProcessed:  time.Now(),
-					KeywordsSynced: true,
-					UpdateField:    p.config.UpdateField,
-				}
+			removed := append(cleaned, p.removeStaleKeywords(item, libraryID, stale, mediaType)...)
 
-				if err := p.storage.Set(processedItem); err != nil {
-					fmt.Printf("[WARN] Warning: Failed to save processed item to storage: %v\n", err)
-				}
+			if err := p.saveProcessed(item, libraryID, tmdbID, currentValues, keywords, removed); err != nil {
+				fmt.Printf("[WARN] Warning: Failed to save processed item to storage: %v\n", err)
 			}
 
+			p.reconcileDynamicLabels(item, libraryID, tmdbID, mediaType)
+			p.syncRadarr(item, details, libraryID, tmdbID, mediaType, keywords)
+			p.pushKeywordTags(item, tmdbID, mediaType, keywords)
+
 			if exists {
 				updatedItems++
 			} else {
@@ -660,11 +967,15 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 				fmt.Printf("[OK] Successfully processed new %s: %s\n", strings.TrimSuffix(displayName, "s"), item.GetTitle())
 			}
 
-			time.Sleep(p.config.ItemDelay)
+			p.sleep(p.itemDelay())
 		}
 
+		p.saveCheckpoint(libraryID, lastRatingKey, progress.done, totalCount)
 		p.pauseAfterBatch(b, emoji+" Processing")
 	}
+	if !stopped {
+		p.clearCheckpoint(libraryID)
+	}
 
 	if p.config.VerboseLogging && skippedItems > 10 {
 		fmt.Printf("   ... and %d more items skipped\n", skippedItems-10)
@@ -675,9 +986,20 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 	fmt.Printf("  [NEW] New %s processed: %d\n", displayName, newItems)
 	fmt.Printf("  [SYNC] Updated %s: %d\n", displayName, updatedItems)
 	fmt.Printf("  [SKIP] Skipped %s: %d\n", displayName, skippedItems)
+	if failedItems > 0 {
+		fmt.Printf("  [ERROR] Failed %s: %d\n", displayName, failedItems)
+	}
 	if skippedAlreadyExist > 0 {
 		fmt.Printf("  [OK] Already have all keywords: %d\n", skippedAlreadyExist)
 	}
+	printIDSources(idSources)
+	progress.printSummary()
+	if p.throttle != nil {
+		fmt.Printf("  [TIMER] Adaptive item delay now: %s\n", p.throttle.Delay().Round(time.Millisecond))
+	}
+	if stopped {
+		fmt.Printf("[CHECKPOINT] %s stopped by shutdown after %d/%d %s (last rating key %s)\n", libraryName, progress.done, totalCount, displayName, lastRatingKey)
+	}
 
 	if p.exporter != nil {
 		librarySummary, err := p.exporter.GetLibraryExportSummary()
@@ -699,7 +1021,14 @@ func (p *Processor) ProcessAllItems(libraryID string, libraryName string, mediaT
 		}
 	}
 
-	return nil
+	var errs []error
+	if stopped {
+		errs = append(errs, ErrStopped)
+	}
+	if failedItems > 0 {
+		errs = append(errs, fmt.Errorf("%w: %d of %d %s", ErrItemsFailed, failedItems, totalCount, displayName))
+	}
+	return errors.Join(errs...)
 }
 
 // RemoveKeywordsFromItems removes TMDb keywords from all items in the specified library
@@ -716,6 +1045,10 @@ func (p *Processor) RemoveKeywordsFromItems(libraryID string, mediaType MediaTyp
 		return fmt.Errorf("unsupported media type: %s", mediaType)
 	}
 
+	if p.config.RemoveMode == "applied" {
+		return p.rollbackItems(libraryID, displayName, emoji, mediaType)
+	}
+
 	fmt.Printf("\n[INFO] Fetching all %s for keyword removal...\n", displayName)
 
 	items, err := p.fetchItems(libraryID, mediaType)
@@ -754,8 +1087,8 @@ func (p *Processor) RemoveKeywordsFromItems(libraryID string, mediaType MediaTyp
 				continue
 			}
 
-			tmdbID := p.extractTMDbID(item, mediaType)
-			if tmdbID == "" {
+			tmdbID, _, ok := p.resolveItemID(item, libraryID, mediaType)
+			if !ok {
 				skippedCount++
 				continue
 			}
@@ -774,22 +1107,28 @@ func (p *Processor) RemoveKeywordsFromItems(libraryID string, mediaType MediaTyp
 				continue
 			}
 
-			keywords, err := p.getKeywords(tmdbID, mediaType)
+			keywords, err := p.buildLabels(item, libraryID, tmdbID, mediaType)
 			if err != nil {
 				keywords = []string{}
 			}
 
-			keywords = p.applyKeywordPrefix(keywords)
-
 			keywordMap := make(map[string]bool)
 			for _, keyword := range keywords {
 				keywordMap[strings.ToLower(keyword)] = true
 			}
 
+			var owned map[string]bool
+			if p.config.ProtectManualLabels {
+				owned = make(map[string]bool)
+				for _, value := range p.ownedLabels(item.GetRatingKey()) {
+					owned[strings.ToLower(value)] = true
+				}
+			}
+
 			var valuesToRemove []string
 			foundTMDbKeywords := false
 			for _, value := range currentValues {
-				if keywordMap[strings.ToLower(value)] {
+				if keywordMap[strings.ToLower(value)] && (owned == nil || owned[strings.ToLower(value)]) {
 					foundTMDbKeywords = true
 					valuesToRemove = append(valuesToRemove, value)
 				}
@@ -804,8 +1143,7 @@ func (p *Processor) RemoveKeywordsFromItems(libraryID string, mediaType MediaTyp
 			fmt.Printf("[KEY] TMDb ID: %s\n", tmdbID)
 			fmt.Printf("[REMOVE] Removing %d TMDb keywords from %s field\n", len(valuesToRemove), p.config.UpdateField)
 
-			lockField := p.config.RemoveMode == "lock"
-			err = p.removeItemFieldKeywords(item.GetRatingKey(), libraryID, valuesToRemove, lockField, mediaType)
+			err = p.removeItemFieldKeywords(item.GetRatingKey(), libraryID, valuesToRemove, p.lockField(), mediaType)
 			if err != nil {
 				fmt.Printf("[ERROR] Error removing keywords from %s: %v\n", item.GetTitle(), err)
 				skippedCount++
@@ -816,7 +1154,7 @@ func (p *Processor) RemoveKeywordsFromItems(libraryID string, mediaType MediaTyp
 			removedCount++
 			fmt.Printf("[OK] Successfully removed keywords from %s\n", item.GetTitle())
 
-			time.Sleep(p.config.ItemDelay)
+			p.sleep(p.itemDelay())
 		}
 
 		p.pauseAfterBatch(b, emoji+" Removal")
@@ -844,7 +1182,7 @@ func (p *Processor) RemoveKeywordsFromItems(libraryID string, mediaType MediaTyp
 func (p *Processor) fetchItems(libraryID string, mediaType MediaType) ([]MediaItem, error) {
 	switch mediaType {
 	case MediaTypeMovie:
-		movies, err := p.plexClient.GetMoviesFromLibrary(libraryID)
+		movies, err := p.server.GetMoviesFromLibrary(libraryID)
 		if err != nil {
 			return nil, err
 		}
@@ -855,7 +1193,7 @@ func (p *Processor) fetchItems(libraryID string, mediaType MediaType) ([]MediaIt
 		return items, nil
 
 	case MediaTypeTV:
-		tvShows, err := p.plexClient.GetTVShowsFromLibrary(libraryID)
+		tvShows, err := p.server.GetTVShowsFromLibrary(libraryID)
 		if err != nil {
 			return nil, err
 		}
@@ -874,14 +1212,14 @@ func (p *Processor) fetchItems(libraryID string, mediaType MediaType) ([]MediaIt
 func (p *Processor) getItemDetails(ratingKey string, mediaType MediaType) (MediaItem, error) {
 	switch mediaType {
 	case MediaTypeMovie:
-		movie, err := p.plexClient.GetMovieDetails(ratingKey)
+		movie, err := p.server.GetMovieDetails(ratingKey)
 		if err != nil {
 			return nil, err
 		}
 		return *movie, nil
 
 	case MediaTypeTV:
-		tvShow, err := p.plexClient.GetTVShowDetails(ratingKey)
+		tvShow, err := p.server.GetTVShowDetails(ratingKey)
 		if err != nil {
 			return nil, err
 		}
@@ -905,6 +1243,26 @@ func (p *Processor) getKeywords(tmdbID string, mediaType MediaType) ([]string, e
 
 	var keywords []string
 	var err error
+
+	// When another source needs the details record anyway, take keywords
+	// from its appended section instead of a separate request.
+	if p.needsDetails() {
+		if details, detailsErr := p.getDetails(tmdbID, mediaType); detailsErr == nil {
+			appended, ok, err := p.tmdbClient.KeywordsFromDetails(details)
+			if err != nil {
+				// Fall back to the keywords endpoint
+				if p.config.VerboseLogging {
+					fmt.Printf("   [WARN] Could not read keywords from TMDb details: %v\n", err)
+				}
+			} else if ok {
+				p.cacheMu.Lock()
+				p.keywordCache[cacheKey] = appended
+				p.cacheMu.Unlock()
+				return appended, nil
+			}
+		}
+	}
+
 	switch mediaType {
 	case MediaTypeMovie:
 		keywords, err = p.tmdbClient.GetMovieKeywords(tmdbID)
@@ -924,17 +1282,24 @@ func (p *Processor) getKeywords(tmdbID string, mediaType MediaType) ([]string, e
 }
 
 // syncFieldWithKeywords synchronizes the configured field with TMDb keywords
-func (p *Processor) syncFieldWithKeywords(itemID, libraryID string, currentValues []string, keywords []string, mediaType MediaType) error {
+// and returns the current values it cleaned up.
+func (p *Processor) syncFieldWithKeywords(itemID, libraryID string, currentValues []string, keywords []string, mediaType MediaType) ([]string, error) {
 	// Clean duplicates: remove old unnormalized versions when normalized versions are present
 	// This helps clean up cases like having both "sci-fi" and "Sci-Fi"
-	cleanedValues := utils.CleanDuplicateKeywords(currentValues, keywords)
+	cleanedValues := p.dropAliasedValues(utils.CleanDuplicateKeywords(currentValues, keywords), keywords)
+	if p.config.ProtectManualLabels {
+		cleanedValues = restoreUnowned(currentValues, cleanedValues, p.ownedLabels(itemID))
+	}
 
 	if p.config.VerboseLogging && len(cleanedValues) != len(currentValues) {
 		removedCount := len(currentValues) - len(cleanedValues) + len(keywords)
 		fmt.Printf("   [CLEAN] Cleaned %d duplicate/unnormalized keywords\n", removedCount)
 	}
 
-	return p.updateItemField(itemID, libraryID, cleanedValues, mediaType)
+	if err := p.updateItemField(itemID, libraryID, cleanedValues, mediaType); err != nil {
+		return nil, err
+	}
+	return missingFrom(currentValues, cleanedValues), nil
 }
 
 // toPlexMediaType converts MediaType to the string format expected by plex client
@@ -956,7 +1321,13 @@ func (p *Processor) updateItemField(itemID, libraryID string, keywords []string,
 		return err
 	}
 
-	return p.plexClient.UpdateMediaField(itemID, libraryID, keywords, p.config.UpdateField, plexMediaType)
+	return p.server.UpdateMediaField(itemID, libraryID, keywords, p.config.UpdateField, plexMediaType)
+}
+
+// lockField reports whether removals lock the field, like the writes of a
+// normal sync do. Only REMOVE=unlock hands the field back to the server.
+func (p *Processor) lockField() bool {
+	return p.config.RemoveMode != "unlock"
 }
 
 // removeItemFieldKeywords removes specific keywords from the configured field based on media type
@@ -966,12 +1337,24 @@ func (p *Processor) removeItemFieldKeywords(itemID, libraryID string, valuesToRe
 		return err
 	}
 
-	return p.plexClient.RemoveMediaFieldKeywords(itemID, libraryID, valuesToRemove, p.config.UpdateField, lockField, plexMediaType)
+	return p.server.RemoveMediaFieldKeywords(itemID, libraryID, valuesToRemove, p.config.UpdateField, lockField, plexMediaType)
 }
 
 // extractCurrentValues extracts current values from the configured field
 func (p *Processor) extractCurrentValues(item MediaItem) []string {
-	switch strings.ToLower(p.config.UpdateField) {
+	return fieldValues(item, p.config.UpdateField)
+}
+
+// fieldValues returns the tags in the item's label, genre or collection field
+func fieldValues(item MediaItem, field string) []string {
+	switch strings.ToLower(field) {
+	case "collection":
+		collections := item.GetCollection()
+		values := make([]string, len(collections))
+		for i, collection := range collections {
+			values[i] = collection.Tag
+		}
+		return values
 	case "label":
 		labels := item.GetLabel()
 		values := make([]string, len(labels))
@@ -991,251 +1374,123 @@ func (p *Processor) extractCurrentValues(item MediaItem) []string {
 	}
 }
 
-// extractTMDbID extracts TMDb ID using the appropriate strategy for each media type
-func (p *Processor) extractTMDbID(item MediaItem, mediaType MediaType) string {
-	switch mediaType {
-	case MediaTypeMovie:
-		return p.extractMovieTMDbID(item)
-	case MediaTypeTV:
-		return p.extractTVShowTMDbID(item)
-	default:
-		return ""
-	}
-}
-
-// extractMovieTMDbID extracts TMDb ID from movie metadata or file paths
-func (p *Processor) extractMovieTMDbID(item MediaItem) string {
-	verbose := p.config.VerboseLogging
-	if verbose {
-		fmt.Printf("\n[LOOKUP] Movie: %s (%d)\n", item.GetTitle(), item.GetYear())
-	}
-
-	// 1. Plex metadata
-	for _, guid := range item.GetGuid() {
-		if strings.Contains(guid.ID, "tmdb://") {
-			parts := strings.Split(guid.ID, "//")
-			if len(parts) > 1 {
-				tmdbID := strings.Split(parts[1], "?")[0]
-				if verbose {
-					fmt.Printf("   [OK] Plex metadata: %s\n", tmdbID)
-				}
-				return tmdbID
-			}
+// resolveItemID returns the item's TMDb ID, the source it came from (see
+// idSourceOrder) and whether the item can be processed. With
+// TVDB_KEYWORDS=only, TV shows with a tvdb:// GUID skip TMDb resolution
+// entirely and are processed with an empty TMDb ID. Items in AniList
+// libraries without a TMDb match are likewise processed with an empty TMDb ID
+// if they map to AniList, as is any item without a TMDb ID while
+// PLEX_SIMILAR_SEEDS is set (its clusters need no external IDs).
+func (p *Processor) resolveItemID(item MediaItem, libraryID string, mediaType MediaType) (string, string, bool) {
+	if p.tvdbOnly(item, mediaType) {
+		if p.config.VerboseLogging {
+			fmt.Printf("\n[LOOKUP] TV show: %s (%d) - using TVDB %s (TVDB_KEYWORDS=only)\n", item.GetTitle(), item.GetYear(), tvdbGUID(item))
 		}
+		return "", "TVDB_KEYWORDS=only", true
 	}
-
-	// 2. Radarr lookup (title/year, then IMDb ID)
-	if p.config.UseRadarr && p.radarrClient != nil {
-		movie, err := p.radarrClient.FindMovieMatch(item.GetTitle(), item.GetYear())
-		if err == nil && movie != nil {
-			tmdbID := p.radarrClient.GetTMDbIDFromMovie(movie)
-			if verbose {
-				fmt.Printf("   [OK] Radarr match: %s (TMDb: %s)\n", movie.Title, tmdbID)
-			}
-			return tmdbID
-		} else if verbose {
-			fmt.Printf("   [SKIP] No Radarr match by title/year\n")
-		}
-
-		for _, guid := range item.GetGuid() {
-			if strings.Contains(guid.ID, "imdb://") {
-				imdbID := strings.TrimPrefix(guid.ID, "imdb://")
-				movie, err := p.radarrClient.GetMovieByIMDbID(imdbID)
-				if err == nil && movie != nil {
-					tmdbID := p.radarrClient.GetTMDbIDFromMovie(movie)
-					if verbose {
-						fmt.Printf("   [OK] Radarr match by IMDb %s: %s (TMDb: %s)\n", imdbID, movie.Title, tmdbID)
-					}
-					return tmdbID
-				} else if verbose {
-					fmt.Printf("   [SKIP] No Radarr match by IMDb ID %s\n", imdbID)
-				}
+	tmdbID, source := p.ResolveTMDbID(item, mediaType)
+	if tmdbID == "" && p.librarySource(libraryID) == "anilist" {
+		if entry, ok := p.animeEntry(item, mediaType); ok && entry.AniList != "" {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [LOOKUP] No TMDb ID for %s - using AniList %s\n", item.GetTitle(), entry.AniList)
 			}
+			return "", "AniList", true
 		}
 	}
-
-	// 3. File paths: check Radarr path match AND TMDb ID regex in one pass
-	logged := 0
-	for _, mediaItem := range item.GetMedia() {
-		for _, part := range mediaItem.Part {
-			if verbose && logged < 3 {
-				fmt.Printf("   [INFO] Checking path: %s\n", part.File)
-				logged++
-			}
-			if p.config.UseRadarr && p.radarrClient != nil {
-				movie, err := p.radarrClient.GetMovieByPath(part.File)
-				if err == nil && movie != nil {
-					tmdbID := p.radarrClient.GetTMDbIDFromMovie(movie)
-					if verbose {
-						fmt.Printf("   [OK] Radarr path match: %s (TMDb: %s)\n", movie.Title, tmdbID)
-					}
-					return tmdbID
-				}
-			}
-			if tmdbID := ExtractTMDbIDFromPath(part.File); tmdbID != "" {
-				if verbose {
-					fmt.Printf("   [OK] TMDb ID in file path: %s\n", tmdbID)
-				}
-				return tmdbID
-			}
+	if tmdbID == "" && len(p.config.PlexSimilarSeeds) > 0 {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [LOOKUP] No TMDb ID for %s - using Plex similar clusters only\n", item.GetTitle())
 		}
+		return "", "PLEX_SIMILAR_SEEDS", true
+	}
+	if tmdbID == "" {
+		return "", "none", false
 	}
+	return tmdbID, source, true
+}
 
-	if verbose {
-		fmt.Printf("   [SKIP] No TMDb ID found for: %s\n", item.GetTitle())
+// idSourceLine describes where the item's ID came from for logging.
+func (p *Processor) idSourceLine(item MediaItem, libraryID string, mediaType MediaType, tmdbID, source string) string {
+	switch {
+	case tmdbID != "":
+		return fmt.Sprintf("[KEY] TMDb ID: %s (source: %s)", tmdbID, source)
+	case source == "TVDB_KEYWORDS=only":
+		return fmt.Sprintf("[KEY] TVDB ID: %s (TVDB_KEYWORDS=only)", tvdbGUID(item))
+	case source == "AniList":
+		if entry, ok := p.animeEntry(item, mediaType); ok {
+			return fmt.Sprintf("[KEY] AniList ID: %s (LIBRARY_SOURCE_OVERRIDE)", entry.AniList)
+		}
 	}
-	return ""
+	return fmt.Sprintf("[KEY] No external ID - Plex rating key: %s (PLEX_SIMILAR_SEEDS)", item.GetRatingKey())
 }
 
-// extractTVShowTMDbID extracts TMDb ID from TV show metadata or episode file paths
-func (p *Processor) extractTVShowTMDbID(item MediaItem) string {
-	verbose := p.config.VerboseLogging
-	if verbose {
-		fmt.Printf("\n[LOOKUP] TV show: %s (%d)\n", item.GetTitle(), item.GetYear())
+// tmdbMediaType converts MediaType to the path segment used by the TMDb API
+func tmdbMediaType(mediaType MediaType) string {
+	if mediaType == MediaTypeTV {
+		return "tv"
 	}
+	return "movie"
+}
 
-	// 1. Plex metadata
+// externalIDs returns the TMDb find sources ("imdb_id"/"tvdb_id") and IDs
+// exposed by the item's Plex GUIDs, in lookup order for the media type.
+func externalIDs(item MediaItem, mediaType MediaType) [][2]string {
+	var imdb, tvdb [][2]string
 	for _, guid := range item.GetGuid() {
-		if strings.HasPrefix(guid.ID, "tmdb://") {
-			tmdbID := strings.TrimPrefix(guid.ID, "tmdb://")
-			if verbose {
-				fmt.Printf("   [OK] Plex metadata: %s\n", tmdbID)
-			}
-			return tmdbID
+		switch {
+		case strings.HasPrefix(guid.ID, "imdb://"):
+			imdb = append(imdb, [2]string{"imdb_id", strings.TrimPrefix(guid.ID, "imdb://")})
+		case strings.HasPrefix(guid.ID, "tvdb://"):
+			tvdb = append(tvdb, [2]string{"tvdb_id", strings.TrimPrefix(guid.ID, "tvdb://")})
 		}
 	}
+	if mediaType == MediaTypeTV {
+		return append(tvdb, imdb...)
+	}
+	return imdb
+}
 
-	// 2. Sonarr lookup (title/year, TVDb ID, IMDb ID)
-	if p.config.UseSonarr && p.sonarrClient != nil {
-		series, err := p.sonarrClient.FindSeriesMatch(item.GetTitle(), item.GetYear())
-		if err == nil && series != nil {
-			tmdbID := p.sonarrClient.GetTMDbIDFromSeries(series)
-			if verbose {
-				fmt.Printf("   [OK] Sonarr match: %s (TMDb: %s)\n", series.Title, tmdbID)
-			}
-			return tmdbID
-		} else if verbose {
-			fmt.Printf("   [SKIP] No Sonarr match by title/year\n")
-		}
+// findTMDbIDByExternalID resolves the item's IMDb/TVDb GUIDs through the
+// TMDb /find endpoint. Results (including misses) are cached until the next
+// ClearCaches call.
+func (p *Processor) findTMDbIDByExternalID(item MediaItem, mediaType MediaType) string {
+	verbose := p.config.VerboseLogging
+	tmdbType := tmdbMediaType(mediaType)
 
-		for _, guid := range item.GetGuid() {
-			if strings.Contains(guid.ID, "tvdb://") {
-				tvdbIDStr := strings.TrimPrefix(guid.ID, "tvdb://")
-				var tvdbID int
-				if _, err := fmt.Sscanf(tvdbIDStr, "%d", &tvdbID); err == nil {
-					series, err := p.sonarrClient.GetSeriesByTVDbID(tvdbID)
-					if err == nil && series != nil {
-						tmdbID := p.sonarrClient.GetTMDbIDFromSeries(series)
-						if verbose {
-							fmt.Printf("   [OK] Sonarr match by TVDb %d: %s (TMDb: %s)\n", tvdbID, series.Title, tmdbID)
-						}
-						return tmdbID
-					} else if verbose {
-						fmt.Printf("   [SKIP] No Sonarr match by TVDb ID %d\n", tvdbID)
-					}
-				}
-			}
-			if strings.Contains(guid.ID, "imdb://") {
-				imdbID := strings.TrimPrefix(guid.ID, "imdb://")
-				series, err := p.sonarrClient.GetSeriesByIMDbID(imdbID)
-				if err == nil && series != nil {
-					tmdbID := p.sonarrClient.GetTMDbIDFromSeries(series)
-					if verbose {
-						fmt.Printf("   [OK] Sonarr match by IMDb %s: %s (TMDb: %s)\n", imdbID, series.Title, tmdbID)
-					}
-					return tmdbID
-				} else if verbose {
-					fmt.Printf("   [SKIP] No Sonarr match by IMDb ID %s\n", imdbID)
-				}
-			}
-		}
-	}
+	for _, ext := range externalIDs(item, mediaType) {
+		source, id := ext[0], ext[1]
+		cacheKey := tmdbType + ":" + source + ":" + id
 
-	// 3. Episode file paths: check Sonarr path match AND TMDb ID regex in one pass
-	episodes, err := p.plexClient.GetTVShowEpisodes(item.GetRatingKey())
-	if err != nil {
-		if verbose {
-			fmt.Printf("   [WARN] Could not fetch episodes: %v\n", err)
-		}
-		return ""
-	}
+		p.cacheMu.RLock()
+		tmdbID, cached := p.findCache[cacheKey]
+		p.cacheMu.RUnlock()
 
-	logged := 0
-	for _, episode := range episodes {
-		for _, mediaItem := range episode.Media {
-			for _, part := range mediaItem.Part {
-				if verbose && logged < 3 {
-					fmt.Printf("   [INFO] Checking path: %s\n", part.File)
-					logged++
-				}
-				if p.config.UseSonarr && p.sonarrClient != nil {
-					series, err := p.sonarrClient.GetSeriesByPath(part.File)
-					if err == nil && series != nil {
-						tmdbID := p.sonarrClient.GetTMDbIDFromSeries(series)
-						if verbose {
-							fmt.Printf("   [OK] Sonarr path match: %s (TMDb: %s)\n", series.Title, tmdbID)
-						}
-						return tmdbID
-					}
-				}
-				if tmdbID := ExtractTMDbIDFromPath(part.File); tmdbID != "" {
-					if verbose {
-						fmt.Printf("   [OK] TMDb ID in file path: %s\n", tmdbID)
-					}
-					return tmdbID
+		if !cached {
+			var err error
+			tmdbID, err = p.tmdbClient.FindByExternalID(id, source, tmdbType)
+			if err != nil {
+				if verbose {
+					fmt.Printf("   [WARN] TMDb find failed for %s %s: %v\n", source, id, err)
 				}
+				continue
 			}
+			p.cacheMu.Lock()
+			p.findCache[cacheKey] = tmdbID
+			p.cacheMu.Unlock()
 		}
-	}
 
-	if verbose && logged > 0 {
-		totalPaths := 0
-		for _, ep := range episodes {
-			for _, m := range ep.Media {
-				totalPaths += len(m.Part)
+		if tmdbID != "" {
+			if verbose {
+				fmt.Printf("   [OK] TMDb find by %s %s: %s\n", source, id, tmdbID)
 			}
-		}
-		if totalPaths > 3 {
-			fmt.Printf("   [INFO] ... and %d more paths checked\n", totalPaths-3)
+			return tmdbID
+		} else if verbose {
+			fmt.Printf("   [SKIP] No TMDb find match for %s %s\n", source, id)
 		}
 	}
-
-	if verbose {
-		fmt.Printf("   [SKIP] No TMDb ID found for: %s\n", item.GetTitle())
-	}
 	return ""
 }
 
-// getTMDbIDSource determines the source of the TMDb ID
-func (p *Processor) getTMDbIDSource(item MediaItem, mediaType MediaType, tmdbID string) string {
-	// Check if it's from Plex metadata
-	for _, guid := range item.GetGuid() {
-		if strings.Contains(guid.ID, "tmdb://") {
-			return "Plex metadata"
-		}
-	}
-
-	// Check if it could be from Radarr/Sonarr
-	if mediaType == MediaTypeMovie && p.config.UseRadarr && p.radarrClient != nil {
-		// Quick check if movie exists in Radarr with this TMDb ID
-		movie, err := p.radarrClient.FindMovieMatch(item.GetTitle(), item.GetYear())
-		if err == nil && movie != nil && p.radarrClient.GetTMDbIDFromMovie(movie) == tmdbID {
-			return "Radarr"
-		}
-	}
-
-	if mediaType == MediaTypeTV && p.config.UseSonarr && p.sonarrClient != nil {
-		// Quick check if series exists in Sonarr with this TMDb ID
-		series, err := p.sonarrClient.FindSeriesMatch(item.GetTitle(), item.GetYear())
-		if err == nil && series != nil && p.sonarrClient.GetTMDbIDFromSeries(series) == tmdbID {
-			return "Sonarr"
-		}
-	}
-
-	// Must be from file path
-	return "file path"
-}
-
 // ExtractTMDbIDFromPath extracts TMDb ID from file path using regex
 func ExtractTMDbIDFromPath(filePath string) string {
 	// Flexible regex pattern to match tmdb followed by digits with separators around the whole pattern
@@ -1265,6 +1520,27 @@ func (p *Processor) extractFilePaths(item MediaItem, mediaType MediaType) ([]str
 	return filePaths, nil
 }
 
+// exportItem accumulates the item's files for export. labels are the item's
+// UPDATE_FIELD values; EXPORT_MATCH_FIELD can match another field of details
+// instead. details also supplies the rating key and added date recorded with
+// EXPORT_METADATA.
+func (p *Processor) exportItem(item, details MediaItem, tmdbID string, labels []string, fileInfos []export.FileInfo) error {
+	metadata := export.ItemMetadata{
+		Title:     item.GetTitle(),
+		Year:      item.GetYear(),
+		RatingKey: details.GetRatingKey(),
+		TMDbID:    tmdbID,
+		Labels:    labels,
+	}
+	if field := p.config.ExportMatch; field != "" && !strings.EqualFold(field, p.config.UpdateField) {
+		metadata.Match = fieldValues(details, field)
+	}
+	if addedAt := details.GetAddedAt(); addedAt > 0 {
+		metadata.AddedAt = time.Unix(addedAt, 0).UTC().Format(time.RFC3339)
+	}
+	return p.exporter.ExportItemWithSizes(metadata, fileInfos)
+}
+
 // extractFileInfos extracts all file paths and sizes from a media item
 func (p *Processor) extractFileInfos(item MediaItem, mediaType MediaType) ([]export.FileInfo, error) {
 	var fileInfos []export.FileInfo
@@ -1283,8 +1559,15 @@ func (p *Processor) extractFileInfos(item MediaItem, mediaType MediaType) ([]exp
 			}
 		}
 	case MediaTypeTV:
+		// One Sonarr request replaces walking every episode in Plex
+		if p.config.ExportSonarr {
+			if fileInfos, ok := p.sonarrFileInfos(item); ok {
+				return fileInfos, nil
+			}
+		}
+
 		// For TV shows, get file info from all episodes (use GetAllTVShowEpisodes for export)
-		episodes, err := p.plexClient.GetAllTVShowEpisodes(item.GetRatingKey())
+		episodes, err := p.allShowEpisodes(item.GetRatingKey())
 		if err != nil {
 			return nil, fmt.Errorf("failed to get all episodes for TV show %s: %w", item.GetTitle(), err)
 		}
@@ -1305,3 +1588,37 @@ func (p *Processor) extractFileInfos(item MediaItem, mediaType MediaType) ([]exp
 
 	return fileInfos, nil
 }
+
+// sonarrFileInfos returns the show's episode files as Sonarr knows them,
+// from the first Sonarr instance that has the show. ok is false when the
+// show isn't in Sonarr, has no files there or the request fails, so the
+// caller can fall back to Plex.
+func (p *Processor) sonarrFileInfos(item MediaItem) ([]export.FileInfo, bool) {
+	var tmdbID string
+	for _, guid := range item.GetGuid() {
+		if strings.HasPrefix(guid.ID, "tmdb://") {
+			tmdbID = strings.TrimPrefix(guid.ID, "tmdb://")
+			break
+		}
+	}
+	entries := p.sonarrSeries(item, tmdbID)
+	if len(entries) == 0 {
+		return nil, false
+	}
+
+	files, err := entries[0].client.GetEpisodeFilesBySeries(entries[0].series.ID)
+	if err != nil {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Could not fetch Sonarr episode files, using Plex: %v\n", err)
+		}
+		return nil, false
+	}
+
+	var fileInfos []export.FileInfo
+	for _, file := range files {
+		if file.Path != "" {
+			fileInfos = append(fileInfos, export.FileInfo{Path: file.Path, Size: file.Size})
+		}
+	}
+	return fileInfos, len(fileInfos) > 0
+}
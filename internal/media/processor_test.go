@@ -1,10 +1,20 @@
 package media
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/nullable-eth/labelarr/internal/config"
 	"github.com/nullable-eth/labelarr/internal/plex"
+	"github.com/nullable-eth/labelarr/internal/radarr"
+	"github.com/nullable-eth/labelarr/internal/sonarr"
+	"github.com/nullable-eth/labelarr/internal/storage"
+	"github.com/nullable-eth/labelarr/internal/tmdb"
 )
 
 func TestExtractTMDbIDFromPath(t *testing.T) {
@@ -371,3 +381,941 @@ func TestIsExcludedByLabel(t *testing.T) {
 		})
 	}
 }
+
+func TestExternalIDs(t *testing.T) {
+	item := plex.TVShow{Guid: plex.FlexibleGuid{
+		{ID: "imdb://tt0903747"},
+		{ID: "tvdb://81189"},
+		{ID: "plex://show/5d9c086c"},
+	}}
+
+	tv := externalIDs(item, MediaTypeTV)
+	if len(tv) != 2 || tv[0] != [2]string{"tvdb_id", "81189"} || tv[1] != [2]string{"imdb_id", "tt0903747"} {
+		t.Errorf("TV external IDs = %v, want TVDb before IMDb", tv)
+	}
+
+	movie := externalIDs(item, MediaTypeMovie)
+	if len(movie) != 1 || movie[0] != [2]string{"imdb_id", "tt0903747"} {
+		t.Errorf("movie external IDs = %v, want IMDb only", movie)
+	}
+}
+
+func TestCleanTitle(t *testing.T) {
+	tests := map[string]string{
+		"Spider-Man: No Way Home": "spidermannowayhome",
+		"Amélie":                  "amelie",
+		"Ame\u0301lie":            "amelie",
+		"千と千尋の神隠し":                "千と千尋の神隠し",
+		"Сталкер":                 "сталкер",
+		"기생충 (2019)":              "기생충2019",
+		"?!":                      "",
+	}
+	for title, want := range tests {
+		if got := cleanTitle(title); got != want {
+			t.Errorf("cleanTitle(%q) = %q, want %q", title, got, want)
+		}
+	}
+	if !titleIn(cleanTitle("Сталкер"), []string{"Stalker", "СТАЛКЕР"}) {
+		t.Error("titleIn did not match a Cyrillic title")
+	}
+}
+
+func TestSearchConfidence(t *testing.T) {
+	tests := []struct {
+		name   string
+		title  string
+		year   int
+		result tmdb.SearchResult
+		want   float64
+	}{
+		{"exact title and year", "The Matrix", 1999, tmdb.SearchResult{Title: "The Matrix", ReleaseDate: "1999-03-30"}, 1.0},
+		{"punctuation ignored", "Spider-Man: No Way Home", 2021, tmdb.SearchResult{Title: "Spider-Man No Way Home", ReleaseDate: "2021-12-15"}, 1.0},
+		{"off by one year", "Dune", 2021, tmdb.SearchResult{Title: "Dune", ReleaseDate: "2020-09-03"}, 0.85},
+		{"original title match", "Amelie", 2001, tmdb.SearchResult{Title: "Le Fabuleux Destin", OriginalTitle: "Amélie", ReleaseDate: "2001-04-25"}, 1.0},
+		{"accents folded", "Les Misérables", 2012, tmdb.SearchResult{Title: "Les Miserables", ReleaseDate: "2012-12-18"}, 1.0},
+		{"TV name and first air date", "Dark", 2017, tmdb.SearchResult{Name: "Dark", FirstAirDate: "2017-12-01"}, 1.0},
+		{"partial title wrong year", "Alien", 1979, tmdb.SearchResult{Title: "Aliens", ReleaseDate: "1986-07-18"}, 0.4},
+		{"unrelated", "Heat", 1995, tmdb.SearchResult{Title: "Frozen", ReleaseDate: "2013-11-27"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := searchConfidence(tt.title, tt.year, tt.result)
+			if diff := got - tt.want; diff > 0.001 || diff < -0.001 {
+				t.Errorf("searchConfidence() = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecadeLabel(t *testing.T) {
+	tests := map[int]string{
+		1987: "1980s",
+		1990: "1990s",
+		2009: "2000s",
+		2024: "2020s",
+		0:    "",
+	}
+	for year, want := range tests {
+		if got := decadeLabel(year); got != want {
+			t.Errorf("decadeLabel(%d) = %q, want %q", year, got, want)
+		}
+	}
+}
+
+func TestCertificationAge(t *testing.T) {
+	tests := []struct {
+		country       string
+		certification string
+		wantAge       int
+		wantOK        bool
+	}{
+		{"US", "PG-13", 13, true},
+		{"US", "TV-MA", 17, true},
+		{"GB", "12A", 12, true},
+		{"DE", "16", 16, true},
+		{"BR", "L", 0, false},
+		{"US", "NR", 0, false},
+	}
+	for _, tt := range tests {
+		age, ok := certificationAge(tt.country, tt.certification)
+		if age != tt.wantAge || ok != tt.wantOK {
+			t.Errorf("certificationAge(%q, %q) = %d, %v, want %d, %v", tt.country, tt.certification, age, ok, tt.wantAge, tt.wantOK)
+		}
+	}
+}
+
+func TestParseAgeOverrides(t *testing.T) {
+	ages, err := parseAgeOverrides(strings.NewReader("# family picks\nmovie:603, 13\n\nTT0317219,6\n"))
+	if err != nil {
+		t.Fatalf("parseAgeOverrides() error: %v", err)
+	}
+	if ages["movie:603"] != 13 || ages["tt0317219"] != 6 || len(ages) != 2 {
+		t.Errorf("parseAgeOverrides() = %v", ages)
+	}
+
+	if _, err := parseAgeOverrides(strings.NewReader("movie:603\n")); err == nil {
+		t.Error("expected error for a line without an age")
+	}
+}
+
+func TestAudioLanguages(t *testing.T) {
+	media := []plex.Media{
+		{Part: []plex.Part{{Stream: []plex.Stream{
+			{StreamType: plex.StreamTypeVideo},
+			{StreamType: plex.StreamTypeAudio, LanguageCode: "jpn", Language: "日本語"},
+			{StreamType: plex.StreamTypeAudio, LanguageCode: "eng"},
+			{StreamType: plex.StreamTypeSubtitle, LanguageCode: "fre"},
+			{StreamType: plex.StreamTypeAudio, LanguageCode: "und"},
+		}}}},
+		{Part: []plex.Part{{Stream: []plex.Stream{
+			{StreamType: plex.StreamTypeAudio, LanguageCode: "ENG"},
+			{StreamType: plex.StreamTypeAudio, LanguageCode: "gsw", Language: "Swiss German"},
+		}}}},
+	}
+
+	got := audioLanguages(media, map[string]string{"en": "English (US)"})
+	want := []string{"Japanese", "English (US)", "Swiss German"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("audioLanguages() = %v, want %v", got, want)
+	}
+}
+
+func TestVideoQualities(t *testing.T) {
+	media := []plex.Media{
+		{VideoResolution: "4k", Part: []plex.Part{{Stream: []plex.Stream{
+			{StreamType: plex.StreamTypeVideo, DOVIPresent: true, ColorTrc: "smpte2084", DisplayTitle: "4K DoVi/HDR10 (HEVC Main 10)"},
+			{StreamType: plex.StreamTypeAudio, LanguageCode: "eng"},
+		}}}},
+		{VideoResolution: "1080", Part: []plex.Part{{Stream: []plex.Stream{
+			{StreamType: plex.StreamTypeVideo, DisplayTitle: "1080p (H.264)"},
+		}}}},
+	}
+
+	got := videoQualities(media, []string{"resolution", "hdr"})
+	want := []string{"4K", "Dolby Vision", "HDR10", "1080p"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("videoQualities() = %v, want %v", got, want)
+	}
+
+	if got := videoQualities(media, []string{"hdr"}); !reflect.DeepEqual(got, []string{"Dolby Vision", "HDR10"}) {
+		t.Errorf("videoQualities(hdr) = %v", got)
+	}
+}
+
+func TestRadarrQualities(t *testing.T) {
+	movie := &radarr.Movie{QualityProfileID: 4, HasFile: true}
+	movie.MovieFile.Quality.Quality.Name = "Remux-2160p"
+	profiles := map[int]string{4: "Ultra-HD"}
+
+	got := radarrQualities(movie, []string{"profile", "file"}, profiles)
+	if want := []string{"Ultra-HD", "Remux-2160p"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("radarrQualities() = %v, want %v", got, want)
+	}
+
+	movie.HasFile = false
+	if got := radarrQualities(movie, []string{"file"}, profiles); len(got) != 0 {
+		t.Errorf("expected no file quality without a file, got %v", got)
+	}
+}
+
+func TestMediaFootprint(t *testing.T) {
+	media := []plex.Media{
+		{Part: []plex.Part{
+			{Size: 30e9, Duration: 3600000},
+			{Size: 15e9, Duration: 1800000},
+		}},
+		{Part: []plex.Part{{Size: 5e9}}},
+	}
+
+	sizeGB, bitrateMbps := mediaFootprint(media)
+	if sizeGB != 50 {
+		t.Errorf("Expected 50 GB, got %v", sizeGB)
+	}
+	// 45 GB over 90 minutes is 66.67 Mbps; the untimed part is left out.
+	if bitrateMbps < 66.6 || bitrateMbps > 66.7 {
+		t.Errorf("Expected ~66.67 Mbps, got %v", bitrateMbps)
+	}
+
+	if _, bitrateMbps := mediaFootprint([]plex.Media{{Part: []plex.Part{{Size: 1e9}}}}); bitrateMbps != 0 {
+		t.Errorf("Expected no bitrate without durations, got %v", bitrateMbps)
+	}
+}
+
+func TestRadarrTagNames(t *testing.T) {
+	labels := []string{"Sci-Fi Classics", "  Director's Cut ", "4K"}
+
+	keep, drop := radarrTagNames(labels, nil)
+	if want := []string{"sci-fi-classics", "director-s-cut", "4k"}; !reflect.DeepEqual(keep, want) || drop != nil {
+		t.Errorf("radarrTagNames(all) = %v, %v, want %v, nil", keep, drop, want)
+	}
+
+	keep, drop = radarrTagNames(labels, []string{"4k", "Watched"})
+	if !reflect.DeepEqual(keep, []string{"4k"}) || !reflect.DeepEqual(drop, []string{"watched"}) {
+		t.Errorf("radarrTagNames(subset) = %v, %v, want [4k], [watched]", keep, drop)
+	}
+}
+
+func TestTagLabels(t *testing.T) {
+	tags := map[int]string{1: "anime", 2: "kids", 3: "4k"}
+	got := tagLabels([]int{2, 9, 1, 2}, tags, "sonarr:")
+	if want := []string{"sonarr:kids", "sonarr:anime"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tagLabels() = %v, want %v", got, want)
+	}
+}
+
+func TestMatchArrItem(t *testing.T) {
+	items := []MediaItem{
+		plex.Movie{RatingKey: "1", Title: "Heat", Year: 1995},
+		plex.Movie{RatingKey: "2", Title: "Alien", Year: 1979, Guid: plex.FlexibleGuid{{ID: "tmdb://348"}}},
+		plex.Movie{RatingKey: "3", Title: "Up", Year: 2009, Media: []plex.Media{{Part: []plex.Part{{File: "/movies/Up (2009)/Up.mkv"}}}}},
+	}
+
+	tests := []struct {
+		name string
+		arr  ArrItem
+		want string
+	}{
+		{"guid", ArrItem{Title: "Alien (Director's Cut)", TMDbID: 348}, "2"},
+		{"path", ArrItem{Title: "Up!", Path: "/movies/Up (2009)/Up.mkv"}, "3"},
+		{"title and year", ArrItem{Title: "heat", Year: 1995}, "1"},
+		{"no match", ArrItem{Title: "Heat", Year: 1986}, ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if item := matchArrItem(items, tt.arr); item != nil {
+			got = item.GetRatingKey()
+		}
+		if got != tt.want {
+			t.Errorf("%s: matchArrItem() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStatusLabels(t *testing.T) {
+	rules := map[string]string{"unmonitored": "Unmonitored", "ended": "Ended Series"}
+
+	if got := statusLabels(false, "ended", rules); !reflect.DeepEqual(got, []string{"Unmonitored", "Ended Series"}) {
+		t.Errorf("statusLabels(unmonitored, ended) = %v", got)
+	}
+	if got := statusLabels(true, "continuing", rules); len(got) != 0 {
+		t.Errorf("statusLabels(monitored, continuing) = %v, want none", got)
+	}
+}
+
+func TestTagFilterAllows(t *testing.T) {
+	tests := []struct {
+		tags   []string
+		filter []string
+		want   bool
+	}{
+		{[]string{"4K", "kids"}, []string{"4k"}, true},
+		{[]string{"kids"}, []string{"4k", "anime"}, false},
+		{[]string{"kids"}, []string{"!kids"}, false},
+		{nil, []string{"!kids"}, true},
+		{[]string{"4k", "kids"}, []string{"4k", "!kids"}, false},
+		{nil, []string{"4k"}, false},
+	}
+	for _, tt := range tests {
+		if got := tagFilterAllows(tt.tags, tt.filter); got != tt.want {
+			t.Errorf("tagFilterAllows(%v, %v) = %v, want %v", tt.tags, tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestRequestLabel(t *testing.T) {
+	if got := requestLabel("Requested by %s", "Alice"); got != "Requested by Alice" {
+		t.Errorf("requestLabel(Requested by %%s) = %q", got)
+	}
+	if got := requestLabel("Requested", "Alice"); got != "Requested" {
+		t.Errorf("requestLabel(Requested) = %q, want the format unchanged", got)
+	}
+}
+
+func TestRadarrLanguageName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Spanish", "Spanish"},
+		{"korean", "Korean Cinema"},
+		{"English", ""},
+		{"Unknown", ""},
+		{"Klingon", "Klingon"},
+	}
+	overrides := map[string]string{"ko": "Korean Cinema"}
+	for _, tt := range tests {
+		if got := radarrLanguageName(tt.name, overrides, []string{"en"}); got != tt.want {
+			t.Errorf("radarrLanguageName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSeasonsComplete(t *testing.T) {
+	season := func(number, files, aired int) sonarr.Season {
+		return sonarr.Season{SeasonNumber: number, Statistics: &sonarr.SeasonStatistics{EpisodeFileCount: files, EpisodeCount: aired}}
+	}
+
+	tests := []struct {
+		name         string
+		seasons      []sonarr.Season
+		wantComplete bool
+		wantOK       bool
+	}{
+		{"all available", []sonarr.Season{season(1, 10, 10), season(2, 8, 8)}, true, true},
+		{"missing episode", []sonarr.Season{season(1, 10, 10), season(2, 7, 8)}, false, true},
+		{"specials ignored", []sonarr.Season{season(0, 0, 5), season(1, 10, 10)}, true, true},
+		{"unaired season ignored", []sonarr.Season{season(1, 10, 10), season(2, 0, 0)}, true, true},
+		{"no statistics", []sonarr.Season{{SeasonNumber: 1}}, false, false},
+		{"no seasons", nil, false, false},
+	}
+	for _, tt := range tests {
+		complete, ok := seasonsComplete(tt.seasons)
+		if complete != tt.wantComplete || ok != tt.wantOK {
+			t.Errorf("%s: seasonsComplete() = %v, %v, want %v, %v", tt.name, complete, ok, tt.wantComplete, tt.wantOK)
+		}
+	}
+}
+
+func TestKeywordTagNames(t *testing.T) {
+	keywords := []string{"Time Travel", "Dystopia", "time travel", "Sci-Fi"}
+
+	got := keywordTagNames(keywords, nil, "kw-")
+	want := []string{"kw-time-travel", "kw-dystopia", "kw-sci-fi"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("keywordTagNames() = %v, want %v", got, want)
+	}
+
+	got = keywordTagNames(keywords, []string{"dystopia"}, "kw-")
+	want = []string{"kw-dystopia"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("keywordTagNames() with allowlist = %v, want %v", got, want)
+	}
+
+	for prefix, want := range map[string]string{"kw-": "kw-", "KW_": "kw-", "kw": "kw"} {
+		if got := keywordTagPrefix(prefix); got != want {
+			t.Errorf("keywordTagPrefix(%q) = %q, want %q", prefix, got, want)
+		}
+	}
+}
+
+func TestKeywordFrequencies(t *testing.T) {
+	got := keywordFrequencies([][]string{
+		{"Heist", "Time Travel", "heist"},
+		{"heist", "Dystopia"},
+		{"Time Travel"},
+	})
+	want := map[string]int{"heist": 2, "time travel": 2, "dystopia": 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("keywordFrequencies() = %v, want %v", got, want)
+	}
+}
+
+func TestAliasKeywords(t *testing.T) {
+	aliases := map[string][]string{
+		"superhero":  {"Superheroes"},
+		"super hero": {"Superheroes"},
+	}
+	got := aliasKeywords([]string{"Superhero", "Heist", "Super Hero", "superheroes"}, aliases)
+	want := []string{"Superheroes", "Heist"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("aliasKeywords() = %v, want %v", got, want)
+	}
+}
+
+func TestSelectKeywords(t *testing.T) {
+	keywords := []string{"Heist", "Sequel", "Based on Novel", "Con Artist"}
+	counts := map[string]int{"sequel": 12, "based on novel": 30, "heist": 12}
+
+	tests := []struct {
+		name   string
+		max    int
+		counts map[string]int
+		want   []string
+	}{
+		{"relevance keeps source order", 2, nil, []string{"Heist", "Sequel"}},
+		{"frequency keeps most common", 2, counts, []string{"Based on Novel", "Heist"}},
+		{"uncounted keywords rank last", 4, counts, []string{"Based on Novel", "Heist", "Sequel", "Con Artist"}},
+		{"max above length", 10, nil, keywords},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectKeywords(keywords, tt.max, tt.counts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectKeywords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaleValues(t *testing.T) {
+	applied := []string{"Heist", "Sequel", "Trending"}
+	managed := []string{"Trending"}
+	current := []string{"heist", "Sequel", "Trending", "Favorites"}
+	want := []string{"Heist", "Con Artist"}
+
+	if got := staleValues(applied, managed, current, want); !reflect.DeepEqual(got, []string{"Sequel"}) {
+		t.Errorf("staleValues() = %v, want [Sequel]", got)
+	}
+}
+
+func TestSaveProcessedAttribution(t *testing.T) {
+	current := []string{"Favorites", "Heist"}
+	keywords := []string{"Heist", "Sequel", "Favorites"}
+
+	tests := []struct {
+		name      string
+		existing  *storage.ProcessedItem
+		protect   bool
+		wantOwned []string
+	}{
+		{"values already present are manual", nil, false, []string{"Sequel"}},
+		{"previous history is kept", &storage.ProcessedItem{History: []storage.LabelChange{{Added: []string{"heist"}}}}, false, []string{"heist", "Sequel"}},
+		{"untracked entries claim every keyword", &storage.ProcessedItem{KeywordsSynced: true, Untracked: true}, false, []string{"Heist", "Favorites", "Sequel"}},
+		{"protection leaves untracked values alone", &storage.ProcessedItem{KeywordsSynced: true, Untracked: true}, true, []string{"Sequel"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stor, err := storage.NewStorage(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.existing != nil {
+				tt.existing.RatingKey = "1"
+				if err := stor.Set(tt.existing); err != nil {
+					t.Fatal(err)
+				}
+			}
+			p := &Processor{config: &config.Config{UpdateField: "label", ProtectManualLabels: tt.protect}, storage: stor}
+			if err := p.saveProcessed(plex.Movie{RatingKey: "1"}, "1", "949", current, keywords, nil); err != nil {
+				t.Fatal(err)
+			}
+			processed, _ := stor.Get("1")
+			if got := processed.NetChange().Added; !reflect.DeepEqual(got, tt.wantOwned) {
+				t.Errorf("owned after sync = %v, want %v", got, tt.wantOwned)
+			}
+			if processed.Untracked {
+				t.Error("entry still marked untracked after a sync")
+			}
+		})
+	}
+}
+
+func TestKeywordDroppedInAdditiveMode(t *testing.T) {
+	stor, err := storage.NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Processor{config: &config.Config{UpdateField: "label", SyncMode: "additive"}, storage: stor}
+	item := plex.Movie{RatingKey: "1", Title: "Heat"}
+
+	if err := p.saveProcessed(item, "1", "949", nil, []string{"heist", "sequel"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	// TMDb drops "sequel"; additive mode leaves it on the item
+	current := []string{"heist", "sequel"}
+	if err := p.saveProcessed(item, "1", "949", current, []string{"heist"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	p.config.SyncMode = "exact"
+	if got := p.staleKeywords(item, current, []string{"heist"}); !reflect.DeepEqual(got, []string{"sequel"}) {
+		t.Errorf("staleKeywords() = %v, want [sequel]", got)
+	}
+}
+
+func TestRestoreUnowned(t *testing.T) {
+	current := []string{"scifi", "Favorites", "old keyword"}
+	cleaned := []string{"Sci-Fi"}
+	owned := []string{"Old Keyword"}
+
+	got := restoreUnowned(current, cleaned, owned)
+	want := []string{"Sci-Fi", "scifi", "Favorites"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("restoreUnowned() = %v, want %v", got, want)
+	}
+}
+
+func TestSplitTitleYear(t *testing.T) {
+	tests := []struct {
+		in    string
+		title string
+		year  int
+	}{
+		{"The Matrix (1999)", "The Matrix", 1999},
+		{" Heat ", "Heat", 0},
+		{"Blade Runner 2049", "Blade Runner 2049", 0},
+		{"(500) Days of Summer", "(500) Days of Summer", 0},
+		{"Dune (Part Two)", "Dune (Part Two)", 0},
+	}
+	for _, tt := range tests {
+		title, year := splitTitleYear(tt.in)
+		if title != tt.title || year != tt.year {
+			t.Errorf("splitTitleYear(%q) = %q, %d, want %q, %d", tt.in, title, year, tt.title, tt.year)
+		}
+	}
+}
+
+func TestEstimateRemaining(t *testing.T) {
+	tests := []struct {
+		done, total   int
+		elapsed       time.Duration
+		wantPerMinute float64
+		wantRemaining time.Duration
+	}{
+		{0, 100, time.Minute, 0, 0},
+		{10, 100, 0, 0, 0},
+		{30, 120, time.Minute, 30, 3 * time.Minute},
+		{50, 100, 10 * time.Minute, 5, 10 * time.Minute},
+		{100, 100, 2 * time.Minute, 50, 0},
+	}
+	for _, tt := range tests {
+		perMinute, remaining := estimateRemaining(tt.done, tt.total, tt.elapsed)
+		if perMinute != tt.wantPerMinute || remaining != tt.wantRemaining {
+			t.Errorf("estimateRemaining(%d, %d, %s) = %v, %s; want %v, %s", tt.done, tt.total, tt.elapsed, perMinute, remaining, tt.wantPerMinute, tt.wantRemaining)
+		}
+	}
+}
+
+func TestCheckpointIndex(t *testing.T) {
+	items := []MediaItem{
+		plex.Movie{RatingKey: "10"},
+		plex.Movie{RatingKey: "11"},
+		plex.Movie{RatingKey: "12"},
+	}
+
+	tests := []struct {
+		name string
+		cp   storage.Checkpoint
+		want int
+	}{
+		{"item found", storage.Checkpoint{RatingKey: "11", Offset: 0}, 2},
+		{"last item", storage.Checkpoint{RatingKey: "12", Offset: 3}, 3},
+		{"item gone", storage.Checkpoint{RatingKey: "99", Offset: 1}, 1},
+		{"offset past end", storage.Checkpoint{RatingKey: "99", Offset: 5}, 3},
+	}
+	for _, tt := range tests {
+		if got := checkpointIndex(&tt.cp, items); got != tt.want {
+			t.Errorf("%s: checkpointIndex() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStudioLabels(t *testing.T) {
+	details := &tmdb.Details{ProductionCompanies: []tmdb.Company{
+		{Name: "A24"},
+		{Name: " Warner Bros. Pictures "},
+		{Name: ""},
+		{Name: "a24"},
+	}}
+	tests := []struct {
+		name      string
+		allowlist []string
+		want      []string
+	}{
+		{"no allowlist", nil, []string{"A24", "Warner Bros. Pictures"}},
+		{"allowlist spelling wins", []string{"a24"}, []string{"a24"}},
+		{"nothing allowed", []string{"Pixar"}, nil},
+	}
+	for _, tt := range tests {
+		p := &Processor{config: &config.Config{StudioLabelAllowlist: tt.allowlist}}
+		if got := p.studioLabels(details); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: studioLabels() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveTMDbIDOrder(t *testing.T) {
+	p := &Processor{config: &config.Config{}}
+	path := []plex.Media{{Part: []plex.Part{{File: "/movies/Heat (1995) {tmdb-949}/Heat.mkv"}}}}
+
+	tests := []struct {
+		name       string
+		item       plex.Movie
+		wantID     string
+		wantSource string
+	}{
+		{"guid wins over path", plex.Movie{Guid: plex.FlexibleGuid{{ID: "tmdb://1"}}, Media: path}, "1", idSourceGUID},
+		{"path", plex.Movie{Media: path}, "949", idSourcePath},
+		{"nothing", plex.Movie{Title: "Unknown"}, "", ""},
+	}
+	for _, tt := range tests {
+		id, source := p.ResolveTMDbID(tt.item, MediaTypeMovie)
+		if id != tt.wantID || source != tt.wantSource {
+			t.Errorf("%s: ResolveTMDbID() = %q, %q; want %q, %q", tt.name, id, source, tt.wantID, tt.wantSource)
+		}
+	}
+}
+
+func TestSameValues(t *testing.T) {
+	tests := []struct {
+		a, b []string
+		want bool
+	}{
+		{[]string{"Drama", "Crime"}, []string{"crime", "drama"}, true},
+		{[]string{"Drama"}, []string{"Drama", "Crime"}, false},
+		{[]string{"Drama", "Crime"}, []string{"Drama"}, false},
+		{[]string{"Drama", "drama"}, []string{"Drama"}, true},
+		{nil, nil, true},
+	}
+	for _, tt := range tests {
+		if got := sameValues(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameValues(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFieldValues(t *testing.T) {
+	movie := plex.Movie{
+		Label:      []plex.Label{{Tag: "keep"}},
+		Genre:      []plex.Genre{{Tag: "Drama"}},
+		Collection: []plex.Label{{Tag: "Heist Films"}},
+	}
+	tests := map[string][]string{
+		"label":      {"keep"},
+		"Genre":      {"Drama"},
+		"collection": {"Heist Films"},
+		"other":      {},
+	}
+	for field, want := range tests {
+		if got := fieldValues(movie, field); !reflect.DeepEqual(got, want) {
+			t.Errorf("fieldValues(%q) = %v, want %v", field, got, want)
+		}
+	}
+}
+
+// labelServer is a MediaServer holding one movie's labels.
+type labelServer struct {
+	MediaServer
+	labels []string
+	locked bool // lock setting of the last removal
+}
+
+func (s *labelServer) GetMovieDetails(ratingKey string) (*plex.Movie, error) {
+	movie := &plex.Movie{RatingKey: ratingKey}
+	for _, label := range s.labels {
+		movie.Label = append(movie.Label, plex.Label{Tag: label})
+	}
+	return movie, nil
+}
+
+func (s *labelServer) GetMoviesFromLibrary(libraryID string) ([]plex.Movie, error) {
+	return []plex.Movie{{RatingKey: "1", Title: "Heat"}, {RatingKey: "2", Title: "Ronin"}}, nil
+}
+
+func (s *labelServer) SetMediaField(mediaID, libraryID string, values []string, field string, lockField bool, mediaType string) error {
+	s.labels = values
+	return nil
+}
+
+func (s *labelServer) UpdateMediaField(mediaID, libraryID string, keywords []string, updateField string, mediaType string) error {
+	s.labels = keywords
+	return nil
+}
+
+func (s *labelServer) RemoveMediaFieldKeywords(mediaID, libraryID string, valuesToRemove []string, updateField string, lockField bool, mediaType string) error {
+	s.labels = missingFrom(s.labels, valuesToRemove)
+	s.locked = lockField
+	return nil
+}
+
+func TestReconcileDynamicLabelsKeepsLabelsWhenASourceFails(t *testing.T) {
+	stor, err := storage.NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stor.Set(&storage.ProcessedItem{RatingKey: "1", ManagedLabels: []string{"Trending", "On List"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var listErr error
+	saved := managedSources
+	defer func() { managedSources = saved }()
+	managedSources = []managedSource{
+		{
+			name:    "list",
+			enabled: func(p *Processor) bool { return true },
+			labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+				return nil, listErr
+			},
+		},
+	}
+
+	server := &labelServer{labels: []string{"Trending", "On List"}}
+	p := &Processor{config: &config.Config{UpdateField: "label"}, server: server, storage: stor}
+	item := plex.Movie{RatingKey: "1", Title: "Heat"}
+
+	listErr = errors.New("list unavailable")
+	p.reconcileDynamicLabels(item, "1", "949", MediaTypeMovie)
+	if want := []string{"Trending", "On List"}; !reflect.DeepEqual(server.labels, want) {
+		t.Errorf("labels after failed source = %v, want %v", server.labels, want)
+	}
+
+	listErr = nil
+	p.reconcileDynamicLabels(item, "1", "949", MediaTypeMovie)
+	if len(server.labels) != 0 {
+		t.Errorf("labels after source answered = %v, want none", server.labels)
+	}
+	if !server.locked {
+		t.Error("expired labels were removed without locking the field")
+	}
+	if processed, _ := stor.Get("1"); len(processed.ManagedLabels) != 0 {
+		t.Errorf("ManagedLabels = %v, want none", processed.ManagedLabels)
+	}
+}
+
+func TestRollbackItemsReplaysHistory(t *testing.T) {
+	stor, err := storage.NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	heat := &storage.ProcessedItem{RatingKey: "1", UpdateField: "label", KeywordsSynced: true, History: []storage.LabelChange{
+		{Field: "label", Prior: []string{"4K", "sci fi"}, Added: []string{"heist", "sequel"}, Removed: []string{"sci fi"}},
+		{Field: "label", Removed: []string{"sequel"}},
+	}}
+	ronin := &storage.ProcessedItem{RatingKey: "2", UpdateField: "label", KeywordsSynced: true, Untracked: true}
+	for _, item := range []*storage.ProcessedItem{heat, ronin} {
+		if err := stor.Set(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := &labelServer{labels: []string{"4K", "heist", "Favorites"}}
+	p := &Processor{config: &config.Config{UpdateField: "label", BatchSize: 10}, server: server, storage: stor}
+	if err := p.rollbackItems("1", "movies", "", MediaTypeMovie); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"4K", "Favorites", "sci fi"}; !reflect.DeepEqual(server.labels, want) {
+		t.Errorf("labels after rollback = %v, want %v", server.labels, want)
+	}
+	if _, ok := stor.Get("1"); ok {
+		t.Error("rolled back item is still in storage")
+	}
+	if _, ok := stor.Get("2"); !ok {
+		t.Error("untracked item was forgotten although nothing was rolled back")
+	}
+}
+
+// refServer reports every item as deleted and records the lookups.
+type refServer struct {
+	MediaServer
+	looked []string
+}
+
+func (s *refServer) GetItemRef(ratingKey string) (*plex.ItemRef, error) {
+	s.looked = append(s.looked, ratingKey)
+	return nil, plex.ErrNotFound
+}
+
+func TestPruneDeletedOnlyChecksScannedLibraries(t *testing.T) {
+	stor, err := storage.NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range []*storage.ProcessedItem{
+		{RatingKey: "1", LibraryID: "movies"},
+		{RatingKey: "2", LibraryID: "movies"},
+		{RatingKey: "3", LibraryID: "shows"},
+		{RatingKey: "4"},
+	} {
+		if err := stor.Set(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := &refServer{}
+	p := &Processor{
+		config:  &config.Config{PruneDeleted: true},
+		server:  server,
+		storage: stor,
+		seen:    make(map[string]bool),
+		scanned: make(map[string]bool),
+	}
+	p.markSeen("movies", []MediaItem{plex.Movie{RatingKey: "1"}, plex.Movie{RatingKey: "4"}})
+	p.PruneDeleted()
+
+	if want := []string{"2"}; !reflect.DeepEqual(server.looked, want) {
+		t.Errorf("looked up %v, want %v", server.looked, want)
+	}
+	if processed, ok := stor.Get("4"); !ok || processed.LibraryID != "movies" {
+		t.Errorf("item 4 = %+v, want kept with library movies", processed)
+	}
+	for key, want := range map[string]bool{"1": true, "2": false, "3": true} {
+		if _, ok := stor.Get(key); ok != want {
+			t.Errorf("item %s kept = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+// episodeServer counts the episode listings it serves.
+type episodeServer struct {
+	MediaServer
+	calls int
+}
+
+func (s *episodeServer) GetTVShowEpisodes(ratingKey string) ([]plex.Episode, error) {
+	s.calls++
+	return []plex.Episode{{RatingKey: ratingKey + "1"}}, nil
+}
+
+func TestShowEpisodesCachedPerShow(t *testing.T) {
+	server := &episodeServer{}
+	p := &Processor{server: server, episodeCache: make(map[string][]plex.Episode)}
+	for range 3 {
+		if _, err := p.showEpisodes("10"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if server.calls != 1 {
+		t.Errorf("listings fetched = %d, want 1", server.calls)
+	}
+
+	p.forgetEpisodes("10")
+	if _, err := p.showEpisodes("10"); err != nil {
+		t.Fatal(err)
+	}
+	if server.calls != 2 {
+		t.Errorf("listings fetched after forgetEpisodes = %d, want 2", server.calls)
+	}
+}
+
+// guidServer lists the labelServer movie with its TMDb GUID.
+type guidServer struct {
+	labelServer
+}
+
+func (s *guidServer) GetMoviesFromLibrary(libraryID string) ([]plex.Movie, error) {
+	return []plex.Movie{{RatingKey: "1", Title: "Heat", Guid: plex.FlexibleGuid{{ID: "tmdb://949"}}}}, nil
+}
+
+func TestRemoveLockOnBaselineStorage(t *testing.T) {
+	dir := t.TempDir()
+	baseline := `{"1": {"ratingKey": "1", "title": "Heat", "tmdbId": "949", "lastProcessed": "2025-01-02T03:04:05Z", "keywordsSynced": true, "updateField": "label"}}`
+	if err := os.WriteFile(filepath.Join(dir, "processed_items.json"), []byte(baseline), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stor, err := storage.NewStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := &guidServer{labelServer{labels: []string{"Heist", "Favorites"}}}
+	p := &Processor{
+		config:       &config.Config{UpdateField: "label", RemoveMode: "lock", BatchSize: 10},
+		server:       server,
+		storage:      stor,
+		keywordCache: map[string][]string{string(MediaTypeMovie) + ":949": {"heist"}},
+	}
+	if err := p.RemoveKeywordsFromItems("1", MediaTypeMovie); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Favorites"}; !reflect.DeepEqual(server.labels, want) {
+		t.Errorf("labels after REMOVE=lock = %v, want %v", server.labels, want)
+	}
+}
+
+func TestExpiredLabelsKeepFieldUnlocked(t *testing.T) {
+	stor, err := storage.NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stor.Set(&storage.ProcessedItem{RatingKey: "1", ManagedLabels: []string{"Trending"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := managedSources
+	defer func() { managedSources = saved }()
+	managedSources = []managedSource{{
+		name:    "trending",
+		enabled: func(p *Processor) bool { return true },
+		labels: func(p *Processor, item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+			return nil, nil
+		},
+	}}
+
+	server := &labelServer{labels: []string{"Trending"}, locked: true}
+	p := &Processor{config: &config.Config{UpdateField: "label", RemoveMode: "unlock"}, server: server, storage: stor}
+	p.reconcileDynamicLabels(plex.Movie{RatingKey: "1", Title: "Heat"}, "1", "949", MediaTypeMovie)
+	if len(server.labels) != 0 {
+		t.Fatalf("labels = %v, want the expired label removed", server.labels)
+	}
+	if server.locked {
+		t.Error("REMOVE=unlock run locked the field when removing an expired label")
+	}
+}
+
+// failingServer lists the guidServer movie but fails every field update.
+type failingServer struct {
+	guidServer
+}
+
+func (s *failingServer) UpdateMediaField(mediaID, libraryID string, keywords []string, updateField string, mediaType string) error {
+	return errors.New("plex unavailable")
+}
+
+func TestProcessAllItemsReportsItemFailures(t *testing.T) {
+	newProcessor := func(server MediaServer) *Processor {
+		return &Processor{
+			config:       &config.Config{UpdateField: "label", BatchSize: 10},
+			server:       server,
+			keywordCache: map[string][]string{string(MediaTypeMovie) + ":949": {"heist"}},
+			seen:         make(map[string]bool),
+			scanned:      make(map[string]bool),
+			processing:   make(map[string]bool),
+			stopCh:       make(chan struct{}),
+		}
+	}
+
+	ok := &guidServer{}
+	if err := newProcessor(ok).ProcessAllItems("1", "Movies", MediaTypeMovie); err != nil {
+		t.Fatalf("ProcessAllItems() = %v, want nil", err)
+	}
+	if want := []string{"heist"}; !reflect.DeepEqual(ok.labels, want) {
+		t.Errorf("labels = %v, want %v", ok.labels, want)
+	}
+
+	err := newProcessor(&failingServer{}).ProcessAllItems("1", "Movies", MediaTypeMovie)
+	if !errors.Is(err, ErrItemsFailed) {
+		t.Errorf("ProcessAllItems() with a failing item = %v, want ErrItemsFailed", err)
+	}
+}
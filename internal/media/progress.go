@@ -0,0 +1,87 @@
+package media
+
+import (
+	"fmt"
+	"time"
+)
+
+// runProgress tracks how far a library run has got and where its time goes,
+// so progress lines can show throughput and an estimated completion time and
+// the summary can split the run into keyword fetching and Plex writes.
+type runProgress struct {
+	start      time.Time
+	total      int
+	done       int
+	resumed    int // items skipped by resuming from a checkpoint
+	fetchTime  time.Duration
+	fetchCount int
+	writeTime  time.Duration
+	writeCount int
+}
+
+func newRunProgress(total int) *runProgress {
+	return &runProgress{start: time.Now(), total: total}
+}
+
+// resume marks the first n items as done by an earlier, interrupted scan.
+// They count towards the percentage but not the throughput.
+func (r *runProgress) resume(n int) {
+	r.done = n
+	r.resumed = n
+}
+
+// timeFetch adds the time since start to the keyword fetch phase.
+func (r *runProgress) timeFetch(start time.Time) {
+	r.fetchTime += time.Since(start)
+	r.fetchCount++
+}
+
+// timeWrite adds the time since start to the Plex write phase.
+func (r *runProgress) timeWrite(start time.Time) {
+	r.writeTime += time.Since(start)
+	r.writeCount++
+}
+
+// progressLine formats the periodic progress report, e.g.
+// "40% (400/1000 movies processed, 120.0 items/min, ETA 14:32, ~5m0s left)".
+func (r *runProgress) progressLine(displayName string) string {
+	percent := r.done * 100 / r.total
+	perMinute, remaining := estimateRemaining(r.done-r.resumed, r.total-r.resumed, time.Since(r.start))
+	line := fmt.Sprintf("%d%% (%d/%d %s processed, %.1f items/min", percent, r.done, r.total, displayName, perMinute)
+	if remaining > 0 {
+		precision := time.Minute
+		if remaining < time.Minute {
+			precision = time.Second
+		}
+		line += fmt.Sprintf(", ETA %s, ~%s left", time.Now().Add(remaining).Format("15:04"), remaining.Round(precision))
+	}
+	return line + ")"
+}
+
+// printSummary prints the run's elapsed time, throughput and per-phase
+// timings as part of the processing summary.
+func (r *runProgress) printSummary() {
+	elapsed := time.Since(r.start)
+	perMinute, _ := estimateRemaining(r.done-r.resumed, r.total-r.resumed, elapsed)
+	fmt.Printf("  [TIMER] Elapsed: %s (%.1f items/min)\n", elapsed.Round(time.Second), perMinute)
+	if r.fetchCount > 0 {
+		fmt.Printf("  [TIMER] Keyword fetch: %s over %d items (avg %s)\n", r.fetchTime.Round(time.Second), r.fetchCount, (r.fetchTime / time.Duration(r.fetchCount)).Round(time.Millisecond))
+	}
+	if r.writeCount > 0 {
+		fmt.Printf("  [TIMER] Plex write: %s over %d items (avg %s)\n", r.writeTime.Round(time.Second), r.writeCount, (r.writeTime / time.Duration(r.writeCount)).Round(time.Millisecond))
+	}
+}
+
+// estimateRemaining returns the throughput in items per minute after done of
+// total items took elapsed, and the time the remaining items will take at
+// that rate. Both are 0 until there is something to measure.
+func estimateRemaining(done, total int, elapsed time.Duration) (float64, time.Duration) {
+	if done <= 0 || elapsed <= 0 {
+		return 0, 0
+	}
+	perMinute := float64(done) / elapsed.Minutes()
+	if done >= total {
+		return perMinute, 0
+	}
+	return perMinute, time.Duration(float64(total-done) / float64(done) * float64(elapsed))
+}
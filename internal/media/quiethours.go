@@ -0,0 +1,19 @@
+package media
+
+import (
+	"fmt"
+	"time"
+)
+
+// WaitQuietHours blocks while the current time is inside a NO_PROCESS_WINDOW
+// window, so runs that would start during quiet hours are delayed until the
+// window closes, or Stop is called. A run already in progress when a window
+// opens is not interrupted. what names the delayed run in the log.
+func (p *Processor) WaitQuietHours(what string) {
+	wait := p.quietHours.Remaining(time.Now())
+	if wait <= 0 {
+		return
+	}
+	fmt.Printf("[TIMER] %s delayed by NO_PROCESS_WINDOW until %s\n", what, time.Now().Add(wait).Format("15:04"))
+	p.sleep(wait)
+}
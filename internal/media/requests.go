@@ -0,0 +1,52 @@
+package media
+
+import (
+	"fmt"
+	"strings"
+)
+
+// requestLabels returns an OVERSEERR_LABEL_FORMAT label for every user who
+// requested the item in Overseerr. err is set when the requests can't be
+// fetched.
+func (p *Processor) requestLabels(tmdbID string, mediaType MediaType) ([]string, error) {
+	requesters, err := p.requesters()
+	if err != nil {
+		return nil, fmt.Errorf("Overseerr requests: %w", err)
+	}
+
+	var labels []string
+	for _, user := range requesters[tmdbMediaType(mediaType)+":"+tmdbID] {
+		labels = appendUnique(labels, requestLabel(p.config.OverseerrLabelFormat, user))
+	}
+	return labels, nil
+}
+
+// requestLabel formats the label for a requester. A format without %s names
+// no one, so every requested item gets the same label.
+func requestLabel(format, user string) string {
+	if !strings.Contains(format, "%s") {
+		return format
+	}
+	return fmt.Sprintf(format, user)
+}
+
+// requesters returns who requested each item in Overseerr, fetched once per
+// processing cycle.
+func (p *Processor) requesters() (map[string][]string, error) {
+	p.cacheMu.RLock()
+	requesters := p.requestCache
+	p.cacheMu.RUnlock()
+	if requesters != nil {
+		return requesters, nil
+	}
+
+	requesters, err := p.overseerrClient.GetRequesters()
+	if err != nil {
+		return nil, err
+	}
+
+	p.cacheMu.Lock()
+	p.requestCache = requesters
+	p.cacheMu.Unlock()
+	return requesters, nil
+}
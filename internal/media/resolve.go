@@ -0,0 +1,278 @@
+package media
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TMDb ID sources, in the order ResolveTMDbID tries them.
+const (
+	idSourceGUID   = "Plex metadata"
+	idSourcePath   = "file path"
+	idSourceRadarr = "Radarr"
+	idSourceSonarr = "Sonarr"
+	idSourceFind   = "TMDb find"
+	idSourceSearch = "TMDb search"
+)
+
+// idSourceOrder lists every source resolveItemID can report, in summary order.
+// The last three resolve items without a TMDb ID; "none" counts failures.
+var idSourceOrder = []string{
+	idSourceGUID, idSourcePath, idSourceRadarr, idSourceSonarr, idSourceFind, idSourceSearch,
+	"TVDB_KEYWORDS=only", "AniList", "PLEX_SIMILAR_SEEDS", "none",
+}
+
+// ResolveTMDbID finds the item's TMDb ID by trying, in order: its Plex GUIDs,
+// a tmdb-<id> tag in its file paths, Radarr (movies) or Sonarr (TV shows) by
+// title/year, external ID and path, a TMDb find by IMDb/TVDb ID, and finally
+// a TMDb title search. It returns the ID and the source that produced it, or
+// two empty strings. Each step is traced under VERBOSE_LOGGING.
+func (p *Processor) ResolveTMDbID(item MediaItem, mediaType MediaType) (string, string) {
+	verbose := p.config.VerboseLogging
+	if verbose {
+		kind := "Movie"
+		if mediaType == MediaTypeTV {
+			kind = "TV show"
+		}
+		fmt.Printf("\n[LOOKUP] %s: %s (%d)\n", kind, item.GetTitle(), item.GetYear())
+	}
+
+	// File paths are fetched at most once, and only if a step needs them:
+	// for TV shows they come from an extra episode listing.
+	var paths []string
+	fetched := false
+	itemPaths := func() []string {
+		if !fetched {
+			paths, fetched = p.lookupPaths(item, mediaType), true
+		}
+		return paths
+	}
+
+	steps := []struct {
+		source  string
+		resolve func() string
+	}{
+		{idSourceGUID, func() string { return p.guidTMDbID(item) }},
+		{idSourcePath, func() string { return p.pathTMDbID(itemPaths()) }},
+		{idSourceRadarr, func() string { return p.radarrTMDbID(item, mediaType, itemPaths) }},
+		{idSourceSonarr, func() string { return p.sonarrTMDbID(item, mediaType, itemPaths) }},
+		{idSourceFind, func() string { return p.findTMDbIDByExternalID(item, mediaType) }},
+		{idSourceSearch, func() string { return p.searchTMDbID(item, mediaType) }},
+	}
+	for _, step := range steps {
+		if tmdbID := step.resolve(); tmdbID != "" {
+			if verbose {
+				fmt.Printf("   [KEY] Resolved TMDb ID %s via %s\n", tmdbID, step.source)
+			}
+			return tmdbID, step.source
+		}
+	}
+
+	if verbose {
+		fmt.Printf("   [SKIP] No TMDb ID found for: %s\n", item.GetTitle())
+	}
+	return "", ""
+}
+
+// guidTMDbID returns the ID from the item's tmdb:// GUID.
+func (p *Processor) guidTMDbID(item MediaItem) string {
+	for _, guid := range item.GetGuid() {
+		if !strings.Contains(guid.ID, "tmdb://") {
+			continue
+		}
+		parts := strings.Split(guid.ID, "//")
+		if len(parts) < 2 {
+			continue
+		}
+		tmdbID := strings.Split(parts[1], "?")[0]
+		if p.config.VerboseLogging {
+			fmt.Printf("   [OK] Plex metadata: %s\n", tmdbID)
+		}
+		return tmdbID
+	}
+	return ""
+}
+
+// pathTMDbID returns the first TMDb ID tagged in one of the file paths.
+func (p *Processor) pathTMDbID(paths []string) string {
+	verbose := p.config.VerboseLogging
+	for i, path := range paths {
+		if verbose && i < 3 {
+			fmt.Printf("   [INFO] Checking path: %s\n", path)
+		}
+		if tmdbID := ExtractTMDbIDFromPath(path); tmdbID != "" {
+			if verbose {
+				fmt.Printf("   [OK] TMDb ID in file path: %s\n", tmdbID)
+			}
+			return tmdbID
+		}
+	}
+	if verbose && len(paths) > 3 {
+		fmt.Printf("   [INFO] ... and %d more paths checked\n", len(paths)-3)
+	}
+	return ""
+}
+
+// lookupPaths returns the item's file paths: a movie's parts, or the parts of
+// every episode of a TV show.
+func (p *Processor) lookupPaths(item MediaItem, mediaType MediaType) []string {
+	media := item.GetMedia()
+	if mediaType == MediaTypeTV {
+		episodes, err := p.showEpisodes(item.GetRatingKey())
+		if err != nil && p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Could not fetch episodes: %v\n", err)
+		}
+		media = nil
+		for _, episode := range episodes {
+			media = append(media, episode.Media...)
+		}
+	}
+
+	var paths []string
+	for _, m := range media {
+		for _, part := range m.Part {
+			paths = append(paths, part.File)
+		}
+	}
+	return paths
+}
+
+// radarrTMDbID looks a movie up in Radarr by title/year, IMDb ID and file
+// path, in that order.
+func (p *Processor) radarrTMDbID(item MediaItem, mediaType MediaType, paths func() []string) string {
+	if mediaType != MediaTypeMovie || len(p.radarrClients) == 0 {
+		return ""
+	}
+	verbose := p.config.VerboseLogging
+
+	if p.config.UseRadarr {
+		if tmdbID, title := p.radarrTitleMatch(item); tmdbID != "" {
+			if verbose {
+				fmt.Printf("   [OK] Radarr match: %s (TMDb: %s)\n", title, tmdbID)
+			}
+			return tmdbID
+		} else if verbose {
+			fmt.Printf("   [SKIP] No Radarr match by title/year\n")
+		}
+
+		for _, guid := range item.GetGuid() {
+			if !strings.Contains(guid.ID, "imdb://") {
+				continue
+			}
+			imdbID := strings.TrimPrefix(guid.ID, "imdb://")
+			for _, client := range p.radarrClients {
+				movie, err := client.GetMovieByIMDbID(imdbID)
+				if err == nil && movie != nil {
+					tmdbID := client.GetTMDbIDFromMovie(movie)
+					if verbose {
+						fmt.Printf("   [OK] Radarr match by IMDb %s: %s (TMDb: %s)\n", imdbID, movie.Title, tmdbID)
+					}
+					return tmdbID
+				}
+			}
+			if verbose {
+				fmt.Printf("   [SKIP] No Radarr match by IMDb ID %s\n", imdbID)
+			}
+		}
+	}
+
+	for _, path := range paths() {
+		for _, client := range p.radarrClients {
+			movie, err := client.GetMovieByPath(path)
+			if err == nil && movie != nil {
+				tmdbID := client.GetTMDbIDFromMovie(movie)
+				if verbose {
+					fmt.Printf("   [OK] Radarr path match: %s (TMDb: %s)\n", movie.Title, tmdbID)
+				}
+				return tmdbID
+			}
+		}
+	}
+	return ""
+}
+
+// sonarrTMDbID looks a TV show up in Sonarr by title/year, TVDb ID, IMDb ID
+// and episode file path, in that order.
+func (p *Processor) sonarrTMDbID(item MediaItem, mediaType MediaType, paths func() []string) string {
+	if mediaType != MediaTypeTV || len(p.sonarrClients) == 0 {
+		return ""
+	}
+	verbose := p.config.VerboseLogging
+
+	if p.config.UseSonarr {
+		if tmdbID, title := p.sonarrTitleMatch(item); tmdbID != "" {
+			if verbose {
+				fmt.Printf("   [OK] Sonarr match: %s (TMDb: %s)\n", title, tmdbID)
+			}
+			return tmdbID
+		} else if verbose {
+			fmt.Printf("   [SKIP] No Sonarr match by title/year\n")
+		}
+
+		for _, guid := range item.GetGuid() {
+			if strings.Contains(guid.ID, "tvdb://") {
+				tvdbIDStr := strings.TrimPrefix(guid.ID, "tvdb://")
+				var tvdbID int
+				if _, err := fmt.Sscanf(tvdbIDStr, "%d", &tvdbID); err == nil {
+					for _, client := range p.sonarrClients {
+						series, err := client.GetSeriesByTVDbID(tvdbID)
+						if err == nil && series != nil {
+							tmdbID := client.GetTMDbIDFromSeries(series)
+							if verbose {
+								fmt.Printf("   [OK] Sonarr match by TVDb %d: %s (TMDb: %s)\n", tvdbID, series.Title, tmdbID)
+							}
+							return tmdbID
+						}
+					}
+					if verbose {
+						fmt.Printf("   [SKIP] No Sonarr match by TVDb ID %d\n", tvdbID)
+					}
+				}
+			}
+			if strings.Contains(guid.ID, "imdb://") {
+				imdbID := strings.TrimPrefix(guid.ID, "imdb://")
+				for _, client := range p.sonarrClients {
+					series, err := client.GetSeriesByIMDbID(imdbID)
+					if err == nil && series != nil {
+						tmdbID := client.GetTMDbIDFromSeries(series)
+						if verbose {
+							fmt.Printf("   [OK] Sonarr match by IMDb %s: %s (TMDb: %s)\n", imdbID, series.Title, tmdbID)
+						}
+						return tmdbID
+					}
+				}
+				if verbose {
+					fmt.Printf("   [SKIP] No Sonarr match by IMDb ID %s\n", imdbID)
+				}
+			}
+		}
+	}
+
+	for _, path := range paths() {
+		for _, client := range p.sonarrClients {
+			series, err := client.GetSeriesByPath(path)
+			if err == nil && series != nil {
+				tmdbID := client.GetTMDbIDFromSeries(series)
+				if verbose {
+					fmt.Printf("   [OK] Sonarr path match: %s (TMDb: %s)\n", series.Title, tmdbID)
+				}
+				return tmdbID
+			}
+		}
+	}
+	return ""
+}
+
+// printIDSources prints how many items each ID source resolved, in chain
+// order, as part of the processing summary.
+func printIDSources(counts map[string]int) {
+	var parts []string
+	for _, source := range idSourceOrder {
+		if counts[source] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", source, counts[source]))
+		}
+	}
+	if len(parts) > 0 {
+		fmt.Printf("  [KEY] IDs by source: %s\n", strings.Join(parts, ", "))
+	}
+}
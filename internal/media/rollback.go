@@ -0,0 +1,120 @@
+package media
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rollbackItems implements REMOVE=applied: it replays each item's history
+// backwards, stripping the values labelarr added and putting back the values
+// it removed that were there before, then unlocks the field and forgets the
+// item, so the library is back to its pre-labelarr state and a later run
+// starts from scratch. Values that were already on an item before labelarr
+// added them are kept. Items whose history attributes nothing to labelarr
+// are left in storage.
+func (p *Processor) rollbackItems(libraryID, displayName, emoji string, mediaType MediaType) error {
+	if p.storage == nil {
+		return fmt.Errorf("REMOVE=applied requires DATA_DIR")
+	}
+
+	fmt.Printf("\n[INFO] Fetching all %s for rollback...\n", displayName)
+
+	items, err := p.fetchItems(libraryID, mediaType)
+	if err != nil {
+		return fmt.Errorf("error fetching %s: %w", displayName, err)
+	}
+	fmt.Printf("[OK] Found %d %s in library\n", len(items), displayName)
+
+	rolledBack := 0
+	skippedCount := 0
+	totalRemoved := 0
+	totalRestored := 0
+	var forget []string
+
+	for _, b := range p.makeBatches(items) {
+		b.logStart(emoji+" Rollback", len(items))
+
+		for _, item := range b.items {
+			processed, ok := p.storage.Get(item.GetRatingKey())
+			if !ok {
+				skippedCount++
+				continue
+			}
+			if !strings.EqualFold(processed.UpdateField, p.config.UpdateField) {
+				fmt.Printf("[WARN] %s was labelled in the %s field; run with UPDATE_FIELD=%s to roll it back\n", item.GetTitle(), processed.UpdateField, processed.UpdateField)
+				skippedCount++
+				continue
+			}
+
+			if len(processed.History) == 0 {
+				if processed.Untracked {
+					hint := "use REMOVE=lock or REMOVE=unlock to clean it up"
+					if p.config.ProtectManualLabels {
+						hint = "set PROTECT_MANUAL_LABELS=false and use REMOVE=lock or REMOVE=unlock to clean it up"
+					}
+					fmt.Printf("[WARN] %s was labelled by a version that didn't record what it applied; %s\n", item.GetTitle(), hint)
+				}
+				skippedCount++
+				continue
+			}
+
+			details, err := p.getItemDetails(item.GetRatingKey(), mediaType)
+			if err != nil {
+				fmt.Printf("[ERROR] Error fetching %s details for %s: %v\n", strings.TrimSuffix(displayName, "s"), item.GetTitle(), err)
+				skippedCount++
+				continue
+			}
+
+			currentValues := p.extractCurrentValues(details)
+			net := processed.NetChange()
+			valuesToRemove := missingFrom(currentValues, missingFrom(currentValues, net.Added))
+			valuesToRestore := missingFrom(net.Removed, currentValues)
+
+			if len(valuesToRestore) > 0 {
+				plexMediaType, err := p.toPlexMediaType(mediaType)
+				if err == nil {
+					restored := append(append([]string{}, currentValues...), valuesToRestore...)
+					err = p.server.SetMediaField(item.GetRatingKey(), libraryID, restored, p.config.UpdateField, false, plexMediaType)
+				}
+				if err != nil {
+					fmt.Printf("[ERROR] Error rolling back %s: %v\n", item.GetTitle(), err)
+					skippedCount++
+					continue
+				}
+				fmt.Printf("[REMOVE] %s: restored %d values labelarr removed %v\n", item.GetTitle(), len(valuesToRestore), valuesToRestore)
+				totalRestored += len(valuesToRestore)
+			}
+			if len(valuesToRemove) > 0 {
+				if err := p.removeItemFieldKeywords(item.GetRatingKey(), libraryID, valuesToRemove, false, mediaType); err != nil {
+					fmt.Printf("[ERROR] Error rolling back %s: %v\n", item.GetTitle(), err)
+					skippedCount++
+					continue
+				}
+				fmt.Printf("[REMOVE] %s: removed %d labelarr values %v\n", item.GetTitle(), len(valuesToRemove), valuesToRemove)
+				totalRemoved += len(valuesToRemove)
+			}
+			if len(valuesToRemove) > 0 || len(valuesToRestore) > 0 {
+				rolledBack++
+				p.sleep(p.itemDelay())
+			}
+			forget = append(forget, item.GetRatingKey())
+		}
+
+		p.pauseAfterBatch(b, emoji+" Rollback")
+	}
+
+	if len(forget) > 0 {
+		if err := p.storage.Delete(forget...); err != nil {
+			fmt.Printf("[WARN] Failed to remove rolled back items from storage: %v\n", err)
+		}
+	}
+
+	fmt.Printf("\n[STATS] Rollback Summary:\n")
+	fmt.Printf("  [TOTAL] Total %s checked: %d\n", displayName, len(items))
+	fmt.Printf("  [REMOVE] %s rolled back: %d\n", displayName, rolledBack)
+	fmt.Printf("  [SKIP] Skipped %s: %d\n", displayName, skippedCount)
+	fmt.Printf("  [LABEL] Total values removed: %d\n", totalRemoved)
+	fmt.Printf("  [LABEL] Total values restored: %d\n", totalRestored)
+
+	return nil
+}
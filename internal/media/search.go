@@ -0,0 +1,135 @@
+package media
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/nullable-eth/labelarr/internal/tmdb"
+)
+
+// accentFolds maps accented Latin letters to their plain forms, so "Amélie"
+// and "Amelie" clean to the same title.
+var accentFolds = func() *strings.Replacer {
+	groups := map[string]string{
+		"a": "àáâãäåāăą", "c": "çćĉċč", "d": "ďđð", "e": "èéêëēĕėęě",
+		"g": "ĝğġģ", "h": "ĥħ", "i": "ìíîïĩīĭįı", "j": "ĵ", "k": "ķ",
+		"l": "ĺļľŀł", "n": "ñńņňŉ", "o": "òóôõöøōŏő", "r": "ŕŗř",
+		"s": "śŝşš", "t": "ţťŧ", "u": "ùúûüũūŭůűų", "w": "ŵ", "y": "ýÿŷ",
+		"z": "źżž", "ae": "æ", "oe": "œ", "ss": "ß", "th": "þ",
+	}
+	var pairs []string
+	for plain, accented := range groups {
+		for _, r := range accented {
+			pairs = append(pairs, string(r), plain)
+		}
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// cleanTitle lowercases a title, folds accents and strips everything but
+// letters and digits so accent, punctuation and spacing differences don't
+// affect matching. Letters of any script are kept; combining marks, as in
+// decomposed accents, are dropped.
+func cleanTitle(s string) string {
+	var b strings.Builder
+	for _, r := range accentFolds.Replace(strings.ToLower(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// searchConfidence scores how well a TMDb search result matches the Plex
+// title and year, from 0 (no match) to 1 (exact title and year). Title
+// similarity contributes up to 0.7 and year proximity up to 0.3.
+func searchConfidence(title string, year int, result tmdb.SearchResult) float64 {
+	query := cleanTitle(title)
+	if query == "" {
+		return 0
+	}
+
+	titleScore := 0.0
+	for _, candidate := range []string{result.DisplayTitle(), result.OriginalTitle, result.OriginalName} {
+		c := cleanTitle(candidate)
+		if c == "" {
+			continue
+		}
+		switch {
+		case c == query:
+			titleScore = 0.7
+		case (strings.Contains(c, query) || strings.Contains(query, c)) && titleScore < 0.4:
+			titleScore = 0.4
+		}
+	}
+
+	yearScore := 0.0
+	if resultYear := result.Year(); year > 0 && resultYear > 0 {
+		switch diff := resultYear - year; {
+		case diff == 0:
+			yearScore = 0.3
+		case diff == 1 || diff == -1:
+			yearScore = 0.15
+		}
+	}
+
+	return titleScore + yearScore
+}
+
+// searchTMDbID is the last-resort lookup: a TMDb title search scored by
+// searchConfidence. Only the best result at or above
+// TMDB_SEARCH_MIN_CONFIDENCE is accepted.
+func (p *Processor) searchTMDbID(item MediaItem, mediaType MediaType) string {
+	if !p.config.TMDbSearchFallback {
+		return ""
+	}
+	verbose := p.config.VerboseLogging
+	tmdbType := tmdbMediaType(mediaType)
+
+	cacheKey := tmdbType + ":search:" + cleanTitle(item.GetTitle()) + ":" + strconv.Itoa(item.GetYear())
+	p.cacheMu.RLock()
+	cached, ok := p.findCache[cacheKey]
+	p.cacheMu.RUnlock()
+	if ok {
+		return cached
+	}
+
+	// No year filter: TMDb and Plex often disagree by a year (festival vs.
+	// general release), which searchConfidence still scores
+	results, err := p.tmdbClient.SearchByTitle(item.GetTitle(), 0, tmdbType)
+	if err != nil {
+		if verbose {
+			fmt.Printf("   [WARN] TMDb search failed for %q: %v\n", item.GetTitle(), err)
+		}
+		return ""
+	}
+
+	var best tmdb.SearchResult
+	bestScore := 0.0
+	for _, r := range results {
+		if score := searchConfidence(item.GetTitle(), item.GetYear(), r); score > bestScore {
+			best, bestScore = r, score
+		}
+	}
+
+	tmdbID := ""
+	if bestScore >= p.config.TMDbSearchMinConfidence {
+		tmdbID = strconv.Itoa(best.ID)
+		if verbose {
+			fmt.Printf("   [OK] TMDb search match: %s (%d) -> TMDb %s (confidence %.2f)\n", best.DisplayTitle(), best.Year(), tmdbID, bestScore)
+		}
+	} else if verbose {
+		if bestScore > 0 {
+			fmt.Printf("   [SKIP] Best TMDb search match %s (%d) below confidence threshold (%.2f < %.2f)\n", best.DisplayTitle(), best.Year(), bestScore, p.config.TMDbSearchMinConfidence)
+		} else {
+			fmt.Printf("   [SKIP] No TMDb search results for %q\n", item.GetTitle())
+		}
+	}
+
+	p.cacheMu.Lock()
+	p.findCache[cacheKey] = tmdbID
+	p.cacheMu.Unlock()
+	return tmdbID
+}
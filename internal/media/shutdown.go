@@ -0,0 +1,57 @@
+package media
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+// ErrStopped is returned by ProcessAllItems when Stop ended the run before
+// every item was processed.
+var ErrStopped = errors.New("processing stopped by shutdown")
+
+// Stop asks in-flight runs to stop after the item they are on; pauses between
+// items and batches end early. Safe to call more than once.
+func (p *Processor) Stop() {
+	p.stopOnce.Do(func() { close(p.stopCh) })
+}
+
+// Stopped reports whether Stop has been called.
+func (p *Processor) Stopped() bool {
+	select {
+	case <-p.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep pauses for d, returning early if Stop is called.
+func (p *Processor) sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-p.stopCh:
+	}
+}
+
+// Sleep pauses for d like sleep, for work outside the package that should
+// end early on shutdown, such as webhook retries.
+func (p *Processor) Sleep(d time.Duration) {
+	p.sleep(d)
+}
+
+// FlushStorage writes the processed-items storage, if DATA_DIR is set, and
+// the pending changes of the API caches to disk.
+func (p *Processor) FlushStorage() error {
+	cacheErr := utils.FlushFileCaches()
+	if p.storage == nil {
+		return cacheErr
+	}
+	return errors.Join(p.storage.Flush(), cacheErr)
+}
@@ -0,0 +1,79 @@
+package media
+
+import (
+	"fmt"
+	"sort"
+)
+
+// similarCluster is a PLEX_SIMILAR_SEEDS seed together with the items Plex
+// relates to it.
+type similarCluster struct {
+	label   string
+	members map[string]bool // rating keys, including the seed's own
+}
+
+// similarLabels returns the label of every PLEX_SIMILAR_SEEDS cluster the
+// item belongs to. Clusters come from Plex's own similar and related hubs,
+// so they work for items without any external ID.
+func (p *Processor) similarLabels(item MediaItem) []string {
+	seeds := make([]string, 0, len(p.config.PlexSimilarSeeds))
+	for seed := range p.config.PlexSimilarSeeds {
+		seeds = append(seeds, seed)
+	}
+	sort.Strings(seeds)
+
+	var labels []string
+	for _, seed := range seeds {
+		cluster, err := p.similarCluster(seed)
+		if err != nil {
+			if p.config.VerboseLogging {
+				fmt.Printf("   [WARN] Could not fetch Plex similar items for %s: %v\n", seed, err)
+			}
+			continue
+		}
+		if cluster.members[item.GetRatingKey()] {
+			labels = appendUnique(labels, cluster.label)
+		}
+	}
+	return labels
+}
+
+// similarCluster returns the seed's cluster, fetched once per processing
+// cycle.
+func (p *Processor) similarCluster(seed string) (*similarCluster, error) {
+	p.cacheMu.RLock()
+	cluster, ok := p.similarCache[seed]
+	p.cacheMu.RUnlock()
+	if ok {
+		return cluster, nil
+	}
+
+	label := p.config.PlexSimilarSeeds[seed]
+	if label == "" {
+		ref, err := p.server.GetItemRef(seed)
+		if err != nil {
+			return nil, err
+		}
+		label = fmt.Sprintf(p.config.PlexSimilarLabelFormat, ref.Title)
+	}
+
+	cluster = &similarCluster{label: label, members: map[string]bool{seed: true}}
+	for _, hub := range p.config.PlexSimilarHubs {
+		fetch := p.server.GetSimilarItems
+		if hub == "related" {
+			fetch = p.server.GetRelatedItems
+		}
+		items, err := fetch(seed)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range items {
+			cluster.members[ref.RatingKey] = true
+		}
+	}
+
+	p.cacheMu.Lock()
+	p.similarCache[seed] = cluster
+	p.cacheMu.Unlock()
+	return cluster, nil
+}
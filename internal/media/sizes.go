@@ -0,0 +1,79 @@
+package media
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nullable-eth/labelarr/internal/config"
+	"github.com/nullable-eth/labelarr/internal/plex"
+)
+
+// sizeLabels returns the SIZE_LABELS labels whose rule matches the item's
+// total size and average bitrate. A TV show is measured across all of its
+// episodes. err is set when the episodes can't be fetched.
+func (p *Processor) sizeLabels(item MediaItem, mediaType MediaType) ([]string, error) {
+	media := item.GetMedia()
+	if mediaType == MediaTypeTV {
+		episodes, err := p.allShowEpisodes(item.GetRatingKey())
+		if err != nil {
+			return nil, fmt.Errorf("episodes for size labels: %w", err)
+		}
+		media = nil
+		for _, episode := range episodes {
+			media = append(media, episode.Media...)
+		}
+	}
+
+	sizeGB, bitrateMbps := mediaFootprint(media)
+	if sizeGB == 0 {
+		return nil, nil
+	}
+
+	rules := make([]string, 0, len(p.config.SizeLabels))
+	for rule := range p.config.SizeLabels {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	var labels []string
+	for _, rule := range rules {
+		metric, above, threshold, err := config.ParseSizeRule(rule)
+		if err != nil {
+			continue
+		}
+		value := sizeGB
+		if metric == "bitrate" {
+			// Without durations there is no bitrate to compare.
+			if bitrateMbps == 0 {
+				continue
+			}
+			value = bitrateMbps
+		}
+		if (above && value > threshold) || (!above && value < threshold) {
+			labels = appendUnique(labels, p.config.SizeLabels[rule])
+		}
+	}
+	return labels, nil
+}
+
+// mediaFootprint returns the total size in GB (10^9 bytes) of every part of
+// every version, and their average bitrate in Mbps. The bitrate is 0 when the
+// server reported no part durations.
+func mediaFootprint(media []plex.Media) (float64, float64) {
+	var size, timedSize, duration int64
+	for _, m := range media {
+		for _, part := range m.Part {
+			size += part.Size
+			if part.Duration > 0 {
+				timedSize += part.Size
+				duration += part.Duration
+			}
+		}
+	}
+
+	var bitrate float64
+	if duration > 0 {
+		bitrate = float64(timedSize) * 8 / (float64(duration) / 1000) / 1e6
+	}
+	return float64(size) / 1e9, bitrate
+}
@@ -0,0 +1,58 @@
+package media
+
+import (
+	"fmt"
+
+	"github.com/nullable-eth/labelarr/internal/config"
+)
+
+// IntegrationStatus is the state of one external service, as served by the
+// webhook server's /status endpoint.
+type IntegrationStatus struct {
+	Name     string   `json:"name"`
+	OK       bool     `json:"ok"`
+	Error    string   `json:"error,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ArrStatus checks every Radarr and Sonarr instance: whether it answers, and
+// what its health checks report. Instances are named by their env var
+// prefix (RADARR, RADARR_1, ...). Every call queries the instances afresh.
+func (p *Processor) ArrStatus() []IntegrationStatus {
+	var statuses []IntegrationStatus
+	for i, client := range p.radarrClients {
+		statuses = append(statuses, arrInstanceStatus(instanceName(p.config.RadarrInstances, i, "RADARR"), client.TestConnection, client.HealthWarnings))
+	}
+	for i, client := range p.sonarrClients {
+		statuses = append(statuses, arrInstanceStatus(instanceName(p.config.SonarrInstances, i, "SONARR"), client.TestConnection, client.HealthWarnings))
+	}
+	return statuses
+}
+
+// instanceName returns the env var prefix of the i-th configured instance.
+// Clients are created in configuration order, so the indexes line up.
+func instanceName(instances []config.ArrInstance, i int, fallback string) string {
+	if i < len(instances) {
+		return instances[i].Name
+	}
+	return fmt.Sprintf("%s_%d", fallback, i)
+}
+
+// arrInstanceStatus tests one instance's connection and reads its health.
+// A health report that can't be fetched is a warning, not a failure.
+func arrInstanceStatus(name string, test func() error, health func() ([]string, error)) IntegrationStatus {
+	status := IntegrationStatus{Name: name}
+	if err := test(); err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.OK = true
+
+	warnings, err := health()
+	if err != nil {
+		status.Warnings = []string{fmt.Sprintf("could not fetch health: %v", err)}
+		return status
+	}
+	status.Warnings = warnings
+	return status
+}
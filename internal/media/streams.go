@@ -0,0 +1,159 @@
+package media
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nullable-eth/labelarr/internal/plex"
+)
+
+// needsStreams reports whether any enabled label source reads stream
+// metadata.
+func (p *Processor) needsStreams() bool {
+	return p.config.AudioLanguageLabels || p.config.DualAudioLabel != "" || len(p.config.QualityLabels) > 0
+}
+
+// streamLabels returns the audio-language and quality labels read from the
+// item's streams. Library listings leave streams out, so the item's media
+// (or, for TV shows, the first episode's) is fetched separately.
+func (p *Processor) streamLabels(item MediaItem, mediaType MediaType) []string {
+	ratingKey := item.GetRatingKey()
+	if mediaType == MediaTypeTV {
+		episodes, err := p.showEpisodes(ratingKey)
+		if err != nil || len(episodes) == 0 {
+			if err != nil && p.config.VerboseLogging {
+				fmt.Printf("   [WARN] Could not fetch episodes for stream labels: %v\n", err)
+			}
+			return nil
+		}
+		ratingKey = episodes[0].RatingKey
+	}
+
+	media, err := p.server.GetItemMedia(ratingKey)
+	if err != nil {
+		if p.config.VerboseLogging {
+			fmt.Printf("   [WARN] Could not fetch streams: %v\n", err)
+		}
+		return nil
+	}
+
+	labels := p.audioLabels(media)
+	for _, quality := range videoQualities(media, p.config.QualityLabels) {
+		if label, ok := p.config.QualityLabelMap[strings.ToLower(quality)]; ok {
+			quality = label
+		}
+		labels = appendUnique(labels, quality)
+	}
+	return labels
+}
+
+// audioLabels returns the AUDIO_LANGUAGE_LABELS label of each audio language
+// the item has, plus DUAL_AUDIO_LABEL when there are at least two.
+func (p *Processor) audioLabels(media []plex.Media) []string {
+	languages := audioLanguages(media, p.config.LanguageLabelMap)
+	var labels []string
+	if p.config.AudioLanguageLabels {
+		for _, language := range languages {
+			labels = append(labels, fmt.Sprintf(p.config.AudioLanguageLabelFormat, language))
+		}
+	}
+	if p.config.DualAudioLabel != "" && len(languages) >= 2 {
+		labels = append(labels, p.config.DualAudioLabel)
+	}
+	return labels
+}
+
+// audioLanguages returns the distinct languages of the audio streams across
+// all versions of an item, in stream order. Streams without a language are
+// skipped.
+func audioLanguages(media []plex.Media, overrides map[string]string) []string {
+	var languages []string
+	for _, m := range media {
+		for _, part := range m.Part {
+			for _, stream := range part.Stream {
+				if stream.StreamType != plex.StreamTypeAudio {
+					continue
+				}
+				if language := audioLanguage(stream, overrides); language != "" {
+					languages = appendUnique(languages, language)
+				}
+			}
+		}
+	}
+	return languages
+}
+
+// audioLanguage names a stream's language: through LANGUAGE_LABEL_MAP and
+// the built-in table when its code is known, otherwise by the server's own
+// display name.
+func audioLanguage(stream plex.Stream, overrides map[string]string) string {
+	code := strings.ToLower(strings.TrimSpace(stream.LanguageCode))
+	switch code {
+	case "", "und", "mis", "mul", "zxx":
+		return ""
+	}
+	if short, ok := iso6392Codes[code]; ok {
+		return languageLabel(short, overrides)
+	}
+	if name, ok := overrides[code]; ok {
+		return name
+	}
+	if stream.Language != "" {
+		return stream.Language
+	}
+	return strings.ToUpper(code)
+}
+
+// resolutionNames maps Plex's videoResolution values to labels.
+var resolutionNames = map[string]string{
+	"4k":   "4K",
+	"1080": "1080p",
+	"720":  "720p",
+	"480":  "SD",
+	"576":  "SD",
+	"sd":   "SD",
+}
+
+// videoQualities returns the resolution and dynamic-range labels of every
+// version of an item, limited to the QUALITY_LABELS categories. Dolby Vision
+// files that carry an HDR10 base layer get both labels.
+func videoQualities(media []plex.Media, categories []string) []string {
+	resolution, hdr := false, false
+	for _, category := range categories {
+		switch category {
+		case "resolution":
+			resolution = true
+		case "hdr":
+			hdr = true
+		}
+	}
+
+	var qualities []string
+	for _, m := range media {
+		if name, ok := resolutionNames[strings.ToLower(m.VideoResolution)]; ok && resolution {
+			qualities = appendUnique(qualities, name)
+		}
+		if !hdr {
+			continue
+		}
+		for _, part := range m.Part {
+			for _, stream := range part.Stream {
+				if stream.StreamType != plex.StreamTypeVideo {
+					continue
+				}
+				if stream.DOVIPresent {
+					qualities = appendUnique(qualities, "Dolby Vision")
+				}
+				switch {
+				case strings.Contains(stream.DisplayTitle, "HDR10+"):
+					qualities = appendUnique(qualities, "HDR10+")
+				case stream.ColorTrc == "smpte2084":
+					qualities = appendUnique(qualities, "HDR10")
+				case stream.ColorTrc == "arib-std-b67":
+					qualities = appendUnique(qualities, "HLG")
+				}
+			}
+		}
+	}
+	return qualities
+}
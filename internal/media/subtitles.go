@@ -0,0 +1,69 @@
+package media
+
+import (
+	"fmt"
+)
+
+// subtitleLabels returns BAZARR_MISSING_LABEL if Bazarr wants subtitles for
+// the movie, or for any episode of the show. Bazarr knows items by their
+// Radarr and Sonarr IDs, so items the matching *arr doesn't have get none.
+// err is set when Bazarr, Radarr or Sonarr can't be read.
+func (p *Processor) subtitleLabels(item MediaItem, tmdbID string, mediaType MediaType) ([]string, error) {
+	if err := p.arrLookupErr(tmdbID, mediaType); err != nil {
+		return nil, err
+	}
+
+	// Bazarr is connected to a single Radarr and Sonarr: the first instance.
+	var arrID int
+	switch mediaType {
+	case MediaTypeMovie:
+		entries := p.radarrMovies(tmdbID)
+		if len(entries) == 0 || entries[0].client != p.radarrClients[0] {
+			return nil, nil
+		}
+		arrID = entries[0].movie.ID
+	case MediaTypeTV:
+		entries := p.sonarrSeries(item, tmdbID)
+		if len(entries) == 0 || entries[0].client != p.sonarrClients[0] {
+			return nil, nil
+		}
+		arrID = entries[0].series.ID
+	default:
+		return nil, nil
+	}
+
+	missing, err := p.missingSubtitles(mediaType)
+	if err != nil {
+		return nil, fmt.Errorf("Bazarr wanted subtitles: %w", err)
+	}
+	if missing[arrID] {
+		return []string{p.config.BazarrMissingLabel}, nil
+	}
+	return nil, nil
+}
+
+// missingSubtitles returns the Radarr or Sonarr IDs of items Bazarr wants
+// subtitles for, fetched once per processing cycle.
+func (p *Processor) missingSubtitles(mediaType MediaType) (map[int]bool, error) {
+	p.cacheMu.RLock()
+	missing, ok := p.subtitleCache[mediaType]
+	p.cacheMu.RUnlock()
+	if ok {
+		return missing, nil
+	}
+
+	var err error
+	if mediaType == MediaTypeMovie {
+		missing, err = p.bazarrClient.GetMoviesMissingSubtitles()
+	} else {
+		missing, err = p.bazarrClient.GetSeriesMissingSubtitles()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.cacheMu.Lock()
+	p.subtitleCache[mediaType] = missing
+	p.cacheMu.Unlock()
+	return missing, nil
+}
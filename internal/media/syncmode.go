@@ -0,0 +1,60 @@
+package media
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SYNC_MODE=exact removes keywords TMDb (or another keyword source) no longer
+// returns. Only values the item's history records labelarr as having added
+// are ever removed, so labels added by hand, or present before labelarr
+// first added the same value, are left alone. Lifecycle-managed labels
+// follow their own rules and are never removed here.
+
+// staleKeywords returns the values labelarr applied to the item earlier that
+// are still on it but no longer among keywords. It is nil unless
+// SYNC_MODE=exact. Untracked entries from older versions have no history, so
+// nothing is stale until the item is synced once more.
+func (p *Processor) staleKeywords(item MediaItem, currentValues, keywords []string) []string {
+	if p.config.SyncMode != "exact" || p.storage == nil {
+		return nil
+	}
+	processed, ok := p.storage.Get(item.GetRatingKey())
+	if !ok {
+		return nil
+	}
+	return staleValues(processed.NetChange().Added, processed.ManagedLabels, currentValues, keywords)
+}
+
+// removeStaleKeywords removes the values staleKeywords returned, and
+// returns them unless the removal failed.
+func (p *Processor) removeStaleKeywords(item MediaItem, libraryID string, stale []string, mediaType MediaType) []string {
+	if len(stale) == 0 {
+		return nil
+	}
+	if err := p.removeItemFieldKeywords(item.GetRatingKey(), libraryID, stale, p.lockField(), mediaType); err != nil {
+		fmt.Printf("[ERROR] Error removing stale keywords %v from %s: %v\n", stale, item.GetTitle(), err)
+		return nil
+	}
+	fmt.Printf("[SYNC] %s: removed %d stale keywords %v (SYNC_MODE=exact)\n", item.GetTitle(), len(stale), stale)
+	return stale
+}
+
+// staleValues returns the applied values that are still current but no
+// longer wanted, skipping lifecycle-managed labels. Comparisons ignore case.
+func staleValues(applied, managed, current, want []string) []string {
+	var stale []string
+	for _, value := range missingFrom(missingFrom(applied, want), managed) {
+		if containsFold(current, value) {
+			stale = append(stale, value)
+		}
+	}
+	return stale
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool {
+		return strings.EqualFold(v, value)
+	})
+}
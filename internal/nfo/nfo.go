@@ -0,0 +1,86 @@
+package nfo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// document holds the elements labelarr reads from a Kodi-style movie.nfo or
+// tvshow.nfo. The root element name is not checked.
+type document struct {
+	Tags   []string `xml:"tag"`
+	Genres []string `xml:"genre"`
+}
+
+// Parse returns the <tag> and <genre> values of an NFO document, tags first.
+// Kodi also writes NFOs that are just a URL line; those have no values.
+func Parse(r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(string(data), "<") {
+		return nil, nil
+	}
+
+	var doc document
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid NFO: %w", err)
+	}
+
+	var values []string
+	seen := make(map[string]bool)
+	for _, value := range append(doc.Tags, doc.Genres...) {
+		// Some scrapers write several genres into one element.
+		for _, part := range strings.Split(value, " / ") {
+			part = strings.TrimSpace(part)
+			if part != "" && !seen[strings.ToLower(part)] {
+				seen[strings.ToLower(part)] = true
+				values = append(values, part)
+			}
+		}
+	}
+	return values, nil
+}
+
+// Candidates returns the NFO paths to try for a media file, in order. Movies
+// use <file>.nfo or movie.nfo beside the file; shows use tvshow.nfo in the
+// episode's folder or up to two folders above it (Show/Season 1/episode).
+func Candidates(mediaFile string, isShow bool) []string {
+	dir := filepath.Dir(mediaFile)
+	if !isShow {
+		base := strings.TrimSuffix(mediaFile, filepath.Ext(mediaFile))
+		return []string{base + ".nfo", filepath.Join(dir, "movie.nfo")}
+	}
+	parent := filepath.Dir(dir)
+	return []string{
+		filepath.Join(dir, "tvshow.nfo"),
+		filepath.Join(parent, "tvshow.nfo"),
+		filepath.Join(filepath.Dir(parent), "tvshow.nfo"),
+	}
+}
+
+// Read returns the values of the first existing candidate NFO. found is
+// false when none of the candidates exist.
+func Read(candidates []string) (values []string, found bool, err error) {
+	for _, path := range candidates {
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		values, err := Parse(f)
+		f.Close()
+		if err != nil {
+			return nil, true, fmt.Errorf("%s: %w", path, err)
+		}
+		return values, true, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,32 @@
+package nfo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>
+<movie>
+  <title>The Matrix</title>
+  <genre>Action / Science Fiction</genre>
+  <tag>Cyberpunk</tag>
+  <tag>Simulated Reality</tag>
+  <tag>cyberpunk</tag>
+</movie>`
+
+	got, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	want := []string{"Cyberpunk", "Simulated Reality", "Action", "Science Fiction"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %v, want %v", got, want)
+	}
+
+	urlOnly, err := Parse(strings.NewReader("https://www.themoviedb.org/movie/603\n"))
+	if err != nil || urlOnly != nil {
+		t.Errorf("Parse(url) = %v, %v, want nil, nil", urlOnly, err)
+	}
+}
@@ -0,0 +1,159 @@
+package omdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+const baseURL = "https://www.omdbapi.com/"
+
+// Client is an OMDb API client. Free API keys allow 1,000 requests a day,
+// so scores are cached (in DATA_DIR when set) for the cache TTL.
+type Client struct {
+	apiKey      string
+	httpClient  *http.Client
+	retryClient *utils.RetryableHTTPClient
+	cache       *utils.FileCache[Scores]
+}
+
+// NewClient creates an OMDb client. When dataDir is non-empty the score
+// cache is persisted to omdb_scores.json there.
+func NewClient(apiKey, dataDir string, cacheTTL time.Duration) *Client {
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	cachePath := ""
+	if dataDir != "" {
+		cachePath = filepath.Join(dataDir, "omdb_scores.json")
+	}
+	return &Client{
+		apiKey:      apiKey,
+		httpClient:  httpClient,
+		retryClient: utils.NewRetryableHTTPClient(httpClient, nil),
+		cache:       utils.NewFileCache[Scores](cachePath, cacheTTL),
+	}
+}
+
+func (c *Client) getTitle(imdbID string) (*Title, error) {
+	params := url.Values{}
+	params.Set("apikey", c.apiKey)
+	params.Set("i", imdbID)
+
+	req, err := http.NewRequest("GET", baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.retryClient.Do(req)
+	if err != nil {
+		// The request URL carries the API key; don't echo it.
+		return nil, fmt.Errorf("error making OMDb request for %s", imdbID)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("omdb API rejected the key (status 401) - check OMDB_API_KEY or the daily request limit")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("omdb API returned status %d for %s", resp.StatusCode, imdbID)
+	}
+
+	var title Title
+	if err := json.NewDecoder(resp.Body).Decode(&title); err != nil {
+		return nil, fmt.Errorf("error decoding OMDb response: %w", err)
+	}
+	if title.Response == "False" {
+		if isNotFound(title.Error) {
+			return nil, fmt.Errorf("omdb: %s (%s): %w", title.Error, imdbID, ErrNotFound)
+		}
+		return nil, fmt.Errorf("omdb: %s (%s)", title.Error, imdbID)
+	}
+	return &title, nil
+}
+
+// ErrNotFound is returned for IMDb IDs OMDb has no title for.
+var ErrNotFound = errors.New("title not found")
+
+// isNotFound reports whether an OMDb error message means the title doesn't
+// exist, as opposed to a failure such as "Request limit reached!".
+func isNotFound(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "not found") || strings.Contains(message, "incorrect imdb id")
+}
+
+// GetScores returns the Rotten Tomatoes, Metacritic, and IMDb scores for an
+// IMDb title ID, served from cache while younger than the TTL. A title OMDb
+// doesn't know has every score missing; any other failure, such as the
+// daily request limit, is an error.
+func (c *Client) GetScores(imdbID string) (Scores, error) {
+	if !strings.HasPrefix(imdbID, "tt") {
+		imdbID = "tt" + imdbID
+	}
+	if scores, ok := c.cache.Get(imdbID); ok {
+		return scores, nil
+	}
+
+	var scores Scores
+	title, err := c.getTitle(imdbID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		scores = Scores{RottenTomatoes: -1, Metacritic: -1, IMDb: -1}
+	case err != nil:
+		return Scores{}, err
+	default:
+		scores = parseScores(title)
+	}
+
+	if err := c.cache.Set(imdbID, scores); err != nil {
+		fmt.Printf("[WARN] Failed to save OMDb score cache: %v\n", err)
+	}
+	return scores, nil
+}
+
+// parseScores converts OMDb's display strings ("87%", "74/100", "8.1/10",
+// "N/A") into numbers, using -1 for missing scores.
+func parseScores(title *Title) Scores {
+	scores := Scores{
+		RottenTomatoes: -1,
+		Metacritic:     parseScore(title.Metascore),
+		IMDb:           parseScore(title.IMDbRating),
+	}
+	for _, rating := range title.Ratings {
+		switch rating.Source {
+		case "Rotten Tomatoes":
+			scores.RottenTomatoes = parseScore(strings.TrimSuffix(rating.Value, "%"))
+		case "Metacritic":
+			if scores.Metacritic < 0 {
+				scores.Metacritic = parseScore(strings.TrimSuffix(rating.Value, "/100"))
+			}
+		case "Internet Movie Database":
+			if scores.IMDb < 0 {
+				scores.IMDb = parseScore(strings.TrimSuffix(rating.Value, "/10"))
+			}
+		}
+	}
+	return scores
+}
+
+func parseScore(value string) float64 {
+	score, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return -1
+	}
+	return score
+}
+
+// TestConnection verifies the API key with a known title (The Godfather).
+func (c *Client) TestConnection() error {
+	_, err := c.getTitle("tt0068646")
+	return err
+}
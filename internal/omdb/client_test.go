@@ -0,0 +1,41 @@
+package omdb
+
+import "testing"
+
+func TestParseScores(t *testing.T) {
+	title := &Title{
+		IMDbRating: "8.7",
+		Metascore:  "N/A",
+		Ratings: []Rating{
+			{Source: "Internet Movie Database", Value: "8.7/10"},
+			{Source: "Rotten Tomatoes", Value: "83%"},
+			{Source: "Metacritic", Value: "73/100"},
+		},
+	}
+
+	got := parseScores(title)
+	want := Scores{RottenTomatoes: 83, Metacritic: 73, IMDb: 8.7}
+	if got != want {
+		t.Errorf("parseScores() = %+v, want %+v", got, want)
+	}
+
+	missing := parseScores(&Title{IMDbRating: "N/A", Metascore: "N/A"})
+	if missing.RottenTomatoes != -1 || missing.Metacritic != -1 || missing.IMDb != -1 {
+		t.Errorf("expected -1 for missing scores, got %+v", missing)
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	tests := map[string]bool{
+		"Incorrect IMDb ID.":     true,
+		"Movie not found!":       true,
+		"Request limit reached!": false,
+		"Invalid API key!":       false,
+		"Error getting data.":    false,
+	}
+	for message, want := range tests {
+		if got := isNotFound(message); got != want {
+			t.Errorf("isNotFound(%q) = %v, want %v", message, got, want)
+		}
+	}
+}
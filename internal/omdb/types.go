@@ -0,0 +1,26 @@
+package omdb
+
+// Rating is a single third-party rating reported by OMDb
+type Rating struct {
+	Source string `json:"Source"`
+	Value  string `json:"Value"`
+}
+
+// Title represents the parts of an OMDb title record labelarr uses
+type Title struct {
+	Title      string   `json:"Title"`
+	Year       string   `json:"Year"`
+	IMDbID     string   `json:"imdbID"`
+	IMDbRating string   `json:"imdbRating"`
+	Metascore  string   `json:"Metascore"`
+	Ratings    []Rating `json:"Ratings"`
+	Response   string   `json:"Response"`
+	Error      string   `json:"Error"`
+}
+
+// Scores holds the parsed scores of a title; a missing score is -1
+type Scores struct {
+	RottenTomatoes float64 `json:"rt"`
+	Metacritic     float64 `json:"metacritic"`
+	IMDb           float64 `json:"imdb"`
+}
@@ -0,0 +1,146 @@
+package overseerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+// requestPageSize is how many requests are fetched per page.
+const requestPageSize = 100
+
+// Client is an Overseerr API client. Jellyseerr serves the same API and works
+// with it unchanged.
+type Client struct {
+	baseURL     string
+	apiKey      string
+	httpClient  *http.Client
+	retryClient *utils.RetryableHTTPClient
+}
+
+// NewClient creates an Overseerr client
+func NewClient(baseURL, apiKey string) *Client {
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	return &Client{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		apiKey:      apiKey,
+		httpClient:  httpClient,
+		retryClient: utils.NewRetryableHTTPClient(httpClient, nil),
+	}
+}
+
+func (c *Client) makeRequest(endpoint string, params url.Values) (*http.Response, error) {
+	fullURL := c.baseURL + endpoint
+	if len(params) > 0 {
+		fullURL += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("X-Api-Key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.retryClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		resp.Body.Close()
+		return nil, fmt.Errorf("overseerr API rejected the key (status %d) - check OVERSEERR_API_KEY", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("overseerr API returned status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// GetRequests fetches every request, following pagination.
+func (c *Client) GetRequests() ([]Request, error) {
+	var requests []Request
+	for skip := 0; ; skip += requestPageSize {
+		params := url.Values{}
+		params.Set("take", strconv.Itoa(requestPageSize))
+		params.Set("skip", strconv.Itoa(skip))
+		params.Set("filter", "all")
+
+		resp, err := c.makeRequest("/api/v1/request", params)
+		if err != nil {
+			return nil, err
+		}
+		var page requestPage
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error decoding requests: %w", err)
+		}
+
+		requests = append(requests, page.Results...)
+		if len(page.Results) < requestPageSize || len(requests) >= page.PageInfo.Results {
+			break
+		}
+	}
+	return requests, nil
+}
+
+// GetRequesters returns who requested each movie and show, keyed by
+// "movie:<tmdbID>" and "tv:<tmdbID>".
+func (c *Client) GetRequesters() (map[string][]string, error) {
+	requests, err := c.GetRequests()
+	if err != nil {
+		return nil, err
+	}
+	return requesters(requests), nil
+}
+
+// requesters folds requests into sorted, de-duplicated requester names per
+// item. Declined requests are ignored.
+func requesters(requests []Request) map[string][]string {
+	seen := make(map[string]map[string]bool)
+	for _, request := range requests {
+		if request.Status == StatusDeclined || request.Media.TMDbID == 0 {
+			continue
+		}
+		key := request.Media.MediaType + ":" + strconv.Itoa(request.Media.TMDbID)
+		if seen[key] == nil {
+			seen[key] = make(map[string]bool)
+		}
+		seen[key][request.RequestedBy.Name()] = true
+	}
+
+	result := make(map[string][]string, len(seen))
+	for key, users := range seen {
+		names := make([]string, 0, len(users))
+		for name := range users {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		result[key] = names
+	}
+	return result
+}
+
+// TestConnection verifies the URL and API key. /api/v1/status is public, so
+// a one-request page is fetched to exercise the key.
+func (c *Client) TestConnection() error {
+	params := url.Values{}
+	params.Set("take", "1")
+	resp, err := c.makeRequest("/api/v1/request", params)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,30 @@
+package overseerr
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRequesters(t *testing.T) {
+	body := `[
+		{"id": 1, "status": 2, "media": {"tmdbId": 603, "mediaType": "movie"}, "requestedBy": {"displayName": "Alice"}},
+		{"id": 2, "status": 1, "media": {"tmdbId": 603, "mediaType": "movie"}, "requestedBy": {"plexUsername": "bob"}},
+		{"id": 3, "status": 2, "media": {"tmdbId": 603, "mediaType": "movie"}, "requestedBy": {"displayName": "Alice"}},
+		{"id": 4, "status": 3, "media": {"tmdbId": 1399, "mediaType": "tv"}, "requestedBy": {"displayName": "Carol"}},
+		{"id": 5, "status": 2, "media": {"tmdbId": 1399, "mediaType": "tv"}, "requestedBy": {"email": "dave@example.com"}}
+	]`
+	var requests []Request
+	if err := json.Unmarshal([]byte(body), &requests); err != nil {
+		t.Fatalf("failed to decode requests: %v", err)
+	}
+
+	got := requesters(requests)
+	want := map[string][]string{
+		"movie:603": {"Alice", "bob"},
+		"tv:1399":   {"dave@example.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("requesters() = %v, want %v", got, want)
+	}
+}
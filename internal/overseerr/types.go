@@ -0,0 +1,48 @@
+package overseerr
+
+// Request is a media request from Overseerr's /api/v1/request endpoint
+type Request struct {
+	ID          int   `json:"id"`
+	Status      int   `json:"status"` // 1 pending, 2 approved, 3 declined
+	Media       Media `json:"media"`
+	RequestedBy User  `json:"requestedBy"`
+}
+
+// Media is the movie or show a request is for
+type Media struct {
+	TMDbID    int    `json:"tmdbId"`
+	TVDbID    int    `json:"tvdbId,omitempty"`
+	MediaType string `json:"mediaType"` // "movie" or "tv"
+}
+
+// User is an Overseerr user
+type User struct {
+	ID           int    `json:"id"`
+	DisplayName  string `json:"displayName"`
+	Username     string `json:"username"`
+	PlexUsername string `json:"plexUsername"`
+	Email        string `json:"email"`
+}
+
+// Name returns the user's display name, falling back to their Plex or local
+// username and finally their email address.
+func (u User) Name() string {
+	for _, name := range []string{u.DisplayName, u.PlexUsername, u.Username, u.Email} {
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// StatusDeclined is the status of a declined request
+const StatusDeclined = 3
+
+type requestPage struct {
+	PageInfo struct {
+		Pages   int `json:"pages"`
+		Page    int `json:"page"`
+		Results int `json:"results"`
+	} `json:"pageInfo"`
+	Results []Request `json:"results"`
+}
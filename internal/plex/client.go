@@ -3,6 +3,7 @@ package plex
 import (
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,8 +13,13 @@ import (
 	"time"
 
 	"github.com/nullable-eth/labelarr/internal/config"
+	"github.com/nullable-eth/labelarr/internal/utils"
 )
 
+// ErrNotFound is returned (wrapped) when the server has no item with the
+// requested rating key, e.g. because it was deleted.
+var ErrNotFound = errors.New("item not found")
+
 // urlSecretRedactor matches credential query params so tokens don't leak into
 // error messages produced by net/http (which embed the full request URL).
 var urlSecretRedactor = regexp.MustCompile(`([?&](?:X-Plex-Token|apikey|api_key)=)[^&\s"]+`)
@@ -23,9 +29,14 @@ func redactURLSecrets(s string) string {
 }
 
 // safeDo wraps httpClient.Do so transport errors have their request URL
-// stripped of secret query params before bubbling up.
+// stripped of secret query params before bubbling up. Each response's latency
+// is reported to the adaptive delay, if one is set.
 func (c *Client) safeDo(req *http.Request) (*http.Response, error) {
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
+	if c.adaptiveDelay != nil {
+		c.adaptiveDelay.Observe(time.Since(start), err != nil || resp.StatusCode >= 500)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%s", redactURLSecrets(err.Error()))
 	}
@@ -34,8 +45,9 @@ func (c *Client) safeDo(req *http.Request) (*http.Response, error) {
 
 // Client represents a Plex API client
 type Client struct {
-	config     *config.Config
-	httpClient *http.Client
+	config        *config.Config
+	httpClient    *http.Client
+	adaptiveDelay *utils.AdaptiveDelay
 }
 
 // NewClient creates a new Plex client
@@ -53,6 +65,12 @@ func NewClient(cfg *config.Config) *Client {
 	}
 }
 
+// SetAdaptiveDelay makes the client report the latency of every Plex
+// response to d (ADAPTIVE_DELAY).
+func (c *Client) SetAdaptiveDelay(d *utils.AdaptiveDelay) {
+	c.adaptiveDelay = d
+}
+
 // GetAllLibraries fetches all libraries from Plex
 func (c *Client) GetAllLibraries() ([]Library, error) {
 	librariesURL := c.buildURL(fmt.Sprintf("/library/sections?X-Plex-Token=%s", c.config.PlexToken))
@@ -165,7 +183,13 @@ func (c *Client) UpdateMediaField(mediaID, libraryID string, keywords []string,
 	if c.config.VerboseLogging {
 		fmt.Printf("   [API] Making Plex API call to update %s field with %d keywords\n", updateField, len(keywords))
 	}
-	return c.updateMediaField(mediaID, libraryID, keywords, updateField, c.getMediaTypeForLibraryType(mediaType))
+	return c.updateMediaField(mediaID, libraryID, keywords, updateField, true, c.getMediaTypeForLibraryType(mediaType))
+}
+
+// SetMediaField replaces a media item's field with values, locking or
+// unlocking the field as requested
+func (c *Client) SetMediaField(mediaID, libraryID string, values []string, field string, lockField bool, mediaType string) error {
+	return c.updateMediaField(mediaID, libraryID, values, field, lockField, c.getMediaTypeForLibraryType(mediaType))
 }
 
 // RemoveMediaFieldKeywords removes keywords from a media item's field
@@ -313,8 +337,117 @@ func (c *Client) GetAllTVShowEpisodes(ratingKey string) ([]Episode, error) {
 	return episodeResponse.MediaContainer.Metadata, nil
 }
 
+// GetItemMedia fetches the media of any library item (a movie or an
+// episode) including its streams, which library listings leave out
+func (c *Client) GetItemMedia(ratingKey string) ([]Media, error) {
+	itemURL := c.buildURL(fmt.Sprintf("/library/metadata/%s", ratingKey))
+
+	req, err := http.NewRequest("GET", itemURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Plex-Token", c.config.PlexToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.safeDo(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch item media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("plex API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Movies and episodes share the Media/Part/Stream shape.
+	var plexResponse PlexResponse
+	if err := json.Unmarshal(body, &plexResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse item media: %w", err)
+	}
+
+	if len(plexResponse.MediaContainer.Metadata) == 0 {
+		return nil, fmt.Errorf("no item found with rating key %s", ratingKey)
+	}
+
+	return plexResponse.MediaContainer.Metadata[0].Media, nil
+}
+
+// GetItemRef fetches the title and type of any library item
+func (c *Client) GetItemRef(ratingKey string) (*ItemRef, error) {
+	response, err := c.getItemRefs(fmt.Sprintf("/library/metadata/%s", ratingKey))
+	if err != nil {
+		return nil, err
+	}
+	if len(response.MediaContainer.Metadata) == 0 {
+		return nil, fmt.Errorf("%w: rating key %s", ErrNotFound, ratingKey)
+	}
+	return &response.MediaContainer.Metadata[0], nil
+}
+
+// GetSimilarItems fetches the items Plex lists as similar to an item
+func (c *Client) GetSimilarItems(ratingKey string) ([]ItemRef, error) {
+	response, err := c.getItemRefs(fmt.Sprintf("/library/metadata/%s/similar", ratingKey))
+	if err != nil {
+		return nil, err
+	}
+	return response.MediaContainer.Metadata, nil
+}
+
+// GetRelatedItems fetches the items in every related hub Plex shows for an
+// item, such as other films by the same director or in the same collection
+func (c *Client) GetRelatedItems(ratingKey string) ([]ItemRef, error) {
+	response, err := c.getItemRefs(fmt.Sprintf("/library/metadata/%s/related", ratingKey))
+	if err != nil {
+		return nil, err
+	}
+	var items []ItemRef
+	for _, hub := range response.MediaContainer.Hub {
+		items = append(items, hub.Metadata...)
+	}
+	return items, nil
+}
+
+// getItemRefs fetches a metadata endpoint as item references
+func (c *Client) getItemRefs(path string) (*ItemRefResponse, error) {
+	req, err := http.NewRequest("GET", c.buildURL(path), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Plex-Token", c.config.PlexToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.safeDo(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("plex API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var response ItemRefResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", path, err)
+	}
+	return &response, nil
+}
+
 // updateMediaField is a generic function to update media fields (movies: type=1, TV shows: type=2)
-func (c *Client) updateMediaField(mediaID, libraryID string, keywords []string, updateField string, mediaType int) error {
+func (c *Client) updateMediaField(mediaID, libraryID string, keywords []string, updateField string, lockField bool, mediaType int) error {
 	startTime := time.Now()
 
 	// Build the base URL
@@ -338,7 +471,11 @@ func (c *Client) updateMediaField(mediaID, libraryID string, keywords []string,
 		params.Set(paramName, keyword)
 	}
 
-	params.Set(fmt.Sprintf("%s.locked", updateField), "1")
+	if lockField {
+		params.Set(fmt.Sprintf("%s.locked", updateField), "1")
+	} else {
+		params.Set(fmt.Sprintf("%s.locked", updateField), "0")
+	}
 
 	// Add the Plex token
 	params.Set("X-Plex-Token", c.config.PlexToken)
@@ -26,43 +26,51 @@ type LibraryResponse struct {
 
 // Movie represents a Plex movie
 type Movie struct {
-	RatingKey string       `json:"ratingKey"`
-	Title     string       `json:"title"`
-	Year      int          `json:"year"`
-	Label     []Label      `json:"Label,omitempty"`
-	Genre     []Genre      `json:"Genre,omitempty"`
-	Guid      FlexibleGuid `json:"Guid,omitempty"`
-	Media     []Media      `json:"Media,omitempty"`
+	RatingKey  string       `json:"ratingKey"`
+	Title      string       `json:"title"`
+	Year       int          `json:"year"`
+	Label      []Label      `json:"Label,omitempty"`
+	Genre      []Genre      `json:"Genre,omitempty"`
+	Collection []Label      `json:"Collection,omitempty"`
+	Guid       FlexibleGuid `json:"Guid,omitempty"`
+	Media      []Media      `json:"Media,omitempty"`
+	AddedAt    int64        `json:"addedAt,omitempty"` // Unix seconds
 }
 
 // MediaItem interface implementation for Movie
-func (m Movie) GetRatingKey() string { return m.RatingKey }
-func (m Movie) GetTitle() string     { return m.Title }
-func (m Movie) GetYear() int         { return m.Year }
-func (m Movie) GetGuid() []Guid      { return []Guid(m.Guid) }
-func (m Movie) GetMedia() []Media    { return m.Media }
-func (m Movie) GetLabel() []Label    { return m.Label }
-func (m Movie) GetGenre() []Genre    { return m.Genre }
+func (m Movie) GetRatingKey() string   { return m.RatingKey }
+func (m Movie) GetTitle() string       { return m.Title }
+func (m Movie) GetYear() int           { return m.Year }
+func (m Movie) GetGuid() []Guid        { return []Guid(m.Guid) }
+func (m Movie) GetMedia() []Media      { return m.Media }
+func (m Movie) GetLabel() []Label      { return m.Label }
+func (m Movie) GetGenre() []Genre      { return m.Genre }
+func (m Movie) GetCollection() []Label { return m.Collection }
+func (m Movie) GetAddedAt() int64      { return m.AddedAt }
 
 // TVShow represents a Plex TV show
 type TVShow struct {
-	RatingKey string       `json:"ratingKey"`
-	Title     string       `json:"title"`
-	Year      int          `json:"year"`
-	Label     []Label      `json:"Label,omitempty"`
-	Genre     []Genre      `json:"Genre,omitempty"`
-	Guid      FlexibleGuid `json:"Guid,omitempty"`
-	Media     []Media      `json:"Media,omitempty"`
+	RatingKey  string       `json:"ratingKey"`
+	Title      string       `json:"title"`
+	Year       int          `json:"year"`
+	Label      []Label      `json:"Label,omitempty"`
+	Genre      []Genre      `json:"Genre,omitempty"`
+	Collection []Label      `json:"Collection,omitempty"`
+	Guid       FlexibleGuid `json:"Guid,omitempty"`
+	Media      []Media      `json:"Media,omitempty"`
+	AddedAt    int64        `json:"addedAt,omitempty"` // Unix seconds
 }
 
 // MediaItem interface implementation for TVShow
-func (t TVShow) GetRatingKey() string { return t.RatingKey }
-func (t TVShow) GetTitle() string     { return t.Title }
-func (t TVShow) GetYear() int         { return t.Year }
-func (t TVShow) GetGuid() []Guid      { return []Guid(t.Guid) }
-func (t TVShow) GetMedia() []Media    { return t.Media }
-func (t TVShow) GetLabel() []Label    { return t.Label }
-func (t TVShow) GetGenre() []Genre    { return t.Genre }
+func (t TVShow) GetRatingKey() string   { return t.RatingKey }
+func (t TVShow) GetTitle() string       { return t.Title }
+func (t TVShow) GetYear() int           { return t.Year }
+func (t TVShow) GetGuid() []Guid        { return []Guid(t.Guid) }
+func (t TVShow) GetMedia() []Media      { return t.Media }
+func (t TVShow) GetLabel() []Label      { return t.Label }
+func (t TVShow) GetGenre() []Genre      { return t.Genre }
+func (t TVShow) GetCollection() []Label { return t.Collection }
+func (t TVShow) GetAddedAt() int64      { return t.AddedAt }
 
 // Label represents a Plex label
 type Label struct {
@@ -81,13 +89,34 @@ type Guid struct {
 
 // Media represents Plex media information
 type Media struct {
-	Part []Part `json:"Part,omitempty"`
+	VideoResolution string `json:"videoResolution,omitempty"` // "4k", "1080", "720", "sd"
+	Part            []Part `json:"Part,omitempty"`
 }
 
 // Part represents a media part with file information
 type Part struct {
-	File string `json:"file,omitempty"`
-	Size int64  `json:"size,omitempty"`
+	File     string   `json:"file,omitempty"`
+	Size     int64    `json:"size,omitempty"`
+	Duration int64    `json:"duration,omitempty"` // milliseconds
+	Stream   []Stream `json:"Stream,omitempty"`
+}
+
+// Stream types as reported in Stream.StreamType
+const (
+	StreamTypeVideo    = 1
+	StreamTypeAudio    = 2
+	StreamTypeSubtitle = 3
+)
+
+// Stream represents a video, audio or subtitle stream of a media part. Plex
+// only includes streams when a single item's metadata is fetched.
+type Stream struct {
+	StreamType   int    `json:"streamType"`
+	Language     string `json:"language,omitempty"`     // display name, e.g. "Japanese"
+	LanguageCode string `json:"languageCode,omitempty"` // ISO 639-2, e.g. "jpn"
+	DisplayTitle string `json:"displayTitle,omitempty"` // e.g. "4K DoVi/HDR10 (HEVC Main 10)"
+	ColorTrc     string `json:"colorTrc,omitempty"`     // "smpte2084" (PQ/HDR10), "arib-std-b67" (HLG)
+	DOVIPresent  bool   `json:"DOVIPresent,omitempty"`
 }
 
 // FlexibleGuid handles both string and array formats from Plex API
@@ -157,3 +186,31 @@ type EpisodeContainer struct {
 type EpisodeResponse struct {
 	MediaContainer EpisodeContainer `json:"MediaContainer"`
 }
+
+// ItemRef is a minimal reference to a library item, as listed in Plex's
+// similar and related hubs
+type ItemRef struct {
+	RatingKey string `json:"ratingKey"`
+	Title     string `json:"title"`
+	Type      string `json:"type"`
+}
+
+// Hub is a group of items Plex relates to another item (e.g. "More from
+// this director")
+type Hub struct {
+	HubIdentifier string    `json:"hubIdentifier"`
+	Title         string    `json:"title"`
+	Metadata      []ItemRef `json:"Metadata"`
+}
+
+// ItemRefContainer holds item references and hubs
+type ItemRefContainer struct {
+	Size     int       `json:"size"`
+	Metadata []ItemRef `json:"Metadata"`
+	Hub      []Hub     `json:"Hub"`
+}
+
+// ItemRefResponse represents a Plex API response listing item references
+type ItemRefResponse struct {
+	MediaContainer ItemRefContainer `json:"MediaContainer"`
+}
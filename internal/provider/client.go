@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Request is the item metadata POSTed to the keyword provider
+type Request struct {
+	RatingKey string   `json:"ratingKey"`
+	LibraryID string   `json:"libraryId"`
+	MediaType string   `json:"mediaType"` // "movie" or "tv"
+	Title     string   `json:"title"`
+	Year      int      `json:"year,omitempty"`
+	TMDbID    string   `json:"tmdbId,omitempty"`
+	IMDbID    string   `json:"imdbId,omitempty"`
+	TVDbID    string   `json:"tvdbId,omitempty"`
+	Paths     []string `json:"paths,omitempty"`
+	Labels    []string `json:"labels"` // keywords labelarr computed so far
+}
+
+// Client calls a user-supplied HTTP endpoint (KEYWORD_PROVIDER_URL) that
+// returns extra keywords for an item. The endpoint may answer with a JSON
+// array of strings or an object with a "keywords" array; 204 No Content
+// means no keywords.
+type Client struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a keyword provider client. token, when set, is sent as
+// a bearer token.
+func NewClient(url, token string, timeout time.Duration) *Client {
+	return &Client{
+		url:        url,
+		token:      token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetKeywords asks the provider for the item's keywords
+func (c *Client) GetKeywords(request Request) ([]string, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("keyword provider returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return parseKeywords(body)
+}
+
+// parseKeywords accepts ["a", "b"] or {"keywords": ["a", "b"]}
+func parseKeywords(body []byte) ([]string, error) {
+	var keywords []string
+	if err := json.Unmarshal(body, &keywords); err == nil {
+		return keywords, nil
+	}
+	var wrapped struct {
+		Keywords []string `json:"keywords"`
+	}
+	if err := json.Unmarshal(body, &wrapped); err != nil {
+		return nil, fmt.Errorf("keyword provider response must be a JSON array of strings or {\"keywords\": [...]}: %w", err)
+	}
+	return wrapped.Keywords, nil
+}
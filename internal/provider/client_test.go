@@ -0,0 +1,22 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeywords(t *testing.T) {
+	for _, body := range []string{`["Heist", "Los Angeles"]`, `{"keywords": ["Heist", "Los Angeles"]}`} {
+		got, err := parseKeywords([]byte(body))
+		if err != nil {
+			t.Fatalf("parseKeywords(%s) error: %v", body, err)
+		}
+		if want := []string{"Heist", "Los Angeles"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("parseKeywords(%s) = %v, want %v", body, got, want)
+		}
+	}
+
+	if _, err := parseKeywords([]byte(`{"keywords": "Heist"}`)); err == nil {
+		t.Error("expected error for a non-array keywords field")
+	}
+}
@@ -1,11 +1,13 @@
 package radarr
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,17 +27,30 @@ type Client struct {
 	apiKey      string
 	httpClient  *http.Client
 	retryClient *utils.RetryableHTTPClient
+	proxyAuth   *utils.ProxyAuth
 	movies      []Movie
+	byTMDb      map[int]*Movie    // index into movies
+	byIMDb      map[string]*Movie // index into movies
+	moviesAt    time.Time
 	moviesMu    sync.Mutex
+	cacheTTL    time.Duration
+	lookups     map[int]*Movie // per-ID lookups made before movies was loaded; nil = not in Radarr
+	lookupsMu   sync.Mutex
+	profiles    map[int]string
+	profilesMu  sync.Mutex
+	tags        map[string]int
+	tagsMu      sync.Mutex
 }
 
-func NewClient(baseURL, apiKey string) *Client {
-	return NewClientWithRetryConfig(baseURL, apiKey, nil)
+// NewClient creates a Radarr API client. cacheTTL is how long the movie list
+// is kept; 0 keeps it until ClearCache.
+func NewClient(baseURL, apiKey string, cacheTTL time.Duration) *Client {
+	return NewClientWithRetryConfig(baseURL, apiKey, cacheTTL, nil)
 }
 
 // NewClientWithRetryConfig creates a new Radarr API client with custom retry configuration.
 // Pass nil for retryConfig to use the default exponential backoff configuration.
-func NewClientWithRetryConfig(baseURL, apiKey string, retryConfig *utils.RetryConfig) *Client {
+func NewClientWithRetryConfig(baseURL, apiKey string, cacheTTL time.Duration, retryConfig *utils.RetryConfig) *Client {
 	baseURL = strings.TrimRight(baseURL, "/")
 	httpClient := &http.Client{
 		Timeout: 30 * time.Second,
@@ -45,9 +60,16 @@ func NewClientWithRetryConfig(baseURL, apiKey string, retryConfig *utils.RetryCo
 		apiKey:      apiKey,
 		httpClient:  httpClient,
 		retryClient: utils.NewRetryableHTTPClient(httpClient, retryConfig),
+		cacheTTL:    cacheTTL,
 	}
 }
 
+// SetProxyAuth attaches extra headers and basic auth credentials to every
+// request, for Radarr instances behind an authenticating reverse proxy.
+func (c *Client) SetProxyAuth(auth *utils.ProxyAuth) {
+	c.proxyAuth = auth
+}
+
 // makeRequest performs an API request to Radarr with exponential backoff retry.
 // Query parameters are URL-encoded via url.Values.Encode() so values containing
 // spaces, "+", "&", etc. are escaped correctly.
@@ -64,6 +86,7 @@ func (c *Client) makeRequest(method, endpoint string, params url.Values) (*http.
 	}
 
 	req.Header.Set("X-Api-Key", c.apiKey)
+	c.proxyAuth.Apply(req)
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.retryClient.Do(req)
@@ -79,13 +102,44 @@ func (c *Client) makeRequest(method, endpoint string, params url.Values) (*http.
 	return resp, nil
 }
 
+// makeJSONRequest sends body as JSON to a Radarr endpoint. Any 2xx status is
+// a success, since creates return 201 and bulk edits 202.
+func (c *Client) makeJSONRequest(method, endpoint string, body interface{}) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %w", err)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("X-Api-Key", c.apiKey)
+	c.proxyAuth.Apply(req)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.retryClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("radarr API returned status %d", resp.StatusCode)
+	}
+
+	return resp, nil
+}
+
 // GetAllMovies fetches the full movie list from Radarr, caching the result
-// for the lifetime of the client. Call ClearCache to refresh.
+// for the cache TTL or, without one, until ClearCache.
 func (c *Client) GetAllMovies() ([]Movie, error) {
 	c.moviesMu.Lock()
 	defer c.moviesMu.Unlock()
 
-	if c.movies != nil {
+	if c.moviesFresh() {
 		return c.movies, nil
 	}
 
@@ -100,15 +154,212 @@ func (c *Client) GetAllMovies() ([]Movie, error) {
 		return nil, fmt.Errorf("error decoding movies: %w", err)
 	}
 
-	c.movies = movies
+	c.setMovies(movies)
 	return movies, nil
 }
 
-// ClearCache forces the next GetAllMovies call to re-fetch from Radarr.
+// moviesFresh reports whether the cached movie list can be used. Callers
+// hold moviesMu.
+func (c *Client) moviesFresh() bool {
+	return c.movies != nil && (c.cacheTTL == 0 || time.Since(c.moviesAt) < c.cacheTTL)
+}
+
+// setMovies caches the movie list and indexes it by TMDb and IMDb ID.
+// Callers hold moviesMu.
+func (c *Client) setMovies(movies []Movie) {
+	c.movies = movies
+	c.moviesAt = time.Now()
+	c.byTMDb = make(map[int]*Movie, len(movies))
+	c.byIMDb = make(map[string]*Movie, len(movies))
+	for i := range movies {
+		if movies[i].TMDbID > 0 {
+			c.byTMDb[movies[i].TMDbID] = &movies[i]
+		}
+		if movies[i].IMDbID != "" {
+			c.byIMDb[movies[i].IMDbID] = &movies[i]
+		}
+	}
+}
+
+// ClearCache forces the next GetQualityProfiles and GetTags calls to
+// re-fetch from Radarr, and the next GetAllMovies call too unless a cache
+// TTL is set, in which case the movie list expires on its own.
 func (c *Client) ClearCache() {
-	c.moviesMu.Lock()
-	c.movies = nil
-	c.moviesMu.Unlock()
+	if c.cacheTTL == 0 {
+		c.moviesMu.Lock()
+		c.setMovies(nil)
+		c.moviesMu.Unlock()
+	}
+
+	c.lookupsMu.Lock()
+	c.lookups = nil
+	c.lookupsMu.Unlock()
+
+	c.profilesMu.Lock()
+	c.profiles = nil
+	c.profilesMu.Unlock()
+
+	c.tagsMu.Lock()
+	c.tags = nil
+	c.tagsMu.Unlock()
+}
+
+// GetQualityProfiles returns quality profile names by ID, cached like the
+// movie list.
+func (c *Client) GetQualityProfiles() (map[int]string, error) {
+	c.profilesMu.Lock()
+	defer c.profilesMu.Unlock()
+
+	if c.profiles != nil {
+		return c.profiles, nil
+	}
+
+	resp, err := c.makeRequest("GET", "/api/v3/qualityprofile", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var profiles []QualityProfile
+	if err := json.NewDecoder(resp.Body).Decode(&profiles); err != nil {
+		return nil, fmt.Errorf("error decoding quality profiles: %w", err)
+	}
+
+	c.profiles = make(map[int]string, len(profiles))
+	for _, profile := range profiles {
+		c.profiles[profile.ID] = profile.Name
+	}
+	return c.profiles, nil
+}
+
+// GetTags returns tag IDs by lowercased label, cached like the movie list.
+func (c *Client) GetTags() (map[string]int, error) {
+	c.tagsMu.Lock()
+	defer c.tagsMu.Unlock()
+	return c.getTagsLocked()
+}
+
+func (c *Client) getTagsLocked() (map[string]int, error) {
+	if c.tags != nil {
+		return c.tags, nil
+	}
+
+	resp, err := c.makeRequest("GET", "/api/v3/tag", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tags []Tag
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("error decoding tags: %w", err)
+	}
+
+	c.tags = make(map[string]int, len(tags))
+	for _, tag := range tags {
+		c.tags[strings.ToLower(tag.Label)] = tag.ID
+	}
+	return c.tags, nil
+}
+
+// EnsureTag returns the ID of the tag with the given label, creating the tag
+// if Radarr doesn't have it yet.
+func (c *Client) EnsureTag(label string) (int, error) {
+	c.tagsMu.Lock()
+	defer c.tagsMu.Unlock()
+
+	tags, err := c.getTagsLocked()
+	if err != nil {
+		return 0, err
+	}
+	label = strings.ToLower(label)
+	if id, ok := tags[label]; ok {
+		return id, nil
+	}
+
+	resp, err := c.makeJSONRequest("POST", "/api/v3/tag", Tag{Label: label})
+	if err != nil {
+		return 0, fmt.Errorf("error creating tag %q: %w", label, err)
+	}
+	defer resp.Body.Close()
+
+	var created Tag
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return 0, fmt.Errorf("error decoding created tag: %w", err)
+	}
+	tags[label] = created.ID
+	return created.ID, nil
+}
+
+// EditMovieTags adds or removes (applyTags "add" or "remove") tags on a
+// movie through Radarr's bulk movie editor, which leaves the rest of the
+// movie untouched.
+func (c *Client) EditMovieTags(movieID int, tagIDs []int, applyTags string) error {
+	resp, err := c.makeJSONRequest("PUT", "/api/v3/movie/editor", MovieEditor{
+		MovieIDs:  []int{movieID},
+		Tags:      tagIDs,
+		ApplyTags: applyTags,
+	})
+	if err != nil {
+		return fmt.Errorf("error editing tags of movie %d: %w", movieID, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// GetImportListMembership returns the names of the import lists that
+// currently provide each movie, keyed by TMDb ID. Lists are fetched fresh on
+// every call.
+func (c *Client) GetImportListMembership() (map[int][]string, error) {
+	resp, err := c.makeRequest("GET", "/api/v3/importlist", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var lists []ImportList
+	if err := json.NewDecoder(resp.Body).Decode(&lists); err != nil {
+		return nil, fmt.Errorf("error decoding import lists: %w", err)
+	}
+	if len(lists) == 0 {
+		return map[int][]string{}, nil
+	}
+
+	params := url.Values{}
+	params.Set("includeRecommendations", "false")
+	moviesResp, err := c.makeRequest("GET", "/api/v3/importlist/movie", params)
+	if err != nil {
+		return nil, err
+	}
+	defer moviesResp.Body.Close()
+
+	var movies []ImportListMovie
+	if err := json.NewDecoder(moviesResp.Body).Decode(&movies); err != nil {
+		return nil, fmt.Errorf("error decoding import list movies: %w", err)
+	}
+
+	return importListMembership(lists, movies), nil
+}
+
+// importListMembership maps each movie's TMDb ID to the names of the lists
+// that have it, skipping list IDs that no longer exist.
+func importListMembership(lists []ImportList, movies []ImportListMovie) map[int][]string {
+	names := make(map[int]string, len(lists))
+	for _, list := range lists {
+		names[list.ID] = list.Name
+	}
+
+	membership := make(map[int][]string)
+	for _, movie := range movies {
+		for _, id := range movie.Lists {
+			name := names[id]
+			if name == "" || slices.Contains(membership[movie.TMDbID], name) {
+				continue
+			}
+			membership[movie.TMDbID] = append(membership[movie.TMDbID], name)
+		}
+	}
+	return membership
 }
 
 // SearchMovieByTitle returns all movies whose title, original title, clean title,
@@ -220,18 +471,87 @@ func (c *Client) GetMovieByIMDbID(imdbID string) (*Movie, error) {
 		imdbID = "tt" + imdbID
 	}
 
-	movies, err := c.GetAllMovies()
-	if err != nil {
+	if _, err := c.GetAllMovies(); err != nil {
 		return nil, err
 	}
 
-	for i := range movies {
-		if movies[i].IMDbID == imdbID {
-			return &movies[i], nil
+	c.moviesMu.Lock()
+	movie := c.byIMDb[imdbID]
+	c.moviesMu.Unlock()
+	if movie == nil {
+		return nil, fmt.Errorf("movie with IMDb ID %s not found", imdbID)
+	}
+	return movie, nil
+}
+
+// GetMovieByTMDbID returns the Radarr movie with the given TMDb ID. While the
+// movie list is cached this is an index lookup; otherwise it asks Radarr
+// for just this movie (/api/v3/movie?tmdbId=) instead of downloading the
+// whole library, and remembers the answer until ClearCache.
+func (c *Client) GetMovieByTMDbID(tmdbID int) (*Movie, error) {
+	c.moviesMu.Lock()
+	loaded, movie := c.moviesFresh(), c.byTMDb[tmdbID]
+	c.moviesMu.Unlock()
+	if !loaded {
+		var err error
+		if movie, err = c.lookupMovie(tmdbID); err != nil {
+			return nil, err
+		}
+	}
+	if movie == nil {
+		return nil, fmt.Errorf("movie with TMDb ID %d not found", tmdbID)
+	}
+	return movie, nil
+}
+
+// HasMovie reports whether Radarr has the movie with the given TMDb ID.
+// Unlike GetMovieByTMDbID, a failed request is an error rather than "not
+// found".
+func (c *Client) HasMovie(tmdbID int) (bool, error) {
+	c.moviesMu.Lock()
+	loaded, movie := c.moviesFresh(), c.byTMDb[tmdbID]
+	c.moviesMu.Unlock()
+	if !loaded {
+		var err error
+		if movie, err = c.lookupMovie(tmdbID); err != nil {
+			return false, err
 		}
 	}
+	return movie != nil, nil
+}
+
+// lookupMovie fetches a single movie by TMDb ID, returning nil if Radarr
+// doesn't have it.
+func (c *Client) lookupMovie(tmdbID int) (*Movie, error) {
+	c.lookupsMu.Lock()
+	defer c.lookupsMu.Unlock()
+
+	if movie, ok := c.lookups[tmdbID]; ok {
+		return movie, nil
+	}
+
+	params := url.Values{}
+	params.Set("tmdbId", strconv.Itoa(tmdbID))
+	resp, err := c.makeRequest("GET", "/api/v3/movie", params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var movies []Movie
+	if err := json.NewDecoder(resp.Body).Decode(&movies); err != nil {
+		return nil, fmt.Errorf("error decoding movie: %w", err)
+	}
 
-	return nil, fmt.Errorf("movie with IMDb ID %s not found", imdbID)
+	var movie *Movie
+	if len(movies) > 0 {
+		movie = &movies[0]
+	}
+	if c.lookups == nil {
+		c.lookups = make(map[int]*Movie)
+	}
+	c.lookups[tmdbID] = movie
+	return movie, nil
 }
 
 func (c *Client) GetMovieByPath(filePath string) (*Movie, error) {
@@ -279,6 +599,34 @@ func (c *Client) GetSystemStatus() (*SystemStatus, error) {
 	return &status, nil
 }
 
+// HealthWarnings returns the warnings and errors from Radarr's health checks,
+// such as unavailable indexers or low disk space.
+func (c *Client) HealthWarnings() ([]string, error) {
+	resp, err := c.makeRequest("GET", "/api/v3/health", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var checks []HealthCheck
+	if err := json.NewDecoder(resp.Body).Decode(&checks); err != nil {
+		return nil, fmt.Errorf("error decoding health: %w", err)
+	}
+
+	return healthWarnings(checks), nil
+}
+
+// healthWarnings formats the checks of type warning or error.
+func healthWarnings(checks []HealthCheck) []string {
+	var warnings []string
+	for _, check := range checks {
+		if check.Type == "warning" || check.Type == "error" {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", check.Type, check.Message))
+		}
+	}
+	return warnings
+}
+
 func (c *Client) TestConnection() error {
 	_, err := c.GetSystemStatus()
 	return err
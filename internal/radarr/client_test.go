@@ -0,0 +1,53 @@
+package radarr
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestImportListMembership(t *testing.T) {
+	lists := []ImportList{
+		{ID: 1, Name: "Plex Watchlist", Enabled: true},
+		{ID: 2, Name: "IMDb Top 250", Enabled: true},
+	}
+	body := `[
+		{"tmdbId": 603, "title": "The Matrix", "lists": [1, 2]},
+		{"tmdbId": 550, "title": "Fight Club", "lists": [2, 2]},
+		{"tmdbId": 27205, "title": "Inception", "lists": [9]}
+	]`
+	var movies []ImportListMovie
+	if err := json.Unmarshal([]byte(body), &movies); err != nil {
+		t.Fatalf("failed to decode import list movies: %v", err)
+	}
+
+	got := importListMembership(lists, movies)
+	want := map[int][]string{
+		603: {"Plex Watchlist", "IMDb Top 250"},
+		550: {"IMDb Top 250"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("importListMembership() = %v, want %v", got, want)
+	}
+}
+
+func TestHealthWarnings(t *testing.T) {
+	body := `[
+		{"source": "IndexerStatusCheck", "type": "warning", "message": "Indexers unavailable due to failures: NZBgeek"},
+		{"source": "UpdateCheck", "type": "notice", "message": "New update is available"},
+		{"source": "RootFolderCheck", "type": "error", "message": "Missing root folder: /movies"}
+	]`
+	var checks []HealthCheck
+	if err := json.Unmarshal([]byte(body), &checks); err != nil {
+		t.Fatalf("failed to decode health: %v", err)
+	}
+
+	got := healthWarnings(checks)
+	want := []string{
+		"warning: Indexers unavailable due to failures: NZBgeek",
+		"error: Missing root folder: /movies",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("healthWarnings() = %v, want %v", got, want)
+	}
+}
@@ -19,9 +19,39 @@ type Movie struct {
 	MinimumAvailability string         `json:"minimumAvailability"`
 	IsAvailable      bool              `json:"isAvailable"`
 	ProfileID        int               `json:"profileId"`
+	QualityProfileID int               `json:"qualityProfileId"`
 	Runtime          int               `json:"runtime"`
 	CleanTitle       string            `json:"cleanTitle"`
 	TitleSlug        string            `json:"titleSlug"`
+	Tags             []int             `json:"tags"`
+	Collection       *MovieCollection  `json:"collection,omitempty"`
+	OriginalLanguage *Language         `json:"originalLanguage,omitempty"`
+}
+
+// CollectionName returns the name of the TMDb collection the movie belongs
+// to, or "" if none.
+func (m *Movie) CollectionName() string {
+	if m.Collection == nil {
+		return ""
+	}
+	if m.Collection.Title != "" {
+		return m.Collection.Title
+	}
+	return m.Collection.Name
+}
+
+// MovieCollection is the TMDb collection of a movie. Radarr v5 calls the
+// name "title", earlier versions "name".
+type MovieCollection struct {
+	Title  string `json:"title,omitempty"`
+	Name   string `json:"name,omitempty"`
+	TMDbID int    `json:"tmdbId"`
+}
+
+// Language is a Radarr language, e.g. {ID: 3, Name: "Spanish"}
+type Language struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
 }
 
 // AlternateTitle represents alternate titles for a movie
@@ -47,6 +77,53 @@ type MovieFile struct {
 	Path             string `json:"path"`
 	Size             int64  `json:"size"`
 	DateAdded        string `json:"dateAdded"`
+	Quality          QualityModel `json:"quality"`
+}
+
+// QualityModel wraps the quality of a movie file
+type QualityModel struct {
+	Quality Quality `json:"quality"`
+}
+
+// Quality is a Radarr quality definition (e.g. "Bluray-1080p", "Remux-2160p")
+type Quality struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// QualityProfile represents a Radarr quality profile
+type QualityProfile struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Tag represents a Radarr tag
+type Tag struct {
+	ID    int    `json:"id"`
+	Label string `json:"label"`
+}
+
+// ImportList represents a Radarr import list
+type ImportList struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// ImportListMovie is a movie currently provided by Radarr's import lists;
+// Lists holds the IDs of every list that has it.
+type ImportListMovie struct {
+	TMDbID int    `json:"tmdbId"`
+	Title  string `json:"title"`
+	Lists  []int  `json:"lists"`
+}
+
+// MovieEditor is the body of a bulk movie edit; ApplyTags is "add",
+// "remove" or "replace"
+type MovieEditor struct {
+	MovieIDs  []int  `json:"movieIds"`
+	Tags      []int  `json:"tags"`
+	ApplyTags string `json:"applyTags"`
 }
 
 // SearchResult represents a movie search result
@@ -54,6 +131,15 @@ type SearchResult struct {
 	Movie
 }
 
+// HealthCheck is one entry of Radarr's health report; Type is "ok",
+// "notice", "warning" or "error"
+type HealthCheck struct {
+	Source  string `json:"source"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	WikiURL string `json:"wikiUrl,omitempty"`
+}
+
 // SystemStatus represents Radarr system status
 type SystemStatus struct {
 	Version          string `json:"version"`
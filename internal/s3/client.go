@@ -0,0 +1,163 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+// Client uploads objects to an S3-compatible bucket (AWS S3, MinIO, ...)
+// using path-style URLs and AWS Signature Version 4.
+type Client struct {
+	endpoint    string
+	region      string
+	bucket      string
+	accessKey   string
+	secretKey   string
+	retryClient *utils.RetryableHTTPClient
+}
+
+// NewClient creates a client for bucket at endpoint, e.g.
+// "https://s3.eu-west-1.amazonaws.com" or "http://minio:9000".
+func NewClient(endpoint, region, bucket, accessKey, secretKey string) *Client {
+	httpClient := &http.Client{
+		Timeout: 5 * time.Minute,
+	}
+	return &Client{
+		endpoint:    strings.TrimRight(endpoint, "/"),
+		region:      region,
+		bucket:      bucket,
+		accessKey:   accessKey,
+		secretKey:   secretKey,
+		retryClient: utils.NewRetryableHTTPClient(httpClient, nil),
+	}
+}
+
+// PutObject uploads body as the object key, replacing any existing object.
+func (c *Client) PutObject(key string, body []byte, contentType string) error {
+	path := "/" + uriEncode(c.bucket, false) + "/" + uriEncode(key, true)
+	req, err := http.NewRequest("PUT", c.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	signV4(req, path, payloadHash, c.accessKey, c.secretKey, c.region, "s3", time.Now().UTC())
+
+	resp, err := c.retryClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error uploading %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("S3 returned status %d uploading %s: %s", resp.StatusCode, key, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// signV4 adds an AWS Signature Version 4 Authorization header to req. The
+// Host header and every header already set on req are signed; canonicalPath
+// is the URI-encoded request path.
+func signV4(req *http.Request, canonicalPath, payloadHash, accessKey, secretKey, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath,
+		canonicalQuery(req),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery returns the request's query string with names and values
+// URI-encoded and sorted, as SigV4 requires.
+func canonicalQuery(req *http.Request) string {
+	query := req.URL.Query()
+	var pairs []string
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name, false)+"="+uriEncode(value, false))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes every byte except the unreserved characters
+// A-Z a-z 0-9 - _ . ~ and, if keepSlash is set, "/".
+func uriEncode(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch >= 'A' && ch <= 'Z', ch >= 'a' && ch <= 'z', ch >= '0' && ch <= '9',
+			ch == '-', ch == '_', ch == '.', ch == '~', ch == '/' && keepSlash:
+			b.WriteByte(ch)
+		default:
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,42 @@
+package s3
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestSignV4 checks the signer against the "get-vanilla" case of the AWS
+// Signature Version 4 test suite.
+func TestSignV4(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	emptyHash := sha256Hex(nil)
+	signV4(req, "/", emptyHash, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "service", now)
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestURIEncode(t *testing.T) {
+	tests := []struct {
+		in        string
+		keepSlash bool
+		want      string
+	}{
+		{"Movies/4K Remux.txt", true, "Movies/4K%20Remux.txt"},
+		{"a+b=c&d", false, "a%2Bb%3Dc%26d"},
+		{"a/b", false, "a%2Fb"},
+		{"Amélie~", true, "Am%C3%A9lie~"},
+	}
+	for _, tt := range tests {
+		if got := uriEncode(tt.in, tt.keepSlash); got != tt.want {
+			t.Errorf("uriEncode(%q, %v) = %q, want %q", tt.in, tt.keepSlash, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package sonarr
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -29,17 +30,24 @@ type Client struct {
 	apiKey      string
 	httpClient  *http.Client
 	retryClient *utils.RetryableHTTPClient
+	proxyAuth   *utils.ProxyAuth
 	series      []Series
+	seriesAt    time.Time
 	seriesMu    sync.Mutex
+	cacheTTL    time.Duration
+	tags        map[int]string
+	tagsMu      sync.Mutex
 }
 
-func NewClient(baseURL, apiKey string) *Client {
-	return NewClientWithRetryConfig(baseURL, apiKey, nil)
+// NewClient creates a Sonarr API client. cacheTTL is how long the series
+// list is kept; 0 keeps it until ClearCache.
+func NewClient(baseURL, apiKey string, cacheTTL time.Duration) *Client {
+	return NewClientWithRetryConfig(baseURL, apiKey, cacheTTL, nil)
 }
 
 // NewClientWithRetryConfig creates a new Sonarr API client with custom retry configuration.
 // Pass nil for retryConfig to use the default exponential backoff configuration.
-func NewClientWithRetryConfig(baseURL, apiKey string, retryConfig *utils.RetryConfig) *Client {
+func NewClientWithRetryConfig(baseURL, apiKey string, cacheTTL time.Duration, retryConfig *utils.RetryConfig) *Client {
 	baseURL = strings.TrimRight(baseURL, "/")
 	httpClient := &http.Client{
 		Timeout: 30 * time.Second,
@@ -49,9 +57,16 @@ func NewClientWithRetryConfig(baseURL, apiKey string, retryConfig *utils.RetryCo
 		apiKey:      apiKey,
 		httpClient:  httpClient,
 		retryClient: utils.NewRetryableHTTPClient(httpClient, retryConfig),
+		cacheTTL:    cacheTTL,
 	}
 }
 
+// SetProxyAuth attaches extra headers and basic auth credentials to every
+// request, for Sonarr instances behind an authenticating reverse proxy.
+func (c *Client) SetProxyAuth(auth *utils.ProxyAuth) {
+	c.proxyAuth = auth
+}
+
 func (c *Client) makeRequest(method, endpoint string, params url.Values) (*http.Response, error) {
 	fullURL := fmt.Sprintf("%s%s", c.baseURL, endpoint)
 
@@ -65,6 +80,7 @@ func (c *Client) makeRequest(method, endpoint string, params url.Values) (*http.
 	}
 
 	req.Header.Set("X-Api-Key", c.apiKey)
+	c.proxyAuth.Apply(req)
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.retryClient.Do(req)
@@ -80,13 +96,44 @@ func (c *Client) makeRequest(method, endpoint string, params url.Values) (*http.
 	return resp, nil
 }
 
+// makeJSONRequest sends body as JSON and accepts any 2xx response, for the
+// write endpoints that answer 201 or 202.
+func (c *Client) makeJSONRequest(method, endpoint string, body interface{}) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %w", err)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("X-Api-Key", c.apiKey)
+	c.proxyAuth.Apply(req)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.retryClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("sonarr API returned status %d", resp.StatusCode)
+	}
+
+	return resp, nil
+}
+
 // GetAllSeries fetches the full series list from Sonarr, caching the result
-// for the lifetime of the client. Call ClearCache to refresh.
+// for the cache TTL or, without one, until ClearCache.
 func (c *Client) GetAllSeries() ([]Series, error) {
 	c.seriesMu.Lock()
 	defer c.seriesMu.Unlock()
 
-	if c.series != nil {
+	if c.series != nil && (c.cacheTTL == 0 || time.Since(c.seriesAt) < c.cacheTTL) {
 		return c.series, nil
 	}
 
@@ -102,14 +149,100 @@ func (c *Client) GetAllSeries() ([]Series, error) {
 	}
 
 	c.series = series
+	c.seriesAt = time.Now()
 	return series, nil
 }
 
-// ClearCache forces the next GetAllSeries call to re-fetch from Sonarr.
+// ClearCache forces the next GetTags call to re-fetch from Sonarr, and the
+// next GetAllSeries call too unless a cache TTL is set, in which case the
+// series list expires on its own.
 func (c *Client) ClearCache() {
-	c.seriesMu.Lock()
-	c.series = nil
-	c.seriesMu.Unlock()
+	if c.cacheTTL == 0 {
+		c.seriesMu.Lock()
+		c.series = nil
+		c.seriesMu.Unlock()
+	}
+
+	c.tagsMu.Lock()
+	c.tags = nil
+	c.tagsMu.Unlock()
+}
+
+// GetTags returns tag labels by ID, cached like the series list.
+func (c *Client) GetTags() (map[int]string, error) {
+	c.tagsMu.Lock()
+	defer c.tagsMu.Unlock()
+	return c.getTagsLocked()
+}
+
+func (c *Client) getTagsLocked() (map[int]string, error) {
+	if c.tags != nil {
+		return c.tags, nil
+	}
+
+	resp, err := c.makeRequest("GET", "/api/v3/tag", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tags []Tag
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("error decoding tags: %w", err)
+	}
+
+	c.tags = make(map[int]string, len(tags))
+	for _, tag := range tags {
+		c.tags[tag.ID] = tag.Label
+	}
+	return c.tags, nil
+}
+
+// EnsureTag returns the ID of the tag with the given label, creating the tag
+// if Sonarr doesn't have it yet.
+func (c *Client) EnsureTag(label string) (int, error) {
+	c.tagsMu.Lock()
+	defer c.tagsMu.Unlock()
+
+	tags, err := c.getTagsLocked()
+	if err != nil {
+		return 0, err
+	}
+	label = strings.ToLower(label)
+	for id, name := range tags {
+		if strings.EqualFold(name, label) {
+			return id, nil
+		}
+	}
+
+	resp, err := c.makeJSONRequest("POST", "/api/v3/tag", Tag{Label: label})
+	if err != nil {
+		return 0, fmt.Errorf("error creating tag %q: %w", label, err)
+	}
+	defer resp.Body.Close()
+
+	var created Tag
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return 0, fmt.Errorf("error decoding created tag: %w", err)
+	}
+	tags[created.ID] = created.Label
+	return created.ID, nil
+}
+
+// EditSeriesTags adds or removes (applyTags "add" or "remove") tags on a
+// series through Sonarr's bulk series editor, which leaves the rest of the
+// series untouched.
+func (c *Client) EditSeriesTags(seriesID int, tagIDs []int, applyTags string) error {
+	resp, err := c.makeJSONRequest("PUT", "/api/v3/series/editor", SeriesEditor{
+		SeriesIDs: []int{seriesID},
+		Tags:      tagIDs,
+		ApplyTags: applyTags,
+	})
+	if err != nil {
+		return fmt.Errorf("error editing tags of series %d: %w", seriesID, err)
+	}
+	resp.Body.Close()
+	return nil
 }
 
 // SearchSeriesByTitle returns all series whose title, sort title, clean title,
@@ -296,6 +429,45 @@ func (c *Client) GetEpisodesBySeries(seriesID int) ([]Episode, error) {
 	return episodes, nil
 }
 
+// GetEpisodeFilesBySeries returns the series' episode files, with their
+// paths and sizes as Sonarr sees them.
+func (c *Client) GetEpisodeFilesBySeries(seriesID int) ([]EpisodeFile, error) {
+	params := url.Values{}
+	params.Set("seriesId", strconv.Itoa(seriesID))
+
+	resp, err := c.makeRequest("GET", "/api/v3/episodefile", params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var files []EpisodeFile
+	if err := json.NewDecoder(resp.Body).Decode(&files); err != nil {
+		return nil, fmt.Errorf("error decoding episode files: %w", err)
+	}
+
+	return files, nil
+}
+
+// GetQueueBySeries returns the series' downloads in Sonarr's queue.
+func (c *Client) GetQueueBySeries(seriesID int) ([]QueueItem, error) {
+	params := url.Values{}
+	params.Set("seriesId", strconv.Itoa(seriesID))
+
+	resp, err := c.makeRequest("GET", "/api/v3/queue/details", params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var queue []QueueItem
+	if err := json.NewDecoder(resp.Body).Decode(&queue); err != nil {
+		return nil, fmt.Errorf("error decoding queue: %w", err)
+	}
+
+	return queue, nil
+}
+
 func (c *Client) GetSystemStatus() (*SystemStatus, error) {
 	resp, err := c.makeRequest("GET", "/api/v3/system/status", nil)
 	if err != nil {
@@ -311,6 +483,34 @@ func (c *Client) GetSystemStatus() (*SystemStatus, error) {
 	return &status, nil
 }
 
+// HealthWarnings returns the warnings and errors from Sonarr's health checks,
+// such as unavailable indexers or low disk space.
+func (c *Client) HealthWarnings() ([]string, error) {
+	resp, err := c.makeRequest("GET", "/api/v3/health", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var checks []HealthCheck
+	if err := json.NewDecoder(resp.Body).Decode(&checks); err != nil {
+		return nil, fmt.Errorf("error decoding health: %w", err)
+	}
+
+	return healthWarnings(checks), nil
+}
+
+// healthWarnings formats the checks of type warning or error.
+func healthWarnings(checks []HealthCheck) []string {
+	var warnings []string
+	for _, check := range checks {
+		if check.Type == "warning" || check.Type == "error" {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", check.Type, check.Message))
+		}
+	}
+	return warnings
+}
+
 func (c *Client) TestConnection() error {
 	_, err := c.GetSystemStatus()
 	return err
@@ -25,6 +25,21 @@ type Series struct {
 	TitleSlug        string            `json:"titleSlug"`
 	FirstAired       string            `json:"firstAired,omitempty"`
 	Added            string            `json:"added"`
+	Tags             []int             `json:"tags"`
+}
+
+// Tag represents a Sonarr tag
+type Tag struct {
+	ID    int    `json:"id"`
+	Label string `json:"label"`
+}
+
+// SeriesEditor is the body of a bulk series edit; ApplyTags is "add",
+// "remove" or "replace"
+type SeriesEditor struct {
+	SeriesIDs []int  `json:"seriesIds"`
+	Tags      []int  `json:"tags"`
+	ApplyTags string `json:"applyTags"`
 }
 
 // AlternateTitle represents alternate titles for a series
@@ -42,8 +57,27 @@ type Image struct {
 
 // Season represents a season of a TV series
 type Season struct {
-	SeasonNumber int  `json:"seasonNumber"`
-	Monitored    bool `json:"monitored"`
+	SeasonNumber int               `json:"seasonNumber"`
+	Monitored    bool              `json:"monitored"`
+	Statistics   *SeasonStatistics `json:"statistics,omitempty"`
+}
+
+// SeasonStatistics holds Sonarr's episode counts for a season.
+// EpisodeCount counts the monitored episodes that have aired (or already
+// have a file); TotalEpisodeCount includes unaired ones.
+type SeasonStatistics struct {
+	EpisodeFileCount  int `json:"episodeFileCount"`
+	EpisodeCount      int `json:"episodeCount"`
+	TotalEpisodeCount int `json:"totalEpisodeCount"`
+}
+
+// QueueItem represents a download in Sonarr's queue
+type QueueItem struct {
+	ID        int    `json:"id"`
+	SeriesID  int    `json:"seriesId"`
+	EpisodeID int    `json:"episodeId"`
+	Title     string `json:"title"`
+	Status    string `json:"status"`
 }
 
 // Episode represents an episode of a TV series
@@ -73,6 +107,15 @@ type EpisodeFile struct {
 	DateAdded    string `json:"dateAdded"`
 }
 
+// HealthCheck is one entry of Sonarr's health report; Type is "ok",
+// "notice", "warning" or "error"
+type HealthCheck struct {
+	Source  string `json:"source"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	WikiURL string `json:"wikiUrl,omitempty"`
+}
+
 // SystemStatus represents Sonarr system status
 type SystemStatus struct {
 	Version          string `json:"version"`
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetBatching buffers changes instead of rewriting the file on every Set,
+// Delete or Cleanup: the file is saved once items changes are pending, or
+// interval after the first of them, whichever comes first. Callers must
+// Flush before exiting. items <= 1 saves every change, the default.
+func (s *Storage) SetBatching(items int, interval time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.saveItems = items
+	s.saveInterval = interval
+}
+
+// changed records a change and saves the file if the batch is full. It
+// also reports a failed timed save, which had no caller to return it to.
+func (s *Storage) changed() error {
+	s.pending++
+	if s.saveItems <= 1 || s.pending >= s.saveItems {
+		s.timerErr = nil
+		return s.save()
+	}
+	if s.timer == nil && s.saveInterval > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(s.saveInterval, func() {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			// A save since the timer fired replaced or stopped it
+			if s.timer != timer {
+				return
+			}
+			s.timer = nil
+			if err := s.save(); err != nil {
+				s.timerErr = err
+			}
+		})
+		s.timer = timer
+	}
+	if err := s.timerErr; err != nil {
+		s.timerErr = nil
+		return fmt.Errorf("timed save failed: %w", err)
+	}
+	return nil
+}
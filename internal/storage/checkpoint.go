@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Checkpoint records how far an interrupted library scan got.
+type Checkpoint struct {
+	LibraryID string    `json:"libraryId"`
+	RatingKey string    `json:"ratingKey"` // last item the scan finished
+	Offset    int       `json:"offset"`    // items finished, in server order
+	Total     int       `json:"total"`     // items in the library at the time
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Checkpoints persists scan checkpoints by library ID, in checkpoints.json
+// next to the processed items.
+type Checkpoints struct {
+	filePath string
+	data     map[string]*Checkpoint
+	mutex    sync.Mutex
+}
+
+// NewCheckpoints loads the checkpoints stored in dataDir.
+func NewCheckpoints(dataDir string) (*Checkpoints, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	c := &Checkpoints{
+		filePath: filepath.Join(dataDir, "checkpoints.json"),
+		data:     make(map[string]*Checkpoint),
+	}
+
+	data, err := os.ReadFile(c.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("failed to load checkpoints: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.data); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoints: %w", err)
+	}
+	return c, nil
+}
+
+// Get returns the checkpoint for a library, if it has one.
+func (c *Checkpoints) Get(libraryID string) (*Checkpoint, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cp, ok := c.data[libraryID]
+	return cp, ok
+}
+
+// Set stores a library's checkpoint.
+func (c *Checkpoints) Set(cp *Checkpoint) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.data[cp.LibraryID] = cp
+	return c.save()
+}
+
+// Delete removes a library's checkpoint once its scan has completed.
+func (c *Checkpoints) Delete(libraryID string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.data[libraryID]; !ok {
+		return nil
+	}
+	delete(c.data, libraryID)
+	return c.save()
+}
+
+// save writes the checkpoints to disk via a temp file and rename.
+func (c *Checkpoints) save() error {
+	data, err := json.MarshalIndent(c.data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tempFile := c.filePath + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempFile, c.filePath)
+}
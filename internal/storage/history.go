@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// MaxHistory is the number of label changes kept per item. Beyond it the two
+// oldest changes are folded into one, so the first change's Prior and the
+// net effect of every change are never lost.
+const MaxHistory = 20
+
+// LabelChange records what one run changed in an item's label or genre
+// field.
+type LabelChange struct {
+	At    time.Time `json:"at"`
+	Field string    `json:"field"` // "label" or "genre"
+
+	// Prior holds the field's values before the run. nil when they weren't
+	// read, e.g. for changes migrated from older versions.
+	Prior   []string `json:"prior"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// AddHistory appends change to the item's history, folding the oldest
+// changes together beyond MaxHistory. Changes that added and removed nothing
+// are ignored.
+func (item *ProcessedItem) AddHistory(change LabelChange) {
+	if len(change.Added) == 0 && len(change.Removed) == 0 {
+		return
+	}
+	history := append(append([]LabelChange(nil), item.History...), change)
+	for len(history) > MaxHistory {
+		history = append([]LabelChange{foldChanges(history[0], history[1])}, history[2:]...)
+	}
+	item.History = history
+}
+
+// NetChange returns the combined effect of the item's history: Added holds
+// the values labelarr added and hasn't removed since, Removed the values
+// that were on the item before labelarr removed them and that it hasn't put
+// back. Prior, At and Field are those of the first change. This is the
+// record of which values labelarr owns.
+func (item *ProcessedItem) NetChange() LabelChange {
+	var net LabelChange
+	for i, change := range item.History {
+		if i == 0 {
+			net = LabelChange{At: change.At, Field: change.Field, Prior: change.Prior}
+		}
+		net = foldChanges(net, change)
+	}
+	return net
+}
+
+// foldChanges combines two consecutive changes into one with the same
+// effect, keeping the first's At, Field and Prior. A value added and later
+// removed, or removed and later added back, cancels out. Comparisons ignore
+// case.
+func foldChanges(first, next LabelChange) LabelChange {
+	folded := LabelChange{
+		At:      first.At,
+		Field:   first.Field,
+		Prior:   first.Prior,
+		Added:   slices.Clone(first.Added),
+		Removed: slices.Clone(first.Removed),
+	}
+	for _, value := range next.Removed {
+		if i := indexFold(folded.Added, value); i >= 0 {
+			folded.Added = slices.Delete(folded.Added, i, i+1)
+		} else if indexFold(folded.Removed, value) < 0 {
+			folded.Removed = append(folded.Removed, value)
+		}
+	}
+	for _, value := range next.Added {
+		if i := indexFold(folded.Removed, value); i >= 0 {
+			folded.Removed = slices.Delete(folded.Removed, i, i+1)
+		} else if indexFold(folded.Added, value) < 0 {
+			folded.Added = append(folded.Added, value)
+		}
+	}
+	if len(folded.Added) == 0 {
+		folded.Added = nil
+	}
+	if len(folded.Removed) == 0 {
+		folded.Removed = nil
+	}
+	return folded
+}
+
+func indexFold(values []string, value string) int {
+	return slices.IndexFunc(values, func(v string) bool {
+		return strings.EqualFold(v, value)
+	})
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// schemaVersion is the version of the processed items file this build
+// writes. Version 1, written before the file was versioned, is a bare map of
+// rating key to item without history.
+const schemaVersion = 2
+
+// storedItems is the processed items file from version 2 on.
+type storedItems struct {
+	Version int                       `json:"version"`
+	Items   map[string]*ProcessedItem `json:"items"`
+}
+
+// decodeItems parses the processed items file, migrating older versions. It
+// returns the version the file was written with.
+func decodeItems(data []byte) (map[string]*ProcessedItem, int, error) {
+	var probe struct {
+		Version json.RawMessage `json:"version"`
+		Items   json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, 0, err
+	}
+
+	// A version 1 file could only have "version" as a rating key holding an
+	// item object, never a number
+	var version int
+	if probe.Items == nil || json.Unmarshal(probe.Version, &version) != nil {
+		items := make(map[string]*ProcessedItem)
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, 0, err
+		}
+		markUntracked(items)
+		return items, 1, nil
+	}
+	if version > schemaVersion {
+		return nil, version, fmt.Errorf("processed items file has version %d; this version of labelarr only reads up to %d", version, schemaVersion)
+	}
+
+	var stored storedItems
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, version, err
+	}
+	if stored.Items == nil {
+		stored.Items = make(map[string]*ProcessedItem)
+	}
+	return stored.Items, version, nil
+}
+
+// markUntracked marks the synced items of a version 1 file Untracked, since
+// that version didn't record which values it applied.
+func markUntracked(items map[string]*ProcessedItem) {
+	for _, item := range items {
+		if item != nil {
+			item.Untracked = item.KeywordsSynced && len(item.History) == 0
+		}
+	}
+}
+
+// backupOld copies a file from an older schema version aside before it is
+// first rewritten, since older versions of labelarr can't read the current
+// one.
+func backupOld(filePath string, version int, data []byte) error {
+	backup := fmt.Sprintf("%s.v%d.bak", filePath, version)
+	if _, err := os.Stat(backup); err == nil {
+		return nil
+	}
+	return os.WriteFile(backup, data, 0644)
+}
@@ -17,6 +17,23 @@ type ProcessedItem struct {
 	LastProcessed  time.Time `json:"lastProcessed"`
 	KeywordsSynced bool      `json:"keywordsSynced"`
 	UpdateField    string    `json:"updateField"`
+
+	// LibraryID is the library the item was last listed in. Empty for
+	// entries written by older versions until their library is scanned.
+	LibraryID string `json:"libraryId,omitempty"`
+
+	// ManagedLabels are labels from lifecycle-managed sources (e.g. trending)
+	// that labelarr applied and will remove once they no longer apply.
+	ManagedLabels []string `json:"managedLabels,omitempty"`
+
+	// Untracked marks entries from versions that didn't record what labelarr
+	// applied, so History can't tell labelarr's values from the user's.
+	Untracked bool `json:"untracked,omitempty"`
+
+	// History lists the changes labelarr made to the field, oldest first.
+	// Its NetChange is the record of which values labelarr applied. See
+	// AddHistory.
+	History []LabelChange `json:"history,omitempty"`
 }
 
 // Storage handles persistent storage of processed items
@@ -24,6 +41,13 @@ type Storage struct {
 	filePath string
 	data     map[string]*ProcessedItem
 	mutex    sync.RWMutex
+
+	// Batched saves, see SetBatching
+	saveItems    int
+	saveInterval time.Duration
+	pending      int
+	timer        *time.Timer
+	timerErr     error
 }
 
 // NewStorage creates a new storage instance
@@ -36,8 +60,9 @@ func NewStorage(dataDir string) (*Storage, error) {
 	filePath := filepath.Join(dataDir, "processed_items.json")
 	
 	s := &Storage{
-		filePath: filePath,
-		data:     make(map[string]*ProcessedItem),
+		filePath:  filePath,
+		data:      make(map[string]*ProcessedItem),
+		saveItems: 1,
 	}
 	
 	// Load existing data
@@ -51,19 +76,36 @@ func NewStorage(dataDir string) (*Storage, error) {
 	return s, nil
 }
 
-// load reads data from the JSON file
+// load reads data from the JSON file. A file from an older schema version
+// is backed up and rewritten in the current one.
 func (s *Storage) load() error {
 	data, err := os.ReadFile(s.filePath)
 	if err != nil {
 		return err
 	}
-	
-	return json.Unmarshal(data, &s.data)
+
+	items, version, err := decodeItems(data)
+	if err != nil {
+		return err
+	}
+	s.data = items
+	if version == schemaVersion {
+		return nil
+	}
+	if version < schemaVersion {
+		if err := backupOld(s.filePath, version, data); err != nil {
+			return fmt.Errorf("failed to back up processed items before migration: %w", err)
+		}
+	}
+	if err := s.save(); err != nil {
+		return fmt.Errorf("failed to save migrated processed items: %w", err)
+	}
+	return nil
 }
 
 // save writes data to the JSON file
 func (s *Storage) save() error {
-	data, err := json.MarshalIndent(s.data, "", "  ")
+	data, err := json.MarshalIndent(storedItems{Version: schemaVersion, Items: s.data}, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -73,8 +115,25 @@ func (s *Storage) save() error {
 	if err := os.WriteFile(tempFile, data, 0644); err != nil {
 		return err
 	}
-	
-	return os.Rename(tempFile, s.filePath)
+	if err := os.Rename(tempFile, s.filePath); err != nil {
+		return err
+	}
+
+	s.pending = 0
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	return nil
+}
+
+// Flush writes the current data to disk, including changes still waiting
+// for a batched save.
+func (s *Storage) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.save()
 }
 
 // Get retrieves a processed item by rating key
@@ -93,7 +152,7 @@ func (s *Storage) Set(item *ProcessedItem) error {
 	
 	s.data[item.RatingKey] = item
 	
-	return s.save()
+	return s.changed()
 }
 
 // GetAll returns all processed items
@@ -118,6 +177,18 @@ func (s *Storage) Count() int {
 	return len(s.data)
 }
 
+// Delete removes the processed items with the given rating keys
+func (s *Storage) Delete(ratingKeys ...string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, key := range ratingKeys {
+		delete(s.data, key)
+	}
+
+	return s.changed()
+}
+
 // Cleanup removes old processed items (older than specified duration)
 func (s *Storage) Cleanup(maxAge time.Duration) error {
 	s.mutex.Lock()
@@ -131,5 +202,5 @@ func (s *Storage) Cleanup(maxAge time.Duration) error {
 		}
 	}
 	
-	return s.save()
+	return s.changed()
 }
\ No newline at end of file
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMigrateV1(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "processed_items.json")
+	v1 := `{
+  "101": {"ratingKey": "101", "title": "Heat", "tmdbId": "949", "lastProcessed": "2025-01-15T03:00:00Z", "keywordsSynced": true, "updateField": "label"},
+  "102": {"ratingKey": "102", "title": "Ronin", "tmdbId": "8195", "lastProcessed": "2025-01-15T03:00:00Z", "keywordsSynced": false, "updateField": "label"}
+}`
+	if err := os.WriteFile(filePath, []byte(v1), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	heat, ok := s.Get("101")
+	if !ok {
+		t.Fatal("item 101 missing after migration")
+	}
+	if heat.History != nil || !heat.Untracked {
+		t.Errorf("synced item = %+v, want no history and marked untracked", heat)
+	}
+	if ronin, _ := s.Get("102"); ronin == nil || ronin.Untracked {
+		t.Errorf("unsynced item = %+v, want it not marked untracked", ronin)
+	}
+
+	backup, err := os.ReadFile(filePath + ".v1.bak")
+	if err != nil || string(backup) != v1 {
+		t.Errorf("backup = %q, %v; want the version 1 file", backup, err)
+	}
+	var stored storedItems
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Version != schemaVersion || len(stored.Items) != 2 {
+		t.Errorf("migrated file: version %d, %d items, %v", stored.Version, len(stored.Items), err)
+	}
+
+	// The migrated file loads without migrating again
+	s, err = NewStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if heat, _ := s.Get("101"); heat == nil || !heat.Untracked {
+		t.Errorf("item after reload = %+v", heat)
+	}
+}
+
+func TestNewerSchemaRejected(t *testing.T) {
+	dir := t.TempDir()
+	data := fmt.Sprintf(`{"version": %d, "items": {}}`, schemaVersion+1)
+	if err := os.WriteFile(filepath.Join(dir, "processed_items.json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewStorage(dir); err == nil {
+		t.Error("NewStorage accepted a file from a newer schema version")
+	}
+}
+
+func TestAddHistory(t *testing.T) {
+	item := &ProcessedItem{}
+	item.AddHistory(LabelChange{Field: "label"})
+	if item.History != nil {
+		t.Errorf("change without additions or removals was recorded: %+v", item.History)
+	}
+
+	for i := 0; i < MaxHistory+5; i++ {
+		item.AddHistory(LabelChange{Field: "label", Added: []string{fmt.Sprint(i)}})
+	}
+	if len(item.History) != MaxHistory {
+		t.Fatalf("history has %d changes, want %d", len(item.History), MaxHistory)
+	}
+	if first, want := item.History[0].Added, []string{"0", "1", "2", "3", "4", "5"}; !reflect.DeepEqual(first, want) {
+		t.Errorf("oldest change added %v, want the folded %v", first, want)
+	}
+}
+
+func TestNetChange(t *testing.T) {
+	first := time.Date(2025, 1, 15, 3, 0, 0, 0, time.UTC)
+	item := &ProcessedItem{History: []LabelChange{
+		{At: first, Field: "label", Prior: []string{"4K", "sci fi"}, Added: []string{"heist", "sequel"}, Removed: []string{"sci fi"}},
+		{At: first.Add(time.Hour), Field: "label", Added: []string{"Trending"}, Removed: []string{"Sequel"}},
+		{At: first.Add(2 * time.Hour), Field: "label", Added: []string{"sci fi"}, Removed: []string{"trending"}},
+	}}
+	want := LabelChange{At: first, Field: "label", Prior: []string{"4K", "sci fi"}, Added: []string{"heist"}}
+	if got := item.NetChange(); !reflect.DeepEqual(got, want) {
+		t.Errorf("NetChange() = %+v, want %+v", got, want)
+	}
+
+	// Folding the oldest changes keeps the net effect and the first Prior
+	for i := 0; i < MaxHistory; i++ {
+		change := LabelChange{Field: "label", Added: []string{fmt.Sprint(i)}}
+		if i > 0 {
+			change.Removed = []string{fmt.Sprint(i - 1)}
+		}
+		item.AddHistory(change)
+	}
+	want.Added = []string{"heist", fmt.Sprint(MaxHistory - 1)}
+	if got := item.NetChange(); !reflect.DeepEqual(got, want) {
+		t.Errorf("NetChange() after folding = %+v, want %+v", got, want)
+	}
+}
+
+func TestBatchedSaves(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetBatching(3, time.Hour)
+
+	saved := func() int {
+		reloaded, err := NewStorage(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return reloaded.Count()
+	}
+	for i := 1; i <= 2; i++ {
+		if err := s.Set(&ProcessedItem{RatingKey: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := saved(); n != 0 {
+		t.Errorf("saved %d items before the batch was full, want 0", n)
+	}
+	if err := s.Set(&ProcessedItem{RatingKey: "3"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := saved(); n != 3 {
+		t.Errorf("saved %d items once the batch was full, want 3", n)
+	}
+
+	if err := s.Delete("1"); err != nil {
+		t.Fatal(err)
+	}
+	if n := saved(); n != 3 {
+		t.Errorf("saved %d items before Flush, want 3", n)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n := saved(); n != 2 {
+		t.Errorf("saved %d items after Flush, want 2", n)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "processed_items.json.tmp")); !os.IsNotExist(err) {
+		t.Errorf("temp file left behind: %v", err)
+	}
+}
+
+func TestBatchedSaveInterval(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetBatching(100, 10*time.Millisecond)
+	if err := s.Set(&ProcessedItem{RatingKey: "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "processed_items.json")); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("pending change was not saved after the interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,132 @@
+package tautulli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+// historyPageSize is how many plays are requested per get_history call.
+const historyPageSize = 1000
+
+// Client is a Tautulli API client
+type Client struct {
+	baseURL     string
+	apiKey      string
+	httpClient  *http.Client
+	retryClient *utils.RetryableHTTPClient
+}
+
+// NewClient creates a Tautulli client
+func NewClient(baseURL, apiKey string) *Client {
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	return &Client{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		apiKey:      apiKey,
+		httpClient:  httpClient,
+		retryClient: utils.NewRetryableHTTPClient(httpClient, nil),
+	}
+}
+
+func (c *Client) getHistory(start, length int) (*historyResponse, error) {
+	params := url.Values{}
+	params.Set("apikey", c.apiKey)
+	params.Set("cmd", "get_history")
+	params.Set("start", strconv.Itoa(start))
+	params.Set("length", strconv.Itoa(length))
+	params.Set("order_column", "date")
+	params.Set("order_dir", "desc")
+
+	req, err := http.NewRequest("GET", c.baseURL+"/api/v2?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.retryClient.Do(req)
+	if err != nil {
+		// The request URL carries the API key; don't echo it.
+		return nil, fmt.Errorf("error making Tautulli request to %s", c.baseURL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("tautulli API rejected the key (status 401) - check TAUTULLI_API_KEY")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tautulli API returned status %d", resp.StatusCode)
+	}
+
+	var result historyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decoding Tautulli response: %w", err)
+	}
+	if result.Response.Result != "success" {
+		return nil, fmt.Errorf("tautulli: %s", result.Response.Message)
+	}
+	return &result, nil
+}
+
+// GetWatchStats reads the full watch history and aggregates it per movie
+// and per show, keyed by Plex rating key.
+func (c *Client) GetWatchStats() (map[string]*WatchStats, error) {
+	var entries []HistoryEntry
+	for start := 0; ; start += historyPageSize {
+		page, err := c.getHistory(start, historyPageSize)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, page.Response.Data.Data...)
+		if len(page.Response.Data.Data) < historyPageSize || len(entries) >= page.Response.Data.RecordsFiltered {
+			break
+		}
+	}
+	return aggregate(entries), nil
+}
+
+// aggregate folds watched plays into per-item stats. Episodes count towards
+// their show; partial plays are ignored.
+func aggregate(entries []HistoryEntry) map[string]*WatchStats {
+	stats := make(map[string]*WatchStats)
+	for _, entry := range entries {
+		if entry.WatchedStatus < 1 {
+			continue
+		}
+		key := string(entry.RatingKey)
+		if entry.MediaType == "episode" {
+			key = string(entry.GrandparentRatingKey)
+		}
+		if key == "" {
+			continue
+		}
+
+		s, ok := stats[key]
+		if !ok {
+			s = &WatchStats{Users: make(map[string]bool)}
+			stats[key] = s
+		}
+		if watched := time.Unix(entry.Date, 0); watched.After(s.LastWatched) {
+			s.LastWatched = watched
+		}
+		for _, user := range []string{entry.User, entry.FriendlyName} {
+			if user != "" {
+				s.Users[strings.ToLower(user)] = true
+			}
+		}
+	}
+	return stats
+}
+
+// TestConnection verifies the URL and API key with a one-entry history
+// request.
+func (c *Client) TestConnection() error {
+	_, err := c.getHistory(0, 1)
+	return err
+}
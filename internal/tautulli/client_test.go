@@ -0,0 +1,45 @@
+package tautulli
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAggregate(t *testing.T) {
+	body := `[
+		{"rating_key": 101, "grandparent_rating_key": "", "media_type": "movie", "user": "alice", "friendly_name": "Alice", "date": 1700000000, "watched_status": 1},
+		{"rating_key": 101, "grandparent_rating_key": "", "media_type": "movie", "user": "kid", "friendly_name": "Kids Room", "date": 1600000000, "watched_status": 1},
+		{"rating_key": 501, "grandparent_rating_key": 500, "media_type": "episode", "user": "bob", "friendly_name": "", "date": 1650000000, "watched_status": 1},
+		{"rating_key": 201, "grandparent_rating_key": "", "media_type": "movie", "user": "bob", "friendly_name": "", "date": 1690000000, "watched_status": 0.5}
+	]`
+	var entries []HistoryEntry
+	if err := json.Unmarshal([]byte(body), &entries); err != nil {
+		t.Fatalf("failed to decode history: %v", err)
+	}
+
+	stats := aggregate(entries)
+
+	movie, ok := stats["101"]
+	if !ok {
+		t.Fatal("expected stats for movie 101")
+	}
+	if !movie.LastWatched.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("LastWatched = %v, want the most recent play", movie.LastWatched)
+	}
+	for _, user := range []string{"alice", "kid", "kids room"} {
+		if !movie.Users[user] {
+			t.Errorf("expected user %q in %v", user, movie.Users)
+		}
+	}
+
+	if _, ok := stats["500"]; !ok {
+		t.Error("expected episode plays to count towards show 500")
+	}
+	if _, ok := stats["501"]; ok {
+		t.Error("expected no stats keyed by the episode itself")
+	}
+	if _, ok := stats["201"]; ok {
+		t.Error("expected partial plays to be ignored")
+	}
+}
@@ -0,0 +1,54 @@
+package tautulli
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RatingKey is a Plex rating key. Tautulli reports rating keys as numbers,
+// or as an empty string when there is none (e.g. a movie's grandparent).
+type RatingKey string
+
+func (k *RatingKey) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*k = RatingKey(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err == nil {
+		*k = RatingKey(n.String())
+		return nil
+	}
+	*k = ""
+	return nil
+}
+
+// HistoryEntry is a single play from Tautulli's get_history command
+type HistoryEntry struct {
+	RatingKey            RatingKey `json:"rating_key"`
+	GrandparentRatingKey RatingKey `json:"grandparent_rating_key"`
+	MediaType            string    `json:"media_type"` // "movie", "episode", "track"
+	User                 string    `json:"user"`
+	FriendlyName         string    `json:"friendly_name"`
+	Date                 int64     `json:"date"`           // Unix time the play started
+	WatchedStatus        float64   `json:"watched_status"` // 1 once the watched threshold was reached
+}
+
+type historyResponse struct {
+	Response struct {
+		Result  string `json:"result"`
+		Message string `json:"message"`
+		Data    struct {
+			RecordsFiltered int            `json:"recordsFiltered"`
+			Data            []HistoryEntry `json:"data"`
+		} `json:"data"`
+	} `json:"response"`
+}
+
+// WatchStats aggregates an item's watched plays. Shows are aggregated across
+// their episodes.
+type WatchStats struct {
+	LastWatched time.Time
+	Users       map[string]bool // lowercased usernames and friendly names
+}
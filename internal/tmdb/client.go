@@ -5,24 +5,119 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/nullable-eth/labelarr/internal/config"
 	"github.com/nullable-eth/labelarr/internal/utils"
 )
 
-// Client represents a TMDb API client
+// maxRateLimitRetries bounds how often a single request is retried after
+// TMDb answers 429 Too Many Requests.
+const maxRateLimitRetries = 5
+
+// releaseTypeTheatrical is the release_dates type of a theatrical release
+const releaseTypeTheatrical = 3
+
+// Client represents a TMDb API client. It is safe for concurrent use; all
+// requests share one rate limiter (TMDB_RATE_LIMIT).
 type Client struct {
 	config     *config.Config
 	httpClient *http.Client
+	limiter    *utils.RateLimiter
+
+	// blacklistedKeywords holds TMDb keyword IDs from TMDB_KEYWORD_ID_BLACKLIST.
+	blacklistedKeywords map[int]bool
 }
 
 // NewClient creates a new TMDb client
 func NewClient(cfg *config.Config) *Client {
+	blacklist := make(map[int]bool, len(cfg.TMDbKeywordIDBlacklist))
+	for _, raw := range cfg.TMDbKeywordIDBlacklist {
+		if id, err := strconv.Atoi(raw); err == nil {
+			blacklist[id] = true
+		}
+	}
+
 	return &Client{
-		config:     cfg,
-		httpClient: &http.Client{},
+		config:              cfg,
+		httpClient:          &http.Client{},
+		limiter:             utils.NewRateLimiter(cfg.TMDbRateLimit),
+		blacklistedKeywords: blacklist,
+	}
+}
+
+// filterBlacklisted drops keywords whose TMDb ID is in TMDB_KEYWORD_ID_BLACKLIST
+// and returns the remaining names in their original order.
+func (c *Client) filterBlacklisted(keywords []Keyword) []string {
+	names := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		if c.blacklistedKeywords[keyword.ID] {
+			if c.config.VerboseLogging {
+				fmt.Printf("   [FILTER] Dropped blacklisted TMDb keyword %d (%s)\n", keyword.ID, keyword.Name)
+			}
+			continue
+		}
+		names = append(names, keyword.Name)
 	}
+	return names
+}
+
+// do sends req once the rate limiter allows it. A 429 pauses the shared
+// limiter for the server's Retry-After (1s if absent) so concurrent callers
+// back off together, then the request is retried up to maxRateLimitRetries
+// times before the 429 response is returned to the caller.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+		resp, err := c.httpClient.Do(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt >= maxRateLimitRetries {
+			return resp, err
+		}
+		resp.Body.Close()
+
+		backoff := time.Second
+		if wait := retryAfter(resp.Header.Get("Retry-After"), time.Now()); wait > 0 {
+			backoff = wait
+		}
+		if c.config.VerboseLogging {
+			fmt.Printf("   [WARN] TMDb rate limit hit, pausing requests for %v\n", backoff)
+		}
+		c.limiter.Pause(backoff)
+	}
+}
+
+// retryAfter parses a Retry-After header, given either as delta-seconds or
+// as an HTTP-date, into how long to wait from now. It returns 0 if the
+// header is absent, invalid or already past.
+func retryAfter(header string, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// normalize applies keyword normalization for proper capitalization and
+// spelling, logging each rewrite in verbose mode.
+func (c *Client) normalize(keywords []string) []string {
+	normalizedKeywords := utils.NormalizeKeywords(keywords)
+
+	if c.config.VerboseLogging {
+		for i, original := range keywords {
+			if i < len(normalizedKeywords) && original != normalizedKeywords[i] {
+				fmt.Printf("   [NOTE] Normalized: \"%s\" -> \"%s\"\n", original, normalizedKeywords[i])
+			}
+		}
+	}
+
+	return normalizedKeywords
 }
 
 // GetMovieKeywords fetches keywords for a movie from TMDb
@@ -37,17 +132,12 @@ func (c *Client) GetMovieKeywords(tmdbID string) ([]string, error) {
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.TMDbReadAccessToken))
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch movie keywords: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		time.Sleep(1 * time.Second)
-		return c.GetMovieKeywords(tmdbID)
-	}
-
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		if resp.StatusCode == http.StatusUnauthorized {
@@ -66,24 +156,9 @@ func (c *Client) GetMovieKeywords(tmdbID string) ([]string, error) {
 		return nil, fmt.Errorf("failed to parse keywords response: %w", err)
 	}
 
-	keywords := make([]string, len(keywordsResponse.Keywords))
-	for i, keyword := range keywordsResponse.Keywords {
-		keywords[i] = keyword.Name
-	}
+	keywords := c.filterBlacklisted(keywordsResponse.Keywords)
 
-	// Normalize keywords for proper capitalization and spelling
-	normalizedKeywords := utils.NormalizeKeywords(keywords)
-	
-	// Show normalization in verbose mode
-	if c.config.VerboseLogging {
-		for i, original := range keywords {
-			if i < len(normalizedKeywords) && original != normalizedKeywords[i] {
-				fmt.Printf("   [NOTE] Normalized: \"%s\" -> \"%s\"\n", original, normalizedKeywords[i])
-			}
-		}
-	}
-
-	return normalizedKeywords, nil
+	return c.normalize(keywords), nil
 }
 
 // GetTVShowKeywords fetches keywords for a TV show from TMDb
@@ -98,17 +173,12 @@ func (c *Client) GetTVShowKeywords(tmdbID string) ([]string, error) {
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.TMDbReadAccessToken))
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch TV show keywords: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		time.Sleep(1 * time.Second)
-		return c.GetTVShowKeywords(tmdbID)
-	}
-
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		if resp.StatusCode == http.StatusUnauthorized {
@@ -127,24 +197,343 @@ func (c *Client) GetTVShowKeywords(tmdbID string) ([]string, error) {
 		return nil, fmt.Errorf("failed to parse TV keywords response: %w", err)
 	}
 
-	keywords := make([]string, len(tvKeywordsResponse.Results))
-	for i, keyword := range tvKeywordsResponse.Results {
-		keywords[i] = keyword.Name
+	keywords := c.filterBlacklisted(tvKeywordsResponse.Results)
+
+	return c.normalize(keywords), nil
+}
+
+// getJSON performs an authenticated GET against the TMDb v3 API and decodes
+// the response into out. Rate limiting is handled by do; what describes the
+// request in error messages.
+func (c *Client) getJSON(path string, params url.Values, out interface{}, what string) error {
+	reqURL := "https://api.themoviedb.org/3" + path
+	if len(params) > 0 {
+		reqURL += "?" + params.Encode()
 	}
 
-	// Normalize keywords for proper capitalization and spelling
-	normalizedKeywords := utils.NormalizeKeywords(keywords)
-	
-	// Show normalization in verbose mode
-	if c.config.VerboseLogging {
-		for i, original := range keywords {
-			if i < len(normalizedKeywords) && original != normalizedKeywords[i] {
-				fmt.Printf("   [NOTE] Normalized: \"%s\" -> \"%s\"\n", original, normalizedKeywords[i])
+	req, err := http.NewRequest("GET", reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.TMDbReadAccessToken))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", what, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusUnauthorized {
+			return fmt.Errorf("tmdb API authentication failed (status 401) - check your TMDB_READ_ACCESS_TOKEN. Response: %s", string(body))
+		}
+		return fmt.Errorf("tmdb API returned status %d for %s. Response: %s", resp.StatusCode, what, string(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", what, err)
+	}
+	return nil
+}
+
+// FindByExternalID resolves an external ID (IMDb "tt..." or TVDb numeric ID)
+// to a TMDb ID using the /find endpoint. source is the TMDb external source
+// name ("imdb_id" or "tvdb_id") and mediaType is "movie" or "tv". Returns an
+// empty string without error when TMDb has no match.
+func (c *Client) FindByExternalID(externalID, source, mediaType string) (string, error) {
+	params := url.Values{}
+	params.Set("external_source", source)
+
+	var findResponse FindResponse
+	if err := c.getJSON("/find/"+url.PathEscape(externalID), params, &findResponse, fmt.Sprintf("%s %s", source, externalID)); err != nil {
+		return "", err
+	}
+
+	results := findResponse.MovieResults
+	if mediaType == "tv" {
+		results = findResponse.TVResults
+	}
+	if len(results) == 0 {
+		return "", nil
+	}
+	return strconv.Itoa(results[0].ID), nil
+}
+
+// GetDetails fetches the primary details record for a movie or TV show
+// ("movie"/"tv"). Sub-resources named in appends (e.g. "keywords",
+// "release_dates") are fetched in the same request via append_to_response
+// and can be read back with Details.Appended.
+func (c *Client) GetDetails(tmdbID, mediaType string, appends ...string) (*Details, error) {
+	var params url.Values
+	if len(appends) > 0 {
+		params = url.Values{}
+		params.Set("append_to_response", strings.Join(appends, ","))
+	}
+
+	var raw json.RawMessage
+	if err := c.getJSON(fmt.Sprintf("/%s/%s", mediaType, tmdbID), params, &raw, fmt.Sprintf("%s %s details", mediaType, tmdbID)); err != nil {
+		return nil, err
+	}
+	return splitAppended(raw, appends)
+}
+
+// splitAppended decodes a details response and sets aside the raw JSON of
+// each appended sub-resource, keyed by its append_to_response name.
+func splitAppended(raw json.RawMessage, appends []string) (*Details, error) {
+	var details Details
+	if err := json.Unmarshal(raw, &details); err != nil {
+		return nil, fmt.Errorf("failed to parse details response: %w", err)
+	}
+	if len(appends) == 0 {
+		return &details, nil
+	}
+
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &sections); err != nil {
+		return nil, fmt.Errorf("failed to parse details response: %w", err)
+	}
+	details.appended = make(map[string]json.RawMessage, len(appends))
+	for _, name := range appends {
+		if section, ok := sections[name]; ok {
+			details.appended[name] = section
+		}
+	}
+	return &details, nil
+}
+
+// KeywordsFromDetails returns the keywords appended to a details record,
+// blacklist-filtered and normalized exactly like GetMovieKeywords and
+// GetTVShowKeywords. ok is false when keywords were not appended.
+func (c *Client) KeywordsFromDetails(details *Details) (keywords []string, ok bool, err error) {
+	// Movies nest keywords under "keywords", TV shows under "results".
+	var section struct {
+		Keywords []Keyword `json:"keywords"`
+		Results  []Keyword `json:"results"`
+	}
+	found, err := details.Appended("keywords", &section)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	return c.normalize(c.filterBlacklisted(append(section.Keywords, section.Results...))), true, nil
+}
+
+// AlternativeTitlesFromDetails returns the alternative titles appended to a
+// details record. ok is false when alternative_titles was not appended.
+func (c *Client) AlternativeTitlesFromDetails(details *Details) (titles []string, ok bool, err error) {
+	// Movies nest titles under "titles", TV shows under "results".
+	var section struct {
+		Titles  []AlternativeTitle `json:"titles"`
+		Results []AlternativeTitle `json:"results"`
+	}
+	found, err := details.Appended("alternative_titles", &section)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	for _, t := range append(section.Titles, section.Results...) {
+		titles = append(titles, t.Title)
+	}
+	return titles, true, nil
+}
+
+// CertificationFromDetails returns a country's certification from a details
+// record: the theatrical release certification (or the first one given) for
+// movies, the content rating for TV shows. ok is false when neither
+// release_dates nor content_ratings was appended or the country has none.
+func (c *Client) CertificationFromDetails(details *Details, country string) (certification string, ok bool, err error) {
+	var releases struct {
+		Results []ReleaseDates `json:"results"`
+	}
+	found, err := details.Appended("release_dates", &releases)
+	if err != nil {
+		return "", false, err
+	}
+	if found {
+		for _, result := range releases.Results {
+			if result.ISO31661 != country {
+				continue
+			}
+			for _, release := range result.ReleaseDates {
+				if release.Certification == "" {
+					continue
+				}
+				if release.Type == releaseTypeTheatrical {
+					return release.Certification, true, nil
+				}
+				if certification == "" {
+					certification = release.Certification
+				}
 			}
 		}
+		return certification, certification != "", nil
 	}
 
-	return normalizedKeywords, nil
+	var ratings struct {
+		Results []ContentRating `json:"results"`
+	}
+	found, err = details.Appended("content_ratings", &ratings)
+	if err != nil || !found {
+		return "", false, err
+	}
+	for _, result := range ratings.Results {
+		if result.ISO31661 == country && result.Rating != "" {
+			return result.Rating, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// SearchByTitle searches TMDb for movies or TV shows ("movie"/"tv") by
+// title, narrowing by year when it is non-zero.
+func (c *Client) SearchByTitle(title string, year int, mediaType string) ([]SearchResult, error) {
+	params := url.Values{}
+	params.Set("query", title)
+	if year > 0 {
+		if mediaType == "tv" {
+			params.Set("first_air_date_year", strconv.Itoa(year))
+		} else {
+			params.Set("year", strconv.Itoa(year))
+		}
+	}
+
+	var searchResponse SearchResponse
+	if err := c.getJSON("/search/"+mediaType, params, &searchResponse, fmt.Sprintf("%s search %q", mediaType, title)); err != nil {
+		return nil, err
+	}
+	return searchResponse.Results, nil
+}
+
+// GetTrendingIDs returns the TMDb IDs on the first pages of the trending list
+// for a media type ("movie"/"tv") and time window ("day"/"week").
+func (c *Client) GetTrendingIDs(mediaType, window string, pages int) (map[int]bool, error) {
+	ids := make(map[int]bool)
+	for page := 1; page <= pages; page++ {
+		params := url.Values{}
+		params.Set("page", strconv.Itoa(page))
+
+		var trending SearchResponse
+		if err := c.getJSON(fmt.Sprintf("/trending/%s/%s", mediaType, window), params, &trending, mediaType+" trending list"); err != nil {
+			return nil, err
+		}
+		for _, result := range trending.Results {
+			ids[result.ID] = true
+		}
+		if len(trending.Results) == 0 {
+			break
+		}
+	}
+	return ids, nil
+}
+
+// GetWatchProviders returns the names of the services streaming a movie or
+// TV show in region by subscription, for free or with ads.
+func (c *Client) GetWatchProviders(tmdbID, mediaType, region string) ([]string, error) {
+	var providers WatchProvidersResponse
+	if err := c.getJSON(fmt.Sprintf("/%s/%s/watch/providers", mediaType, tmdbID), nil, &providers, mediaType+" watch providers"); err != nil {
+		return nil, err
+	}
+	return providers.names(region), nil
+}
+
+// WatchProvidersFromDetails is GetWatchProviders for a details record with
+// watch/providers appended. ok is false when it was not appended.
+func (c *Client) WatchProvidersFromDetails(details *Details, region string) (names []string, ok bool, err error) {
+	var providers WatchProvidersResponse
+	found, err := details.Appended("watch/providers", &providers)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	return providers.names(region), true, nil
+}
+
+// GetListItems returns the members of a TMDb list as "movie:<id>" and
+// "tv:<id>" keys, following pagination.
+func (c *Client) GetListItems(listID string) (map[string]bool, error) {
+	items := make(map[string]bool)
+	for page := 1; ; page++ {
+		params := url.Values{}
+		params.Set("page", strconv.Itoa(page))
+
+		var list ListResponse
+		if err := c.getJSON("/list/"+url.PathEscape(listID), params, &list, "list "+listID); err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			items[fmt.Sprintf("%s:%d", item.MediaType, item.ID)] = true
+		}
+		if page >= list.TotalPages {
+			break
+		}
+	}
+	return items, nil
+}
+
+// changesWindow is the longest date range TMDb accepts on changes endpoints.
+const changesWindow = 14 * 24 * time.Hour
+
+// changeWindows splits [start, end] into consecutive ranges TMDb accepts.
+func changeWindows(start, end time.Time) [][2]time.Time {
+	var windows [][2]time.Time
+	for from := start; from.Before(end); from = from.Add(changesWindow) {
+		to := from.Add(changesWindow)
+		if to.After(end) {
+			to = end
+		}
+		windows = append(windows, [2]time.Time{from, to})
+	}
+	return windows
+}
+
+// GetChangedIDs returns the set of movie or TV ("movie"/"tv") TMDb IDs that
+// had any change between start and end, following pagination.
+func (c *Client) GetChangedIDs(mediaType string, start, end time.Time) (map[int]bool, error) {
+	ids := make(map[int]bool)
+	for _, window := range changeWindows(start, end) {
+		for page := 1; ; page++ {
+			params := url.Values{}
+			params.Set("start_date", window[0].Format("2006-01-02"))
+			params.Set("end_date", window[1].Format("2006-01-02"))
+			params.Set("page", strconv.Itoa(page))
+
+			var changes ChangeListResponse
+			if err := c.getJSON("/"+mediaType+"/changes", params, &changes, mediaType+" change list"); err != nil {
+				return nil, err
+			}
+			for _, result := range changes.Results {
+				ids[result.ID] = true
+			}
+			if page >= changes.TotalPages {
+				break
+			}
+		}
+	}
+	return ids, nil
+}
+
+// GetChangedKeys returns the change keys (e.g. "keywords", "genres")
+// recorded for a single movie or TV show between start and end.
+func (c *Client) GetChangedKeys(tmdbID, mediaType string, start, end time.Time) ([]string, error) {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, window := range changeWindows(start, end) {
+		params := url.Values{}
+		params.Set("start_date", window[0].Format("2006-01-02"))
+		params.Set("end_date", window[1].Format("2006-01-02"))
+
+		var changes ItemChangesResponse
+		if err := c.getJSON(fmt.Sprintf("/%s/%s/changes", mediaType, tmdbID), params, &changes, fmt.Sprintf("%s %s changes", mediaType, tmdbID)); err != nil {
+			return nil, err
+		}
+		for _, change := range changes.Changes {
+			if !seen[change.Key] {
+				seen[change.Key] = true
+				keys = append(keys, change.Key)
+			}
+		}
+	}
+	return keys, nil
 }
 
 // TestConnection tests the TMDb API connection
@@ -160,7 +549,7 @@ func (c *Client) TestConnection() error {
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.TMDbReadAccessToken))
 	req.Header.Set("Accept", "application/json")
 	
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("failed to connect to TMDb API: %w", err)
 	}
@@ -0,0 +1,88 @@
+package tmdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/config"
+)
+
+func TestSplitAppendedKeywords(t *testing.T) {
+	client := NewClient(&config.Config{TMDbKeywordIDBlacklist: []string{"2"}})
+
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{
+		{"movie", `{"id":1,"original_language":"en","keywords":{"keywords":[{"id":1,"name":"heist"},{"id":2,"name":"based on novel or book"}]}}`, []string{"Heist"}},
+		{"tv", `{"id":1,"original_language":"ko","keywords":{"results":[{"id":3,"name":"time travel"}]}}`, []string{"Time Travel"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details, err := splitAppended([]byte(tt.raw), []string{"keywords"})
+			if err != nil {
+				t.Fatalf("splitAppended() error: %v", err)
+			}
+			if details.ID != 1 || details.OriginalLanguage == "" {
+				t.Errorf("primary fields not decoded: %+v", details)
+			}
+			got, ok, err := client.KeywordsFromDetails(details)
+			if err != nil || !ok {
+				t.Fatalf("KeywordsFromDetails() = ok %v, err %v", ok, err)
+			}
+			if len(got) != len(tt.want) || got[0] != tt.want[0] {
+				t.Errorf("KeywordsFromDetails() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	details, err := splitAppended([]byte(`{"id":1}`), nil)
+	if err != nil {
+		t.Fatalf("splitAppended() error: %v", err)
+	}
+	if _, ok, _ := client.KeywordsFromDetails(details); ok {
+		t.Error("expected ok=false when keywords were not appended")
+	}
+}
+
+func TestWatchProvidersFromDetails(t *testing.T) {
+	client := NewClient(&config.Config{})
+	raw := `{"id":1,"watch/providers":{"results":{"US":{"flatrate":[{"provider_id":8,"provider_name":"Netflix"}],"rent":[{"provider_id":2,"provider_name":"Apple TV"}],"ads":[{"provider_id":73,"provider_name":"Tubi TV"}]}}}}`
+	details, err := splitAppended([]byte(raw), []string{"watch/providers"})
+	if err != nil {
+		t.Fatalf("splitAppended() error: %v", err)
+	}
+	got, ok, err := client.WatchProvidersFromDetails(details, "US")
+	if err != nil || !ok {
+		t.Fatalf("WatchProvidersFromDetails() = ok %v, err %v", ok, err)
+	}
+	if len(got) != 2 || got[0] != "Netflix" || got[1] != "Tubi TV" {
+		t.Errorf("WatchProvidersFromDetails() = %v, want [Netflix Tubi TV]", got)
+	}
+	if got, _, _ := client.WatchProvidersFromDetails(details, "GB"); len(got) != 0 {
+		t.Errorf("WatchProvidersFromDetails(GB) = %v, want none", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"-1", 0},
+		{"Sat, 01 Mar 2025 12:00:05 GMT", 5 * time.Second},
+		{"Saturday, 01-Mar-25 12:00:10 GMT", 10 * time.Second},
+		{"Sat, 01 Mar 2025 11:59:00 GMT", 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := retryAfter(tt.header, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
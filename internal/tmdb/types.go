@@ -1,5 +1,10 @@
 package tmdb
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // Movie represents a TMDb movie
 type Movie struct {
 	ID       int    `json:"id"`
@@ -7,6 +12,69 @@ type Movie struct {
 	Overview string `json:"overview"`
 }
 
+// Company represents a TMDb production company
+type Company struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	OriginCountry string `json:"origin_country"`
+}
+
+// Genre represents a TMDb genre
+type Genre struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Country represents a TMDb production country
+type Country struct {
+	ISO31661 string `json:"iso_3166_1"`
+	Name     string `json:"name"`
+}
+
+// Details represents the TMDb movie or TV details record. Fields that only
+// exist for one media type are left empty for the other.
+type Details struct {
+	ID                  int       `json:"id"`
+	ProductionCompanies []Company `json:"production_companies"`
+	OriginalLanguage    string    `json:"original_language"`
+	ProductionCountries []Country `json:"production_countries"`
+	OriginCountry       []string  `json:"origin_country"`
+	ReleaseDate         string    `json:"release_date"`
+	FirstAirDate        string    `json:"first_air_date"`
+	Adult               bool      `json:"adult"`
+	IMDbID              string    `json:"imdb_id"`
+	Title               string    `json:"title"`
+	OriginalTitle       string    `json:"original_title"`
+	Name                string    `json:"name"`
+	OriginalName        string    `json:"original_name"`
+	Genres              []Genre   `json:"genres"`
+
+	// appended holds raw append_to_response sections by name.
+	appended map[string]json.RawMessage
+}
+
+// Appended decodes the append_to_response section called name into out.
+// It returns false without error when the section was not requested or
+// TMDb omitted it.
+func (d *Details) Appended(name string, out interface{}) (bool, error) {
+	section, ok := d.appended[name]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(section, out); err != nil {
+		return false, fmt.Errorf("failed to parse appended %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// Year returns the release (or first air) year, or 0 if unknown
+func (d Details) Year() int {
+	if d.ReleaseDate != "" {
+		return dateYear(d.ReleaseDate)
+	}
+	return dateYear(d.FirstAirDate)
+}
+
 // Keyword represents a TMDb keyword
 type Keyword struct {
 	ID   int    `json:"id"`
@@ -24,3 +92,138 @@ type TVKeywordsResponse struct {
 	ID      int       `json:"id"`
 	Results []Keyword `json:"results"`
 }
+
+// FindResult represents a single match returned by the TMDb find endpoint
+type FindResult struct {
+	ID int `json:"id"`
+}
+
+// FindResponse represents the response from the TMDb find endpoint
+type FindResponse struct {
+	MovieResults []FindResult `json:"movie_results"`
+	TVResults    []FindResult `json:"tv_results"`
+}
+
+// SearchResult represents a movie or TV show returned by the TMDb search endpoints
+type SearchResult struct {
+	ID            int     `json:"id"`
+	Title         string  `json:"title"`
+	Name          string  `json:"name"`
+	OriginalTitle string  `json:"original_title"`
+	OriginalName  string  `json:"original_name"`
+	ReleaseDate   string  `json:"release_date"`
+	FirstAirDate  string  `json:"first_air_date"`
+	Popularity    float64 `json:"popularity"`
+}
+
+// DisplayTitle returns the movie title or TV show name
+func (r SearchResult) DisplayTitle() string {
+	if r.Title != "" {
+		return r.Title
+	}
+	return r.Name
+}
+
+// Year returns the release (or first air) year, or 0 if unknown
+func (r SearchResult) Year() int {
+	if r.ReleaseDate != "" {
+		return dateYear(r.ReleaseDate)
+	}
+	return dateYear(r.FirstAirDate)
+}
+
+// dateYear parses the year from a TMDb "YYYY-MM-DD" date, or returns 0
+func dateYear(date string) int {
+	if len(date) < 4 {
+		return 0
+	}
+	year := 0
+	for _, ch := range date[:4] {
+		if ch < '0' || ch > '9' {
+			return 0
+		}
+		year = year*10 + int(ch-'0')
+	}
+	return year
+}
+
+// SearchResponse represents the response from the TMDb search endpoints
+type SearchResponse struct {
+	Page    int            `json:"page"`
+	Results []SearchResult `json:"results"`
+}
+
+// ChangeListResponse represents a page of the TMDb movie/TV change list
+type ChangeListResponse struct {
+	Results []struct {
+		ID int `json:"id"`
+	} `json:"results"`
+	Page       int `json:"page"`
+	TotalPages int `json:"total_pages"`
+}
+
+// AlternativeTitle represents a regional alternative title of a movie or TV show
+type AlternativeTitle struct {
+	ISO31661 string `json:"iso_3166_1"`
+	Title    string `json:"title"`
+	Type     string `json:"type"`
+}
+
+// ReleaseDates represents one country's releases in a movie's release_dates
+type ReleaseDates struct {
+	ISO31661     string `json:"iso_3166_1"`
+	ReleaseDates []struct {
+		Certification string `json:"certification"`
+		Type          int    `json:"type"`
+	} `json:"release_dates"`
+}
+
+// ContentRating represents one country's rating in a TV show's content_ratings
+type ContentRating struct {
+	ISO31661 string `json:"iso_3166_1"`
+	Rating   string `json:"rating"`
+}
+
+// WatchProvider represents a streaming service in TMDb watch provider data
+type WatchProvider struct {
+	ProviderID   int    `json:"provider_id"`
+	ProviderName string `json:"provider_name"`
+}
+
+// WatchProvidersResponse represents the TMDb watch/providers response, keyed
+// by region. The data is supplied to TMDb by JustWatch.
+type WatchProvidersResponse struct {
+	Results map[string]struct {
+		Flatrate []WatchProvider `json:"flatrate"`
+		Free     []WatchProvider `json:"free"`
+		Ads      []WatchProvider `json:"ads"`
+	} `json:"results"`
+}
+
+// names returns the services offering the title in region by subscription,
+// for free or with ads.
+func (r WatchProvidersResponse) names(region string) []string {
+	var names []string
+	regional := r.Results[region]
+	for _, provider := range append(append(regional.Flatrate, regional.Free...), regional.Ads...) {
+		names = append(names, provider.ProviderName)
+	}
+	return names
+}
+
+// ListResponse represents a page of a TMDb v3 list
+type ListResponse struct {
+	Items []struct {
+		ID        int    `json:"id"`
+		MediaType string `json:"media_type"`
+	} `json:"items"`
+	Page       int `json:"page"`
+	TotalPages int `json:"total_pages"`
+}
+
+// ItemChangesResponse represents the change history for a single movie or TV show
+type ItemChangesResponse struct {
+	Changes []struct {
+		Key string `json:"key"`
+	} `json:"changes"`
+}
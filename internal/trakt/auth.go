@@ -0,0 +1,165 @@
+package trakt
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Trakt uses the OAuth device flow: labelarr shows a short code, the user
+// enters it at trakt.tv/activate, and labelarr polls until it is approved.
+// The resulting token is stored in DATA_DIR and refreshed when it expires.
+
+// Authorize ensures the client holds a usable token, running the device
+// flow if none was stored. It blocks until the user approves the code or
+// the code expires.
+func (c *Client) Authorize() error {
+	if c.clientSecret == "" {
+		return fmt.Errorf("TRAKT_CLIENT_SECRET is required for Trakt authorization")
+	}
+	if c.hasToken() {
+		_, err := c.accessToken()
+		return err
+	}
+
+	resp, err := c.makeRequest("POST", "/oauth/device/code", map[string]string{"client_id": c.clientID}, false)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("trakt device code request returned status %d", resp.StatusCode)
+	}
+
+	var code DeviceCode
+	if err := json.NewDecoder(resp.Body).Decode(&code); err != nil {
+		return fmt.Errorf("error decoding device code: %w", err)
+	}
+
+	fmt.Printf("[TRAKT] To authorize labelarr, visit %s and enter code: %s\n", code.VerificationURL, code.UserCode)
+	return c.pollDeviceToken(code)
+}
+
+// pollDeviceToken polls for the token at the interval Trakt requested.
+func (c *Client) pollDeviceToken(code DeviceCode) error {
+	interval := time.Duration(code.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
+
+		resp, err := c.makeRequest("POST", "/oauth/device/token", map[string]string{
+			"code":          code.DeviceCode,
+			"client_id":     c.clientID,
+			"client_secret": c.clientSecret,
+		}, false)
+		if err != nil {
+			return err
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			var token Token
+			err := json.NewDecoder(resp.Body).Decode(&token)
+			resp.Body.Close()
+			if err != nil {
+				return fmt.Errorf("error decoding token: %w", err)
+			}
+			fmt.Println("[OK] Trakt authorization complete")
+			return c.setToken(&token)
+		case http.StatusBadRequest:
+			// Authorization pending
+		case http.StatusTooManyRequests:
+			interval += time.Second
+		case http.StatusGone:
+			resp.Body.Close()
+			return fmt.Errorf("trakt device code expired before it was approved")
+		case http.StatusTeapot:
+			resp.Body.Close()
+			return fmt.Errorf("trakt authorization was denied")
+		default:
+			resp.Body.Close()
+			return fmt.Errorf("trakt device token request returned status %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+	}
+	return fmt.Errorf("trakt device code expired before it was approved")
+}
+
+// accessToken returns a valid access token, refreshing it when expired.
+func (c *Client) accessToken() (string, error) {
+	c.tokenMu.Lock()
+	token := c.token
+	c.tokenMu.Unlock()
+
+	if token == nil {
+		return "", fmt.Errorf("trakt is not authorized - set TRAKT_CLIENT_SECRET and DATA_DIR and restart to authorize")
+	}
+	expires := time.Unix(token.CreatedAt+token.ExpiresIn, 0)
+	if time.Now().Add(time.Hour).Before(expires) {
+		return token.AccessToken, nil
+	}
+
+	resp, err := c.makeRequest("POST", "/oauth/token", map[string]string{
+		"refresh_token": token.RefreshToken,
+		"client_id":     c.clientID,
+		"client_secret": c.clientSecret,
+		"redirect_uri":  "urn:ietf:wg:oauth:2.0:oob",
+		"grant_type":    "refresh_token",
+	}, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("trakt token refresh returned status %d - delete trakt_token.json to re-authorize", resp.StatusCode)
+	}
+
+	var refreshed Token
+	if err := json.NewDecoder(resp.Body).Decode(&refreshed); err != nil {
+		return "", fmt.Errorf("error decoding refreshed token: %w", err)
+	}
+	if err := c.setToken(&refreshed); err != nil {
+		return "", err
+	}
+	return refreshed.AccessToken, nil
+}
+
+func (c *Client) hasToken() bool {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.token != nil
+}
+
+// setToken stores the token in memory and, when DATA_DIR is set, on disk.
+func (c *Client) setToken(token *Token) error {
+	c.tokenMu.Lock()
+	c.token = token
+	c.tokenMu.Unlock()
+
+	if c.tokenPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.tokenPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to save trakt token: %w", err)
+	}
+	return nil
+}
+
+func loadToken(path string) *Token {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil || token.AccessToken == "" {
+		return nil
+	}
+	return &token
+}
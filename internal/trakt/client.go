@@ -0,0 +1,139 @@
+package trakt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+const baseURL = "https://api.trakt.tv"
+
+// Watchlist is the TRAKT_LISTS name for the authenticated user's watchlist
+const Watchlist = "watchlist"
+
+type Client struct {
+	clientID     string
+	clientSecret string
+	tokenPath    string
+	token        *Token
+	tokenMu      sync.Mutex
+	httpClient   *http.Client
+	retryClient  *utils.RetryableHTTPClient
+}
+
+// NewClient creates a Trakt API client. clientSecret and dataDir are only
+// needed for OAuth (the watchlist and private lists); public lists work with
+// the client ID alone. A previously authorized token is loaded from dataDir.
+func NewClient(clientID, clientSecret, dataDir string) *Client {
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	c := &Client{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient:   httpClient,
+		retryClient:  utils.NewRetryableHTTPClient(httpClient, nil),
+	}
+	if dataDir != "" {
+		c.tokenPath = filepath.Join(dataDir, "trakt_token.json")
+		c.token = loadToken(c.tokenPath)
+	}
+	return c
+}
+
+func (c *Client) makeRequest(method, endpoint string, body interface{}, authenticated bool) (*http.Response, error) {
+	var reqBody *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	} else {
+		reqBody = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, baseURL+endpoint, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("trakt-api-version", "2")
+	req.Header.Set("trakt-api-key", c.clientID)
+	if authenticated {
+		token, err := c.accessToken()
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.retryClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	return resp, nil
+}
+
+// GetListItems returns the members of a Trakt list as "movie:<tmdbID>" and
+// "tv:<tmdbID>" keys. list is either Watchlist or "user/list-slug".
+func (c *Client) GetListItems(list string) (map[string]bool, error) {
+	endpoint := "/users/me/watchlist"
+	authenticated := true
+	if list != Watchlist {
+		user, slug, ok := strings.Cut(list, "/")
+		if !ok || user == "" || slug == "" {
+			return nil, fmt.Errorf("invalid trakt list %q, expected user/list-slug", list)
+		}
+		endpoint = fmt.Sprintf("/users/%s/lists/%s/items", url.PathEscape(user), url.PathEscape(slug))
+		authenticated = c.hasToken()
+	}
+
+	resp, err := c.makeRequest("GET", endpoint+"/movies,shows", nil, authenticated)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("trakt API returned status %d for list %s", resp.StatusCode, list)
+	}
+
+	var items []ListItem
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, fmt.Errorf("error decoding list %s: %w", list, err)
+	}
+
+	members := make(map[string]bool, len(items))
+	for _, item := range items {
+		switch {
+		case item.Movie != nil && item.Movie.IDs.TMDb > 0:
+			members[fmt.Sprintf("movie:%d", item.Movie.IDs.TMDb)] = true
+		case item.Show != nil && item.Show.IDs.TMDb > 0:
+			members[fmt.Sprintf("tv:%d", item.Show.IDs.TMDb)] = true
+		}
+	}
+	return members, nil
+}
+
+// TestConnection checks that the client ID is accepted.
+func (c *Client) TestConnection() error {
+	resp, err := c.makeRequest("GET", "/genres/movies", nil, false)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("trakt API returned status %d - check TRAKT_CLIENT_ID", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package trakt
+
+// IDs holds the external IDs Trakt attaches to a movie or show
+type IDs struct {
+	Trakt int    `json:"trakt"`
+	Slug  string `json:"slug"`
+	IMDb  string `json:"imdb"`
+	TMDb  int    `json:"tmdb"`
+	TVDb  int    `json:"tvdb"`
+}
+
+// Media is the movie or show inside a list item
+type Media struct {
+	Title string `json:"title"`
+	Year  int    `json:"year"`
+	IDs   IDs    `json:"ids"`
+}
+
+// ListItem represents an entry in a Trakt list or watchlist
+type ListItem struct {
+	Type  string `json:"type"`
+	Movie *Media `json:"movie,omitempty"`
+	Show  *Media `json:"show,omitempty"`
+}
+
+// DeviceCode is the response to a device code request
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURL string `json:"verification_url"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// Token is an OAuth access token, persisted in DATA_DIR
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	CreatedAt    int64  `json:"created_at"`
+}
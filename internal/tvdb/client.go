@@ -0,0 +1,155 @@
+package tvdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+const baseURL = "https://api4.thetvdb.com/v4"
+
+// Client is a TheTVDB v4 API client. It logs in lazily and logs in again
+// when the token is rejected (tokens are valid for a month).
+type Client struct {
+	apiKey      string
+	pin         string
+	token       string
+	tokenMu     sync.Mutex
+	httpClient  *http.Client
+	retryClient *utils.RetryableHTTPClient
+}
+
+// NewClient creates a TVDB client. pin is only needed for user-supported
+// (subscriber) API keys.
+func NewClient(apiKey, pin string) *Client {
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	return &Client{
+		apiKey:      apiKey,
+		pin:         pin,
+		httpClient:  httpClient,
+		retryClient: utils.NewRetryableHTTPClient(httpClient, nil),
+	}
+}
+
+func (c *Client) login() (string, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if c.token != "" {
+		return c.token, nil
+	}
+
+	body := map[string]string{"apikey": c.apiKey}
+	if c.pin != "" {
+		body["pin"] = c.pin
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("error encoding login: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", baseURL+"/login", bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.retryClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("tvdb login returned status %d - check TVDB_API_KEY/TVDB_PIN", resp.StatusCode)
+	}
+
+	var login loginResponse
+	if err := json.NewDecoder(resp.Body).Decode(&login); err != nil {
+		return "", fmt.Errorf("error decoding login: %w", err)
+	}
+	c.token = login.Data.Token
+	return c.token, nil
+}
+
+// getJSON performs an authenticated GET and decodes the response into out,
+// logging in again once if the token has expired.
+func (c *Client) getJSON(endpoint string, out interface{}) error {
+	for attempt := 0; attempt < 2; attempt++ {
+		token, err := c.login()
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequest("GET", baseURL+endpoint, nil)
+		if err != nil {
+			return fmt.Errorf("error creating request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := c.retryClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("error making request: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			resp.Body.Close()
+			c.tokenMu.Lock()
+			c.token = ""
+			c.tokenMu.Unlock()
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("tvdb API returned status %d for %s", resp.StatusCode, endpoint)
+		}
+
+		err = json.NewDecoder(resp.Body).Decode(out)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("error decoding %s: %w", endpoint, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("tvdb API rejected the token for %s", endpoint)
+}
+
+// GetSeries fetches the extended record (genres and tags) for a series.
+func (c *Client) GetSeries(tvdbID string) (*Series, error) {
+	var series seriesResponse
+	if err := c.getJSON(fmt.Sprintf("/series/%s/extended?short=true", tvdbID), &series); err != nil {
+		return nil, err
+	}
+	return &series.Data, nil
+}
+
+// GetSeriesKeywords returns a series' genres followed by its tags,
+// normalized like TMDb keywords.
+func (c *Client) GetSeriesKeywords(tvdbID string) ([]string, error) {
+	series, err := c.GetSeries(tvdbID)
+	if err != nil {
+		return nil, err
+	}
+
+	var keywords []string
+	for _, genre := range series.Genres {
+		keywords = append(keywords, genre.Name)
+	}
+	for _, tag := range series.Tags {
+		keywords = append(keywords, tag.Name)
+	}
+	return utils.NormalizeKeywords(keywords), nil
+}
+
+// TestConnection verifies the API key by logging in.
+func (c *Client) TestConnection() error {
+	_, err := c.login()
+	return err
+}
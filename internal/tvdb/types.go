@@ -0,0 +1,35 @@
+package tvdb
+
+// loginResponse is the response to POST /login
+type loginResponse struct {
+	Data struct {
+		Token string `json:"token"`
+	} `json:"data"`
+}
+
+// Genre represents a TVDB genre
+type Genre struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// Tag represents a TVDB tag option (e.g. tag "Plot", name "Time Travel")
+type Tag struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	TagName string `json:"tagName"`
+}
+
+// Series represents the parts of a TVDB extended series record labelarr uses
+type Series struct {
+	ID     int     `json:"id"`
+	Name   string  `json:"name"`
+	Genres []Genre `json:"genres"`
+	Tags   []Tag   `json:"tags"`
+}
+
+// seriesResponse wraps a series record
+type seriesResponse struct {
+	Data Series `json:"data"`
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// Friday 2026-10-16 14:37
+	from := time.Date(2026, 10, 16, 14, 37, 20, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 3 * * *", time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 16, 14, 45, 0, 0, time.UTC)},
+		{"30 14 * * *", time.Date(2026, 10, 17, 14, 30, 0, 0, time.UTC)},
+		{"0 4 * * sun", time.Date(2026, 10, 18, 4, 0, 0, 0, time.UTC)},
+		{"0 4 * * 7", time.Date(2026, 10, 18, 4, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 2 1 * mon", time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * mon-fri", time.Date(2026, 10, 16, 17, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error = %v", tt.expr, err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "0 3 * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "*/0 * * * *", "0 0 * foo *", "5-1 * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) expected error", expr)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileCache is a concurrency-safe TTL cache persisted as JSON to a single
+// file, for API results worth keeping across restarts (rate-limited or
+// scraped sources). With an empty path it only lives in memory.
+type FileCache[T any] struct {
+	path    string
+	ttl     time.Duration
+	entries map[string]fileCacheEntry[T]
+	mu      sync.Mutex
+
+	// Batched saves, see SetFileCacheBatching
+	saveItems    int
+	saveInterval time.Duration
+	pending      int
+	timer        *time.Timer
+	timerErr     error
+}
+
+type fileCacheEntry[T any] struct {
+	Value   T         `json:"value"`
+	Fetched time.Time `json:"fetched"`
+}
+
+type batchedCache interface {
+	setBatching(items int, interval time.Duration)
+	Flush() error
+}
+
+// fileCaches holds every persisted FileCache, so their batching can be set
+// and their pending changes flushed in one place.
+var (
+	fileCachesMu      sync.Mutex
+	fileCaches        []batchedCache
+	fileCacheItems    = 1
+	fileCacheInterval time.Duration
+)
+
+// SetFileCacheBatching buffers changes to every FileCache, existing and
+// created later, like storage.SetBatching: a cache file is saved once items
+// changes are pending, or interval after the first of them. Callers must
+// FlushFileCaches before exiting. items <= 1 saves every change, the default.
+func SetFileCacheBatching(items int, interval time.Duration) {
+	fileCachesMu.Lock()
+	defer fileCachesMu.Unlock()
+	fileCacheItems, fileCacheInterval = items, interval
+	for _, cache := range fileCaches {
+		cache.setBatching(items, interval)
+	}
+}
+
+// FlushFileCaches writes the pending changes of every FileCache to disk.
+func FlushFileCaches() error {
+	fileCachesMu.Lock()
+	defer fileCachesMu.Unlock()
+	var errs []error
+	for _, cache := range fileCaches {
+		errs = append(errs, cache.Flush())
+	}
+	return errors.Join(errs...)
+}
+
+// NewFileCache loads the cache from path if it exists. An unreadable file is
+// reported and replaced rather than treated as fatal.
+func NewFileCache[T any](path string, ttl time.Duration) *FileCache[T] {
+	c := &FileCache[T]{
+		path:    path,
+		ttl:     ttl,
+		entries: make(map[string]fileCacheEntry[T]),
+	}
+	if path == "" {
+		return c
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &c.entries); err != nil {
+			fmt.Printf("[WARN] Ignoring unreadable cache %s: %v\n", path, err)
+			c.entries = make(map[string]fileCacheEntry[T])
+		}
+	}
+
+	fileCachesMu.Lock()
+	c.saveItems, c.saveInterval = fileCacheItems, fileCacheInterval
+	fileCaches = append(fileCaches, c)
+	fileCachesMu.Unlock()
+	return c
+}
+
+// Get returns the cached value for key if it is younger than the TTL.
+func (c *FileCache[T]) Get(key string) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.Fetched) >= c.ttl {
+		var zero T
+		return zero, false
+	}
+	return entry.Value, true
+}
+
+// Set stores value under key and writes the cache to disk, or schedules
+// the write when batching is on.
+func (c *FileCache[T]) Set(key string, value T) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = fileCacheEntry[T]{Value: value, Fetched: time.Now()}
+	if c.path == "" {
+		return nil
+	}
+	return c.changed()
+}
+
+// Put stores value under key like Set, but leaves the write to the next
+// Flush, for callers that add many entries in one go.
+func (c *FileCache[T]) Put(key string, value T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = fileCacheEntry[T]{Value: value, Fetched: time.Now()}
+	if c.path != "" {
+		c.pending++
+	}
+}
+
+// Flush writes the cache to disk if it has unsaved changes.
+func (c *FileCache[T]) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == 0 {
+		return nil
+	}
+	return c.save()
+}
+
+func (c *FileCache[T]) setBatching(items int, interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.saveItems, c.saveInterval = items, interval
+}
+
+// changed records a change and saves the file if the batch is full, like
+// storage's changed.
+func (c *FileCache[T]) changed() error {
+	c.pending++
+	if c.saveItems <= 1 || c.pending >= c.saveItems {
+		c.timerErr = nil
+		return c.save()
+	}
+	if c.timer == nil && c.saveInterval > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(c.saveInterval, func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			// A save since the timer fired replaced or stopped it
+			if c.timer != timer {
+				return
+			}
+			c.timer = nil
+			if err := c.save(); err != nil {
+				c.timerErr = err
+			}
+		})
+		c.timer = timer
+	}
+	if err := c.timerErr; err != nil {
+		c.timerErr = nil
+		return fmt.Errorf("timed save of %s failed: %w", c.path, err)
+	}
+	return nil
+}
+
+// save writes the entries to a temp file and renames it over the cache.
+func (c *FileCache[T]) save() error {
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	tempFile := c.path + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tempFile, c.path); err != nil {
+		return err
+	}
+
+	c.pending = 0
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	return nil
+}
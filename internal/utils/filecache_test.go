@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileCacheBatching(t *testing.T) {
+	defer SetFileCacheBatching(1, 0)
+	SetFileCacheBatching(3, time.Hour)
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+	cache := NewFileCache[string](path, time.Hour)
+	for _, key := range []string{"a", "b"} {
+		if err := cache.Set(key, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := NewFileCache[string](path, time.Hour).Get("a"); ok {
+		t.Error("cache saved before the batch was full")
+	}
+
+	if err := FlushFileCaches(); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "b"} {
+		if got, ok := NewFileCache[string](path, time.Hour).Get(key); !ok || got != key {
+			t.Errorf("after flush Get(%q) = %q, %v, want %q, true", key, got, ok, key)
+		}
+	}
+}
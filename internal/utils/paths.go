@@ -0,0 +1,24 @@
+package utils
+
+import "strings"
+
+// RewritePath replaces the longest matching prefix in mappings (from ->
+// to) so paths reported by Plex can be used from another container or
+// host. A prefix only matches whole path components. Paths without a
+// matching prefix are returned unchanged.
+func RewritePath(path string, mappings map[string]string) string {
+	bestFrom, bestTo := "", ""
+	for from, to := range mappings {
+		from = strings.TrimRight(from, "/\\")
+		if from == "" || len(from) <= len(bestFrom) {
+			continue
+		}
+		if path == from || strings.HasPrefix(path, from+"/") || strings.HasPrefix(path, from+"\\") {
+			bestFrom, bestTo = from, to
+		}
+	}
+	if bestFrom == "" {
+		return path
+	}
+	return strings.TrimRight(bestTo, "/\\") + path[len(bestFrom):]
+}
@@ -0,0 +1,24 @@
+package utils
+
+import "testing"
+
+func TestRewritePath(t *testing.T) {
+	mappings := map[string]string{
+		"/data":         "/mnt/user/data",
+		"/data/movies/": "/mnt/movies",
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/data/movies/Heat (1995)/Heat.mkv", "/mnt/movies/Heat (1995)/Heat.mkv"},
+		{"/data/tv/Show/S01E01.mkv", "/mnt/user/data/tv/Show/S01E01.mkv"},
+		{"/database/file.mkv", "/database/file.mkv"},
+		{"/other/file.mkv", "/other/file.mkv"},
+	}
+	for _, tt := range tests {
+		if got := RewritePath(tt.path, mappings); got != tt.want {
+			t.Errorf("RewritePath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
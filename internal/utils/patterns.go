@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// PatternList matches strings case-insensitively against a list of
+// patterns. Each pattern is an exact name, a glob when it contains *, ? or
+// [ (e.g. "based on *"), or a regular expression between slashes (e.g.
+// "/^woman director$/").
+type PatternList struct {
+	exact   map[string]bool
+	globs   []string
+	regexps []*regexp.Regexp
+}
+
+// NewPatternList compiles patterns, failing on the first invalid glob or
+// regular expression. Empty patterns are ignored.
+func NewPatternList(patterns []string) (*PatternList, error) {
+	l := &PatternList{exact: make(map[string]bool)}
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		switch {
+		case pattern == "":
+			continue
+		case len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/"):
+			re, err := regexp.Compile("(?i)" + pattern[1:len(pattern)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+			}
+			l.regexps = append(l.regexps, re)
+		case strings.ContainsAny(pattern, "*?["):
+			glob := strings.ToLower(pattern)
+			if _, err := path.Match(glob, ""); err != nil {
+				return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+			}
+			l.globs = append(l.globs, glob)
+		default:
+			l.exact[strings.ToLower(pattern)] = true
+		}
+	}
+	return l, nil
+}
+
+// Len returns the number of patterns in the list. A nil list is empty.
+func (l *PatternList) Len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.exact) + len(l.globs) + len(l.regexps)
+}
+
+// Match reports whether s matches any pattern in the list. A nil list
+// matches nothing.
+func (l *PatternList) Match(s string) bool {
+	if l == nil {
+		return false
+	}
+	lower := strings.ToLower(s)
+	if l.exact[lower] {
+		return true
+	}
+	for _, glob := range l.globs {
+		if ok, _ := path.Match(glob, lower); ok {
+			return true
+		}
+	}
+	for _, re := range l.regexps {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package utils
+
+import "testing"
+
+func TestPatternList(t *testing.T) {
+	list, err := NewPatternList([]string{"Sequel", "based on *", "/^duringcreditsstinger$|^aftercreditsstinger$/", " "})
+	if err != nil {
+		t.Fatalf("NewPatternList() error = %v", err)
+	}
+	if list.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", list.Len())
+	}
+
+	tests := []struct {
+		keyword string
+		want    bool
+	}{
+		{"sequel", true},
+		{"Sequels", false},
+		{"Based on Novel or Book", true},
+		{"novel based on", false},
+		{"AfterCreditsStinger", true},
+		{"stinger", false},
+	}
+	for _, tt := range tests {
+		if got := list.Match(tt.keyword); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.keyword, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"/(unclosed/", "[a-"} {
+		if _, err := NewPatternList([]string{bad}); err == nil {
+			t.Errorf("Expected error for pattern %q", bad)
+		}
+	}
+}
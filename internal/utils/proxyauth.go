@@ -0,0 +1,26 @@
+package utils
+
+import "net/http"
+
+// ProxyAuth holds the extra credentials a reverse proxy (SWAG, Authelia,
+// Traefik forward auth, ...) may demand in front of an API that is otherwise
+// authenticated by key.
+type ProxyAuth struct {
+	Headers  map[string]string
+	Username string
+	Password string
+}
+
+// Apply sets the headers and, when a username is configured, HTTP basic auth
+// on req. A nil ProxyAuth leaves the request untouched.
+func (a *ProxyAuth) Apply(req *http.Request) {
+	if a == nil {
+		return
+	}
+	for name, value := range a.Headers {
+		req.Header.Set(name, value)
+	}
+	if a.Username != "" {
+		req.SetBasicAuth(a.Username, a.Password)
+	}
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	l := NewRateLimiter(2)
+	now := l.last
+
+	// Burst of ceil(rate) tokens is available immediately
+	for i := 0; i < 2; i++ {
+		if d := l.reserve(now); d != 0 {
+			t.Fatalf("reserve %d: expected no wait within burst, got %v", i, d)
+		}
+	}
+	if d := l.reserve(now); d != 500*time.Millisecond {
+		t.Errorf("expected 500ms wait once the bucket is empty, got %v", d)
+	}
+	if d := l.reserve(now.Add(500 * time.Millisecond)); d != 0 {
+		t.Errorf("expected a token after refilling for 500ms, got wait %v", d)
+	}
+}
+
+func TestRateLimiterPause(t *testing.T) {
+	l := NewRateLimiter(0)
+	if d := l.reserve(time.Now()); d != 0 {
+		t.Errorf("expected unlimited limiter not to wait, got %v", d)
+	}
+
+	l.Pause(time.Minute)
+	if d := l.reserve(time.Now()); d <= 0 {
+		t.Error("expected a paused limiter to ask callers to wait")
+	}
+}
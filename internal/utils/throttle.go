@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// AdaptiveDelay is a per-item delay that follows a server's health. It keeps
+// a fast and a slow moving average of response latency: when recent
+// responses are clearly slower than the long-run baseline, or a request
+// fails, the delay grows; while latency is at or below the baseline it
+// shrinks towards zero. It is safe for concurrent use.
+type AdaptiveDelay struct {
+	mu    sync.Mutex
+	delay time.Duration
+	max   time.Duration
+	fast  float64 // recent latency average, in seconds
+	slow  float64 // long-run latency baseline, in seconds
+}
+
+const (
+	adaptiveFastWeight = 0.3
+	adaptiveSlowWeight = 0.02
+	adaptiveMinBackoff = 250 * time.Millisecond
+)
+
+// NewAdaptiveDelay creates a delay starting at initial and never exceeding
+// max.
+func NewAdaptiveDelay(initial, max time.Duration) *AdaptiveDelay {
+	return &AdaptiveDelay{delay: min(initial, max), max: max}
+}
+
+// Observe records one response. failed is true for transport errors and 5xx
+// responses.
+func (d *AdaptiveDelay) Observe(latency time.Duration, failed bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if failed {
+		d.delay = min(max(d.delay*2, adaptiveMinBackoff), d.max)
+		return
+	}
+
+	seconds := latency.Seconds()
+	if d.slow == 0 {
+		d.fast, d.slow = seconds, seconds
+	}
+	d.fast += adaptiveFastWeight * (seconds - d.fast)
+	d.slow += adaptiveSlowWeight * (seconds - d.slow)
+
+	switch {
+	case d.fast > 1.5*d.slow:
+		d.delay = min(d.delay*5/4+25*time.Millisecond, d.max)
+	case d.fast < 1.1*d.slow:
+		d.delay = d.delay * 9 / 10
+		if d.delay < 10*time.Millisecond {
+			d.delay = 0
+		}
+	}
+}
+
+// Delay returns the current delay.
+func (d *AdaptiveDelay) Delay() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.delay
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveDelay(t *testing.T) {
+	d := NewAdaptiveDelay(500*time.Millisecond, 5*time.Second)
+
+	for range 50 {
+		d.Observe(20*time.Millisecond, false)
+	}
+	if got := d.Delay(); got != 0 {
+		t.Errorf("steady latency: Delay() = %v, want 0", got)
+	}
+
+	for range 10 {
+		d.Observe(400*time.Millisecond, false)
+	}
+	slowed := d.Delay()
+	if slowed == 0 {
+		t.Error("latency spike: Delay() = 0, want a backoff")
+	}
+
+	d.Observe(0, true)
+	if got := d.Delay(); got < 2*slowed && got != 5*time.Second {
+		t.Errorf("failure: Delay() = %v, want at least %v", got, 2*slowed)
+	}
+
+	for range 20 {
+		d.Observe(0, true)
+	}
+	if got := d.Delay(); got != 5*time.Second {
+		t.Errorf("repeated failures: Delay() = %v, want the 5s cap", got)
+	}
+}
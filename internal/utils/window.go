@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeWindow is a daily window of local time such as 20:00-23:30. A window
+// whose end is before its start crosses midnight (22:00-06:00).
+type TimeWindow struct {
+	start, end int // minutes since midnight
+}
+
+// TimeWindows is a set of daily windows.
+type TimeWindows []TimeWindow
+
+// ParseTimeWindows parses windows written as "HH:MM-HH:MM".
+func ParseTimeWindows(specs []string) (TimeWindows, error) {
+	var windows TimeWindows
+	for _, spec := range specs {
+		from, to, ok := strings.Cut(strings.TrimSpace(spec), "-")
+		if !ok {
+			return nil, fmt.Errorf("time window %q must look like 20:00-23:30", spec)
+		}
+		start, err := parseClock(from)
+		if err != nil {
+			return nil, fmt.Errorf("time window %q: %w", spec, err)
+		}
+		end, err := parseClock(to)
+		if err != nil {
+			return nil, fmt.Errorf("time window %q: %w", spec, err)
+		}
+		if start == end {
+			return nil, fmt.Errorf("time window %q is empty", spec)
+		}
+		windows = append(windows, TimeWindow{start: start, end: end})
+	}
+	return windows, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// contains reports whether t falls inside the window, returning when the
+// window closes if so.
+func (w TimeWindow) contains(t time.Time) (time.Time, bool) {
+	minute := t.Hour()*60 + t.Minute()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch {
+	case w.start < w.end && minute >= w.start && minute < w.end:
+		return midnight.Add(time.Duration(w.end) * time.Minute), true
+	case w.start > w.end && minute >= w.start:
+		return midnight.AddDate(0, 0, 1).Add(time.Duration(w.end) * time.Minute), true
+	case w.start > w.end && minute < w.end:
+		return midnight.Add(time.Duration(w.end) * time.Minute), true
+	}
+	return time.Time{}, false
+}
+
+// Remaining returns how long until t is outside every window, or 0 if it
+// already is. Overlapping and back-to-back windows are waited out together.
+func (ws TimeWindows) Remaining(t time.Time) time.Duration {
+	end := t
+	for range len(ws) + 1 {
+		moved := false
+		for _, w := range ws {
+			if closes, ok := w.contains(end); ok {
+				end, moved = closes, true
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+	return end.Sub(t)
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeWindowsRemaining(t *testing.T) {
+	windows, err := ParseTimeWindows([]string{"20:00-23:30", "23:30-01:00", "06:00-07:00"})
+	if err != nil {
+		t.Fatalf("ParseTimeWindows() error = %v", err)
+	}
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 16, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Duration
+	}{
+		{"before any window", at(19, 59), 0},
+		{"inside chained windows", at(21, 0), 4 * time.Hour},
+		{"after midnight", at(0, 30), 30 * time.Minute},
+		{"window end is exclusive", at(7, 0), 0},
+		{"inside morning window", at(6, 15), 45 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := windows.Remaining(tt.t); got != tt.want {
+				t.Errorf("Remaining(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeWindowsInvalid(t *testing.T) {
+	for _, spec := range []string{"20:00", "25:00-26:00", "8pm-11pm", "20:00-20:00"} {
+		if _, err := ParseTimeWindows([]string{spec}); err == nil {
+			t.Errorf("ParseTimeWindows(%q) expected error", spec)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -18,6 +19,16 @@ import (
 
 const eventLibraryNew = "library.new"
 
+// Radarr and Sonarr send eventType "Download" for both imports and upgrades.
+const (
+	arrEventDownload = "Download"
+	arrEventTest     = "Test"
+)
+
+// arrLocateAttempts is how many times an imported item is looked up in the
+// media server, WEBHOOK_DEBOUNCE apart, before giving up on it.
+const arrLocateAttempts = 5
+
 // PlexWebhookPayload matches the Plex webhook JSON structure.
 // Plex sends this as the "payload" field in a multipart/form-data POST.
 // Requires Plex Pass on the server.
@@ -51,6 +62,29 @@ type PlexWebhookPayload struct {
 	} `json:"Metadata"`
 }
 
+// ArrWebhookPayload is the part of a Radarr or Sonarr webhook that labelarr
+// reads. Radarr fills Movie and MovieFile, Sonarr fills Series.
+type ArrWebhookPayload struct {
+	EventType string `json:"eventType"`
+	IsUpgrade bool   `json:"isUpgrade"`
+	Movie     *struct {
+		Title  string `json:"title"`
+		Year   int    `json:"year"`
+		TMDbID int    `json:"tmdbId"`
+		IMDbID string `json:"imdbId"`
+	} `json:"movie"`
+	MovieFile *struct {
+		Path string `json:"path"`
+	} `json:"movieFile"`
+	Series *struct {
+		Title  string `json:"title"`
+		Year   int    `json:"year"`
+		TVDbID int    `json:"tvdbId"`
+		TMDbID int    `json:"tmdbId"`
+		IMDbID string `json:"imdbId"`
+	} `json:"series"`
+}
+
 type libraryInfo struct {
 	name      string
 	mediaType media.MediaType
@@ -65,10 +99,23 @@ type pendingWork struct {
 	gen         uint64
 }
 
+// pendingImport is a Radarr/Sonarr import waiting out the debounce window.
+// Sonarr sends one event per episode, so a season import collapses into one.
+type pendingImport struct {
+	source    string
+	mediaType media.MediaType
+	arr       media.ArrItem
+	timer     *time.Timer
+	gen       uint64
+}
+
 // Scanner kicks off full or per-library scan cycles. Implemented by main.
 type Scanner interface {
 	RunAll()
 	RunLibrary(libraryID, libraryName string, mediaType media.MediaType) error
+	// Track runs fn as in-flight work that shutdown waits for, or not at
+	// all once shutdown has started.
+	Track(fn func())
 }
 
 type Server struct {
@@ -78,6 +125,7 @@ type Server struct {
 	httpServer *http.Server
 	libraryMap map[string]libraryInfo
 	pending    map[string]*pendingWork
+	imports    map[string]*pendingImport
 	pendingMu  sync.Mutex
 	scanMu     sync.Mutex
 	scanning   bool
@@ -98,17 +146,20 @@ func NewServer(cfg *config.Config, proc *media.Processor, movieLibs, tvLibs []pl
 		scanner:    scanner,
 		libraryMap: libMap,
 		pending:    make(map[string]*pendingWork),
+		imports:    make(map[string]*pendingImport),
 	}
 }
 
 func (s *Server) Start() error {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/webhook", s.handleWebhook)
+	mux.HandleFunc("/arr", s.handleArrWebhook)
 	mux.HandleFunc("/scan", s.handleScan)
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprint(w, "ok")
 	})
+	mux.HandleFunc("/status", s.handleStatus)
 
 	addr := fmt.Sprintf(":%d", s.config.WebhookPort)
 
@@ -132,6 +183,22 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// handleStatus reports the state of the Radarr and Sonarr instances,
+// including their health warnings, as JSON.
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	statuses := s.processor.ArrStatus()
+	if statuses == nil {
+		statuses = []media.IntegrationStatus{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"integrations": statuses})
+}
+
 func (s *Server) Stop(ctx context.Context) error {
 	if s.httpServer != nil {
 		return s.httpServer.Shutdown(ctx)
@@ -213,6 +280,172 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// handleArrWebhook accepts Radarr/Sonarr "On Import" and "On Upgrade"
+// webhooks and processes just the imported movie or show once the media
+// server lists it.
+func (s *Server) handleArrWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload ArrWebhookPayload
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&payload); err != nil {
+		fmt.Printf("[WEBHOOK] 400 InvalidArrPayload: content-type=%q err=%v\n", r.Header.Get("Content-Type"), err)
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		source    string
+		mediaType media.MediaType
+		arr       media.ArrItem
+	)
+	switch {
+	case payload.Movie != nil:
+		source, mediaType = "Radarr", media.MediaTypeMovie
+		arr = media.ArrItem{Title: payload.Movie.Title, Year: payload.Movie.Year, TMDbID: payload.Movie.TMDbID, IMDbID: payload.Movie.IMDbID}
+		if payload.MovieFile != nil {
+			arr.Path = payload.MovieFile.Path
+		}
+	case payload.Series != nil:
+		source, mediaType = "Sonarr", media.MediaTypeTV
+		arr = media.ArrItem{Title: payload.Series.Title, Year: payload.Series.Year, TMDbID: payload.Series.TMDbID, TVDbID: payload.Series.TVDbID, IMDbID: payload.Series.IMDbID}
+	}
+
+	if payload.EventType == arrEventTest {
+		fmt.Printf("[WEBHOOK] %s test event received\n", source)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if payload.EventType != arrEventDownload || mediaType == media.MediaTypeUnknown {
+		if s.config.VerboseLogging {
+			fmt.Printf("[WEBHOOK] ignoring %s event %q\n", source, payload.EventType)
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	action := "import"
+	if payload.IsUpgrade {
+		action = "upgrade"
+	}
+	fmt.Printf("[WEBHOOK] %s %s: %s (%d)\n", source, action, arr.Title, arr.Year)
+	s.addPendingImport(source, mediaType, arr)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// arrImportKey identifies the movie or show an import belongs to, by TMDb
+// ID for movies and TVDB ID for shows, falling back to title and year.
+func arrImportKey(mediaType media.MediaType, arr media.ArrItem) string {
+	switch {
+	case mediaType == media.MediaTypeMovie && arr.TMDbID != 0:
+		return fmt.Sprintf("tmdb:%d", arr.TMDbID)
+	case mediaType == media.MediaTypeTV && arr.TVDbID != 0:
+		return fmt.Sprintf("tvdb:%d", arr.TVDbID)
+	}
+	return fmt.Sprintf("%s:%s (%d)", mediaType, strings.ToLower(arr.Title), arr.Year)
+}
+
+// addPendingImport debounces imports of the same movie or show like
+// addPendingItem does for libraries: each event resets the timer, and when
+// it fires the item is located and processed once, using the latest event.
+func (s *Server) addPendingImport(source string, mediaType media.MediaType, arr media.ArrItem) {
+	key := arrImportKey(mediaType, arr)
+	debounce := s.config.WebhookDebounce
+
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	pi, exists := s.imports[key]
+	if exists {
+		pi.timer.Stop()
+		pi.gen++
+		pi.arr = arr
+		if s.config.VerboseLogging {
+			fmt.Printf("[WEBHOOK] reset debounce for %s import of %s\n", source, arr.Title)
+		}
+	} else {
+		pi = &pendingImport{source: source, mediaType: mediaType, arr: arr}
+		s.imports[key] = pi
+	}
+
+	gen := pi.gen
+	pi.timer = time.AfterFunc(debounce, func() {
+		s.pendingMu.Lock()
+		current, ok := s.imports[key]
+		if !ok || current.gen != gen {
+			s.pendingMu.Unlock()
+			return
+		}
+		delete(s.imports, key)
+		s.pendingMu.Unlock()
+
+		s.track(func() {
+			s.processArrImport(current.source, current.mediaType, current.arr)
+		})
+	})
+}
+
+// track runs fn as work shutdown waits for, see Scanner.Track.
+func (s *Server) track(fn func()) {
+	if s.scanner == nil {
+		fn()
+		return
+	}
+	s.scanner.Track(fn)
+}
+
+// processArrImport looks up an imported item in the media server, then
+// processes it alone. The server may need a moment to scan the new file, so
+// the lookup is tried up to arrLocateAttempts times, WEBHOOK_DEBOUNCE apart.
+// Shutdown ends the retries, and the pause between them, early.
+func (s *Server) processArrImport(source string, mediaType media.MediaType, arr media.ArrItem) {
+	var libraryIDs []string
+	for id, info := range s.libraryMap {
+		if info.mediaType == mediaType {
+			libraryIDs = append(libraryIDs, id)
+		}
+	}
+	sort.Strings(libraryIDs)
+
+	stopped := func() bool {
+		if !s.processor.Stopped() {
+			return false
+		}
+		fmt.Printf("[WEBHOOK] shutting down; %s (%d) is left to the next full scan\n", arr.Title, arr.Year)
+		return true
+	}
+
+	for attempt := 1; attempt <= arrLocateAttempts; attempt++ {
+		if attempt > 1 {
+			s.processor.Sleep(s.config.WebhookDebounce)
+		}
+		for _, libraryID := range libraryIDs {
+			if stopped() {
+				return
+			}
+			ratingKey, err := s.processor.FindItem(libraryID, mediaType, arr)
+			if err != nil {
+				fmt.Printf("[WEBHOOK] error searching library %s for %s: %v\n", s.libraryMap[libraryID].name, arr.Title, err)
+				continue
+			}
+			if ratingKey != "" {
+				s.processItems(libraryID, s.libraryMap[libraryID].name, mediaType, []string{ratingKey})
+				return
+			}
+		}
+		if stopped() {
+			return
+		}
+		if s.config.VerboseLogging && attempt < arrLocateAttempts {
+			fmt.Printf("[WEBHOOK] %s (%d) not in the media server yet, retrying in %v\n", arr.Title, arr.Year, s.config.WebhookDebounce)
+		}
+	}
+	fmt.Printf("[WEBHOOK] %s item %s (%d) not found in any library after %d attempts; the next full scan will pick it up\n", source, arr.Title, arr.Year, arrLocateAttempts)
+}
+
 func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -348,11 +581,15 @@ func (s *Server) addPendingItem(libraryID, libraryName string, mediaType media.M
 		delete(s.pending, libraryID)
 		s.pendingMu.Unlock()
 
-		s.processItems(libraryID, libraryName, mediaType, keys)
+		s.track(func() {
+			s.processItems(libraryID, libraryName, mediaType, keys)
+		})
 	})
 }
 
 func (s *Server) processItems(libraryID, libraryName string, mediaType media.MediaType, ratingKeys []string) {
+	s.processor.WaitQuietHours("Webhook processing for " + libraryName)
+
 	if len(ratingKeys) == 0 {
 		fmt.Printf("[WEBHOOK] processing full library %s (no rating keys in events)\n", libraryName)
 		if err := s.processor.ProcessAllItems(libraryID, libraryName, mediaType); err != nil {
@@ -0,0 +1,210 @@
+package wikidata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nullable-eth/labelarr/internal/utils"
+)
+
+const (
+	sparqlURL = "https://query.wikidata.org/sparql"
+
+	// The query service asks clients to stay well below its limits.
+	requestsPerSecond = 1
+)
+
+// Wikidata properties linking an item to its external IDs
+const (
+	PropIMDb      = "P345"
+	PropTMDbMovie = "P4947"
+	PropTMDbTV    = "P4983"
+)
+
+// Award is a well-known award with its Wikidata item and default label
+type Award struct {
+	QID   string
+	Label string
+}
+
+// Awards maps the aliases accepted in WIKIDATA_AWARDS to their Wikidata
+// items. Any award counts whose item is the given one, an instance of it
+// or a subclass of it, so "oscar" matches every Academy Award category.
+var Awards = map[string]Award{
+	"oscar":        {"Q19020", "Academy Award Winner"},
+	"best-picture": {"Q102427", "Best Picture Winner"},
+	"palme-dor":    {"Q179808", "Palme d'Or"},
+	"golden-lion":  {"Q209459", "Golden Lion"},
+	"golden-bear":  {"Q154590", "Golden Bear"},
+	"golden-globe": {"Q1011547", "Golden Globe Winner"},
+	"emmy":         {"Q123737", "Emmy Winner"},
+}
+
+var qidPattern = regexp.MustCompile(`^Q[0-9]+$`)
+
+// ResolveAwards turns WIKIDATA_AWARDS entries (alias or QID -> label, where
+// the label may be empty for aliases) into a QID -> label map.
+func ResolveAwards(entries map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(entries))
+	for key, label := range entries {
+		if award, ok := Awards[strings.ToLower(key)]; ok {
+			if label == "" {
+				label = award.Label
+			}
+			resolved[award.QID] = label
+			continue
+		}
+		if !qidPattern.MatchString(key) {
+			return nil, fmt.Errorf("unknown award %q: use a Wikidata item ID (Q...) or one of %s", key, strings.Join(aliases(), ", "))
+		}
+		if label == "" {
+			return nil, fmt.Errorf("award %s needs a label (%s=Label)", key, key)
+		}
+		resolved[key] = label
+	}
+	return resolved, nil
+}
+
+func aliases() []string {
+	names := make([]string, 0, len(Awards))
+	for name := range Awards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type sparqlResponse struct {
+	Results struct {
+		Bindings []struct {
+			Class struct {
+				Value string `json:"value"`
+			} `json:"class"`
+		} `json:"bindings"`
+	} `json:"results"`
+}
+
+// Client queries the Wikidata SPARQL endpoint. Award data rarely changes,
+// so results are cached for a long time.
+type Client struct {
+	awards     map[string]string // QID -> label
+	httpClient *http.Client
+	limiter    *utils.RateLimiter
+	cache      *utils.FileCache[[]string]
+}
+
+// NewClient creates a Wikidata client labelling the given awards (QID ->
+// label, see ResolveAwards) and caching results in dataDir (in memory if
+// empty) for cacheTTL.
+func NewClient(awards map[string]string, dataDir string, cacheTTL time.Duration) *Client {
+	cachePath := ""
+	if dataDir != "" {
+		cachePath = filepath.Join(dataDir, "wikidata_awards.json")
+	}
+	return &Client{
+		awards:     awards,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		limiter:    utils.NewRateLimiter(requestsPerSecond),
+		cache:      utils.NewFileCache[[]string](cachePath, cacheTTL),
+	}
+}
+
+// GetAwardLabels returns the labels of the configured awards that the film
+// or show with the given external ID has received, sorted. prop is one of
+// the Prop* constants.
+func (c *Client) GetAwardLabels(prop, id string) ([]string, error) {
+	qids := make([]string, 0, len(c.awards))
+	for qid := range c.awards {
+		qids = append(qids, qid)
+	}
+	sort.Strings(qids)
+
+	// The configured awards are part of the key so changing WIKIDATA_AWARDS
+	// doesn't serve results for the old set.
+	cacheKey := prop + ":" + id + "|" + strings.Join(qids, ",")
+	won, ok := c.cache.Get(cacheKey)
+	if !ok {
+		var err error
+		won, err = c.query(awardQuery(prop, id, qids))
+		if err != nil {
+			return nil, err
+		}
+		if err := c.cache.Set(cacheKey, won); err != nil {
+			fmt.Printf("[WARN] Failed to save Wikidata cache: %v\n", err)
+		}
+	}
+
+	var labels []string
+	for _, qid := range won {
+		if label, ok := c.awards[qid]; ok {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+	return labels, nil
+}
+
+// awardQuery builds a SPARQL query returning the award classes (from the
+// given QIDs) that the item identified by prop/id has received.
+func awardQuery(prop, id string, awards []string) string {
+	values := make([]string, len(awards))
+	for i, qid := range awards {
+		values[i] = "wd:" + qid
+	}
+	return fmt.Sprintf(`SELECT DISTINCT ?class WHERE {
+  VALUES ?class { %s }
+  ?item wdt:%s %q .
+  ?item wdt:P166 ?award .
+  ?award (wdt:P31|wdt:P279)* ?class .
+}`, strings.Join(values, " "), prop, id)
+}
+
+func (c *Client) query(sparql string) ([]string, error) {
+	if err := c.limiter.Wait(context.Background()); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", sparqlURL+"?"+url.Values{"query": {sparql}, "format": {"json"}}.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/sparql-results+json")
+	// The query service rejects requests without a descriptive User-Agent.
+	req.Header.Set("User-Agent", "labelarr (https://github.com/nullable-eth/labelarr)")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		c.limiter.Pause(time.Minute)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("wikidata query returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result sparqlResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	won := []string{}
+	for _, binding := range result.Results.Bindings {
+		// Values are entity URIs such as http://www.wikidata.org/entity/Q19020.
+		value := binding.Class.Value
+		won = append(won, value[strings.LastIndex(value, "/")+1:])
+	}
+	return won, nil
+}
@@ -0,0 +1,33 @@
+package wikidata
+
+import "testing"
+
+func TestResolveAwards(t *testing.T) {
+	got, err := ResolveAwards(map[string]string{
+		"Oscar":     "",
+		"palme-dor": "Cannes Winner",
+		"Q1234":     "Custom Award",
+	})
+	if err != nil {
+		t.Fatalf("ResolveAwards() error: %v", err)
+	}
+	want := map[string]string{
+		"Q19020":  "Academy Award Winner",
+		"Q179808": "Cannes Winner",
+		"Q1234":   "Custom Award",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ResolveAwards() = %v, want %v", got, want)
+	}
+	for qid, label := range want {
+		if got[qid] != label {
+			t.Errorf("ResolveAwards()[%s] = %q, want %q", qid, got[qid], label)
+		}
+	}
+
+	for _, bad := range []map[string]string{{"nobel": ""}, {"Q1234": ""}} {
+		if _, err := ResolveAwards(bad); err == nil {
+			t.Errorf("expected error for %v", bad)
+		}
+	}
+}